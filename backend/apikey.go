@@ -0,0 +1,62 @@
+package main
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// runCreateAPIKey implements "coaching-backend create-api-key", which
+// creates a service account key from the command line, for instance the
+// first one when AUTH_REQUIRED is set. It prints the token and returns the
+// process exit code.
+func runCreateAPIKey(ctx context.Context, args []string, stdout, stderr io.Writer, connect func() *services.Service) int {
+	flags := flag.NewFlagSet("create-api-key", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: coaching-backend create-api-key -name NAME -scopes SCOPE,... [-expires DURATION]")
+		fmt.Fprintln(stderr, "Scopes are <resource>:read or <resource>:write, where resource is one of: "+strings.Join(models.ScopeResources, ", "))
+		flags.PrintDefaults()
+	}
+	name := flags.String("name", "", "what the key is for")
+	scopes := flags.String("scopes", "", "comma-separated scopes, like feedback:read,members:write")
+	expires := flags.Duration("expires", 0, "how long the key is valid, like 720h (default: no expiry)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 || *name == "" || *scopes == "" || *expires < 0 {
+		flags.Usage()
+		return 2
+	}
+
+	key := &models.APIKey{Name: *name}
+	for _, scope := range strings.Split(*scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			key.Scopes = append(key.Scopes, scope)
+		}
+	}
+	if *expires > 0 {
+		expiresAt := time.Now().Add(*expires).UTC()
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := connect().CreateAPIKey(services.AsOperator(ctx), key); err != nil {
+		fmt.Fprintln(stderr, err)
+		var serviceErr *services.Error
+		if errors.As(err, &serviceErr) {
+			for _, field := range serviceErr.Fields {
+				fmt.Fprintf(stderr, "  %s %s\n", field.Field, field.Message)
+			}
+		}
+		return 1
+	}
+	fmt.Fprintf(stderr, "Created API key %d (%s). Store the token now; it is not shown again.\n", key.ID, key.Prefix)
+	fmt.Fprintln(stdout, key.Token)
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKeyCommand(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	connected := false
+	connect := func() *services.Service {
+		connected = true
+		return services.New(db)
+	}
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runCreateAPIKey(context.Background(), args, &stdout, &stderr, connect)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("Usage", func(t *testing.T) {
+		code, _, stderr := run("-name", "CI")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "Usage: coaching-backend create-api-key")
+		assert.False(t, connected, "bad arguments do not connect to the database")
+	})
+
+	t.Run("Creates Key", func(t *testing.T) {
+		code, stdout, stderr := run("-name", "CI", "-scopes", "members:read, feedback:write", "-expires", "24h")
+		require.Equal(t, 0, code, stderr)
+		token := strings.TrimSpace(stdout)
+		assert.True(t, strings.HasPrefix(token, models.APIKeyPrefix))
+
+		key, err := services.New(db).AuthenticateAPIKey(t.Context(), token)
+		require.NoError(t, err)
+		assert.Equal(t, "CI", key.Name)
+		assert.Equal(t, models.StringList{"members:read", "feedback:write"}, key.Scopes)
+		require.NotNil(t, key.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), *key.ExpiresAt, time.Minute)
+	})
+
+	t.Run("Invalid Scope", func(t *testing.T) {
+		code, stdout, stderr := run("-name", "CI", "-scopes", "payroll:read")
+		assert.Equal(t, 1, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "scopes must contain only")
+	})
+}
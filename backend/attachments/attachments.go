@@ -0,0 +1,125 @@
+// Package attachments decides which files may be attached to feedback:
+// their size, their type, sniffed from the content, and whether a virus
+// scanner accepts them.
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrTooLarge      = errors.New("attachment is too large")
+	ErrQuotaExceeded = errors.New("attachments exceed the feedback's quota")
+	ErrType          = errors.New("attachment type is not allowed")
+	ErrInfected      = errors.New("attachment was rejected by the virus scanner")
+)
+
+// Scanner checks an upload for malware before it is stored. Scan returns
+// ErrInfected, possibly wrapped, to reject the file; any other error fails
+// the upload.
+type Scanner interface {
+	Scan(ctx context.Context, name string, r io.Reader) error
+}
+
+// NoopScanner accepts every file.
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(ctx context.Context, name string, r io.Reader) error {
+	return nil
+}
+
+// Policy holds the limits uploads are checked against.
+type Policy struct {
+	// MaxFileSize caps each file.
+	MaxFileSize int64
+	// MaxTotalSize caps all attachments of one feedback together.
+	MaxTotalSize int64
+	// AllowedTypes are the content types that may be attached.
+	AllowedTypes []string
+	Scanner      Scanner
+}
+
+// Default is the policy the services apply.
+var Default = Policy{
+	MaxFileSize:  10 << 20,
+	MaxTotalSize: 25 << 20,
+	AllowedTypes: []string{
+		"image/png", "image/jpeg", "image/gif", "image/webp",
+		"application/pdf", "text/plain",
+		"video/mp4", "video/webm",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	},
+	Scanner: NoopScanner{},
+}
+
+// officeTypes are the ZIP-based formats told apart by extension, since
+// their content sniffs as application/zip.
+var officeTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// ContentType sniffs the type of data. Whatever the client declared is
+// ignored, except that the file name tells Office documents apart.
+func ContentType(name string, data []byte) string {
+	sniffed, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	if sniffed == "application/zip" {
+		if t, ok := officeTypes[strings.ToLower(path.Ext(name))]; ok {
+			return t
+		}
+	}
+	return sniffed
+}
+
+// Check validates an upload of data named name and returns its content
+// type. It does not check the quota, which depends on what is stored.
+func (p Policy) Check(ctx context.Context, name string, data []byte) (string, error) {
+	if int64(len(data)) > p.MaxFileSize {
+		return "", ErrTooLarge
+	}
+	contentType := ContentType(name, data)
+	if !slices.Contains(p.AllowedTypes, contentType) {
+		return "", ErrType
+	}
+	if p.Scanner != nil {
+		if err := p.Scanner.Scan(ctx, name, bytes.NewReader(data)); err != nil {
+			return "", err
+		}
+	}
+	return contentType, nil
+}
+
+// Filename cleans a client-supplied file name for storage and for the
+// Content-Disposition header: no directories, no control characters and at
+// most 255 bytes.
+func Filename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
@@ -0,0 +1,118 @@
+package attachments
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func zipped(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("word/document.xml")
+	require.NoError(t, err)
+	f.Write([]byte("<document/>"))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		name, filename string
+		data           []byte
+		want           string
+	}{
+		{"PDF", "notes.txt", []byte("%PDF-1.7\n"), "application/pdf"},
+		{"Text Drops Charset", "notes.txt", []byte("plain notes"), "text/plain"},
+		{"HTML Named As Text", "notes.txt", []byte("<html><script>alert(1)</script>"), "text/html"},
+		{"Word Document", "Plan.DOCX", zipped(t), "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"Plain Zip", "plan.zip", zipped(t), "application/zip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ContentType(tt.filename, tt.data))
+		})
+	}
+}
+
+type scanner struct {
+	err     error
+	scanned string
+}
+
+func (s *scanner) Scan(ctx context.Context, name string, r io.Reader) error {
+	data, _ := io.ReadAll(r)
+	s.scanned = name + ":" + string(data)
+	return s.err
+}
+
+func TestCheck(t *testing.T) {
+	policy := Default
+	policy.MaxFileSize = 16
+
+	t.Run("Allowed", func(t *testing.T) {
+		contentType, err := policy.Check(context.Background(), "a.txt", []byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain", contentType)
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		_, err := policy.Check(context.Background(), "a.txt", []byte(strings.Repeat("x", 17)))
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+
+	t.Run("Type Not Allowed", func(t *testing.T) {
+		_, err := policy.Check(context.Background(), "a.html", []byte("<html></html>"))
+		assert.ErrorIs(t, err, ErrType)
+	})
+
+	t.Run("Scanned", func(t *testing.T) {
+		s := &scanner{}
+		scanned := policy
+		scanned.Scanner = s
+		_, err := scanned.Check(context.Background(), "a.txt", []byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, "a.txt:hello", s.scanned)
+	})
+
+	t.Run("Infected", func(t *testing.T) {
+		scanned := policy
+		scanned.Scanner = &scanner{err: fmt.Errorf("clamd: %w", ErrInfected)}
+		_, err := scanned.Check(context.Background(), "a.txt", []byte("hello"))
+		assert.ErrorIs(t, err, ErrInfected)
+	})
+
+	t.Run("Scanner Failure", func(t *testing.T) {
+		scanned := policy
+		scanned.Scanner = &scanner{err: errors.New("connection refused")}
+		_, err := scanned.Check(context.Background(), "a.txt", []byte("hello"))
+		assert.EqualError(t, err, "connection refused")
+	})
+}
+
+func TestFilename(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"Plain", "screenshot.png", "screenshot.png"},
+		{"Strips Directories", "../../etc/passwd", "passwd"},
+		{"Strips Windows Directories", `C:\Users\ada\plan.docx`, "plan.docx"},
+		{"Strips Control Characters And Quotes", "a\r\nb\".txt", "ab.txt"},
+		{"Empty", "", "attachment"},
+		{"Only Directories", "../", "attachment"},
+		{"Truncates On Rune Boundary", strings.Repeat("é", 200), strings.Repeat("é", 127)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Filename(tt.in))
+		})
+	}
+}
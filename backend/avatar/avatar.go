@@ -0,0 +1,256 @@
+// Package avatar draws default pictures for members and teams without an
+// uploaded one: initials on a coloured square, or a symmetric identicon.
+// The same input always yields the same picture.
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Version changes whenever the drawings change, so cached copies are
+// revalidated.
+const Version = "1"
+
+type Style string
+
+const (
+	Initials  Style = "initials"
+	Identicon Style = "identicon"
+)
+
+// ParseStyle reports whether s names a style.
+func ParseStyle(s string) (Style, bool) {
+	switch Style(s) {
+	case Initials, Identicon:
+		return Style(s), true
+	}
+	return "", false
+}
+
+// background is the identicon's canvas colour.
+var background = color.RGBA{0xf0, 0xf0, 0xf0, 0xff}
+
+// Avatar describes a default picture. Seed picks the colour and the
+// identicon pattern; Text is drawn by the initials style.
+type Avatar struct {
+	Style Style  `json:"style"`
+	Seed  string `json:"seed"`
+	Text  string `json:"text"`
+}
+
+// ForMember returns initials seeded by the email address, which is unique
+// and survives a change of name.
+func ForMember(name, email string) Avatar {
+	text := initialsOf(name)
+	if text == "" {
+		local, _, _ := strings.Cut(email, "@")
+		text = initialsOf(strings.NewReplacer(".", " ", "_", " ", "-", " ", "+", " ").Replace(local))
+	}
+	return Avatar{Style: Initials, Seed: strings.ToLower(email), Text: text}
+}
+
+// ForTeam returns an identicon seeded by the team's name.
+func ForTeam(name string) Avatar {
+	return Avatar{Style: Identicon, Seed: strings.ToLower(name), Text: initialsOf(name)}
+}
+
+// initialsOf returns the upper-cased first letters of the first and last
+// words of name, or "?" for a blank name.
+func initialsOf(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	if len(words) == 0 {
+		return ""
+	}
+	initials := []rune{firstRune(words[0])}
+	if len(words) > 1 {
+		initials = append(initials, firstRune(words[len(words)-1]))
+	}
+	return strings.ToUpper(string(initials))
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+// ETag identifies the drawing of a at the given size and format.
+func (a Avatar) ETag(size int, format string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{Version, string(a.Style), a.Seed, a.Text, fmt.Sprint(size), format}, "\x00")))
+	return fmt.Sprintf(`"%x"`, sum[:12])
+}
+
+// SVG draws a as a size×size SVG document.
+func (a Avatar) SVG(size int) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, size, size, size, size)
+	if a.Style == Identicon {
+		fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, size, size, hex(background))
+		fg := hex(a.color())
+		for _, cell := range a.cells(size) {
+			fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, cell.Min.X, cell.Min.Y, cell.Dx(), cell.Dy(), fg)
+		}
+	} else {
+		fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, size, size, hex(a.color()))
+		fmt.Fprintf(&buf, `<text x="50%%" y="50%%" dy=".35em" text-anchor="middle" fill="#ffffff" font-family="Helvetica, Arial, sans-serif" font-weight="bold" font-size="%d">`, fontSize(size))
+		xml.EscapeText(&buf, []byte(a.text()))
+		buf.WriteString(`</text>`)
+	}
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+// PNG draws a as a size×size PNG image.
+func (a Avatar) PNG(size int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	if a.Style == Identicon {
+		draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+		fg := image.NewUniform(a.color())
+		for _, cell := range a.cells(size) {
+			draw.Draw(img, cell, fg, image.Point{}, draw.Src)
+		}
+	} else {
+		draw.Draw(img, img.Bounds(), image.NewUniform(a.color()), image.Point{}, draw.Src)
+		if err := drawText(img, a.text(), size); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (a Avatar) text() string {
+	if a.Text == "" {
+		return "?"
+	}
+	return a.Text
+}
+
+func (a Avatar) hash() [32]byte {
+	return sha256.Sum256([]byte(a.Seed))
+}
+
+// color derives a saturated, mid-dark colour from the seed, so white
+// initials stay readable.
+func (a Avatar) color() color.RGBA {
+	h := a.hash()
+	hue := float64(uint16(h[0])<<8|uint16(h[1])) / 65536 * 360
+	return hsl(hue, 0.55, 0.45)
+}
+
+// cells returns the filled squares of a 5×5 identicon, mirrored around
+// the middle column, inside a half-cell margin.
+func (a Avatar) cells(size int) []image.Rectangle {
+	const grid = 5
+	h := a.hash()
+	cell := size / (grid + 1)
+	margin := (size - cell*grid) / 2
+
+	var cells []image.Rectangle
+	for row := 0; row < grid; row++ {
+		for col := 0; col < (grid+1)/2; col++ {
+			// Skip the bytes used for the colour.
+			if h[2+row*3+col]&1 == 0 {
+				continue
+			}
+			for _, c := range []int{col, grid - 1 - col} {
+				x, y := margin+c*cell, margin+row*cell
+				cells = append(cells, image.Rect(x, y, x+cell, y+cell))
+				if c == grid-1-c {
+					break
+				}
+			}
+		}
+	}
+	return cells
+}
+
+func fontSize(size int) int {
+	return size * 2 / 5
+}
+
+var (
+	fontOnce sync.Once
+	boldFont *opentype.Font
+	fontErr  error
+)
+
+// drawText centres white text on img.
+func drawText(img *image.RGBA, text string, size int) error {
+	fontOnce.Do(func() {
+		boldFont, fontErr = opentype.Parse(gobold.TTF)
+	})
+	if fontErr != nil {
+		return fontErr
+	}
+	face, err := opentype.NewFace(boldFont, &opentype.FaceOptions{Size: float64(fontSize(size)), DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return err
+	}
+	defer face.Close()
+
+	d := &font.Drawer{Dst: img, Src: image.White, Face: face}
+	width := d.MeasureString(text)
+	capHeight := face.Metrics().CapHeight
+	d.Dot = fixed.Point26_6{
+		X: (fixed.I(size) - width) / 2,
+		Y: (fixed.I(size) + capHeight) / 2,
+	}
+	d.DrawString(text)
+	return nil
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// hsl converts a hue in degrees and saturation and lightness in [0, 1] to
+// an opaque colour.
+func hsl(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.RGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: 0xff,
+	}
+}
@@ -0,0 +1,105 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForMember(t *testing.T) {
+	tests := []struct {
+		name, member, email, text string
+	}{
+		{"First And Last Word", "Ada King Lovelace", "ada@example.com", "AL"},
+		{"Single Word", "ada", "ada@example.com", "A"},
+		{"Punctuation", "  o'Brien, Pat ", "pat@example.com", "OP"},
+		{"Non-Latin", "Élodie Ünal", "e@example.com", "ÉÜ"},
+		{"Falls Back To Email", "", "grace.hopper@example.com", "GH"},
+		{"Nothing Usable", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := ForMember(tt.member, tt.email)
+			assert.Equal(t, Initials, a.Style)
+			assert.Equal(t, tt.text, a.Text)
+		})
+	}
+
+	t.Run("Seeded By Email", func(t *testing.T) {
+		assert.Equal(t, ForMember("Ada", "ADA@example.com").color(), ForMember("Ada Lovelace", "ada@example.com").color())
+		assert.NotEqual(t, ForMember("Ada", "ada@example.com").color(), ForMember("Ada", "ada2@example.com").color())
+	})
+}
+
+func TestSVG(t *testing.T) {
+	t.Run("Initials", func(t *testing.T) {
+		svg := string(ForMember("Ada Lovelace", "ada@example.com").SVG(64))
+		assert.Contains(t, svg, `width="64" height="64"`)
+		assert.Contains(t, svg, ">AL</text>")
+	})
+
+	t.Run("Escapes Text", func(t *testing.T) {
+		svg := string(Avatar{Style: Initials, Text: "<&"}.SVG(64))
+		assert.Contains(t, svg, ">&lt;&amp;</text>")
+	})
+
+	t.Run("Blank Name", func(t *testing.T) {
+		assert.Contains(t, string(ForMember("", "").SVG(64)), ">?</text>")
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		assert.Equal(t, ForTeam("Platform").SVG(256), ForTeam("Platform").SVG(256))
+		assert.NotEqual(t, ForTeam("Platform").SVG(256), ForTeam("Mobile").SVG(256))
+	})
+}
+
+func decode(t *testing.T, data []byte) image.Image {
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img
+}
+
+func TestPNG(t *testing.T) {
+	t.Run("Initials", func(t *testing.T) {
+		a := ForMember("Ada Lovelace", "ada@example.com")
+		data, err := a.PNG(256)
+		require.NoError(t, err)
+		img := decode(t, data)
+		assert.Equal(t, image.Rect(0, 0, 256, 256), img.Bounds())
+
+		assert.Equal(t, a.color(), color.RGBAModel.Convert(img.At(0, 0)), "background")
+		white := 0
+		for y := 0; y < 256; y++ {
+			for x := 0; x < 256; x++ {
+				if r, g, b, _ := img.At(x, y).RGBA(); r == 0xffff && g == 0xffff && b == 0xffff {
+					white++
+				}
+			}
+		}
+		assert.Greater(t, white, 1000, "initials are drawn")
+	})
+
+	t.Run("Identicon Is Symmetric", func(t *testing.T) {
+		data, err := ForTeam("Platform").PNG(64)
+		require.NoError(t, err)
+		img := decode(t, data)
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 32; x++ {
+				require.Equal(t, img.At(x, y), img.At(63-x, y), "pixel %d,%d", x, y)
+			}
+		}
+	})
+}
+
+func TestETag(t *testing.T) {
+	a := ForTeam("Platform")
+	assert.Equal(t, a.ETag(64, "png"), ForTeam("Platform").ETag(64, "png"))
+	assert.NotEqual(t, a.ETag(64, "png"), a.ETag(64, "svg"))
+	assert.NotEqual(t, a.ETag(64, "png"), a.ETag(256, "png"))
+	assert.NotEqual(t, a.ETag(64, "png"), ForTeam("Mobile").ETag(64, "png"))
+}
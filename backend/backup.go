@@ -0,0 +1,113 @@
+package main
+
+import (
+	"coaching-backend/services"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// runBackup implements "coaching-backend backup", which writes the
+// database and uploaded files to an archive "coaching-backend restore"
+// loads into any supported database. The archive is written next to FILE
+// and renamed once complete, so a failed backup leaves no partial file.
+func runBackup(ctx context.Context, args []string, stdout, stderr io.Writer, connect func() *services.Service) int {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: coaching-backend backup FILE")
+		fmt.Fprintln(stderr, "Writes every table and uploaded file to a zip archive.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := flags.Arg(0)
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer os.Remove(f.Name())
+	manifest, err := connect().Backup(ctx, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	rows := 0
+	for _, table := range manifest.Tables {
+		rows += table.Rows
+	}
+	for _, key := range manifest.MissingBlobs {
+		fmt.Fprintf(stderr, "missing file %s\n", key)
+	}
+	fmt.Fprintf(stdout, "Backed up %d rows from %d tables and %d files to %s\n", rows, len(manifest.Tables), len(manifest.Blobs), path)
+	return 0
+}
+
+// runRestore implements "coaching-backend restore", which loads an
+// archive written by "coaching-backend backup".
+func runRestore(ctx context.Context, args []string, stdout, stderr io.Writer, connect func() *services.Service) int {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: coaching-backend restore FILE")
+		fmt.Fprintln(stderr, "Loads a backup archive. Records get new IDs unless the database is empty.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := flags.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	report, err := connect().Restore(ctx, f, info.Size())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	rows := 0
+	for _, table := range report.Tables {
+		rows += table.Rows
+	}
+	fmt.Fprintf(stdout, "Restored %d rows and %d files from %s\n", rows, report.Blobs, path)
+	if report.Remapped {
+		fmt.Fprintln(stdout, "The database was not empty, so restored records have new IDs")
+	}
+	if report.Dropped > 0 {
+		fmt.Fprintf(stdout, "Left out %d records whose owner was not in the backup\n", report.Dropped)
+	}
+	if report.DisabledWebhooks > 0 {
+		fmt.Fprintf(stdout, "Disabled %d webhooks; enable them again with PUT /api/webhooks/{id}\n", report.DisabledWebhooks)
+	}
+	return 0
+}
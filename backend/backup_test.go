@@ -0,0 +1,281 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"coaching-backend/blob"
+	"coaching-backend/database"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// environment is a database with its own blob store, like one
+// deployment of the server.
+type environment struct {
+	db      *gorm.DB
+	service *services.Service
+}
+
+func newEnvironment(t *testing.T) environment {
+	db := testutils.SetupTestDB(t)
+	previous := blob.Default
+	blob.Default = &blob.FileStore{Root: t.TempDir()}
+	service := services.New(db)
+	blob.Default = previous
+	return environment{db, service}
+}
+
+func (e environment) run(command func(context.Context, []string, io.Writer, io.Writer, func() *services.Service) int, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := command(context.Background(), args, &stdout, &stderr, func() *services.Service { return e.service })
+	return code, stdout.String(), stderr.String()
+}
+
+func pngOf(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 80, 80))
+	for i := range img.Pix {
+		img.Pix[i] = 0xcc
+	}
+	img.Set(1, 1, color.Black)
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// rewriteArchive copies the archive at path, passing each file's content
+// through edit.
+func rewriteArchive(t *testing.T, path string, edit func(name string, data []byte) []byte) string {
+	r, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer r.Close()
+
+	out := filepath.Join(t.TempDir(), "edited.zip")
+	f, err := os.Create(out)
+	require.NoError(t, err)
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for _, file := range r.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		dst, err := w.Create(file.Name)
+		require.NoError(t, err)
+		dst.Write(edit(file.Name, data))
+	}
+	require.NoError(t, w.Close())
+	return out
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	source := newEnvironment(t)
+	s := source.service
+
+	team := testutils.CreateTestTeam(source.db)
+	member := testutils.CreateTestTeamMember(source.db)
+	former := testutils.CreateTestTeamMember(source.db)
+	_, err := s.AssignMember(ctx, member.ID, team.ID)
+	require.NoError(t, err)
+	_, err = s.SetImage(ctx, models.ImageMember, member.ID, bytes.NewReader(pngOf(t)))
+	require.NoError(t, err)
+	feedback := &models.Feedback{Content: "Thorough reviews", TargetType: "member", TargetID: member.ID}
+	require.NoError(t, s.CreateFeedback(ctx, feedback))
+	attachment, err := s.AddAttachment(ctx, feedback.ID, "notes.txt", strings.NewReader("meeting notes"))
+	require.NoError(t, err)
+	hook := &models.Webhook{URL: "https://hooks.example.com/coaching"}
+	require.NoError(t, s.CreateWebhook(ctx, hook))
+	_, err = s.UpdateNotificationPreferences(ctx, member.ID, func(p *models.NotificationPreferences) error {
+		p.WeeklyDigest = true
+		return nil
+	})
+	require.NoError(t, err)
+	source.db.Create(&models.Notification{MemberID: member.ID, EventKey: "event-1", FeedbackID: feedback.ID, Status: models.NotificationPending})
+	require.NoError(t, s.DeleteMember(ctx, former.ID))
+
+	var sourceMember models.TeamMember
+	source.db.First(&sourceMember, member.ID)
+
+	path := filepath.Join(t.TempDir(), "coaching.zip")
+
+	t.Run("Usage", func(t *testing.T) {
+		code, _, stderr := source.run(runBackup)
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "Usage: coaching-backend backup FILE")
+
+		code, _, stderr = source.run(runRestore, "a.zip", "b.zip")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "Usage: coaching-backend restore FILE")
+	})
+
+	t.Run("Backup", func(t *testing.T) {
+		code, stdout, stderr := source.run(runBackup, path)
+		require.Equal(t, 0, code, stderr)
+		assert.Equal(t, "Backed up 9 rows from 14 tables and 4 files to "+path+"\n", stdout)
+
+		r, err := zip.OpenReader(path)
+		require.NoError(t, err)
+		defer r.Close()
+		var manifest services.BackupManifest
+		for _, f := range r.File {
+			if f.Name == "manifest.json" {
+				rc, _ := f.Open()
+				require.NoError(t, json.NewDecoder(rc).Decode(&manifest))
+				rc.Close()
+			}
+		}
+		assert.Equal(t, services.BackupFormat, manifest.Format)
+		assert.Equal(t, database.SchemaVersion, manifest.SchemaVersion)
+		assert.Len(t, manifest.Tables, len(database.Models())-4, "every table but the outbox, its cursors, sessions and API keys")
+		for _, table := range manifest.Tables {
+			assert.NotEqual(t, "outbox_messages", table.Name, "the outbox is not backed up")
+			assert.Len(t, table.SHA256, 64)
+		}
+		sum := sha256.Sum256([]byte("meeting notes"))
+		assert.Contains(t, manifest.Blobs, services.BackupBlob{
+			Key: attachment.Key, ContentType: attachment.ContentType, Size: 13, SHA256: hex.EncodeToString(sum[:]),
+		})
+	})
+
+	t.Run("Restore Into An Empty Database", func(t *testing.T) {
+		target := newEnvironment(t)
+		code, stdout, stderr := target.run(runRestore, path)
+		require.Equal(t, 0, code, stderr)
+		assert.Equal(t, "Restored 9 rows and 4 files from "+path+"\nDisabled 1 webhooks; enable them again with PUT /api/webhooks/{id}\n", stdout)
+
+		var restored models.TeamMember
+		require.NoError(t, target.db.First(&restored, member.ID).Error)
+		assert.Equal(t, sourceMember.Email, restored.Email)
+		assert.Equal(t, sourceMember.Picture, restored.Picture)
+		assert.Equal(t, team.ID, *restored.TeamID)
+		assert.True(t, sourceMember.CreatedAt.Equal(restored.CreatedAt))
+
+		var change models.AssignmentChange
+		require.NoError(t, target.db.First(&change).Error)
+		assert.Equal(t, member.ID, change.MemberID)
+
+		got, err := target.service.GetAttachment(ctx, feedback.ID, attachment.ID)
+		require.NoError(t, err)
+		body, err := target.service.OpenAttachment(ctx, got)
+		require.NoError(t, err)
+		data, _ := io.ReadAll(body)
+		body.Close()
+		assert.Equal(t, "meeting notes", string(data))
+
+		source, err := target.service.GetImageSource(ctx, models.ImageMember, member.ID)
+		require.NoError(t, err)
+		require.NotNil(t, source.Image)
+		thumbnail, _, err := target.service.OpenImage(ctx, source.Image, 64)
+		require.NoError(t, err)
+		thumbnail.Close()
+
+		var restoredHook models.Webhook
+		require.NoError(t, target.db.First(&restoredHook, hook.ID).Error)
+		assert.Equal(t, hook.Secret, restoredHook.Secret, "secrets are kept")
+		assert.False(t, restoredHook.Active)
+		assert.NotNil(t, restoredHook.DisabledAt)
+
+		var notification models.Notification
+		require.NoError(t, target.db.First(&notification).Error)
+		assert.Equal(t, models.NotificationSkipped, notification.Status)
+
+		prefs, err := target.service.GetNotificationPreferences(ctx, member.ID)
+		require.NoError(t, err)
+		assert.True(t, prefs.WeeklyDigest)
+	})
+
+	t.Run("Restore Into A Database With Records", func(t *testing.T) {
+		target := newEnvironment(t)
+		other := &models.TeamMember{Name: "Grace", Email: "grace@example.com"}
+		require.NoError(t, target.service.CreateMember(ctx, other))
+		target.db.Create(&models.Feedback{Content: "Welcome", TargetType: "member", TargetID: other.ID})
+
+		code, stdout, stderr := target.run(runRestore, path)
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, "The database was not empty, so restored records have new IDs\n")
+		assert.NotContains(t, stdout, "Left out")
+
+		var restored models.TeamMember
+		require.NoError(t, target.db.Where("email = ?", member.Email).First(&restored).Error)
+		assert.NotEqual(t, member.ID, restored.ID)
+		assert.Equal(t, fmt.Sprintf("/api/members/%d/avatar", restored.ID), strings.Split(restored.Picture, "?")[0])
+
+		var restoredFeedback models.Feedback
+		require.NoError(t, target.db.Preload("Attachments").Where("content = ?", feedback.Content).First(&restoredFeedback).Error)
+		assert.Equal(t, restored.ID, restoredFeedback.TargetID)
+		require.Len(t, restoredFeedback.Attachments, 1)
+
+		source, err := target.service.GetImageSource(ctx, models.ImageMember, restored.ID)
+		require.NoError(t, err)
+		require.NotNil(t, source.Image)
+		body, _, err := target.service.OpenImage(ctx, source.Image, 0)
+		require.NoError(t, err, "images are stored under their owner's new ID")
+		body.Close()
+
+		var change models.AssignmentChange
+		require.NoError(t, target.db.First(&change).Error)
+		assert.Equal(t, restored.ID, change.MemberID)
+
+		var count int64
+		target.db.Model(&models.Feedback{}).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Conflicts Roll Back", func(t *testing.T) {
+		target := newEnvironment(t)
+		testutils.CreateTestTeam(target.db)
+
+		code, _, stderr := target.run(runRestore, path)
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "same unique value")
+
+		var count int64
+		target.db.Model(&models.TeamMember{}).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Rejects A Corrupted Archive", func(t *testing.T) {
+		edited := rewriteArchive(t, path, func(name string, data []byte) []byte {
+			if name == "tables/team_members.ndjson" {
+				return bytes.Replace(data, []byte("john"), []byte("jane"), 1)
+			}
+			return data
+		})
+		target := newEnvironment(t)
+		code, _, stderr := target.run(runRestore, edited)
+		assert.Equal(t, 1, code)
+		assert.Equal(t, "Invalid backup: tables/team_members.ndjson does not match its checksum\n", stderr)
+	})
+
+	t.Run("Rejects Another Schema Version", func(t *testing.T) {
+		edited := rewriteArchive(t, path, func(name string, data []byte) []byte {
+			if name == "manifest.json" {
+				return bytes.Replace(data, []byte(`"schema_version": 1`), []byte(`"schema_version": 99`), 1)
+			}
+			return data
+		})
+		target := newEnvironment(t)
+		code, _, stderr := target.run(runRestore, edited)
+		assert.Equal(t, 1, code)
+		assert.Equal(t, "Invalid backup: schema version 99 does not match this version's 1\n", stderr)
+	})
+}
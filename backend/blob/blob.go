@@ -0,0 +1,59 @@
+// Package blob stores uploaded files. Store is implemented by FileStore,
+// which keeps blobs on the local filesystem, and S3Store, which talks to
+// any S3-compatible object storage.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("blob: not found")
+	ErrInvalidKey = errors.New("blob: invalid key")
+)
+
+// Info describes a stored blob.
+type Info struct {
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Store keeps blobs under slash-separated keys such as
+// "avatars/members/1/abc/256.png".
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns ErrNotFound for a missing key. The caller closes the
+	// reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, Info, error)
+	// Delete succeeds for a missing key.
+	Delete(ctx context.Context, key string) error
+}
+
+// Default is the store the services use. main replaces it according to
+// the blob settings.
+var Default Store = &FileStore{Root: "data/blobs"}
+
+// ValidKey reports whether key is a clean relative path that cannot
+// escape the store's root.
+func ValidKey(key string) bool {
+	return key != "" &&
+		!strings.HasPrefix(key, "/") &&
+		!strings.Contains(key, "\\") &&
+		path.Clean(key) == key &&
+		key != "." && !strings.HasPrefix(key, "../") && key != ".."
+}
+
+// contentType guesses a blob's content type from its key's extension.
+func contentType(key string) string {
+	if t := mime.TypeByExtension(path.Ext(key)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
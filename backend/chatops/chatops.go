@@ -0,0 +1,208 @@
+// Package chatops speaks the slash-command protocol shared by Slack and
+// Mattermost, so feedback can be given from team chat with
+// "/kudos @alice great demo", and posts new feedback back to a channel
+// through an incoming webhook in the same message format.
+package chatops
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Slack signs requests with these headers.
+	SignatureHeader = "X-Slack-Signature"
+	TimestampHeader = "X-Slack-Request-Timestamp"
+
+	signatureVersion = "v0"
+)
+
+var (
+	ErrNotConfigured      = errors.New("chatops: no signing secret or token configured")
+	ErrMissingSignature   = errors.New("chatops: request is not signed")
+	ErrSignatureMismatch  = errors.New("chatops: signature does not match")
+	ErrSignatureExpired   = errors.New("chatops: signature timestamp outside tolerance")
+	ErrTokenMismatch      = errors.New("chatops: token does not match")
+	ErrMalformedSignature = errors.New("chatops: malformed signature")
+)
+
+// Verifier authenticates slash command requests. Slack requests are
+// signed with SigningSecret; Mattermost requests carry Token as a form
+// field. Either or both may be set.
+type Verifier struct {
+	SigningSecret string
+	Token         string
+	// Tolerance bounds the age of a Slack signature. Zero means 5 minutes.
+	Tolerance time.Duration
+}
+
+// Verify checks the request's headers and raw form body.
+func (v Verifier) Verify(header http.Header, body []byte, now time.Time) error {
+	if v.SigningSecret == "" && v.Token == "" {
+		return ErrNotConfigured
+	}
+	if signature := header.Get(SignatureHeader); signature != "" && v.SigningSecret != "" {
+		return v.verifySignature(signature, header.Get(TimestampHeader), body, now)
+	}
+	if v.Token != "" {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(form.Get("token")), []byte(v.Token)) != 1 {
+			return ErrTokenMismatch
+		}
+		return nil
+	}
+	return ErrMissingSignature
+}
+
+func (v Verifier) verifySignature(signature, timestamp string, body []byte, now time.Time) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || !strings.HasPrefix(signature, signatureVersion+"=") {
+		return ErrMalformedSignature
+	}
+	tolerance := v.Tolerance
+	if tolerance == 0 {
+		tolerance = 5 * time.Minute
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(v.SigningSecret, timestamp, body))) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// Sign returns the X-Slack-Signature value for body sent at timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Command is a slash command invocation. Slack and Mattermost send the
+// same form fields.
+type Command struct {
+	Command     string `json:"command" form:"command"`
+	Text        string `json:"text" form:"text"`
+	UserID      string `json:"user_id" form:"user_id"`
+	UserName    string `json:"user_name" form:"user_name"`
+	ChannelName string `json:"channel_name" form:"channel_name"`
+	TeamDomain  string `json:"team_domain" form:"team_domain"`
+	ResponseURL string `json:"response_url" form:"response_url"`
+	Token       string `json:"token,omitempty" form:"token"`
+}
+
+// ParseCommand decodes a form-encoded slash command body.
+func ParseCommand(body []byte) (Command, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return Command{}, err
+	}
+	return Command{
+		Command:     form.Get("command"),
+		Text:        form.Get("text"),
+		UserID:      form.Get("user_id"),
+		UserName:    form.Get("user_name"),
+		ChannelName: form.Get("channel_name"),
+		TeamDomain:  form.Get("team_domain"),
+		ResponseURL: form.Get("response_url"),
+		Token:       form.Get("token"),
+	}, nil
+}
+
+var (
+	// Slack escapes mentions as <@U123|alice> and emails as
+	// <mailto:alice@example.com|alice@example.com>.
+	slackUser  = regexp.MustCompile(`^<@[A-Z0-9]+\|([^>]+)>$`)
+	slackEmail = regexp.MustCompile(`^<mailto:([^|>]+)(\|[^>]*)?>$`)
+)
+
+var ErrNoMention = errors.New("chatops: text does not start with a mention")
+
+// ParseKudos splits "@alice great demo" into the mentioned handle or email
+// and the message. Handles lose their "@"; emails are kept whole.
+func ParseKudos(text string) (mention, message string, err error) {
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return "", "", ErrNoMention
+	}
+	first := fields[0]
+	message = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), first))
+
+	switch {
+	case slackUser.MatchString(first):
+		mention = slackUser.FindStringSubmatch(first)[1]
+	case slackEmail.MatchString(first):
+		mention = slackEmail.FindStringSubmatch(first)[1]
+	case strings.HasPrefix(first, "@"):
+		mention = strings.TrimPrefix(first, "@")
+	case strings.Contains(first, "@"):
+		mention = first
+	default:
+		return "", "", ErrNoMention
+	}
+	if mention == "" {
+		return "", "", ErrNoMention
+	}
+	return mention, message, nil
+}
+
+const (
+	InChannel = "in_channel"
+	Ephemeral = "ephemeral"
+)
+
+// Message is a chat message in the format Slack and Mattermost accept both
+// as a slash command response and as an incoming webhook payload.
+type Message struct {
+	ResponseType string `json:"response_type,omitempty"`
+	Text         string `json:"text"`
+}
+
+// Reply is a message only the user who ran the command sees.
+func Reply(text string) Message {
+	return Message{ResponseType: Ephemeral, Text: text}
+}
+
+// Usage explains the command.
+func Usage(command string) Message {
+	if command == "" {
+		command = "/kudos"
+	}
+	return Reply("Usage: `" + command + " @handle message` or `" + command + " name@example.com message`")
+}
+
+// KudosMessage announces feedback given with a slash command.
+func KudosMessage(recipient, sender, content string) Message {
+	text := ":tada: Kudos for *" + recipient + "*"
+	if sender != "" {
+		text += " from @" + sender
+	}
+	return Message{ResponseType: InChannel, Text: text + "\n" + quote(content)}
+}
+
+// FeedbackMessage announces new feedback on any target.
+func FeedbackMessage(targetType, targetName, content string) Message {
+	target := targetType
+	if targetName != "" {
+		target += " *" + targetName + "*"
+	}
+	return Message{Text: ":speech_balloon: New feedback for " + target + "\n" + quote(content)}
+}
+
+func quote(s string) string {
+	return "> " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n> ")
+}
@@ -0,0 +1,187 @@
+package chatops
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Recorded payloads in testdata are signed with this secret and carry
+// this token.
+const (
+	testSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+	slackToken = "xyzz0WbapA4vBCDEFasx0q6G"
+	mmToken    = "q8ef1s4uqjdb8yeb5wcztfk6sr"
+)
+
+type recording struct {
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+func load(t *testing.T, name string) (http.Header, []byte) {
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	var rec recording
+	require.NoError(t, json.Unmarshal(data, &rec))
+
+	header := http.Header{}
+	for k, v := range rec.Headers {
+		header.Set(k, v)
+	}
+	return header, []byte(rec.Body)
+}
+
+func recordedAt(header http.Header) time.Time {
+	unix, _ := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	return time.Unix(unix, 0)
+}
+
+func TestVerify(t *testing.T) {
+	slackHeader, slackBody := load(t, "slack_kudos.json")
+	now := recordedAt(slackHeader).Add(30 * time.Second)
+
+	t.Run("Recorded Slack Signature", func(t *testing.T) {
+		v := Verifier{SigningSecret: testSecret}
+		assert.NoError(t, v.Verify(slackHeader, slackBody, now))
+	})
+
+	t.Run("Tampered Body", func(t *testing.T) {
+		v := Verifier{SigningSecret: testSecret}
+		tampered := append([]byte{}, slackBody...)
+		tampered[len(tampered)-1] = 'x'
+		assert.ErrorIs(t, v.Verify(slackHeader, tampered, now), ErrSignatureMismatch)
+	})
+
+	t.Run("Wrong Secret", func(t *testing.T) {
+		v := Verifier{SigningSecret: "other"}
+		assert.ErrorIs(t, v.Verify(slackHeader, slackBody, now), ErrSignatureMismatch)
+	})
+
+	t.Run("Replayed Too Late", func(t *testing.T) {
+		v := Verifier{SigningSecret: testSecret}
+		assert.ErrorIs(t, v.Verify(slackHeader, slackBody, now.Add(10*time.Minute)), ErrSignatureExpired)
+	})
+
+	t.Run("Unsigned Request Without Token", func(t *testing.T) {
+		v := Verifier{SigningSecret: testSecret}
+		assert.ErrorIs(t, v.Verify(http.Header{}, slackBody, now), ErrMissingSignature)
+	})
+
+	t.Run("Recorded Mattermost Token", func(t *testing.T) {
+		header, body := load(t, "mattermost_kudos.json")
+		assert.NoError(t, Verifier{Token: mmToken}.Verify(header, body, now))
+		assert.ErrorIs(t, Verifier{Token: slackToken}.Verify(header, body, now), ErrTokenMismatch)
+	})
+
+	t.Run("Not Configured", func(t *testing.T) {
+		assert.ErrorIs(t, Verifier{}.Verify(slackHeader, slackBody, now), ErrNotConfigured)
+	})
+}
+
+func TestParseCommand(t *testing.T) {
+	_, body := load(t, "slack_kudos.json")
+
+	cmd, err := ParseCommand(body)
+	require.NoError(t, err)
+	assert.Equal(t, "/kudos", cmd.Command)
+	assert.Equal(t, "bob", cmd.UserName)
+	assert.Equal(t, "general", cmd.ChannelName)
+	assert.Equal(t, "<@U0LAN0Z89|alice> great demo today!\nThe charts were clear.", cmd.Text)
+}
+
+func TestParseKudos(t *testing.T) {
+	tests := []struct {
+		name, text, mention, message string
+		err                          bool
+	}{
+		{"Handle", "@alice great demo", "alice", "great demo", false},
+		{"Slack Mention", "<@U0LAN0Z89|alice> great demo", "alice", "great demo", false},
+		{"Slack Email", "<mailto:carol@example.com|carol@example.com> thanks", "carol@example.com", "thanks", false},
+		{"Plain Email", "carol@example.com  thanks  a lot ", "carol@example.com", "thanks  a lot", false},
+		{"Keeps Line Breaks", "@alice great\nwork", "alice", "great\nwork", false},
+		{"No Message", "@alice", "", "", true},
+		{"No Mention", "great demo", "", "", true},
+		{"Empty", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mention, message, err := ParseKudos(tt.text)
+			if tt.err {
+				assert.ErrorIs(t, err, ErrNoMention)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.mention, mention)
+			assert.Equal(t, tt.message, message)
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	t.Run("Kudos", func(t *testing.T) {
+		msg := KudosMessage("Alice", "bob", "great demo\nclear charts")
+		assert.Equal(t, InChannel, msg.ResponseType)
+		assert.Equal(t, ":tada: Kudos for *Alice* from @bob\n> great demo\n> clear charts", msg.Text)
+	})
+
+	t.Run("Feedback", func(t *testing.T) {
+		msg := FeedbackMessage("team", "Platform", "Shipped on time")
+		assert.Empty(t, msg.ResponseType)
+		assert.Equal(t, ":speech_balloon: New feedback for team *Platform*\n> Shipped on time", msg.Text)
+	})
+
+	t.Run("Usage", func(t *testing.T) {
+		msg := Usage("/praise")
+		assert.Equal(t, Ephemeral, msg.ResponseType)
+		assert.Contains(t, msg.Text, "/praise @handle message")
+	})
+}
+
+func TestPoster(t *testing.T) {
+	bodies := make(chan []byte, 10)
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	poster := NewPoster(server.URL)
+
+	t.Run("Posts New Feedback", func(t *testing.T) {
+		feedback := models.Feedback{Content: "Great demo", TargetType: "member", TargetName: "Alice"}
+		require.NoError(t, poster.Publish(context.Background(), events.Event{Type: events.FeedbackCreated, Data: feedback}))
+
+		var msg Message
+		require.NoError(t, json.Unmarshal(<-bodies, &msg))
+		assert.Equal(t, FeedbackMessage("member", "Alice", "Great demo"), msg)
+	})
+
+	t.Run("Ignores Other Events", func(t *testing.T) {
+		require.NoError(t, poster.Publish(context.Background(), events.Event{Type: events.FeedbackUpdated, Data: models.Feedback{}}))
+		require.NoError(t, poster.Publish(context.Background(), events.Event{Type: events.FeedbackCreated, Data: &models.Feedback{Content: "marker"}}))
+		assert.Contains(t, string(<-bodies), "marker", "only the new feedback is posted")
+	})
+
+	t.Run("Reports Failed Posts", func(t *testing.T) {
+		status = http.StatusServiceUnavailable
+		assert.Error(t, poster.Post(context.Background(), Message{Text: "x"}))
+		<-bodies
+
+		err := poster.Publish(context.Background(), events.Event{ID: 7, Type: events.FeedbackCreated, Data: models.Feedback{Content: "x"}})
+		assert.Error(t, err, "the relay retries the event instead of moving past it")
+		<-bodies
+	})
+}
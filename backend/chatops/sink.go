@@ -0,0 +1,82 @@
+package chatops
+
+import (
+	"bytes"
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Poster is an outbox sink that posts new feedback to a chat channel
+// through an incoming webhook URL.
+//
+// Publish posts synchronously and fails when the post does, so the relay
+// retries the event rather than moving past it. The relay keeps the
+// chat's place apart from the other sinks', so a slow or unavailable
+// chat service only holds up chat messages.
+type Poster struct {
+	URL string
+	// Client defaults to one with a 10 second timeout.
+	Client *http.Client
+}
+
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+func NewPoster(url string) *Poster {
+	return &Poster{URL: url}
+}
+
+func (p *Poster) Name() string {
+	return "chat"
+}
+
+func (p *Poster) Publish(ctx context.Context, e events.Event) error {
+	if e.Type != events.FeedbackCreated {
+		return nil
+	}
+	var feedback models.Feedback
+	switch data := e.Data.(type) {
+	case models.Feedback:
+		feedback = data
+	case *models.Feedback:
+		feedback = *data
+	default:
+		return nil
+	}
+
+	if err := p.Post(ctx, FeedbackMessage(feedback.TargetType, feedback.TargetName, feedback.Content)); err != nil {
+		return fmt.Errorf("chatops: failed to post event %d: %w", e.ID, err)
+	}
+	return nil
+}
+
+// Post sends msg to the incoming webhook.
+func (p *Poster) Post(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("chat responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,396 @@
+// Package config holds the server's settings: where it listens and its
+// timeouts, the database and its connection pool, CORS, and the features
+// that talk to other systems, such as single sign-on, email, directory
+// sync and blob storage. Settings are layered: defaults, then a YAML or
+// TOML file, then environment variables, then command-line flags, each
+// overriding the one before. Secrets may be read from files.
+//
+// Each setting is described by its struct tags: key names it in the file
+// and, prefixed with its section, as a flag (-database.max-open-conns);
+// env names its environment variable; secret settings may also be given
+// as a path to a file with key_file in the file or ENV_FILE in the
+// environment.
+package config
+
+import (
+	"coaching-backend/ratelimit"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDatabaseURL is the MySQL server of the Docker Compose setup.
+const DefaultDatabaseURL = "coaching_user:coaching_password@tcp(mysql:3306)/coaching_db?charset=utf8mb4&parseTime=True&loc=Local"
+
+// Config is the server's settings, in sections.
+type Config struct {
+	Server    Server    `key:"server"`
+	Database  Database  `key:"database"`
+	CORS      CORS      `key:"cors"`
+	App       App       `key:"app"`
+	RateLimit RateLimit `key:"rate_limit"`
+	SSO       SSO       `key:"sso"`
+	SCIM      SCIM      `key:"scim"`
+	LDAP      LDAP      `key:"ldap"`
+	SMTP      SMTP      `key:"smtp"`
+	Chat      Chat      `key:"chat"`
+	Blob      Blob      `key:"blob"`
+	Outbox    Outbox    `key:"outbox"`
+}
+
+// Server is where the server listens and how long it waits on clients.
+type Server struct {
+	Port     int `key:"port" env:"PORT" usage:"HTTP port"`
+	GRPCPort int `key:"grpc_port" env:"GRPC_PORT" usage:"gRPC port"`
+	// GRPCReflection lets clients list the gRPC services, which tools like
+	// grpcurl need. It is off unless asked for.
+	GRPCReflection bool `key:"grpc_reflection" env:"GRPC_REFLECTION" usage:"serve gRPC reflection"`
+	// ReadHeaderTimeout bounds reading a request's headers, ReadTimeout
+	// the whole request and WriteTimeout writing the response; streaming
+	// responses lift the write timeout. IdleTimeout closes idle
+	// keep-alive connections.
+	ReadHeaderTimeout time.Duration `key:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" usage:"time to read request headers"`
+	ReadTimeout       time.Duration `key:"read_timeout" env:"HTTP_READ_TIMEOUT" usage:"time to read a whole request"`
+	WriteTimeout      time.Duration `key:"write_timeout" env:"HTTP_WRITE_TIMEOUT" usage:"time to write a response"`
+	IdleTimeout       time.Duration `key:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" usage:"how long idle keep-alive connections stay open"`
+	// ShutdownTimeout bounds how long a stopping server waits for requests
+	// in flight and background workers to finish.
+	ShutdownTimeout time.Duration `key:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" usage:"time to finish requests and background work when stopping"`
+	// AuthRequired turns away anonymous requests to scoped routes and
+	// gRPC methods.
+	AuthRequired bool `key:"auth_required" env:"AUTH_REQUIRED" usage:"require an API key or a sign-in"`
+	// TrustedProxies are the addresses or networks whose X-Forwarded-For
+	// header names the client; none are trusted unless listed.
+	TrustedProxies    []string `key:"trusted_proxies" env:"TRUSTED_PROXIES" usage:"comma-separated proxy addresses or networks"`
+	MaxBodySize       Size     `key:"max_body_size" env:"MAX_BODY_SIZE" usage:"largest request body, like 1MB"`
+	OpenAPIValidation bool     `key:"openapi_validation" env:"OPENAPI_VALIDATION" usage:"validate requests against the OpenAPI document"`
+}
+
+// Database is the database to connect to, its connection pool and how
+// connecting is retried while the database starts up.
+type Database struct {
+	// URL is a MySQL DSN, or sqlite: followed by a file path.
+	URL             string        `key:"url" env:"DATABASE_URL" secret:"true" usage:"MySQL DSN, or sqlite: and a file path"`
+	MaxOpenConns    int           `key:"max_open_conns" env:"DB_MAX_OPEN_CONNS" usage:"most open connections, 0 for no limit"`
+	MaxIdleConns    int           `key:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" usage:"most idle connections kept"`
+	ConnMaxLifetime time.Duration `key:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" usage:"how long a connection is reused, 0 for ever"`
+	ConnMaxIdleTime time.Duration `key:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" usage:"how long a connection may idle, 0 for ever"`
+	// ConnectRetries is how many times connecting is attempted. The wait
+	// after each failed attempt grows by RetryDelay.
+	ConnectRetries int           `key:"connect_retries" env:"DB_CONNECT_RETRIES" usage:"attempts to connect on startup"`
+	RetryDelay     time.Duration `key:"retry_delay" env:"DB_RETRY_DELAY" usage:"added to the wait after each failed attempt"`
+}
+
+// CORS lists the browser origins allowed to call the API, which also
+// applies to WebSocket upgrades.
+type CORS struct {
+	AllowedOrigins []string      `key:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" usage:"comma-separated origins, like https://coaching.example.com"`
+	MaxAge         time.Duration `key:"max_age" env:"CORS_MAX_AGE" usage:"how long browsers cache preflight responses"`
+}
+
+// App is how the frontend is reached and what the organization is called.
+type App struct {
+	// URL is linked from emails and returned to after signing in.
+	URL              string `key:"url" env:"APP_URL" usage:"frontend URL, like https://coaching.example.com"`
+	OrganizationName string `key:"organization_name" env:"ORGANIZATION_NAME" usage:"display name of feedback's organization target"`
+}
+
+// RateLimit is how many requests clients may make, as limits like 600/1m
+// or off.
+type RateLimit struct {
+	IP     string `key:"ip" env:"RATE_LIMIT_IP" usage:"requests per client address"`
+	APIKey string `key:"api_key" env:"RATE_LIMIT_API_KEY" usage:"requests per API key"`
+	// Groups are group:read=limit or group:write=limit entries, limiting
+	// a scope's routes per client; off sets none.
+	Groups []string `key:"groups" env:"RATE_LIMIT_GROUPS" usage:"comma-separated limits per scope, like feedback:write=60/1m"`
+	// RedisURL names a server to share buckets on; they are kept in
+	// memory without it.
+	RedisURL string `key:"redis_url" env:"RATE_LIMIT_REDIS_URL" secret:"true" usage:"redis:// or rediss:// URL to share buckets on"`
+}
+
+// GroupLimits parses Groups into limits by scope.
+func (r RateLimit) GroupLimits() (map[string]ratelimit.Limit, error) {
+	groups := make(map[string]ratelimit.Limit)
+	if len(r.Groups) == 1 && strings.EqualFold(r.Groups[0], "off") {
+		return groups, nil
+	}
+	for _, entry := range r.Groups {
+		scope, raw, ok := strings.Cut(entry, "=")
+		scope = strings.TrimSpace(scope)
+		if !ok || !strings.HasSuffix(scope, ":read") && !strings.HasSuffix(scope, ":write") {
+			return nil, fmt.Errorf("%q is not group:read=N/period or group:write=N/period", entry)
+		}
+		limit, err := ratelimit.ParseLimit(raw)
+		if err != nil {
+			return nil, err
+		}
+		groups[scope] = limit
+	}
+	return groups, nil
+}
+
+// SSO is the OpenID Connect provider people sign in with. Single sign-on
+// is off without an issuer.
+type SSO struct {
+	Issuer       string `key:"issuer" env:"OIDC_ISSUER" usage:"OpenID Connect provider URL"`
+	ClientID     string `key:"client_id" env:"OIDC_CLIENT_ID" usage:"client registered with the provider"`
+	ClientSecret string `key:"client_secret" env:"OIDC_CLIENT_SECRET" secret:"true" usage:"the client's secret"`
+	RedirectURL  string `key:"redirect_url" env:"OIDC_REDIRECT_URL" usage:"this server's callback, like https://coaching.example.com/api/auth/callback"`
+	// Scopes may also be separated by spaces, as in a scope parameter.
+	Scopes      []string `key:"scopes" env:"OIDC_SCOPES" usage:"scopes to ask for (default: openid email profile)"`
+	GroupsClaim string   `key:"groups_claim" env:"OIDC_GROUPS_CLAIM" usage:"claim listing a person's groups (default: groups)"`
+	AdminGroups []string `key:"admin_groups" env:"OIDC_ADMIN_GROUPS" usage:"comma-separated groups whose members are admins"`
+	// TeamGroups are group=Team entries.
+	TeamGroups []string      `key:"team_groups" env:"OIDC_TEAM_GROUPS" usage:"comma-separated group=Team pairs"`
+	SessionTTL time.Duration `key:"session_ttl" env:"SESSION_TTL" usage:"how long a sign-in lasts"`
+}
+
+// TeamMapping parses TeamGroups into teams by group.
+func (s SSO) TeamMapping() (map[string]string, error) {
+	teams := make(map[string]string)
+	for _, entry := range s.TeamGroups {
+		group, team, ok := strings.Cut(entry, "=")
+		group, team = strings.TrimSpace(group), strings.TrimSpace(team)
+		if !ok || group == "" || team == "" {
+			return nil, fmt.Errorf("%q is not group=Team", entry)
+		}
+		teams[group] = team
+	}
+	return teams, nil
+}
+
+// SCIM is how identity providers provision members. It is off without a
+// token.
+type SCIM struct {
+	Token string `key:"token" env:"SCIM_TOKEN" secret:"true" usage:"bearer token SCIM clients send"`
+}
+
+// LDAP is the directory members are synced from. Sync is off without a
+// URL; empty attributes and filters take the directory package's
+// defaults.
+type LDAP struct {
+	URL                  string        `key:"url" env:"LDAP_URL" usage:"directory URL, like ldaps://ldap.example.com"`
+	BindDN               string        `key:"bind_dn" env:"LDAP_BIND_DN" usage:"DN to bind as"`
+	BindPassword         string        `key:"bind_password" env:"LDAP_BIND_PASSWORD" secret:"true" usage:"password to bind with"`
+	BaseDN               string        `key:"base_dn" env:"LDAP_BASE_DN" usage:"where to look for people"`
+	UserFilter           string        `key:"user_filter" env:"LDAP_USER_FILTER" usage:"which people to sync"`
+	EmailAttribute       string        `key:"email_attribute" env:"LDAP_EMAIL_ATTRIBUTE" usage:"attribute holding email (default: mail)"`
+	NameAttribute        string        `key:"name_attribute" env:"LDAP_NAME_ATTRIBUTE" usage:"attribute holding name (default: displayName)"`
+	TeamAttribute        string        `key:"team_attribute" env:"LDAP_TEAM_ATTRIBUTE" usage:"attribute naming a person's team"`
+	GroupBaseDN          string        `key:"group_base_dn" env:"LDAP_GROUP_BASE_DN" usage:"where to look for team groups (default: base_dn)"`
+	GroupFilter          string        `key:"group_filter" env:"LDAP_GROUP_FILTER" usage:"which groups are teams"`
+	GroupNameAttribute   string        `key:"group_name_attribute" env:"LDAP_GROUP_NAME_ATTRIBUTE" usage:"attribute naming a group's team (default: cn)"`
+	GroupMemberAttribute string        `key:"group_member_attribute" env:"LDAP_GROUP_MEMBER_ATTRIBUTE" usage:"attribute listing a group's people (default: member)"`
+	CreateTeams          bool          `key:"create_teams" env:"LDAP_CREATE_TEAMS" usage:"create teams the directory names"`
+	SyncInterval         time.Duration `key:"sync_interval" env:"LDAP_SYNC_INTERVAL" usage:"how often to sync"`
+}
+
+// SMTP is the mail server notifications and digests are sent through.
+// Email is off without a host.
+type SMTP struct {
+	Host     string        `key:"host" env:"SMTP_HOST" usage:"SMTP server"`
+	Port     int           `key:"port" env:"SMTP_PORT" usage:"SMTP port"`
+	Username string        `key:"username" env:"SMTP_USERNAME" usage:"SMTP user"`
+	Password string        `key:"password" env:"SMTP_PASSWORD" secret:"true" usage:"SMTP password"`
+	From     string        `key:"from" env:"SMTP_FROM" usage:"sender, like Coaching <coaching@example.com>"`
+	Timeout  time.Duration `key:"timeout" env:"SMTP_TIMEOUT" usage:"how long one email may take to send"`
+}
+
+// Chat is where new feedback is posted and how slash commands are
+// verified: Slack signs them with its signing secret, Mattermost sends a
+// per-command token.
+type Chat struct {
+	// WebhookURL is secret, as incoming webhook URLs hold their token.
+	WebhookURL             string `key:"webhook_url" env:"CHAT_WEBHOOK_URL" secret:"true" usage:"incoming webhook new feedback is posted to"`
+	SlackSigningSecret     string `key:"slack_signing_secret" env:"SLACK_SIGNING_SECRET" secret:"true" usage:"verifies Slack slash commands"`
+	MattermostCommandToken string `key:"mattermost_command_token" env:"MATTERMOST_COMMAND_TOKEN" secret:"true" usage:"verifies Mattermost slash commands"`
+}
+
+// Blob is where uploaded images and attachments are stored: in Dir with
+// the file store, or in an S3 bucket.
+type Blob struct {
+	Store string `key:"store" env:"BLOB_STORE" usage:"file or s3"`
+	Dir   string `key:"dir" env:"BLOB_DIR" usage:"directory of the file store"`
+	// S3Endpoint defaults to AWS S3 in S3Region.
+	S3Endpoint        string `key:"s3_endpoint" env:"S3_ENDPOINT" usage:"S3-compatible endpoint, like http://minio:9000"`
+	S3Bucket          string `key:"s3_bucket" env:"S3_BUCKET" usage:"S3 bucket"`
+	S3Region          string `key:"s3_region" env:"S3_REGION" usage:"S3 region"`
+	S3AccessKeyID     string `key:"s3_access_key_id" env:"S3_ACCESS_KEY_ID" usage:"S3 access key"`
+	S3SecretAccessKey string `key:"s3_secret_access_key" env:"S3_SECRET_ACCESS_KEY" secret:"true" usage:"S3 secret key"`
+}
+
+// Outbox is how domain events are relayed.
+type Outbox struct {
+	LogEvents bool `key:"log_events" env:"OUTBOX_LOG_EVENTS" usage:"log every relayed event"`
+}
+
+// Size is a number of bytes, written as a number or with a KB or MB
+// suffix.
+type Size int64
+
+// UnmarshalText parses sizes like 1048576, 512KB or 1MB.
+func (s *Size) UnmarshalText(text []byte) error {
+	raw := strings.ToUpper(strings.TrimSpace(string(text)))
+	unit := int64(1)
+	for suffix, size := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20} {
+		if number, ok := strings.CutSuffix(raw, suffix); ok {
+			raw, unit = strings.TrimSpace(number), size
+		}
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q, want bytes like 1048576 or 1MB", text)
+	}
+	*s = Size(n * unit)
+	return nil
+}
+
+// Default returns the settings used when nothing overrides them.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:              8080,
+			GRPCPort:          9090,
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   20 * time.Second,
+			MaxBodySize:       1 << 20,
+		},
+		Database: Database{
+			URL:             DefaultDatabaseURL,
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectRetries:  30,
+			RetryDelay:      time.Second,
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{"http://localhost:3000", "http://frontend:3000"},
+			MaxAge:         12 * time.Hour,
+		},
+		App: App{OrganizationName: "Organization"},
+		RateLimit: RateLimit{
+			IP:     "600/1m",
+			APIKey: "1200/1m",
+			Groups: []string{"feedback:write=60/1m", "members:read=300/1m"},
+		},
+		SSO:  SSO{SessionTTL: 12 * time.Hour},
+		LDAP: LDAP{SyncInterval: time.Hour},
+		SMTP: SMTP{Port: 587, Timeout: 30 * time.Second},
+		Blob: Blob{Store: "file", Dir: "data/blobs"},
+	}
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Server.Port), "server.port: %d is not a port", c.Server.Port)
+	check(validPort(c.Server.GRPCPort), "server.grpc_port: %d is not a port", c.Server.GRPCPort)
+	check(c.Server.Port != c.Server.GRPCPort, "server.port and server.grpc_port must differ")
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"database.conn_max_lifetime", c.Database.ConnMaxLifetime},
+		{"database.conn_max_idle_time", c.Database.ConnMaxIdleTime},
+		{"database.retry_delay", c.Database.RetryDelay},
+		{"cors.max_age", c.CORS.MaxAge},
+	} {
+		check(d.value >= 0, "%s: must not be negative", d.name)
+	}
+
+	check(strings.TrimSpace(c.Database.URL) != "", "database.url: is required")
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns: must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns: must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns: must not exceed max_open_conns (%d)", c.Database.MaxOpenConns)
+	check(c.Database.ConnectRetries >= 1, "database.connect_retries: must be at least 1")
+
+	for _, proxy := range c.Server.TrustedProxies {
+		_, addrErr := netip.ParseAddr(proxy)
+		_, prefixErr := netip.ParsePrefix(proxy)
+		check(addrErr == nil || prefixErr == nil, "server.trusted_proxies: %q is not an address or network", proxy)
+	}
+	check(c.Server.MaxBodySize > 0, "server.max_body_size: must be positive")
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		u, err := url.Parse(origin)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Path == "" && u.RawQuery == "",
+			"cors.allowed_origins: %q is not an origin like https://coaching.example.com", origin)
+	}
+
+	check(c.App.URL == "" || validURL(c.App.URL), "app.url: %q is not an http or https URL", c.App.URL)
+
+	for _, limit := range []struct {
+		name  string
+		value string
+	}{
+		{"rate_limit.ip", c.RateLimit.IP},
+		{"rate_limit.api_key", c.RateLimit.APIKey},
+	} {
+		_, err := ratelimit.ParseLimit(limit.value)
+		check(err == nil, "%s: %v", limit.name, err)
+	}
+	_, err := c.RateLimit.GroupLimits()
+	check(err == nil, "rate_limit.groups: %v", err)
+	if c.RateLimit.RedisURL != "" {
+		// The error would repeat the URL and its password.
+		_, err := ratelimit.NewClient(c.RateLimit.RedisURL)
+		check(err == nil, "rate_limit.redis_url: is not a redis:// or rediss:// URL")
+	}
+
+	check(c.SSO.SessionTTL > 0, "sso.session_ttl: must be positive")
+	if c.SSO.Issuer != "" {
+		check(validURL(c.SSO.Issuer), "sso.issuer: %q is not an http or https URL", c.SSO.Issuer)
+		check(c.SSO.ClientID != "", "sso.client_id: is required with sso.issuer")
+		check(validURL(c.SSO.RedirectURL), "sso.redirect_url: is required with sso.issuer, as an http or https URL")
+	}
+	_, err = c.SSO.TeamMapping()
+	check(err == nil, "sso.team_groups: %v", err)
+
+	check(c.LDAP.SyncInterval > 0, "ldap.sync_interval: must be positive")
+
+	check(c.SMTP.Host == "" || validPort(c.SMTP.Port), "smtp.port: %d is not a port", c.SMTP.Port)
+	check(c.SMTP.Timeout > 0, "smtp.timeout: must be positive")
+
+	check(c.Chat.WebhookURL == "" || validURL(c.Chat.WebhookURL), "chat.webhook_url: is not an http or https URL")
+
+	switch c.Blob.Store {
+	case "file":
+		check(c.Blob.Dir != "", "blob.dir: is required with the file store")
+	case "s3":
+		check(c.Blob.S3Bucket != "", "blob.s3_bucket: is required with the s3 store")
+		check(c.Blob.S3Region != "", "blob.s3_region: is required with the s3 store")
+		check(c.Blob.S3Endpoint == "" || validURL(c.Blob.S3Endpoint), "blob.s3_endpoint: %q is not an http or https URL", c.Blob.S3Endpoint)
+	default:
+		check(false, "blob.store: %q is not file or s3", c.Blob.Store)
+	}
+	return errors.Join(errs...)
+}
+
+func validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validPort(port int) bool {
+	return port > 0 && port < 65536
+}
@@ -0,0 +1,221 @@
+package config_test
+
+import (
+	"coaching-backend/config"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		cfg, err := config.Load(nil, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, config.Default(), cfg)
+		assert.NoError(t, config.Default().Validate())
+	})
+
+	t.Run("Example File Holds The Defaults", func(t *testing.T) {
+		cfg, err := config.Load([]string{"-config", "../config.example.yaml"}, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, config.Default(), cfg)
+	})
+
+	t.Run("YAML File", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", `
+server:
+  port: 8000
+  write_timeout: 2m
+database:
+  max_open_conns: 50
+cors:
+  allowed_origins:
+    - https://coaching.example.com
+`)
+		cfg, err := config.Load([]string{"-config", path}, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, 8000, cfg.Server.Port)
+		assert.Equal(t, 2*time.Minute, cfg.Server.WriteTimeout)
+		assert.Equal(t, 50, cfg.Database.MaxOpenConns)
+		assert.Equal(t, []string{"https://coaching.example.com"}, cfg.CORS.AllowedOrigins)
+		assert.Equal(t, 9090, cfg.Server.GRPCPort)
+	})
+
+	t.Run("TOML File", func(t *testing.T) {
+		path := writeFile(t, "config.toml", `
+[database]
+connect_retries = 5
+retry_delay = "500ms"
+
+[cors]
+allowed_origins = "https://a.example.com, https://b.example.com"
+`)
+		t.Setenv(config.FileEnv, path)
+		cfg, err := config.Load(nil, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, 5, cfg.Database.ConnectRetries)
+		assert.Equal(t, 500*time.Millisecond, cfg.Database.RetryDelay)
+		assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.CORS.AllowedOrigins)
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "server:\n  port: 8000\n  grpc_port: 9000\ndatabase:\n  max_idle_conns: 5\n")
+		t.Setenv("PORT", "8001")
+		t.Setenv("GRPC_PORT", "9001")
+
+		cfg, err := config.Load([]string{"-config", path, "-server.port", "8002"}, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, 8002, cfg.Server.Port, "flags override the environment")
+		assert.Equal(t, 9001, cfg.Server.GRPCPort, "the environment overrides the file")
+		assert.Equal(t, 5, cfg.Database.MaxIdleConns, "the file overrides the defaults")
+	})
+
+	t.Run("Empty Environment Variables Are Ignored", func(t *testing.T) {
+		t.Setenv("PORT", "")
+		cfg, err := config.Load(nil, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, 8080, cfg.Server.Port)
+	})
+
+	t.Run("Secret From Environment File", func(t *testing.T) {
+		t.Setenv("DATABASE_URL_FILE", writeFile(t, "dsn", "user:secret@tcp(db:3306)/coaching\n"))
+		cfg, err := config.Load(nil, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, "user:secret@tcp(db:3306)/coaching", cfg.Database.URL)
+	})
+
+	t.Run("Secret From Config File", func(t *testing.T) {
+		dsn := writeFile(t, "dsn", "sqlite:/var/lib/coaching.db")
+		path := writeFile(t, "config.yaml", "database:\n  url_file: "+dsn+"\n")
+		cfg, err := config.Load([]string{"-config", path}, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, "sqlite:/var/lib/coaching.db", cfg.Database.URL)
+	})
+
+	t.Run("Secret Set Twice", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "sqlite:a.db")
+		t.Setenv("DATABASE_URL_FILE", writeFile(t, "dsn", "sqlite:b.db"))
+		_, err := config.Load(nil, io.Discard)
+		assert.ErrorContains(t, err, "set DATABASE_URL or DATABASE_URL_FILE, not both")
+	})
+
+	t.Run("Only Secrets Read Files", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "server:\n  port_file: /etc/port\n")
+		_, err := config.Load([]string{"-config", path}, io.Discard)
+		assert.ErrorContains(t, err, "unknown setting server.port_file")
+	})
+
+	t.Run("Unknown Setting", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "database:\n  max_open_connections: 10\n")
+		_, err := config.Load([]string{"-config", path}, io.Discard)
+		assert.ErrorContains(t, err, "unknown setting database.max_open_connections")
+	})
+
+	t.Run("Unknown Format", func(t *testing.T) {
+		path := writeFile(t, "config.json", "{}")
+		_, err := config.Load([]string{"-config", path}, io.Discard)
+		assert.ErrorContains(t, err, "unknown config format")
+	})
+
+	t.Run("Invalid Environment Variable", func(t *testing.T) {
+		t.Setenv("HTTP_READ_TIMEOUT", "30")
+		_, err := config.Load(nil, io.Discard)
+		assert.ErrorContains(t, err, `HTTP_READ_TIMEOUT: invalid duration "30"`)
+	})
+
+	t.Run("Invalid Settings Are All Reported", func(t *testing.T) {
+		_, err := config.Load([]string{
+			"-server.port", "0",
+			"-database.max-open-conns", "5",
+			"-database.max-idle-conns", "10",
+			"-cors.allowed-origins", "coaching.example.com",
+		}, io.Discard)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "server.port: 0 is not a port")
+		assert.ErrorContains(t, err, "database.max_idle_conns: must not exceed max_open_conns (5)")
+		assert.ErrorContains(t, err, `cors.allowed_origins: "coaching.example.com" is not an origin`)
+	})
+
+	t.Run("Feature Settings", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", `
+server:
+  trusted_proxies: [10.0.0.0/8]
+  max_body_size: 2097152
+rate_limit:
+  groups: off
+smtp:
+  host: mail.example.com
+  password_file: `+writeFile(t, "smtp", "hunter2\n")+`
+blob:
+  store: s3
+  s3_bucket: coaching
+  s3_region: eu-west-1
+`)
+		t.Setenv("MAX_BODY_SIZE", "512KB")
+		t.Setenv("OIDC_CLIENT_SECRET_FILE", writeFile(t, "oidc", "client-secret"))
+		t.Setenv("SCIM_TOKEN", "scim-token")
+		cfg, err := config.Load([]string{"-config", path, "-sso.admin-groups", "admins, it"}, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.0/8"}, cfg.Server.TrustedProxies)
+		assert.Equal(t, config.Size(512<<10), cfg.Server.MaxBodySize)
+		assert.Equal(t, "hunter2", cfg.SMTP.Password)
+		assert.Equal(t, 587, cfg.SMTP.Port)
+		assert.Equal(t, "client-secret", cfg.SSO.ClientSecret)
+		assert.Equal(t, "scim-token", cfg.SCIM.Token)
+		assert.Equal(t, []string{"admins", "it"}, cfg.SSO.AdminGroups)
+		assert.Equal(t, "s3", cfg.Blob.Store)
+		groups, err := cfg.RateLimit.GroupLimits()
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+	})
+
+	t.Run("Sizes", func(t *testing.T) {
+		for raw, want := range map[string]config.Size{"4096": 4096, "512KB": 512 << 10, "2 mb": 2 << 20} {
+			var size config.Size
+			require.NoError(t, size.UnmarshalText([]byte(raw)), raw)
+			assert.Equal(t, want, size, raw)
+		}
+		t.Setenv("MAX_BODY_SIZE", "big")
+		_, err := config.Load(nil, io.Discard)
+		assert.ErrorContains(t, err, `MAX_BODY_SIZE: invalid size "big"`)
+	})
+
+	t.Run("Invalid Feature Settings Are Reported", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_REDIS_URL", "redis-password@localhost:6379")
+		_, err := config.Load([]string{
+			"-server.trusted-proxies", "proxy.internal",
+			"-rate-limit.ip", "lots",
+			"-rate-limit.groups", "feedback=10/1m",
+			"-sso.issuer", "https://login.example.com",
+			"-sso.team-groups", "Platform",
+			"-blob.store", "s3",
+		}, io.Discard)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, `server.trusted_proxies: "proxy.internal" is not an address or network`)
+		assert.ErrorContains(t, err, "rate_limit.ip: invalid rate limit")
+		assert.ErrorContains(t, err, `rate_limit.groups: "feedback=10/1m" is not group:read=N/period`)
+		assert.ErrorContains(t, err, "rate_limit.redis_url: is not a redis:// or rediss:// URL")
+		assert.NotContains(t, err.Error(), "redis-password", "secrets stay out of errors")
+		assert.ErrorContains(t, err, "sso.client_id: is required with sso.issuer")
+		assert.ErrorContains(t, err, `sso.team_groups: "Platform" is not group=Team`)
+		assert.ErrorContains(t, err, "blob.s3_bucket: is required with the s3 store")
+	})
+
+	t.Run("Help", func(t *testing.T) {
+		_, err := config.Load([]string{"-h"}, io.Discard)
+		assert.ErrorIs(t, err, flag.ErrHelp)
+	})
+}
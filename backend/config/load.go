@@ -0,0 +1,298 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the config file when the -config flag does not.
+const FileEnv = "CONFIG_FILE"
+
+// setting is one leaf of Config.
+type setting struct {
+	// name is section.key, as in the file.
+	name   string
+	key    string
+	env    string
+	secret bool
+	usage  string
+	value  reflect.Value
+}
+
+func (s setting) flagName() string {
+	return strings.ReplaceAll(s.name, "_", "-")
+}
+
+// settings lists the settings of cfg, whose fields they set.
+func settings(cfg *Config) []setting {
+	var list []setting
+	sections := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Type().Field(i).Tag.Get("key")
+		fields := sections.Field(i)
+		for j := 0; j < fields.NumField(); j++ {
+			field := fields.Type().Field(j)
+			key := field.Tag.Get("key")
+			list = append(list, setting{
+				name:   section + "." + key,
+				key:    key,
+				env:    field.Tag.Get("env"),
+				secret: field.Tag.Get("secret") == "true",
+				usage:  field.Tag.Get("usage"),
+				value:  fields.Field(j),
+			})
+		}
+	}
+	return list
+}
+
+// Load builds the configuration from the defaults, the config file, the
+// environment and the flags in args, each overriding the one before, and
+// validates it. The file is named by the -config flag, or else by
+// CONFIG_FILE; without either there is none. Flag errors and usage are
+// written to output; asking for help returns flag.ErrHelp.
+func Load(args []string, output io.Writer) (*Config, error) {
+	cfg := Default()
+	list := settings(cfg)
+
+	flags := flag.NewFlagSet("coaching-backend", flag.ContinueOnError)
+	flags.SetOutput(output)
+	file := flags.String("config", "", "YAML or TOML file to read settings from (default: $"+FileEnv+")")
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var flagValues []flagValue
+	for _, s := range list {
+		flags.Func(s.flagName(), s.usage+" ($"+s.env+")", func(value string) error {
+			flagValues = append(flagValues, flagValue{s, value})
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	path := *file
+	if path == "" {
+		path = os.Getenv(FileEnv)
+	}
+	if path != "" {
+		if err := loadFile(path, list); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadEnv(list); err != nil {
+		return nil, err
+	}
+	for _, f := range flagValues {
+		if err := setString(f.setting, f.value); err != nil {
+			return nil, fmt.Errorf("-%s: %w", f.setting.flagName(), err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile applies the settings of a YAML or TOML file, which has a table
+// per section. Unknown settings are refused, so that typos do not go
+// unnoticed.
+func loadFile(path string, list []setting) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return fmt.Errorf("%s: unknown config format %q, want .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	byName := make(map[string]setting, len(list))
+	for _, s := range list {
+		byName[s.name] = s
+	}
+	for section, value := range doc {
+		table, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: %s: want a table of settings", path, section)
+		}
+		for key, value := range table {
+			name := section + "." + key
+			if s, ok := byName[name]; ok {
+				err = setValue(s, value)
+			} else if s, ok := byName[strings.TrimSuffix(name, "_file")]; ok && s.secret && strings.HasSuffix(name, "_file") {
+				if _, both := table[s.key]; both {
+					return fmt.Errorf("%s: set %s or %s_file, not both", path, s.name, s.name)
+				}
+				err = setSecretFile(s, value)
+			} else {
+				return fmt.Errorf("%s: unknown setting %s", path, name)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", path, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadEnv applies the settings' environment variables that are set and
+// not empty. Secrets may instead be read from the file named by the
+// variable with _FILE appended.
+func loadEnv(list []setting) error {
+	for _, s := range list {
+		value := os.Getenv(s.env)
+		if s.secret {
+			if path := os.Getenv(s.env + "_FILE"); path != "" {
+				if value != "" {
+					return fmt.Errorf("set %s or %s_FILE, not both", s.env, s.env)
+				}
+				if err := setSecretFile(s, path); err != nil {
+					return fmt.Errorf("%s_FILE: %w", s.env, err)
+				}
+				continue
+			}
+		}
+		if value == "" {
+			continue
+		}
+		if err := setString(s, value); err != nil {
+			return fmt.Errorf("%s: %w", s.env, err)
+		}
+	}
+	return nil
+}
+
+// setSecretFile sets s to the contents of the file at path, without
+// trailing newlines.
+func setSecretFile(s setting, path interface{}) error {
+	name, ok := path.(string)
+	if !ok || name == "" {
+		return errors.New("want a file path")
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return setString(s, strings.TrimRight(string(data), "\r\n"))
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue sets s from a decoded file value. Strings are parsed like
+// environment variables; numbers, booleans and lists may also be given as
+// such.
+func setValue(s setting, value interface{}) error {
+	if str, ok := value.(string); ok {
+		return setString(s, str)
+	}
+	switch {
+	case isText(s):
+		switch value.(type) {
+		case int, int64:
+			return setString(s, fmt.Sprint(value))
+		}
+	case s.value.Type() == durationType:
+		return fmt.Errorf("want a duration string like \"30s\", not %v", value)
+	case s.value.Kind() == reflect.Int:
+		switch n := value.(type) {
+		case int:
+			s.value.SetInt(int64(n))
+			return nil
+		case int64:
+			s.value.SetInt(n)
+			return nil
+		}
+	case s.value.Kind() == reflect.Bool:
+		if b, ok := value.(bool); ok {
+			s.value.SetBool(b)
+			return nil
+		}
+	case s.value.Kind() == reflect.Slice:
+		if items, ok := value.([]interface{}); ok {
+			var list []string
+			for _, item := range items {
+				str, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("want a list of strings, not %v", value)
+				}
+				list = append(list, str)
+			}
+			s.value.Set(reflect.ValueOf(list))
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value %v", value)
+}
+
+// setString parses value into s. Lists are comma-separated; types with
+// an UnmarshalText method parse themselves.
+func setString(s setting, value string) error {
+	value = strings.TrimSpace(value)
+	switch {
+	case isText(s):
+		return s.value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	case s.value.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		s.value.SetInt(int64(d))
+	case s.value.Kind() == reflect.String:
+		s.value.SetString(value)
+	case s.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		s.value.SetInt(int64(n))
+	case s.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		s.value.SetBool(b)
+	case s.value.Kind() == reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		s.value.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
+
+func isText(s setting) bool {
+	_, ok := s.value.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
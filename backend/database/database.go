@@ -1,46 +1,70 @@
 package database
 
 import (
-	"coaching-backend/models"
+	"coaching-backend/config"
 	"log"
-	"os"
+	"strings"
 	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func Connect() {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		// Default for Docker Compose environment
-		dsn = "coaching_user:coaching_password@tcp(mysql:3306)/coaching_db?charset=utf8mb4&parseTime=True&loc=Local"
+// Connect opens the database cfg names, retrying while it starts up, sets
+// up the connection pool and migrates the schema.
+func Connect(cfg config.Database) {
+	// "sqlite:" followed by a file path selects SQLite instead of MySQL.
+	dialector := mysql.Open(cfg.URL)
+	if path, ok := strings.CutPrefix(cfg.URL, "sqlite:"); ok {
+		dialector = sqlite.Open(path)
 	}
 
 	// Retry connection with backoff
 	var err error
-	maxRetries := 30
-	for i := 0; i < maxRetries; i++ {
-		DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	for i := 0; i < cfg.ConnectRetries; i++ {
+		DB, err = gorm.Open(dialector, &gorm.Config{TranslateError: true})
 		if err == nil {
 			break
 		}
-		log.Printf("Failed to connect to database (attempt %d/%d): %v", i+1, maxRetries, err)
-		if i < maxRetries-1 {
-			time.Sleep(time.Duration(i+1) * time.Second)
+		log.Printf("Failed to connect to database (attempt %d/%d): %v", i+1, cfg.ConnectRetries, err)
+		if i < cfg.ConnectRetries-1 {
+			time.Sleep(time.Duration(i+1) * cfg.RetryDelay)
 		}
 	}
-	
+
 	if err != nil {
 		log.Fatal("Failed to connect to database after retries:", err)
 	}
 
-	err = DB.AutoMigrate(&models.TeamMember{}, &models.Team{}, &models.Feedback{})
+	sqlDB, err := DB.DB()
+	if err != nil {
+		log.Fatal("Failed to configure the connection pool:", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	err = DB.AutoMigrate(Models()...)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
 	log.Println("Database connected and migrated successfully")
 }
+
+// Close closes the connection pool once the queries in progress finish.
+// It does nothing before Connect.
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,13 @@
+package database
+
+import "coaching-backend/models"
+
+// SchemaVersion is the version of the tables Models migrate to. Bump it
+// when a model changes in a way that backups made before cannot be
+// restored into as they are.
+const SchemaVersion = 1
+
+// Models are the records whose tables Connect migrates.
+func Models() []interface{} {
+	return []interface{}{&models.TeamMember{}, &models.Team{}, &models.Feedback{}, &models.Project{}, &models.Release{}, &models.Meeting{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.OutboxMessage{}, &models.OutboxCursor{}, &models.NotificationPreferences{}, &models.Notification{}, &models.AssignmentChange{}, &models.DigestDelivery{}, &models.Image{}, &models.Attachment{}, &models.Session{}, &models.APIKey{}}
+}
@@ -0,0 +1,168 @@
+// Package digest compiles summaries of what happened to a member or a team
+// over a period: the feedback they received and who joined or left. It
+// renders them as HTML, Markdown or JSON, and its Scheduler emails a
+// weekly digest to every member who asked for one.
+package digest
+
+import (
+	"bytes"
+	"coaching-backend/models"
+	"context"
+	"embed"
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	KindMember = "member"
+	KindTeam   = "team"
+)
+
+// Digest summarizes the half-open period [From, To).
+type Digest struct {
+	Kind string    `json:"kind"`
+	ID   uint32    `json:"id"`
+	Name string    `json:"name"`
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Feedback received in the period, oldest first.
+	Feedback []models.Feedback `json:"feedback"`
+	// Changes are the member's moves between teams, or the team's joins
+	// and departures, oldest first.
+	Changes []models.AssignmentChange `json:"changes"`
+	// Members is a team's current composition.
+	Members []models.TeamMember `json:"members,omitempty"`
+}
+
+// Empty reports whether nothing happened in the period.
+func (d *Digest) Empty() bool {
+	return len(d.Feedback) == 0 && len(d.Changes) == 0
+}
+
+// ForMember compiles the member's digest. It returns
+// gorm.ErrRecordNotFound when the member does not exist.
+func ForMember(ctx context.Context, db *gorm.DB, id uint32, from, to time.Time) (*Digest, error) {
+	db = db.WithContext(ctx)
+	var member models.TeamMember
+	if err := db.First(&member, id).Error; err != nil {
+		return nil, err
+	}
+
+	d := &Digest{Kind: KindMember, ID: member.ID, Name: member.Name, From: from, To: to}
+	if err := compile(db, d, "member", "member_id"); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ForTeam compiles the team's digest. It returns gorm.ErrRecordNotFound
+// when the team does not exist.
+func ForTeam(ctx context.Context, db *gorm.DB, id uint32, from, to time.Time) (*Digest, error) {
+	db = db.WithContext(ctx)
+	var team models.Team
+	if err := db.Preload("Members", "deactivated_at IS NULL").First(&team, id).Error; err != nil {
+		return nil, err
+	}
+
+	d := &Digest{Kind: KindTeam, ID: team.ID, Name: team.Name, From: from, To: to, Members: team.Members}
+	if d.Members == nil {
+		d.Members = []models.TeamMember{}
+	}
+	if err := compile(db, d, "team", "team_id"); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func compile(db *gorm.DB, d *Digest, targetType, changeColumn string) error {
+	d.Feedback = []models.Feedback{}
+	err := db.Where("target_type = ? AND target_id = ? AND created_at >= ? AND created_at < ?", targetType, d.ID, d.From, d.To).
+		Order("created_at, id").
+		Find(&d.Feedback).Error
+	if err != nil {
+		return err
+	}
+
+	d.Changes = []models.AssignmentChange{}
+	return db.Where(changeColumn+" = ? AND created_at >= ? AND created_at < ?", d.ID, d.From, d.To).
+		Order("created_at, id").
+		Find(&d.Changes).Error
+}
+
+type Format string
+
+const (
+	JSON     Format = "json"
+	HTML     Format = "html"
+	Markdown Format = "markdown"
+)
+
+var Formats = []Format{JSON, HTML, Markdown}
+
+// ParseFormat returns the format named s.
+func ParseFormat(s string) (Format, bool) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case HTML:
+		return "text/html; charset=utf-8"
+	case Markdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+//go:embed templates
+var templateFS embed.FS
+
+var funcs = map[string]interface{}{
+	"date": func(t time.Time) string { return t.UTC().Format("Jan 2, 2006") },
+	// quote turns text into a Markdown blockquote.
+	"quote": func(s string) string {
+		return "> " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n> ")
+	},
+}
+
+var (
+	markdownTemplate = texttemplate.Must(texttemplate.New("digest.md.tmpl").Funcs(funcs).ParseFS(templateFS, "templates/digest.md.tmpl"))
+	htmlTemplate     = htmltemplate.Must(htmltemplate.New("digest.html.tmpl").Funcs(funcs).ParseFS(templateFS, "templates/digest.html.tmpl"))
+)
+
+// Render writes the digests in format f: one HTML page or Markdown
+// document holding all of them, or, for JSON, the digest itself or an
+// array when there are several.
+func Render(w io.Writer, f Format, digests ...*Digest) error {
+	switch f {
+	case HTML:
+		return htmlTemplate.Execute(w, digests)
+	case Markdown:
+		return markdownTemplate.Execute(w, digests)
+	default:
+		var v interface{} = digests
+		if len(digests) == 1 {
+			v = digests[0]
+		}
+		return json.NewEncoder(w).Encode(v)
+	}
+}
+
+// RenderString is Render into a string.
+func RenderString(f Format, digests ...*Digest) (string, error) {
+	var buf bytes.Buffer
+	err := Render(&buf, f, digests...)
+	return buf.String(), err
+}
@@ -0,0 +1,241 @@
+package digest
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/notify"
+	"coaching-backend/tests/testutils"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// mailbox is a notify.Sender that keeps the messages it was given and
+// fails while err is set.
+type mailbox struct {
+	err      error
+	messages []notify.Message
+}
+
+func (m *mailbox) Send(ctx context.Context, msg notify.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// monday is a period end under DefaultConfig.
+var monday = time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)
+
+func newScheduler(db *gorm.DB, now time.Time) (*Scheduler, *mailbox) {
+	sender := &mailbox{}
+	s := NewScheduler(db, sender, DefaultConfig)
+	s.now = func() time.Time { return now }
+	return s, sender
+}
+
+type fixture struct {
+	team   models.Team
+	member models.TeamMember
+}
+
+func setupFixture(t *testing.T, db *gorm.DB, weeklyDigest bool) *fixture {
+	f := &fixture{team: models.Team{Name: "Platform"}}
+	require.NoError(t, db.Create(&f.team).Error)
+	f.member = models.TeamMember{Name: "Alice", Email: "alice@example.com", TeamID: &f.team.ID}
+	require.NoError(t, db.Create(&f.member).Error)
+	require.NoError(t, db.Create(&models.NotificationPreferences{MemberID: f.member.ID, FeedbackEmails: true, WeeklyDigest: weeklyDigest}).Error)
+	return f
+}
+
+func createFeedback(t *testing.T, db *gorm.DB, targetType string, targetID uint32, content string, at time.Time) {
+	feedback := models.Feedback{Content: content, TargetType: targetType, TargetID: targetID, CreatedAt: at}
+	require.NoError(t, db.Create(&feedback).Error)
+}
+
+func TestPeriodEnd(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"At The End", monday, monday},
+		{"Just Before", monday.Add(-time.Second), monday.AddDate(0, 0, -7)},
+		{"Later In The Week", monday.AddDate(0, 0, 3), monday},
+		{"Other Time Zone", monday.In(time.FixedZone("UTC-5", -5*3600)), monday},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DefaultConfig.PeriodEnd(tt.now))
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	f := setupFixture(t, db, false)
+	from, to := monday.AddDate(0, 0, -7), monday
+
+	createFeedback(t, db, "member", f.member.ID, "Inside", from.Add(time.Hour))
+	createFeedback(t, db, "member", f.member.ID, "Before", from.Add(-time.Hour))
+	createFeedback(t, db, "member", f.member.ID, "At the end", to)
+	createFeedback(t, db, "team", f.team.ID, "For the team", to.Add(-time.Hour))
+	require.NoError(t, db.Create(&models.AssignmentChange{MemberID: f.member.ID, MemberName: "Alice", TeamID: f.team.ID, TeamName: "Platform", Action: models.MemberJoined, CreatedAt: from.Add(time.Minute)}).Error)
+
+	t.Run("Member", func(t *testing.T) {
+		d, err := ForMember(context.Background(), db, f.member.ID, from, to)
+		require.NoError(t, err)
+		require.Len(t, d.Feedback, 1, "the period is half-open")
+		assert.Equal(t, "Inside", d.Feedback[0].Content)
+		assert.Len(t, d.Changes, 1)
+		assert.Nil(t, d.Members)
+	})
+
+	t.Run("Team", func(t *testing.T) {
+		d, err := ForTeam(context.Background(), db, f.team.ID, from, to)
+		require.NoError(t, err)
+		require.Len(t, d.Feedback, 1)
+		assert.Equal(t, "For the team", d.Feedback[0].Content)
+		assert.Len(t, d.Changes, 1)
+		require.Len(t, d.Members, 1)
+		assert.Equal(t, "Alice", d.Members[0].Name)
+	})
+
+	t.Run("Missing Member", func(t *testing.T) {
+		_, err := ForMember(context.Background(), db, 999, from, to)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestRender(t *testing.T) {
+	d := &Digest{
+		Kind: KindMember, ID: 1, Name: "Alice",
+		From: monday.AddDate(0, 0, -7), To: monday,
+		Feedback: []models.Feedback{{Content: "Line one\nLine <two>", CreatedAt: monday.Add(-time.Hour)}},
+		Changes:  []models.AssignmentChange{},
+	}
+
+	t.Run("Markdown", func(t *testing.T) {
+		out, err := RenderString(Markdown, d)
+		require.NoError(t, err)
+		assert.Contains(t, out, "# Alice: Mar 2, 2026 to Mar 9, 2026")
+		assert.Contains(t, out, "> Line one\n> Line <two>")
+		assert.Contains(t, out, "No team changes this period.")
+	})
+
+	t.Run("HTML Escapes Content", func(t *testing.T) {
+		out, err := RenderString(HTML, d)
+		require.NoError(t, err)
+		assert.Contains(t, out, "Line &lt;two&gt;")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		out, err := RenderString(JSON, d)
+		require.NoError(t, err)
+		var single map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(out), &single))
+		assert.Equal(t, "member", single["kind"])
+
+		out, err = RenderString(JSON, d, d)
+		require.NoError(t, err)
+		var several []map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(out), &several))
+		assert.Len(t, several, 2)
+	})
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("Sends Member And Team Digest Once", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		f := setupFixture(t, db, true)
+		createFeedback(t, db, "member", f.member.ID, "Nice talk", monday.Add(-24*time.Hour))
+		createFeedback(t, db, "team", f.team.ID, "Good release", monday.Add(-48*time.Hour))
+		s, sender := newScheduler(db, monday.Add(time.Hour))
+
+		sent, err := s.SendDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+
+		require.Len(t, sender.messages, 1)
+		msg := sender.messages[0]
+		assert.Equal(t, `"Alice" <alice@example.com>`, msg.To)
+		assert.Equal(t, "Your weekly digest: Mar 2 to Mar 9", msg.Subject)
+		assert.Contains(t, msg.Text, "# Alice")
+		assert.Contains(t, msg.Text, "# Team Platform")
+		assert.Contains(t, msg.Text, "> Nice talk")
+		assert.True(t, strings.Index(msg.Text, "Nice talk") < strings.Index(msg.Text, "Good release"))
+		assert.Contains(t, msg.HTML, "Good release")
+
+		sent, err = s.SendDue(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, sent, "the period is recorded as sent")
+	})
+
+	t.Run("Skips Members Who Did Not Opt In", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		f := setupFixture(t, db, false)
+		createFeedback(t, db, "member", f.member.ID, "Nice talk", monday.Add(-time.Hour))
+		s, sender := newScheduler(db, monday)
+
+		_, err := s.SendDue(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, sender.messages)
+	})
+
+	t.Run("Does Not Send Empty Digests", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		f := setupFixture(t, db, true)
+		s, sender := newScheduler(db, monday)
+
+		sent, err := s.SendDue(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+		assert.Empty(t, sender.messages)
+
+		var count int64
+		db.Model(&models.DigestDelivery{}).Where("member_id = ?", f.member.ID).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Retries After A Failed Send", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		f := setupFixture(t, db, true)
+		createFeedback(t, db, "member", f.member.ID, "Nice talk", monday.Add(-time.Hour))
+		s, sender := newScheduler(db, monday)
+		sender.err = errors.New("connection refused")
+
+		sent, err := s.SendDue(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+
+		sender.err = nil
+		sent, err = s.SendDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+	})
+
+	t.Run("Each Period Is Sent Separately", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		f := setupFixture(t, db, true)
+		createFeedback(t, db, "member", f.member.ID, "Week one", monday.Add(-time.Hour))
+		createFeedback(t, db, "member", f.member.ID, "Week two", monday.Add(time.Hour))
+		s, sender := newScheduler(db, monday)
+
+		_, err := s.SendDue(context.Background())
+		require.NoError(t, err)
+		s.now = func() time.Time { return monday.AddDate(0, 0, 7) }
+		_, err = s.SendDue(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, sender.messages, 2)
+		assert.NotContains(t, sender.messages[1].Text, "Week one")
+		assert.Contains(t, sender.messages[1].Text, "Week two")
+	})
+}
@@ -0,0 +1,169 @@
+package digest
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/notify"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Config struct {
+	// Weekday and Hour (UTC) end each weekly period. Digests for a period
+	// are sent once it has ended.
+	Weekday time.Weekday
+	Hour    int
+	// PollInterval is how often the scheduler checks for unsent digests.
+	PollInterval time.Duration
+}
+
+// DefaultConfig sends digests every Monday at 08:00 UTC.
+var DefaultConfig = Config{
+	Weekday:      time.Monday,
+	Hour:         8,
+	PollInterval: 10 * time.Minute,
+}
+
+// Scheduler emails each member who turned on weekly_digest their digest
+// and their team's for the week that ended last. Sent digests are
+// recorded, so a restart does not send them again.
+type Scheduler struct {
+	db     *gorm.DB
+	sender notify.Sender
+	cfg    Config
+	now    func() time.Time
+}
+
+func NewScheduler(db *gorm.DB, sender notify.Sender, cfg Config) *Scheduler {
+	return &Scheduler{
+		db:     db,
+		sender: sender,
+		cfg:    cfg,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run sends digests until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SendDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("digest: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PeriodEnd returns the end of the last weekly period that ended at or
+// before now.
+func (c Config) PeriodEnd(now time.Time) time.Time {
+	now = now.UTC()
+	end := time.Date(now.Year(), now.Month(), now.Day(), c.Hour, 0, 0, 0, time.UTC)
+	for end.Weekday() != c.Weekday || end.After(now) {
+		end = end.AddDate(0, 0, -1)
+	}
+	return end
+}
+
+// SendDue sends the last period's digest to every subscribed member who
+// has not had it yet, and reports how many emails it sent. Members with
+// nothing to report are marked done without an email. A failed send is
+// logged and retried on the next call.
+func (s *Scheduler) SendDue(ctx context.Context) (int, error) {
+	to := s.cfg.PeriodEnd(s.now())
+	from := to.AddDate(0, 0, -7)
+	db := s.db.WithContext(ctx)
+
+	done := db.Model(&models.DigestDelivery{}).Select("member_id").Where("period_end = ?", to)
+	var members []models.TeamMember
+	err := db.Joins("JOIN notification_preferences ON notification_preferences.member_id = team_members.id").
+		Where("notification_preferences.weekly_digest = ? AND team_members.deactivated_at IS NULL AND team_members.id NOT IN (?)", true, done).
+		Find(&members).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load subscribers: %w", err)
+	}
+
+	sent := 0
+	for i := range members {
+		ok, err := s.sendTo(ctx, &members[i], from, to)
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		if err != nil {
+			log.Printf("digest: failed to send to member %d: %v", members[i].ID, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendTo emails the member's digests for the period, unless they are
+// empty, and records the period as done.
+func (s *Scheduler) sendTo(ctx context.Context, member *models.TeamMember, from, to time.Time) (bool, error) {
+	digests := []*Digest{}
+	d, err := ForMember(ctx, s.db, member.ID, from, to)
+	if err != nil {
+		return false, err
+	}
+	digests = append(digests, d)
+	if member.TeamID != nil {
+		team, err := ForTeam(ctx, s.db, *member.TeamID, from, to)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, err
+		}
+		if team != nil {
+			digests = append(digests, team)
+		}
+	}
+
+	empty := true
+	for _, d := range digests {
+		empty = empty && d.Empty()
+	}
+	if !empty {
+		msg, err := message(member, digests)
+		if err != nil {
+			return false, err
+		}
+		if err := s.sender.Send(ctx, msg); err != nil {
+			return false, err
+		}
+	}
+
+	err = s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.DigestDelivery{MemberID: member.ID, PeriodEnd: to}).Error
+	return !empty, err
+}
+
+func message(member *models.TeamMember, digests []*Digest) (notify.Message, error) {
+	text, err := RenderString(Markdown, digests...)
+	if err != nil {
+		return notify.Message{}, err
+	}
+	html, err := RenderString(HTML, digests...)
+	if err != nil {
+		return notify.Message{}, err
+	}
+	d := digests[0]
+	return notify.Message{
+		To:      (&mail.Address{Name: member.Name, Address: member.Email}).String(),
+		Subject: fmt.Sprintf("Your weekly digest: %s to %s", d.From.Format("Jan 2"), d.To.Format("Jan 2")),
+		Text:    text,
+		HTML:    html,
+	}, nil
+}
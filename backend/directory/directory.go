@@ -0,0 +1,231 @@
+// Package directory syncs members and their teams from an LDAP directory,
+// such as Active Directory, for organizations that cannot provision over
+// SCIM.
+package directory
+
+import (
+	"coaching-backend/ldap"
+	"coaching-backend/services"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	URL          string
+	BindDN       string
+	BindPassword string
+	// BaseDN is searched for people matching UserFilter.
+	BaseDN     string
+	UserFilter string
+	// EmailAttribute and NameAttribute hold a person's email and name.
+	// A person without a name is named after their email.
+	EmailAttribute string
+	NameAttribute  string
+
+	// TeamAttribute, if set, holds the name of a person's team, such as
+	// department. Otherwise, if GroupFilter is set, people are in the
+	// teams named by GroupNameAttribute of the groups below GroupBaseDN
+	// that list them in GroupMemberAttribute. A person in several groups
+	// joins the first by name. With neither, teams are left alone.
+	TeamAttribute        string
+	GroupBaseDN          string
+	GroupFilter          string
+	GroupNameAttribute   string
+	GroupMemberAttribute string
+	// CreateTeams creates teams the directory names that do not exist.
+	CreateTeams bool
+
+	// PageSize is how many entries each page of a search returns.
+	PageSize int
+	// Interval is how often Run syncs.
+	Interval time.Duration
+}
+
+// DefaultConfig holds the defaults NewSyncer fills in, which suit Active
+// Directory as well as OpenLDAP.
+var DefaultConfig = Config{
+	UserFilter:           "(&(objectClass=person)(mail=*))",
+	EmailAttribute:       "mail",
+	NameAttribute:        "displayName",
+	GroupNameAttribute:   "cn",
+	GroupMemberAttribute: "member",
+	PageSize:             500,
+	Interval:             time.Hour,
+}
+
+// Syncer pulls people from the directory and syncs them with
+// services.Service.SyncDirectory.
+type Syncer struct {
+	cfg     Config
+	service *services.Service
+}
+
+func NewSyncer(cfg Config, service *services.Service) *Syncer {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = DefaultConfig.UserFilter
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = DefaultConfig.EmailAttribute
+	}
+	if cfg.NameAttribute == "" {
+		cfg.NameAttribute = DefaultConfig.NameAttribute
+	}
+	if cfg.GroupBaseDN == "" {
+		cfg.GroupBaseDN = cfg.BaseDN
+	}
+	if cfg.GroupNameAttribute == "" {
+		cfg.GroupNameAttribute = DefaultConfig.GroupNameAttribute
+	}
+	if cfg.GroupMemberAttribute == "" {
+		cfg.GroupMemberAttribute = DefaultConfig.GroupMemberAttribute
+	}
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = DefaultConfig.PageSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultConfig.Interval
+	}
+	return &Syncer{cfg: cfg, service: service}
+}
+
+// Run syncs every Interval until ctx is done.
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := s.Sync(ctx, false)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Printf("directory: %v", err)
+		case err == nil:
+			if report.Created+report.Updated+report.Deactivated > 0 {
+				log.Printf("directory: %d created, %d updated, %d deactivated", report.Created, report.Updated, report.Deactivated)
+			}
+			for _, skip := range report.Skipped {
+				log.Printf("directory: skipped %s: %s", skip.DN, skip.Reason)
+			}
+			for _, warning := range report.Warnings {
+				log.Printf("directory: %s", warning)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync fetches the directory and syncs members with it. A dry run reports
+// the changes without making them.
+func (s *Syncer) Sync(ctx context.Context, dryRun bool) (*services.DirectorySyncReport, error) {
+	people, err := s.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.service.SyncDirectory(ctx, people, services.DirectorySyncOptions{
+		DryRun:      dryRun,
+		SyncTeams:   s.cfg.TeamAttribute != "" || s.cfg.GroupFilter != "",
+		CreateTeams: s.cfg.CreateTeams,
+	})
+}
+
+// Fetch reads every person, and their team, from the directory.
+func (s *Syncer) Fetch(ctx context.Context) ([]services.DirectoryPerson, error) {
+	userFilter, err := ldap.ParseFilter(s.cfg.UserFilter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user filter: %w", err)
+	}
+	var groupFilter ldap.Filter
+	if s.cfg.TeamAttribute == "" && s.cfg.GroupFilter != "" {
+		if groupFilter, err = ldap.ParseFilter(s.cfg.GroupFilter); err != nil {
+			return nil, fmt.Errorf("invalid group filter: %w", err)
+		}
+	}
+	if s.cfg.BaseDN == "" {
+		return nil, errors.New("base DN is not set")
+	}
+
+	conn, err := ldap.Dial(ctx, s.cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.Bind(ctx, s.cfg.BindDN, s.cfg.BindPassword); err != nil {
+		return nil, err
+	}
+
+	attrs := []string{s.cfg.EmailAttribute, s.cfg.NameAttribute, "cn"}
+	if s.cfg.TeamAttribute != "" {
+		attrs = append(attrs, s.cfg.TeamAttribute)
+	}
+	entries, err := conn.Search(ctx, ldap.SearchRequest{
+		BaseDN:     s.cfg.BaseDN,
+		Filter:     userFilter,
+		Attributes: attrs,
+		PageSize:   s.cfg.PageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search people: %w", err)
+	}
+
+	var teams map[string]string
+	if groupFilter != nil {
+		if teams, err = s.groupTeams(ctx, conn, groupFilter); err != nil {
+			return nil, err
+		}
+	}
+
+	people := make([]services.DirectoryPerson, len(entries))
+	for i, e := range entries {
+		name := e.Value(s.cfg.NameAttribute)
+		if name == "" {
+			name = e.Value("cn")
+		}
+		people[i] = services.DirectoryPerson{DN: e.DN, Email: e.Value(s.cfg.EmailAttribute), Name: name}
+		if s.cfg.TeamAttribute != "" {
+			people[i].Team = e.Value(s.cfg.TeamAttribute)
+		} else {
+			people[i].Team = teams[strings.ToLower(e.DN)]
+		}
+	}
+	return people, nil
+}
+
+// groupTeams maps the lowercased DN of each group member to the name of
+// their team.
+func (s *Syncer) groupTeams(ctx context.Context, conn *ldap.Conn, filter ldap.Filter) (map[string]string, error) {
+	groups, err := conn.Search(ctx, ldap.SearchRequest{
+		BaseDN:     s.cfg.GroupBaseDN,
+		Filter:     filter,
+		Attributes: []string{s.cfg.GroupNameAttribute, s.cfg.GroupMemberAttribute},
+		PageSize:   s.cfg.PageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search groups: %w", err)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i].Value(s.cfg.GroupNameAttribute)) < strings.ToLower(groups[j].Value(s.cfg.GroupNameAttribute))
+	})
+
+	teams := make(map[string]string)
+	for _, group := range groups {
+		name := group.Value(s.cfg.GroupNameAttribute)
+		if name == "" {
+			continue
+		}
+		for _, member := range group.Values(s.cfg.GroupMemberAttribute) {
+			key := strings.ToLower(member)
+			if _, ok := teams[key]; !ok {
+				teams[key] = name
+			}
+		}
+	}
+	return teams, nil
+}
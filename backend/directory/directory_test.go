@@ -0,0 +1,248 @@
+package directory
+
+import (
+	"coaching-backend/ldap"
+	"coaching-backend/ldap/ldaptest"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const (
+	bindDN   = "cn=sync,dc=example,dc=com"
+	password = "secret"
+	people   = "ou=people,dc=example,dc=com"
+)
+
+func person(uid, name, email, department string) *ldap.Entry {
+	attrs := []string{"objectClass", "person", "displayName", name, "mail", email}
+	if department != "" {
+		attrs = append(attrs, "department", department)
+	}
+	return ldaptest.NewEntry("uid="+uid+","+people, attrs...)
+}
+
+func newSyncer(server *ldaptest.Server, db *gorm.DB, cfg Config) *Syncer {
+	cfg.URL, cfg.BindDN, cfg.BindPassword, cfg.BaseDN = server.URL, server.BindDN, server.Password, people
+	return NewSyncer(cfg, services.New(db))
+}
+
+func members(t *testing.T, db *gorm.DB) map[string]models.TeamMember {
+	var list []models.TeamMember
+	require.NoError(t, db.Preload("Team").Find(&list).Error)
+	out := make(map[string]models.TeamMember, len(list))
+	for _, m := range list {
+		out[m.Email] = m
+	}
+	return out
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Creates Members And Teams", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		server := ldaptest.NewServer(bindDN, password,
+			person("ada", "Ada Lovelace", "ada@example.com", "Platform"),
+			person("grace", "Grace Hopper", "grace@example.com", ""),
+		)
+		defer server.Close()
+		s := newSyncer(server, db, Config{TeamAttribute: "department", CreateTeams: true})
+
+		report, err := s.Sync(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, []string{"Platform"}, report.TeamsCreated)
+		assert.Equal(t, []services.FieldChange{{Field: "name", To: "Ada Lovelace"}, {Field: "team", To: "Platform"}}, report.Changes[0].Fields)
+
+		got := members(t, db)
+		require.Len(t, got, 2)
+		assert.Equal(t, "uid=ada,"+people, got["ada@example.com"].DirectoryDN)
+		require.NotNil(t, got["ada@example.com"].Team)
+		assert.Equal(t, "Platform", got["ada@example.com"].Team.Name)
+		assert.Nil(t, got["grace@example.com"].TeamID)
+
+		report, err = s.Sync(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Unchanged)
+		assert.Empty(t, report.Changes)
+	})
+
+	t.Run("Dry Run Changes Nothing", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		server := ldaptest.NewServer(bindDN, password, person("ada", "Ada Lovelace", "ada@example.com", "Platform"))
+		defer server.Close()
+		s := newSyncer(server, db, Config{TeamAttribute: "department", CreateTeams: true})
+
+		report, err := s.Sync(ctx, true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, []string{"Platform"}, report.TeamsCreated)
+		assert.Empty(t, members(t, db))
+		var teams int64
+		db.Model(&models.Team{}).Count(&teams)
+		assert.Zero(t, teams)
+	})
+
+	t.Run("Adopts, Updates And Deactivates Members", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		manual := testutils.CreateTestTeamMember(db)
+		adopted := models.TeamMember{Name: "Ada", Email: "ADA@example.com"}
+		leaver := models.TeamMember{Name: "Alan Turing", Email: "alan@example.com", DirectoryDN: "uid=alan," + people}
+		renamed := models.TeamMember{Name: "Grace Hopper", Email: "grace@example.com", DirectoryDN: "uid=grace," + people}
+		require.NoError(t, db.Create(&[]*models.TeamMember{&adopted, &leaver, &renamed}).Error)
+
+		server := ldaptest.NewServer(bindDN, password,
+			person("ada", "Ada Lovelace", "ada@example.com", ""),
+			person("grace", "Grace Hopper", "grace.hopper@example.com", ""),
+		)
+		defer server.Close()
+		s := newSyncer(server, db, Config{})
+
+		report, err := s.Sync(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, 0, report.Created)
+		assert.Equal(t, 2, report.Updated)
+		assert.Equal(t, 1, report.Deactivated)
+		assert.Contains(t, report.Changes, services.DirectoryChange{Action: services.DirectoryDeactivate, Email: "alan@example.com", MemberID: leaver.ID})
+		assert.Contains(t, report.Changes, services.DirectoryChange{
+			Action:   services.DirectoryUpdate,
+			Email:    "grace.hopper@example.com",
+			MemberID: renamed.ID,
+			Fields:   []services.FieldChange{{Field: "email", From: "grace@example.com", To: "grace.hopper@example.com"}},
+		})
+
+		got := members(t, db)
+		assert.Len(t, got, 4, "leavers are deactivated, not deleted")
+		assert.Contains(t, got, manual.Email)
+		assert.Equal(t, "Ada Lovelace", got["ada@example.com"].Name)
+		assert.Equal(t, adopted.ID, got["ada@example.com"].ID)
+		assert.Equal(t, "uid=ada,"+people, got["ada@example.com"].DirectoryDN)
+		assert.Equal(t, renamed.ID, got["grace.hopper@example.com"].ID)
+		assert.NotNil(t, got["alan@example.com"].DeactivatedAt)
+		assert.Nil(t, got["ada@example.com"].DeactivatedAt)
+
+		report, err = s.Sync(ctx, false)
+		require.NoError(t, err)
+		assert.Zero(t, report.Deactivated, "deactivated members are not deactivated again")
+	})
+
+	t.Run("Reactivates Returning Members", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		leftAt := time.Now().Add(-time.Hour)
+		returning := models.TeamMember{Name: "Alan Turing", Email: "alan@example.com", DirectoryDN: "uid=alan," + people, DeactivatedAt: &leftAt}
+		require.NoError(t, db.Create(&returning).Error)
+
+		server := ldaptest.NewServer(bindDN, password, person("alan", "Alan Turing", "alan@example.com", ""))
+		defer server.Close()
+		report, err := newSyncer(server, db, Config{}).Sync(ctx, false)
+		require.NoError(t, err)
+		assert.Contains(t, report.Changes, services.DirectoryChange{
+			Action:   services.DirectoryUpdate,
+			Email:    "alan@example.com",
+			MemberID: returning.ID,
+			Fields:   []services.FieldChange{{Field: "active", From: "false", To: "true"}},
+		})
+		assert.Nil(t, members(t, db)["alan@example.com"].DeactivatedAt)
+	})
+
+	t.Run("Maps Groups To Teams", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		team := testutils.CreateTestTeam(db)
+		other := models.Team{Name: "Design"}
+		require.NoError(t, db.Create(&other).Error)
+		moved := models.TeamMember{Name: "Ada Lovelace", Email: "ada@example.com", TeamID: &other.ID}
+		require.NoError(t, db.Create(&moved).Error)
+
+		server := ldaptest.NewServer(bindDN, password,
+			person("ada", "Ada Lovelace", "ada@example.com", ""),
+			person("grace", "Grace Hopper", "grace@example.com", ""),
+			person("alan", "Alan Turing", "alan@example.com", ""),
+			ldaptest.NewEntry("cn=Development Team,ou=groups,dc=example,dc=com", "objectClass", "groupOfNames", "cn", team.Name,
+				"member", "uid=ada,"+people, "member", "UID=GRACE,"+people),
+			ldaptest.NewEntry("cn=Research,ou=groups,dc=example,dc=com", "objectClass", "groupOfNames", "cn", "Research",
+				"member", "uid=alan,"+people),
+		)
+		defer server.Close()
+		s := newSyncer(server, db, Config{GroupBaseDN: "ou=groups,dc=example,dc=com", GroupFilter: "(objectClass=groupOfNames)"})
+
+		report, err := s.Sync(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`alan@example.com is in team "Research", which does not exist`}, report.Warnings)
+		assert.Empty(t, report.TeamsCreated)
+
+		got := members(t, db)
+		assert.Equal(t, team.ID, *got["ada@example.com"].TeamID)
+		assert.Equal(t, team.ID, *got["grace@example.com"].TeamID)
+		assert.Nil(t, got["alan@example.com"].TeamID)
+
+		var history []models.AssignmentChange
+		require.NoError(t, db.Where("member_id = ?", moved.ID).Find(&history).Error)
+		assert.Len(t, history, 2)
+	})
+
+	t.Run("Skips Invalid Entries", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		server := ldaptest.NewServer(bindDN, password,
+			person("ada", "Ada Lovelace", "ada@example.com", ""),
+			person("ada2", "Ada Again", "Ada@Example.com", ""),
+			person("bob", "Bob", "not-an-email", ""),
+		)
+		defer server.Close()
+
+		report, err := newSyncer(server, db, Config{}).Sync(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		require.Len(t, report.Skipped, 2)
+		assert.Equal(t, services.DirectorySkip{DN: "uid=ada2," + people, Reason: "has the same email as uid=ada," + people}, report.Skipped[0])
+		assert.Equal(t, services.DirectorySkip{DN: "uid=bob," + people, Reason: "email must be a valid email address"}, report.Skipped[1])
+	})
+
+	t.Run("Refuses An Empty Directory", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		require.NoError(t, db.Create(&models.TeamMember{Name: "Ada", Email: "ada@example.com", DirectoryDN: "uid=ada," + people}).Error)
+		server := ldaptest.NewServer(bindDN, password)
+		defer server.Close()
+
+		_, err := newSyncer(server, db, Config{}).Sync(ctx, false)
+		require.Error(t, err)
+		assert.Len(t, members(t, db), 1)
+	})
+
+	t.Run("Pages Large Directories", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		var entries []*ldap.Entry
+		for _, uid := range []string{"a", "b", "c", "d", "e"} {
+			entries = append(entries, person(uid, uid, uid+"@example.com", ""))
+		}
+		server := ldaptest.NewServer(bindDN, password, entries...)
+		server.MaxResults = 2
+		defer server.Close()
+
+		report, err := newSyncer(server, db, Config{PageSize: 2}).Sync(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, 5, report.Created)
+		assert.Equal(t, 3, server.Searches())
+	})
+
+	t.Run("Reports Bind Failures", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		server := ldaptest.NewServer(bindDN, password)
+		defer server.Close()
+		s := newSyncer(server, db, Config{})
+		s.cfg.BindPassword = "wrong"
+
+		_, err := s.Sync(ctx, false)
+		var ldapErr *ldap.Error
+		require.ErrorAs(t, err, &ldapErr)
+		assert.Equal(t, ldap.ResultInvalidCredentials, ldapErr.Code)
+	})
+}
@@ -0,0 +1,252 @@
+// Package events is an in-process bus for domain events. The service layer
+// publishes to it after every committed change; the SSE and WebSocket
+// endpoints and the gRPC feedback watch subscribe to it.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	MemberCreated    Type = "member.created"
+	MemberAssigned   Type = "member.assigned"
+	MemberUnassigned Type = "member.unassigned"
+	FeedbackCreated  Type = "feedback.created"
+	FeedbackUpdated  Type = "feedback.updated"
+	FeedbackDeleted  Type = "feedback.deleted"
+	ProjectCreated   Type = "project.created"
+	ProjectUpdated   Type = "project.updated"
+	ProjectDeleted   Type = "project.deleted"
+	ReleaseCreated   Type = "release.created"
+	ReleaseUpdated   Type = "release.updated"
+	ReleaseDeleted   Type = "release.deleted"
+	MeetingCreated   Type = "meeting.created"
+	MeetingUpdated   Type = "meeting.updated"
+	MeetingDeleted   Type = "meeting.deleted"
+)
+
+// Types lists every event type, in a stable order.
+var Types = []Type{
+	MemberCreated, MemberAssigned, MemberUnassigned,
+	FeedbackCreated, FeedbackUpdated, FeedbackDeleted,
+	ProjectCreated, ProjectUpdated, ProjectDeleted,
+	ReleaseCreated, ReleaseUpdated, ReleaseDeleted,
+	MeetingCreated, MeetingUpdated, MeetingDeleted,
+}
+
+// Known reports whether t is one of Types.
+func Known(t Type) bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+type Event struct {
+	ID uint64 `json:"id"`
+	// Key identifies the event across redeliveries. Consumers that may see
+	// an event twice deduplicate on it.
+	Key  string    `json:"key,omitempty"`
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	// TeamID is the team the event concerns, if any: the team a member
+	// joined or left, or the team of the feedback target.
+	TeamID     *uint32 `json:"team_id"`
+	TargetType string  `json:"target_type"`
+	TargetID   uint32  `json:"target_id"`
+	// Data is the member, feedback, project, release or meeting after the
+	// change (before it, for deletions).
+	Data interface{} `json:"data"`
+}
+
+// Filter selects events. Zero fields match anything.
+type Filter struct {
+	TeamID     uint32
+	TargetType string
+	TargetID   uint32
+	Types      []Type
+}
+
+func (f Filter) Matches(e *Event) bool {
+	if f.TeamID != 0 && (e.TeamID == nil || *e.TeamID != f.TeamID) {
+		return false
+	}
+	if f.TargetType != "" && f.TargetType != e.TargetType {
+		return false
+	}
+	if f.TargetID != 0 && f.TargetID != e.TargetID {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// DefaultHistory is how many recent events Default keeps for resuming.
+	DefaultHistory = 1024
+	// subscriberBuffer is how many live events a subscriber may fall
+	// behind before it is dropped.
+	subscriberBuffer = 64
+)
+
+// Default is the process-wide bus.
+var Default = NewBus(DefaultHistory)
+
+type Bus struct {
+	mu     sync.Mutex
+	lastID uint64
+	// evicted is the ID of the newest event dropped from history.
+	evicted     uint64
+	history     []Event
+	size        int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewBus returns a bus that keeps the last history events for resuming.
+func NewBus(history int) *Bus {
+	return &Bus{size: history, subscribers: map[*Subscription]struct{}{}}
+}
+
+// Publish delivers the event to every matching subscriber and returns it
+// with its ID and time set. Events without an ID get the next one. Events
+// relayed from the outbox keep theirs; one at or below the last published
+// ID is a redelivery and is dropped. Publish never blocks: a subscriber
+// whose buffer is full is dropped and its channel closed.
+func (b *Bus) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case e.ID == 0:
+		b.lastID++
+		e.ID = b.lastID
+	case e.ID <= b.lastID:
+		return e
+	default:
+		b.lastID = e.ID
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	b.history = append(b.history, e)
+	if len(b.history) > b.size {
+		drop := len(b.history) - b.size
+		b.evicted = b.history[drop-1].ID
+		b.history = b.history[drop:]
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(&e) {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return e
+}
+
+type Subscription struct {
+	// Events delivers matching events in ID order. It is closed when the
+	// subscription's context is done, or earlier if the subscriber falls
+	// too far behind or the bus is closed; check the context and Closed to
+	// tell these apart.
+	Events <-chan Event
+	// Reset is set when the requested resume point is no longer in the
+	// history (or was never issued by this process). Events in between
+	// were lost, so the subscriber should reload its state.
+	Reset bool
+
+	filter Filter
+	events chan Event
+}
+
+// Subscribe delivers events matching filter until ctx is done. With a
+// non-zero lastID, buffered events after lastID are replayed first.
+func (b *Bus) Subscribe(ctx context.Context, filter Filter, lastID uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []Event
+	reset := false
+	if lastID != 0 {
+		switch {
+		case lastID > b.lastID:
+			reset = true
+		case lastID < b.evicted:
+			reset = true
+		default:
+			for _, e := range b.history {
+				if e.ID > lastID && filter.Matches(&e) {
+					replay = append(replay, e)
+				}
+			}
+		}
+	}
+
+	sub := &Subscription{Reset: reset, filter: filter, events: make(chan Event, subscriberBuffer+len(replay))}
+	sub.Events = sub.events
+	if b.closed {
+		close(sub.events)
+		return sub
+	}
+	for _, e := range replay {
+		sub.events <- e
+	}
+	b.subscribers[sub] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		b.remove(sub)
+	}()
+	return sub
+}
+
+// Close ends every subscription, and those made later end at once, so
+// that long-lived streams let the server shut down. Events are still
+// published to the history.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Closed reports whether Close has been called.
+func (b *Bus) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+func (b *Bus) remove(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,175 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func teamID(id uint32) *uint32 {
+	return &id
+}
+
+func drain(sub *Subscription) []uint64 {
+	var ids []uint64
+	for {
+		select {
+		case e := <-sub.Events:
+			ids = append(ids, e.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	e := &Event{Type: FeedbackCreated, TeamID: teamID(3), TargetType: "member", TargetID: 7}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		matches bool
+	}{
+		{"Empty", Filter{}, true},
+		{"Team", Filter{TeamID: 3}, true},
+		{"Other Team", Filter{TeamID: 4}, false},
+		{"Target", Filter{TargetType: "member", TargetID: 7}, true},
+		{"Other Target", Filter{TargetType: "team", TargetID: 7}, false},
+		{"Types", Filter{Types: []Type{MemberAssigned, FeedbackCreated}}, true},
+		{"Other Types", Filter{Types: []Type{MemberAssigned}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, tt.filter.Matches(e))
+		})
+	}
+
+	t.Run("Team Filter Skips Events Without Team", func(t *testing.T) {
+		assert.False(t, Filter{TeamID: 3}.Matches(&Event{Type: MemberCreated}))
+	})
+}
+
+func TestBus(t *testing.T) {
+	t.Run("Delivers Matching Events", func(t *testing.T) {
+		b := NewBus(10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sub := b.Subscribe(ctx, Filter{TeamID: 1}, 0)
+
+		b.Publish(Event{Type: MemberAssigned, TeamID: teamID(1)})
+		b.Publish(Event{Type: MemberAssigned, TeamID: teamID(2)})
+		third := b.Publish(Event{Type: MemberUnassigned, TeamID: teamID(1)})
+
+		assert.Equal(t, []uint64{1, third.ID}, drain(sub))
+		assert.False(t, sub.Reset)
+	})
+
+	t.Run("Replays After Last Event ID", func(t *testing.T) {
+		b := NewBus(10)
+		for i := 0; i < 5; i++ {
+			b.Publish(Event{Type: FeedbackCreated})
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sub := b.Subscribe(ctx, Filter{}, 3)
+		b.Publish(Event{Type: FeedbackCreated})
+
+		assert.Equal(t, []uint64{4, 5, 6}, drain(sub))
+		assert.False(t, sub.Reset)
+	})
+
+	t.Run("Resets When History Was Evicted", func(t *testing.T) {
+		b := NewBus(3)
+		for i := 0; i < 6; i++ {
+			b.Publish(Event{Type: FeedbackCreated})
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		complete := b.Subscribe(ctx, Filter{}, 3)
+		assert.False(t, complete.Reset)
+		assert.Equal(t, []uint64{4, 5, 6}, drain(complete))
+
+		gap := b.Subscribe(ctx, Filter{}, 2)
+		assert.True(t, gap.Reset)
+		assert.Empty(t, drain(gap))
+	})
+
+	t.Run("Keeps Relayed IDs", func(t *testing.T) {
+		b := NewBus(2)
+		b.Publish(Event{ID: 10, Type: FeedbackCreated})
+		b.Publish(Event{ID: 14, Type: FeedbackUpdated})
+		redelivered := b.Publish(Event{ID: 10, Type: FeedbackCreated})
+		b.Publish(Event{ID: 20, Type: FeedbackDeleted})
+		assert.Equal(t, uint64(21), b.Publish(Event{Type: MemberCreated}).ID)
+		assert.Zero(t, redelivered.Time, "redeliveries are dropped")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// 14 was evicted, but a client that saw it has missed nothing.
+		resumed := b.Subscribe(ctx, Filter{}, 14)
+		assert.False(t, resumed.Reset)
+		assert.Equal(t, []uint64{20, 21}, drain(resumed))
+
+		assert.True(t, b.Subscribe(ctx, Filter{}, 12).Reset)
+	})
+
+	t.Run("Resets On Unknown Event ID", func(t *testing.T) {
+		b := NewBus(3)
+		b.Publish(Event{Type: FeedbackCreated})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		assert.True(t, b.Subscribe(ctx, Filter{}, 99).Reset)
+	})
+
+	t.Run("Drops Slow Subscriber", func(t *testing.T) {
+		b := NewBus(10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sub := b.Subscribe(ctx, Filter{}, 0)
+
+		for i := 0; i <= subscriberBuffer; i++ {
+			b.Publish(Event{Type: FeedbackUpdated})
+		}
+
+		received := 0
+		for range sub.Events {
+			received++
+		}
+		assert.Equal(t, subscriberBuffer, received)
+		assert.NoError(t, ctx.Err())
+	})
+
+	t.Run("Cancel Closes Channel", func(t *testing.T) {
+		b := NewBus(10)
+		ctx, cancel := context.WithCancel(context.Background())
+		sub := b.Subscribe(ctx, Filter{}, 0)
+
+		cancel()
+		_, open := <-sub.Events
+		assert.False(t, open)
+	})
+
+	t.Run("Close Ends Subscriptions", func(t *testing.T) {
+		b := NewBus(10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sub := b.Subscribe(ctx, Filter{}, 0)
+
+		b.Close()
+		_, open := <-sub.Events
+		assert.False(t, open)
+		assert.True(t, b.Closed())
+
+		b.Publish(Event{Type: FeedbackCreated})
+		later := b.Subscribe(ctx, Filter{}, 0)
+		_, open = <-later.Events
+		assert.False(t, open)
+		assert.NoError(t, ctx.Err())
+	})
+}
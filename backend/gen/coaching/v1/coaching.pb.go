@@ -0,0 +1,2150 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: coaching/v1/coaching.proto
+
+package coachingv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FeedbackEvent_Kind int32
+
+const (
+	FeedbackEvent_KIND_UNSPECIFIED FeedbackEvent_Kind = 0
+	FeedbackEvent_KIND_CREATED     FeedbackEvent_Kind = 1
+	FeedbackEvent_KIND_UPDATED     FeedbackEvent_Kind = 2
+	FeedbackEvent_KIND_DELETED     FeedbackEvent_Kind = 3
+)
+
+// Enum value maps for FeedbackEvent_Kind.
+var (
+	FeedbackEvent_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_CREATED",
+		2: "KIND_UPDATED",
+		3: "KIND_DELETED",
+	}
+	FeedbackEvent_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_CREATED":     1,
+		"KIND_UPDATED":     2,
+		"KIND_DELETED":     3,
+	}
+)
+
+func (x FeedbackEvent_Kind) Enum() *FeedbackEvent_Kind {
+	p := new(FeedbackEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x FeedbackEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FeedbackEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_coaching_v1_coaching_proto_enumTypes[0].Descriptor()
+}
+
+func (FeedbackEvent_Kind) Type() protoreflect.EnumType {
+	return &file_coaching_v1_coaching_proto_enumTypes[0]
+}
+
+func (x FeedbackEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FeedbackEvent_Kind.Descriptor instead.
+func (FeedbackEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{34, 0}
+}
+
+type TeamMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Picture       string                 `protobuf:"bytes,4,opt,name=picture,proto3" json:"picture,omitempty"`
+	TeamId        *uint32                `protobuf:"varint,5,opt,name=team_id,json=teamId,proto3,oneof" json:"team_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TeamMember) Reset() {
+	*x = TeamMember{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TeamMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeamMember) ProtoMessage() {}
+
+func (x *TeamMember) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeamMember.ProtoReflect.Descriptor instead.
+func (*TeamMember) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TeamMember) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TeamMember) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TeamMember) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *TeamMember) GetPicture() string {
+	if x != nil {
+		return x.Picture
+	}
+	return ""
+}
+
+func (x *TeamMember) GetTeamId() uint32 {
+	if x != nil && x.TeamId != nil {
+		return *x.TeamId
+	}
+	return 0
+}
+
+func (x *TeamMember) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *TeamMember) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Team struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Logo          string                 `protobuf:"bytes,3,opt,name=logo,proto3" json:"logo,omitempty"`
+	Members       []*TeamMember          `protobuf:"bytes,4,rep,name=members,proto3" json:"members,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Team) Reset() {
+	*x = Team{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Team) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Team) ProtoMessage() {}
+
+func (x *Team) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Team.ProtoReflect.Descriptor instead.
+func (*Team) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Team) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Team) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Team) GetLogo() string {
+	if x != nil {
+		return x.Logo
+	}
+	return ""
+}
+
+func (x *Team) GetMembers() []*TeamMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *Team) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Team) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Feedback struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	TargetType    string                 `protobuf:"bytes,3,opt,name=target_type,json=targetType,proto3" json:"target_type,omitempty"`
+	TargetId      uint32                 `protobuf:"varint,4,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	TargetName    string                 `protobuf:"bytes,5,opt,name=target_name,json=targetName,proto3" json:"target_name,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Feedback) Reset() {
+	*x = Feedback{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Feedback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Feedback) ProtoMessage() {}
+
+func (x *Feedback) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Feedback.ProtoReflect.Descriptor instead.
+func (*Feedback) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Feedback) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Feedback) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Feedback) GetTargetType() string {
+	if x != nil {
+		return x.TargetType
+	}
+	return ""
+}
+
+func (x *Feedback) GetTargetId() uint32 {
+	if x != nil {
+		return x.TargetId
+	}
+	return 0
+}
+
+func (x *Feedback) GetTargetName() string {
+	if x != nil {
+		return x.TargetName
+	}
+	return ""
+}
+
+func (x *Feedback) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Feedback) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type CreateMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Picture       string                 `protobuf:"bytes,3,opt,name=picture,proto3" json:"picture,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMemberRequest) Reset() {
+	*x = CreateMemberRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMemberRequest) ProtoMessage() {}
+
+func (x *CreateMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMemberRequest.ProtoReflect.Descriptor instead.
+func (*CreateMemberRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateMemberRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateMemberRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateMemberRequest) GetPicture() string {
+	if x != nil {
+		return x.Picture
+	}
+	return ""
+}
+
+type GetMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMemberRequest) Reset() {
+	*x = GetMemberRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMemberRequest) ProtoMessage() {}
+
+func (x *GetMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMemberRequest.ProtoReflect.Descriptor instead.
+func (*GetMemberRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetMemberRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMembersRequest) Reset() {
+	*x = ListMembersRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMembersRequest) ProtoMessage() {}
+
+func (x *ListMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMembersRequest.ProtoReflect.Descriptor instead.
+func (*ListMembersRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{5}
+}
+
+type ListMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       []*TeamMember          `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMembersResponse) Reset() {
+	*x = ListMembersResponse{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMembersResponse) ProtoMessage() {}
+
+func (x *ListMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMembersResponse.ProtoReflect.Descriptor instead.
+func (*ListMembersResponse) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListMembersResponse) GetMembers() []*TeamMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type UpdateMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Email         *string                `protobuf:"bytes,3,opt,name=email,proto3,oneof" json:"email,omitempty"`
+	Picture       *string                `protobuf:"bytes,4,opt,name=picture,proto3,oneof" json:"picture,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMemberRequest) Reset() {
+	*x = UpdateMemberRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMemberRequest) ProtoMessage() {}
+
+func (x *UpdateMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMemberRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMemberRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateMemberRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateMemberRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateMemberRequest) GetEmail() string {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return ""
+}
+
+func (x *UpdateMemberRequest) GetPicture() string {
+	if x != nil && x.Picture != nil {
+		return *x.Picture
+	}
+	return ""
+}
+
+type DeleteMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMemberRequest) Reset() {
+	*x = DeleteMemberRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMemberRequest) ProtoMessage() {}
+
+func (x *DeleteMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMemberRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMemberRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteMemberRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteMemberResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMemberResponse) Reset() {
+	*x = DeleteMemberResponse{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMemberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMemberResponse) ProtoMessage() {}
+
+func (x *DeleteMemberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMemberResponse.ProtoReflect.Descriptor instead.
+func (*DeleteMemberResponse) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{9}
+}
+
+type CreateTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Logo          string                 `protobuf:"bytes,2,opt,name=logo,proto3" json:"logo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTeamRequest) Reset() {
+	*x = CreateTeamRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTeamRequest) ProtoMessage() {}
+
+func (x *CreateTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTeamRequest.ProtoReflect.Descriptor instead.
+func (*CreateTeamRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateTeamRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateTeamRequest) GetLogo() string {
+	if x != nil {
+		return x.Logo
+	}
+	return ""
+}
+
+type GetTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTeamRequest) Reset() {
+	*x = GetTeamRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTeamRequest) ProtoMessage() {}
+
+func (x *GetTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTeamRequest.ProtoReflect.Descriptor instead.
+func (*GetTeamRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetTeamRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListTeamsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTeamsRequest) Reset() {
+	*x = ListTeamsRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTeamsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTeamsRequest) ProtoMessage() {}
+
+func (x *ListTeamsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTeamsRequest.ProtoReflect.Descriptor instead.
+func (*ListTeamsRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{12}
+}
+
+type ListTeamsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Teams         []*Team                `protobuf:"bytes,1,rep,name=teams,proto3" json:"teams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTeamsResponse) Reset() {
+	*x = ListTeamsResponse{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTeamsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTeamsResponse) ProtoMessage() {}
+
+func (x *ListTeamsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTeamsResponse.ProtoReflect.Descriptor instead.
+func (*ListTeamsResponse) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListTeamsResponse) GetTeams() []*Team {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+type UpdateTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Logo          *string                `protobuf:"bytes,3,opt,name=logo,proto3,oneof" json:"logo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTeamRequest) Reset() {
+	*x = UpdateTeamRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTeamRequest) ProtoMessage() {}
+
+func (x *UpdateTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTeamRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTeamRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpdateTeamRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateTeamRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateTeamRequest) GetLogo() string {
+	if x != nil && x.Logo != nil {
+		return *x.Logo
+	}
+	return ""
+}
+
+type DeleteTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTeamRequest) Reset() {
+	*x = DeleteTeamRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTeamRequest) ProtoMessage() {}
+
+func (x *DeleteTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTeamRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTeamRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DeleteTeamRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteTeamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTeamResponse) Reset() {
+	*x = DeleteTeamResponse{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTeamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTeamResponse) ProtoMessage() {}
+
+func (x *DeleteTeamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTeamResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTeamResponse) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{16}
+}
+
+type AssignMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MemberId      uint32                 `protobuf:"varint,1,opt,name=member_id,json=memberId,proto3" json:"member_id,omitempty"`
+	TeamId        uint32                 `protobuf:"varint,2,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignMemberRequest) Reset() {
+	*x = AssignMemberRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignMemberRequest) ProtoMessage() {}
+
+func (x *AssignMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignMemberRequest.ProtoReflect.Descriptor instead.
+func (*AssignMemberRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AssignMemberRequest) GetMemberId() uint32 {
+	if x != nil {
+		return x.MemberId
+	}
+	return 0
+}
+
+func (x *AssignMemberRequest) GetTeamId() uint32 {
+	if x != nil {
+		return x.TeamId
+	}
+	return 0
+}
+
+type UnassignMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MemberId      uint32                 `protobuf:"varint,1,opt,name=member_id,json=memberId,proto3" json:"member_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnassignMemberRequest) Reset() {
+	*x = UnassignMemberRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnassignMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnassignMemberRequest) ProtoMessage() {}
+
+func (x *UnassignMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnassignMemberRequest.ProtoReflect.Descriptor instead.
+func (*UnassignMemberRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UnassignMemberRequest) GetMemberId() uint32 {
+	if x != nil {
+		return x.MemberId
+	}
+	return 0
+}
+
+type ListAssignedMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAssignedMembersRequest) Reset() {
+	*x = ListAssignedMembersRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAssignedMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAssignedMembersRequest) ProtoMessage() {}
+
+func (x *ListAssignedMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAssignedMembersRequest.ProtoReflect.Descriptor instead.
+func (*ListAssignedMembersRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{19}
+}
+
+type ListAssignedMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       []*TeamMember          `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAssignedMembersResponse) Reset() {
+	*x = ListAssignedMembersResponse{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAssignedMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAssignedMembersResponse) ProtoMessage() {}
+
+func (x *ListAssignedMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAssignedMembersResponse.ProtoReflect.Descriptor instead.
+func (*ListAssignedMembersResponse) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListAssignedMembersResponse) GetMembers() []*TeamMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type ListUnassignedMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUnassignedMembersRequest) Reset() {
+	*x = ListUnassignedMembersRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUnassignedMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUnassignedMembersRequest) ProtoMessage() {}
+
+func (x *ListUnassignedMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUnassignedMembersRequest.ProtoReflect.Descriptor instead.
+func (*ListUnassignedMembersRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{21}
+}
+
+type ListUnassignedMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       []*TeamMember          `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUnassignedMembersResponse) Reset() {
+	*x = ListUnassignedMembersResponse{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUnassignedMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUnassignedMembersResponse) ProtoMessage() {}
+
+func (x *ListUnassignedMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUnassignedMembersResponse.ProtoReflect.Descriptor instead.
+func (*ListUnassignedMembersResponse) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListUnassignedMembersResponse) GetMembers() []*TeamMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type CreateFeedbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	TargetType    string                 `protobuf:"bytes,2,opt,name=target_type,json=targetType,proto3" json:"target_type,omitempty"`
+	TargetId      uint32                 `protobuf:"varint,3,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateFeedbackRequest) Reset() {
+	*x = CreateFeedbackRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateFeedbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateFeedbackRequest) ProtoMessage() {}
+
+func (x *CreateFeedbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateFeedbackRequest.ProtoReflect.Descriptor instead.
+func (*CreateFeedbackRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateFeedbackRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *CreateFeedbackRequest) GetTargetType() string {
+	if x != nil {
+		return x.TargetType
+	}
+	return ""
+}
+
+func (x *CreateFeedbackRequest) GetTargetId() uint32 {
+	if x != nil {
+		return x.TargetId
+	}
+	return 0
+}
+
+type GetFeedbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFeedbackRequest) Reset() {
+	*x = GetFeedbackRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFeedbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFeedbackRequest) ProtoMessage() {}
+
+func (x *GetFeedbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFeedbackRequest.ProtoReflect.Descriptor instead.
+func (*GetFeedbackRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetFeedbackRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListFeedbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TargetType    *string                `protobuf:"bytes,1,opt,name=target_type,json=targetType,proto3,oneof" json:"target_type,omitempty"`
+	TargetId      *uint32                `protobuf:"varint,2,opt,name=target_id,json=targetId,proto3,oneof" json:"target_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFeedbackRequest) Reset() {
+	*x = ListFeedbackRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFeedbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFeedbackRequest) ProtoMessage() {}
+
+func (x *ListFeedbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFeedbackRequest.ProtoReflect.Descriptor instead.
+func (*ListFeedbackRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListFeedbackRequest) GetTargetType() string {
+	if x != nil && x.TargetType != nil {
+		return *x.TargetType
+	}
+	return ""
+}
+
+func (x *ListFeedbackRequest) GetTargetId() uint32 {
+	if x != nil && x.TargetId != nil {
+		return *x.TargetId
+	}
+	return 0
+}
+
+type ListFeedbackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Feedback      []*Feedback            `protobuf:"bytes,1,rep,name=feedback,proto3" json:"feedback,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFeedbackResponse) Reset() {
+	*x = ListFeedbackResponse{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFeedbackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFeedbackResponse) ProtoMessage() {}
+
+func (x *ListFeedbackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFeedbackResponse.ProtoReflect.Descriptor instead.
+func (*ListFeedbackResponse) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListFeedbackResponse) GetFeedback() []*Feedback {
+	if x != nil {
+		return x.Feedback
+	}
+	return nil
+}
+
+type UpdateFeedbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Content       *string                `protobuf:"bytes,2,opt,name=content,proto3,oneof" json:"content,omitempty"`
+	TargetType    *string                `protobuf:"bytes,3,opt,name=target_type,json=targetType,proto3,oneof" json:"target_type,omitempty"`
+	TargetId      *uint32                `protobuf:"varint,4,opt,name=target_id,json=targetId,proto3,oneof" json:"target_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateFeedbackRequest) Reset() {
+	*x = UpdateFeedbackRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFeedbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFeedbackRequest) ProtoMessage() {}
+
+func (x *UpdateFeedbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFeedbackRequest.ProtoReflect.Descriptor instead.
+func (*UpdateFeedbackRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UpdateFeedbackRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateFeedbackRequest) GetContent() string {
+	if x != nil && x.Content != nil {
+		return *x.Content
+	}
+	return ""
+}
+
+func (x *UpdateFeedbackRequest) GetTargetType() string {
+	if x != nil && x.TargetType != nil {
+		return *x.TargetType
+	}
+	return ""
+}
+
+func (x *UpdateFeedbackRequest) GetTargetId() uint32 {
+	if x != nil && x.TargetId != nil {
+		return *x.TargetId
+	}
+	return 0
+}
+
+type DeleteFeedbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFeedbackRequest) Reset() {
+	*x = DeleteFeedbackRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFeedbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFeedbackRequest) ProtoMessage() {}
+
+func (x *DeleteFeedbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFeedbackRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFeedbackRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *DeleteFeedbackRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteFeedbackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFeedbackResponse) Reset() {
+	*x = DeleteFeedbackResponse{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFeedbackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFeedbackResponse) ProtoMessage() {}
+
+func (x *DeleteFeedbackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFeedbackResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFeedbackResponse) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{29}
+}
+
+type ListTargetTypesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTargetTypesRequest) Reset() {
+	*x = ListTargetTypesRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTargetTypesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTargetTypesRequest) ProtoMessage() {}
+
+func (x *ListTargetTypesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTargetTypesRequest.ProtoReflect.Descriptor instead.
+func (*ListTargetTypesRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{30}
+}
+
+type TargetType struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Label         string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetType) Reset() {
+	*x = TargetType{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetType) ProtoMessage() {}
+
+func (x *TargetType) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetType.ProtoReflect.Descriptor instead.
+func (*TargetType) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *TargetType) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TargetType) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+type ListTargetTypesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TargetTypes   []*TargetType          `protobuf:"bytes,1,rep,name=target_types,json=targetTypes,proto3" json:"target_types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTargetTypesResponse) Reset() {
+	*x = ListTargetTypesResponse{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTargetTypesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTargetTypesResponse) ProtoMessage() {}
+
+func (x *ListTargetTypesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTargetTypesResponse.ProtoReflect.Descriptor instead.
+func (*ListTargetTypesResponse) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListTargetTypesResponse) GetTargetTypes() []*TargetType {
+	if x != nil {
+		return x.TargetTypes
+	}
+	return nil
+}
+
+type WatchFeedbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TargetType    *string                `protobuf:"bytes,1,opt,name=target_type,json=targetType,proto3,oneof" json:"target_type,omitempty"`
+	TargetId      *uint32                `protobuf:"varint,2,opt,name=target_id,json=targetId,proto3,oneof" json:"target_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchFeedbackRequest) Reset() {
+	*x = WatchFeedbackRequest{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchFeedbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchFeedbackRequest) ProtoMessage() {}
+
+func (x *WatchFeedbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchFeedbackRequest.ProtoReflect.Descriptor instead.
+func (*WatchFeedbackRequest) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *WatchFeedbackRequest) GetTargetType() string {
+	if x != nil && x.TargetType != nil {
+		return *x.TargetType
+	}
+	return ""
+}
+
+func (x *WatchFeedbackRequest) GetTargetId() uint32 {
+	if x != nil && x.TargetId != nil {
+		return *x.TargetId
+	}
+	return 0
+}
+
+type FeedbackEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  FeedbackEvent_Kind     `protobuf:"varint,1,opt,name=kind,proto3,enum=coaching.v1.FeedbackEvent_Kind" json:"kind,omitempty"`
+	// For KIND_DELETED, the feedback as it was before deletion.
+	Feedback      *Feedback `protobuf:"bytes,2,opt,name=feedback,proto3" json:"feedback,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FeedbackEvent) Reset() {
+	*x = FeedbackEvent{}
+	mi := &file_coaching_v1_coaching_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeedbackEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeedbackEvent) ProtoMessage() {}
+
+func (x *FeedbackEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_coaching_v1_coaching_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeedbackEvent.ProtoReflect.Descriptor instead.
+func (*FeedbackEvent) Descriptor() ([]byte, []int) {
+	return file_coaching_v1_coaching_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *FeedbackEvent) GetKind() FeedbackEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return FeedbackEvent_KIND_UNSPECIFIED
+}
+
+func (x *FeedbackEvent) GetFeedback() *Feedback {
+	if x != nil {
+		return x.Feedback
+	}
+	return nil
+}
+
+var File_coaching_v1_coaching_proto protoreflect.FileDescriptor
+
+const file_coaching_v1_coaching_proto_rawDesc = "" +
+	"\n" +
+	"\x1acoaching/v1/coaching.proto\x12\vcoaching.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x80\x02\n" +
+	"\n" +
+	"TeamMember\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x18\n" +
+	"\apicture\x18\x04 \x01(\tR\apicture\x12\x1c\n" +
+	"\ateam_id\x18\x05 \x01(\rH\x00R\x06teamId\x88\x01\x01\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAtB\n" +
+	"\n" +
+	"\b_team_id\"\xe7\x01\n" +
+	"\x04Team\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04logo\x18\x03 \x01(\tR\x04logo\x121\n" +
+	"\amembers\x18\x04 \x03(\v2\x17.coaching.v1.TeamMemberR\amembers\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x89\x02\n" +
+	"\bFeedback\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x1f\n" +
+	"\vtarget_type\x18\x03 \x01(\tR\n" +
+	"targetType\x12\x1b\n" +
+	"\ttarget_id\x18\x04 \x01(\rR\btargetId\x12\x1f\n" +
+	"\vtarget_name\x18\x05 \x01(\tR\n" +
+	"targetName\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"Y\n" +
+	"\x13CreateMemberRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x18\n" +
+	"\apicture\x18\x03 \x01(\tR\apicture\"\"\n" +
+	"\x10GetMemberRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\"\x14\n" +
+	"\x12ListMembersRequest\"H\n" +
+	"\x13ListMembersResponse\x121\n" +
+	"\amembers\x18\x01 \x03(\v2\x17.coaching.v1.TeamMemberR\amembers\"\x97\x01\n" +
+	"\x13UpdateMemberRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x19\n" +
+	"\x05email\x18\x03 \x01(\tH\x01R\x05email\x88\x01\x01\x12\x1d\n" +
+	"\apicture\x18\x04 \x01(\tH\x02R\apicture\x88\x01\x01B\a\n" +
+	"\x05_nameB\b\n" +
+	"\x06_emailB\n" +
+	"\n" +
+	"\b_picture\"%\n" +
+	"\x13DeleteMemberRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\"\x16\n" +
+	"\x14DeleteMemberResponse\";\n" +
+	"\x11CreateTeamRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04logo\x18\x02 \x01(\tR\x04logo\" \n" +
+	"\x0eGetTeamRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\"\x12\n" +
+	"\x10ListTeamsRequest\"<\n" +
+	"\x11ListTeamsResponse\x12'\n" +
+	"\x05teams\x18\x01 \x03(\v2\x11.coaching.v1.TeamR\x05teams\"g\n" +
+	"\x11UpdateTeamRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x17\n" +
+	"\x04logo\x18\x03 \x01(\tH\x01R\x04logo\x88\x01\x01B\a\n" +
+	"\x05_nameB\a\n" +
+	"\x05_logo\"#\n" +
+	"\x11DeleteTeamRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\"\x14\n" +
+	"\x12DeleteTeamResponse\"K\n" +
+	"\x13AssignMemberRequest\x12\x1b\n" +
+	"\tmember_id\x18\x01 \x01(\rR\bmemberId\x12\x17\n" +
+	"\ateam_id\x18\x02 \x01(\rR\x06teamId\"4\n" +
+	"\x15UnassignMemberRequest\x12\x1b\n" +
+	"\tmember_id\x18\x01 \x01(\rR\bmemberId\"\x1c\n" +
+	"\x1aListAssignedMembersRequest\"P\n" +
+	"\x1bListAssignedMembersResponse\x121\n" +
+	"\amembers\x18\x01 \x03(\v2\x17.coaching.v1.TeamMemberR\amembers\"\x1e\n" +
+	"\x1cListUnassignedMembersRequest\"R\n" +
+	"\x1dListUnassignedMembersResponse\x121\n" +
+	"\amembers\x18\x01 \x03(\v2\x17.coaching.v1.TeamMemberR\amembers\"o\n" +
+	"\x15CreateFeedbackRequest\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\x12\x1f\n" +
+	"\vtarget_type\x18\x02 \x01(\tR\n" +
+	"targetType\x12\x1b\n" +
+	"\ttarget_id\x18\x03 \x01(\rR\btargetId\"$\n" +
+	"\x12GetFeedbackRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\"{\n" +
+	"\x13ListFeedbackRequest\x12$\n" +
+	"\vtarget_type\x18\x01 \x01(\tH\x00R\n" +
+	"targetType\x88\x01\x01\x12 \n" +
+	"\ttarget_id\x18\x02 \x01(\rH\x01R\btargetId\x88\x01\x01B\x0e\n" +
+	"\f_target_typeB\f\n" +
+	"\n" +
+	"_target_id\"I\n" +
+	"\x14ListFeedbackResponse\x121\n" +
+	"\bfeedback\x18\x01 \x03(\v2\x15.coaching.v1.FeedbackR\bfeedback\"\xb8\x01\n" +
+	"\x15UpdateFeedbackRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x1d\n" +
+	"\acontent\x18\x02 \x01(\tH\x00R\acontent\x88\x01\x01\x12$\n" +
+	"\vtarget_type\x18\x03 \x01(\tH\x01R\n" +
+	"targetType\x88\x01\x01\x12 \n" +
+	"\ttarget_id\x18\x04 \x01(\rH\x02R\btargetId\x88\x01\x01B\n" +
+	"\n" +
+	"\b_contentB\x0e\n" +
+	"\f_target_typeB\f\n" +
+	"\n" +
+	"_target_id\"'\n" +
+	"\x15DeleteFeedbackRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\"\x18\n" +
+	"\x16DeleteFeedbackResponse\"\x18\n" +
+	"\x16ListTargetTypesRequest\"6\n" +
+	"\n" +
+	"TargetType\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\"U\n" +
+	"\x17ListTargetTypesResponse\x12:\n" +
+	"\ftarget_types\x18\x01 \x03(\v2\x17.coaching.v1.TargetTypeR\vtargetTypes\"|\n" +
+	"\x14WatchFeedbackRequest\x12$\n" +
+	"\vtarget_type\x18\x01 \x01(\tH\x00R\n" +
+	"targetType\x88\x01\x01\x12 \n" +
+	"\ttarget_id\x18\x02 \x01(\rH\x01R\btargetId\x88\x01\x01B\x0e\n" +
+	"\f_target_typeB\f\n" +
+	"\n" +
+	"_target_id\"\xcb\x01\n" +
+	"\rFeedbackEvent\x123\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1f.coaching.v1.FeedbackEvent.KindR\x04kind\x121\n" +
+	"\bfeedback\x18\x02 \x01(\v2\x15.coaching.v1.FeedbackR\bfeedback\"R\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fKIND_CREATED\x10\x01\x12\x10\n" +
+	"\fKIND_UPDATED\x10\x02\x12\x10\n" +
+	"\fKIND_DELETED\x10\x032\x91\x03\n" +
+	"\rMemberService\x12I\n" +
+	"\fCreateMember\x12 .coaching.v1.CreateMemberRequest\x1a\x17.coaching.v1.TeamMember\x12C\n" +
+	"\tGetMember\x12\x1d.coaching.v1.GetMemberRequest\x1a\x17.coaching.v1.TeamMember\x12P\n" +
+	"\vListMembers\x12\x1f.coaching.v1.ListMembersRequest\x1a .coaching.v1.ListMembersResponse\x12I\n" +
+	"\fUpdateMember\x12 .coaching.v1.UpdateMemberRequest\x1a\x17.coaching.v1.TeamMember\x12S\n" +
+	"\fDeleteMember\x12 .coaching.v1.DeleteMemberRequest\x1a!.coaching.v1.DeleteMemberResponse2\xe5\x02\n" +
+	"\vTeamService\x12?\n" +
+	"\n" +
+	"CreateTeam\x12\x1e.coaching.v1.CreateTeamRequest\x1a\x11.coaching.v1.Team\x129\n" +
+	"\aGetTeam\x12\x1b.coaching.v1.GetTeamRequest\x1a\x11.coaching.v1.Team\x12J\n" +
+	"\tListTeams\x12\x1d.coaching.v1.ListTeamsRequest\x1a\x1e.coaching.v1.ListTeamsResponse\x12?\n" +
+	"\n" +
+	"UpdateTeam\x12\x1e.coaching.v1.UpdateTeamRequest\x1a\x11.coaching.v1.Team\x12M\n" +
+	"\n" +
+	"DeleteTeam\x12\x1e.coaching.v1.DeleteTeamRequest\x1a\x1f.coaching.v1.DeleteTeamResponse2\x87\x03\n" +
+	"\x11AssignmentService\x12I\n" +
+	"\fAssignMember\x12 .coaching.v1.AssignMemberRequest\x1a\x17.coaching.v1.TeamMember\x12M\n" +
+	"\x0eUnassignMember\x12\".coaching.v1.UnassignMemberRequest\x1a\x17.coaching.v1.TeamMember\x12h\n" +
+	"\x13ListAssignedMembers\x12'.coaching.v1.ListAssignedMembersRequest\x1a(.coaching.v1.ListAssignedMembersResponse\x12n\n" +
+	"\x15ListUnassignedMembers\x12).coaching.v1.ListUnassignedMembersRequest\x1a*.coaching.v1.ListUnassignedMembersResponse2\xd2\x04\n" +
+	"\x0fFeedbackService\x12K\n" +
+	"\x0eCreateFeedback\x12\".coaching.v1.CreateFeedbackRequest\x1a\x15.coaching.v1.Feedback\x12E\n" +
+	"\vGetFeedback\x12\x1f.coaching.v1.GetFeedbackRequest\x1a\x15.coaching.v1.Feedback\x12S\n" +
+	"\fListFeedback\x12 .coaching.v1.ListFeedbackRequest\x1a!.coaching.v1.ListFeedbackResponse\x12K\n" +
+	"\x0eUpdateFeedback\x12\".coaching.v1.UpdateFeedbackRequest\x1a\x15.coaching.v1.Feedback\x12Y\n" +
+	"\x0eDeleteFeedback\x12\".coaching.v1.DeleteFeedbackRequest\x1a#.coaching.v1.DeleteFeedbackResponse\x12\\\n" +
+	"\x0fListTargetTypes\x12#.coaching.v1.ListTargetTypesRequest\x1a$.coaching.v1.ListTargetTypesResponse\x12P\n" +
+	"\rWatchFeedback\x12!.coaching.v1.WatchFeedbackRequest\x1a\x1a.coaching.v1.FeedbackEvent0\x01B-Z+coaching-backend/gen/coaching/v1;coachingv1b\x06proto3"
+
+var (
+	file_coaching_v1_coaching_proto_rawDescOnce sync.Once
+	file_coaching_v1_coaching_proto_rawDescData []byte
+)
+
+func file_coaching_v1_coaching_proto_rawDescGZIP() []byte {
+	file_coaching_v1_coaching_proto_rawDescOnce.Do(func() {
+		file_coaching_v1_coaching_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_coaching_v1_coaching_proto_rawDesc), len(file_coaching_v1_coaching_proto_rawDesc)))
+	})
+	return file_coaching_v1_coaching_proto_rawDescData
+}
+
+var file_coaching_v1_coaching_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_coaching_v1_coaching_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_coaching_v1_coaching_proto_goTypes = []any{
+	(FeedbackEvent_Kind)(0),               // 0: coaching.v1.FeedbackEvent.Kind
+	(*TeamMember)(nil),                    // 1: coaching.v1.TeamMember
+	(*Team)(nil),                          // 2: coaching.v1.Team
+	(*Feedback)(nil),                      // 3: coaching.v1.Feedback
+	(*CreateMemberRequest)(nil),           // 4: coaching.v1.CreateMemberRequest
+	(*GetMemberRequest)(nil),              // 5: coaching.v1.GetMemberRequest
+	(*ListMembersRequest)(nil),            // 6: coaching.v1.ListMembersRequest
+	(*ListMembersResponse)(nil),           // 7: coaching.v1.ListMembersResponse
+	(*UpdateMemberRequest)(nil),           // 8: coaching.v1.UpdateMemberRequest
+	(*DeleteMemberRequest)(nil),           // 9: coaching.v1.DeleteMemberRequest
+	(*DeleteMemberResponse)(nil),          // 10: coaching.v1.DeleteMemberResponse
+	(*CreateTeamRequest)(nil),             // 11: coaching.v1.CreateTeamRequest
+	(*GetTeamRequest)(nil),                // 12: coaching.v1.GetTeamRequest
+	(*ListTeamsRequest)(nil),              // 13: coaching.v1.ListTeamsRequest
+	(*ListTeamsResponse)(nil),             // 14: coaching.v1.ListTeamsResponse
+	(*UpdateTeamRequest)(nil),             // 15: coaching.v1.UpdateTeamRequest
+	(*DeleteTeamRequest)(nil),             // 16: coaching.v1.DeleteTeamRequest
+	(*DeleteTeamResponse)(nil),            // 17: coaching.v1.DeleteTeamResponse
+	(*AssignMemberRequest)(nil),           // 18: coaching.v1.AssignMemberRequest
+	(*UnassignMemberRequest)(nil),         // 19: coaching.v1.UnassignMemberRequest
+	(*ListAssignedMembersRequest)(nil),    // 20: coaching.v1.ListAssignedMembersRequest
+	(*ListAssignedMembersResponse)(nil),   // 21: coaching.v1.ListAssignedMembersResponse
+	(*ListUnassignedMembersRequest)(nil),  // 22: coaching.v1.ListUnassignedMembersRequest
+	(*ListUnassignedMembersResponse)(nil), // 23: coaching.v1.ListUnassignedMembersResponse
+	(*CreateFeedbackRequest)(nil),         // 24: coaching.v1.CreateFeedbackRequest
+	(*GetFeedbackRequest)(nil),            // 25: coaching.v1.GetFeedbackRequest
+	(*ListFeedbackRequest)(nil),           // 26: coaching.v1.ListFeedbackRequest
+	(*ListFeedbackResponse)(nil),          // 27: coaching.v1.ListFeedbackResponse
+	(*UpdateFeedbackRequest)(nil),         // 28: coaching.v1.UpdateFeedbackRequest
+	(*DeleteFeedbackRequest)(nil),         // 29: coaching.v1.DeleteFeedbackRequest
+	(*DeleteFeedbackResponse)(nil),        // 30: coaching.v1.DeleteFeedbackResponse
+	(*ListTargetTypesRequest)(nil),        // 31: coaching.v1.ListTargetTypesRequest
+	(*TargetType)(nil),                    // 32: coaching.v1.TargetType
+	(*ListTargetTypesResponse)(nil),       // 33: coaching.v1.ListTargetTypesResponse
+	(*WatchFeedbackRequest)(nil),          // 34: coaching.v1.WatchFeedbackRequest
+	(*FeedbackEvent)(nil),                 // 35: coaching.v1.FeedbackEvent
+	(*timestamppb.Timestamp)(nil),         // 36: google.protobuf.Timestamp
+}
+var file_coaching_v1_coaching_proto_depIdxs = []int32{
+	36, // 0: coaching.v1.TeamMember.created_at:type_name -> google.protobuf.Timestamp
+	36, // 1: coaching.v1.TeamMember.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 2: coaching.v1.Team.members:type_name -> coaching.v1.TeamMember
+	36, // 3: coaching.v1.Team.created_at:type_name -> google.protobuf.Timestamp
+	36, // 4: coaching.v1.Team.updated_at:type_name -> google.protobuf.Timestamp
+	36, // 5: coaching.v1.Feedback.created_at:type_name -> google.protobuf.Timestamp
+	36, // 6: coaching.v1.Feedback.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 7: coaching.v1.ListMembersResponse.members:type_name -> coaching.v1.TeamMember
+	2,  // 8: coaching.v1.ListTeamsResponse.teams:type_name -> coaching.v1.Team
+	1,  // 9: coaching.v1.ListAssignedMembersResponse.members:type_name -> coaching.v1.TeamMember
+	1,  // 10: coaching.v1.ListUnassignedMembersResponse.members:type_name -> coaching.v1.TeamMember
+	3,  // 11: coaching.v1.ListFeedbackResponse.feedback:type_name -> coaching.v1.Feedback
+	32, // 12: coaching.v1.ListTargetTypesResponse.target_types:type_name -> coaching.v1.TargetType
+	0,  // 13: coaching.v1.FeedbackEvent.kind:type_name -> coaching.v1.FeedbackEvent.Kind
+	3,  // 14: coaching.v1.FeedbackEvent.feedback:type_name -> coaching.v1.Feedback
+	4,  // 15: coaching.v1.MemberService.CreateMember:input_type -> coaching.v1.CreateMemberRequest
+	5,  // 16: coaching.v1.MemberService.GetMember:input_type -> coaching.v1.GetMemberRequest
+	6,  // 17: coaching.v1.MemberService.ListMembers:input_type -> coaching.v1.ListMembersRequest
+	8,  // 18: coaching.v1.MemberService.UpdateMember:input_type -> coaching.v1.UpdateMemberRequest
+	9,  // 19: coaching.v1.MemberService.DeleteMember:input_type -> coaching.v1.DeleteMemberRequest
+	11, // 20: coaching.v1.TeamService.CreateTeam:input_type -> coaching.v1.CreateTeamRequest
+	12, // 21: coaching.v1.TeamService.GetTeam:input_type -> coaching.v1.GetTeamRequest
+	13, // 22: coaching.v1.TeamService.ListTeams:input_type -> coaching.v1.ListTeamsRequest
+	15, // 23: coaching.v1.TeamService.UpdateTeam:input_type -> coaching.v1.UpdateTeamRequest
+	16, // 24: coaching.v1.TeamService.DeleteTeam:input_type -> coaching.v1.DeleteTeamRequest
+	18, // 25: coaching.v1.AssignmentService.AssignMember:input_type -> coaching.v1.AssignMemberRequest
+	19, // 26: coaching.v1.AssignmentService.UnassignMember:input_type -> coaching.v1.UnassignMemberRequest
+	20, // 27: coaching.v1.AssignmentService.ListAssignedMembers:input_type -> coaching.v1.ListAssignedMembersRequest
+	22, // 28: coaching.v1.AssignmentService.ListUnassignedMembers:input_type -> coaching.v1.ListUnassignedMembersRequest
+	24, // 29: coaching.v1.FeedbackService.CreateFeedback:input_type -> coaching.v1.CreateFeedbackRequest
+	25, // 30: coaching.v1.FeedbackService.GetFeedback:input_type -> coaching.v1.GetFeedbackRequest
+	26, // 31: coaching.v1.FeedbackService.ListFeedback:input_type -> coaching.v1.ListFeedbackRequest
+	28, // 32: coaching.v1.FeedbackService.UpdateFeedback:input_type -> coaching.v1.UpdateFeedbackRequest
+	29, // 33: coaching.v1.FeedbackService.DeleteFeedback:input_type -> coaching.v1.DeleteFeedbackRequest
+	31, // 34: coaching.v1.FeedbackService.ListTargetTypes:input_type -> coaching.v1.ListTargetTypesRequest
+	34, // 35: coaching.v1.FeedbackService.WatchFeedback:input_type -> coaching.v1.WatchFeedbackRequest
+	1,  // 36: coaching.v1.MemberService.CreateMember:output_type -> coaching.v1.TeamMember
+	1,  // 37: coaching.v1.MemberService.GetMember:output_type -> coaching.v1.TeamMember
+	7,  // 38: coaching.v1.MemberService.ListMembers:output_type -> coaching.v1.ListMembersResponse
+	1,  // 39: coaching.v1.MemberService.UpdateMember:output_type -> coaching.v1.TeamMember
+	10, // 40: coaching.v1.MemberService.DeleteMember:output_type -> coaching.v1.DeleteMemberResponse
+	2,  // 41: coaching.v1.TeamService.CreateTeam:output_type -> coaching.v1.Team
+	2,  // 42: coaching.v1.TeamService.GetTeam:output_type -> coaching.v1.Team
+	14, // 43: coaching.v1.TeamService.ListTeams:output_type -> coaching.v1.ListTeamsResponse
+	2,  // 44: coaching.v1.TeamService.UpdateTeam:output_type -> coaching.v1.Team
+	17, // 45: coaching.v1.TeamService.DeleteTeam:output_type -> coaching.v1.DeleteTeamResponse
+	1,  // 46: coaching.v1.AssignmentService.AssignMember:output_type -> coaching.v1.TeamMember
+	1,  // 47: coaching.v1.AssignmentService.UnassignMember:output_type -> coaching.v1.TeamMember
+	21, // 48: coaching.v1.AssignmentService.ListAssignedMembers:output_type -> coaching.v1.ListAssignedMembersResponse
+	23, // 49: coaching.v1.AssignmentService.ListUnassignedMembers:output_type -> coaching.v1.ListUnassignedMembersResponse
+	3,  // 50: coaching.v1.FeedbackService.CreateFeedback:output_type -> coaching.v1.Feedback
+	3,  // 51: coaching.v1.FeedbackService.GetFeedback:output_type -> coaching.v1.Feedback
+	27, // 52: coaching.v1.FeedbackService.ListFeedback:output_type -> coaching.v1.ListFeedbackResponse
+	3,  // 53: coaching.v1.FeedbackService.UpdateFeedback:output_type -> coaching.v1.Feedback
+	30, // 54: coaching.v1.FeedbackService.DeleteFeedback:output_type -> coaching.v1.DeleteFeedbackResponse
+	33, // 55: coaching.v1.FeedbackService.ListTargetTypes:output_type -> coaching.v1.ListTargetTypesResponse
+	35, // 56: coaching.v1.FeedbackService.WatchFeedback:output_type -> coaching.v1.FeedbackEvent
+	36, // [36:57] is the sub-list for method output_type
+	15, // [15:36] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_coaching_v1_coaching_proto_init() }
+func file_coaching_v1_coaching_proto_init() {
+	if File_coaching_v1_coaching_proto != nil {
+		return
+	}
+	file_coaching_v1_coaching_proto_msgTypes[0].OneofWrappers = []any{}
+	file_coaching_v1_coaching_proto_msgTypes[7].OneofWrappers = []any{}
+	file_coaching_v1_coaching_proto_msgTypes[14].OneofWrappers = []any{}
+	file_coaching_v1_coaching_proto_msgTypes[25].OneofWrappers = []any{}
+	file_coaching_v1_coaching_proto_msgTypes[27].OneofWrappers = []any{}
+	file_coaching_v1_coaching_proto_msgTypes[33].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_coaching_v1_coaching_proto_rawDesc), len(file_coaching_v1_coaching_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   35,
+			NumExtensions: 0,
+			NumServices:   4,
+		},
+		GoTypes:           file_coaching_v1_coaching_proto_goTypes,
+		DependencyIndexes: file_coaching_v1_coaching_proto_depIdxs,
+		EnumInfos:         file_coaching_v1_coaching_proto_enumTypes,
+		MessageInfos:      file_coaching_v1_coaching_proto_msgTypes,
+	}.Build()
+	File_coaching_v1_coaching_proto = out.File
+	file_coaching_v1_coaching_proto_goTypes = nil
+	file_coaching_v1_coaching_proto_depIdxs = nil
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/services"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Each field reading or changing records needs the scope of the resource
+// it concerns, like the REST route for it would: read access for queries
+// and relationship fields, write access for mutations. Mutations also
+// need graphql:write; the route itself only requires graphql:read.
+
+var queryResources = map[string]string{
+	"teams":               "teams",
+	"team":                "teams",
+	"members":             "members",
+	"member":              "members",
+	"unassignedMembers":   "assignments",
+	"feedback":            "feedback",
+	"feedbackById":        "feedback",
+	"feedbackTargetTypes": "feedback",
+}
+
+var mutationResources = map[string]string{
+	"createTeamMember":     "members",
+	"updateTeamMember":     "members",
+	"deleteTeamMember":     "members",
+	"createTeam":           "teams",
+	"updateTeam":           "teams",
+	"deleteTeam":           "teams",
+	"assignMemberToTeam":   "assignments",
+	"removeMemberFromTeam": "assignments",
+	"createFeedback":       "feedback",
+	"updateFeedback":       "feedback",
+	"deleteFeedback":       "feedback",
+}
+
+var memberResources = map[string]string{
+	"team":     "teams",
+	"feedback": "feedback",
+}
+
+var teamResources = map[string]string{
+	"members":  "members",
+	"feedback": "feedback",
+}
+
+// guard makes the fields listed in resources check the principal's access
+// to their resource before resolving. With required, every field must be
+// listed, so that no root field is left unguarded.
+func guard(fields graphql.Fields, access string, resources map[string]string, required bool) graphql.Fields {
+	for name, field := range fields {
+		resource, ok := resources[name]
+		if !ok {
+			if required {
+				panic("graph: no scope for field " + name)
+			}
+			continue
+		}
+		resolve := field.Resolve
+		if resolve == nil {
+			resolve = graphql.DefaultResolveFn
+		}
+		field.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+			if err := authorize(p, resource, access); err != nil {
+				return nil, err
+			}
+			return resolve(p)
+		}
+	}
+	return fields
+}
+
+func authorize(p graphql.ResolveParams, resource, access string) error {
+	if access == models.AccessWrite {
+		if err := services.Authorize(p.Context, "graphql", models.AccessWrite); err != nil {
+			return serviceError(err)
+		}
+	}
+	if err := services.Authorize(p.Context, resource, access); err != nil {
+		return serviceError(err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits bound how much work a single query may request.
+type Limits struct {
+	// MaxDepth is the deepest allowed selection nesting.
+	MaxDepth int
+	// MaxComplexity caps the query cost: each field costs 1, and the cost of
+	// a list field's selections is multiplied by ListFactor.
+	MaxComplexity int
+	ListFactor    int
+}
+
+var DefaultLimits = Limits{MaxDepth: 8, MaxComplexity: 5000, ListFactor: 10}
+
+type complexityWalker struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	factor    int
+	maxDepth  int
+}
+
+// analyze returns the cost and depth of the selected operation.
+func analyze(schema graphql.Schema, doc *ast.Document, operationName string, limits Limits) (cost, depth int, err error) {
+	w := &complexityWalker{
+		schema:    schema,
+		fragments: map[string]*ast.FragmentDefinition{},
+		factor:    limits.ListFactor,
+	}
+
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			w.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		}
+	}
+	if op == nil {
+		return 0, 0, fmt.Errorf("operation %q not found", operationName)
+	}
+
+	root := schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+
+	cost = w.selectionSet(op.SelectionSet, root, 1, map[string]bool{})
+	return cost, w.maxDepth, nil
+}
+
+func (w *complexityWalker) selectionSet(set *ast.SelectionSet, parent graphql.Type, depth int, visiting map[string]bool) int {
+	if set == nil {
+		return 0
+	}
+	if depth > w.maxDepth {
+		w.maxDepth = depth
+	}
+
+	cost := 0
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			cost += w.field(sel, parent, depth, visiting)
+		case *ast.InlineFragment:
+			t := parent
+			if sel.TypeCondition != nil {
+				if named := w.schema.Type(sel.TypeCondition.Name.Value); named != nil {
+					t = named
+				}
+			}
+			cost += w.selectionSet(sel.SelectionSet, t, depth, visiting)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			frag, ok := w.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			t := parent
+			if named := w.schema.Type(frag.TypeCondition.Name.Value); named != nil {
+				t = named
+			}
+			cost += w.selectionSet(frag.SelectionSet, t, depth, visiting)
+			delete(visiting, name)
+		}
+	}
+	return cost
+}
+
+func (w *complexityWalker) field(f *ast.Field, parent graphql.Type, depth int, visiting map[string]bool) int {
+	name := f.Name.Value
+	// Introspection is bounded by the schema size; count it once.
+	if len(name) >= 2 && name[:2] == "__" {
+		return 1
+	}
+
+	obj, ok := parent.(*graphql.Object)
+	if !ok {
+		return 1 + w.selectionSet(f.SelectionSet, parent, depth+1, visiting)
+	}
+	def, ok := obj.Fields()[name]
+	if !ok {
+		return 1
+	}
+
+	multiplier := 1
+	t := def.Type
+	if nn, ok := t.(*graphql.NonNull); ok {
+		t = nn.OfType
+	}
+	if _, ok := t.(*graphql.List); ok {
+		multiplier = w.factor
+	}
+
+	child, _ := graphql.GetNamed(def.Type).(graphql.Type)
+	return 1 + multiplier*w.selectionSet(f.SelectionSet, child, depth+1, visiting)
+}
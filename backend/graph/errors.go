@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"errors"
+	"log"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Error is a GraphQL error carrying the same stable codes as the REST
+// problem responses in its extensions.
+type Error struct {
+	Message string
+	Code    string
+	Fields  []problem.FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.Code}
+	if len(e.Fields) > 0 {
+		ext["errors"] = e.Fields
+	}
+	return ext
+}
+
+func invalidArgument(field, message string) error {
+	return &Error{
+		Message: "Invalid " + field,
+		Code:    problem.CodeInvalidParam,
+		Fields:  []problem.FieldError{{Field: field, Rule: "type", Message: message}},
+	}
+}
+
+// serviceError converts an error from the services package.
+func serviceError(err error) error {
+	var serviceErr *services.Error
+	if !errors.As(err, &serviceErr) {
+		return err
+	}
+	if serviceErr.Err != nil {
+		log.Printf("graphql: %s: %v", serviceErr.Message, serviceErr.Err)
+	}
+	return &Error{Message: serviceErr.Message, Code: serviceErr.Code, Fields: serviceErr.Fields}
+}
+
+// databaseError converts an error from a query the resolvers make
+// themselves.
+func databaseError(err error, failure string) error {
+	return serviceError(services.DatabaseError(err, failure))
+}
+
+// requestError formats an error raised before execution. gqlerrors only
+// keeps extensions for errors raised inside resolvers.
+func requestError(err *Error) []gqlerrors.FormattedError {
+	formatted := gqlerrors.FormatError(err)
+	formatted.Extensions = err.Extensions()
+	return []gqlerrors.FormattedError{formatted}
+}
@@ -0,0 +1,365 @@
+package graph
+
+import (
+	"bytes"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupGin() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return gin.New()
+}
+
+type testResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func execute(t *testing.T, r *gin.Engine, query string, variables map[string]interface{}) testResponse {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp testResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// countQueries counts SELECT statements issued through db.
+func countQueries(db *gorm.DB) *int {
+	n := 0
+	db.Callback().Query().Before("gorm:query").Register("test:count", func(*gorm.DB) { n++ })
+	return &n
+}
+
+func TestQueries(t *testing.T) {
+	t.Run("Nested Team Members And Feedback", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		for i := 0; i < 3; i++ {
+			team := testutils.CreateTestTeam(db)
+			for j := 0; j < 2; j++ {
+				member := testutils.CreateTestTeamMember(db)
+				member.TeamID = &team.ID
+				db.Save(member)
+				testutils.CreateTestFeedback(db, "member", member.ID)
+			}
+			testutils.CreateTestFeedback(db, "team", team.ID)
+		}
+
+		queries := countQueries(db)
+		resp := execute(t, r, `{
+			teams {
+				id name
+				feedback { content targetName }
+				members { name email team { name } feedback { content } }
+			}
+		}`, nil)
+
+		assert.Empty(t, resp.Errors)
+		teams := resp.Data["teams"].([]interface{})
+		assert.Len(t, teams, 3)
+		for _, raw := range teams {
+			team := raw.(map[string]interface{})
+			assert.Len(t, team["feedback"], 1)
+			members := team["members"].([]interface{})
+			assert.Len(t, members, 2)
+			for _, m := range members {
+				member := m.(map[string]interface{})
+				assert.Equal(t, team["name"], member["team"].(map[string]interface{})["name"])
+				assert.Len(t, member["feedback"], 1)
+			}
+		}
+		// teams, members, member teams, and two feedback batches (the team
+		// feedback batch may include some member keys, depending on field
+		// order): the count does not grow with the number of rows.
+		assert.Equal(t, 5, *queries)
+	})
+
+	t.Run("Not Found Carries Code", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		resp := execute(t, r, `{ team(id: 999) { name } }`, nil)
+
+		assert.Nil(t, resp.Data["team"])
+		assert.Len(t, resp.Errors, 1)
+		assert.Equal(t, "Team not found", resp.Errors[0].Message)
+		assert.Equal(t, "not_found", resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		resp := execute(t, r, `{ member(id: "abc") { name } }`, nil)
+
+		assert.Len(t, resp.Errors, 1)
+		assert.Equal(t, "invalid_parameter", resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("Feedback Target Types", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		resp := execute(t, r, `{ feedbackTargetTypes { type label } }`, nil)
+
+		assert.Empty(t, resp.Errors)
+		assert.Contains(t, resp.Data["feedbackTargetTypes"], map[string]interface{}{"type": "team", "label": "Team"})
+	})
+
+	t.Run("Missing Query", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	})
+}
+
+func TestMutations(t *testing.T) {
+	t.Run("Create Assign And Give Feedback", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		resp := execute(t, r, `mutation { createTeam(input: {name: "Platform", logo: "https://example.com/p.png"}) { id } }`, nil)
+		assert.Empty(t, resp.Errors)
+		teamID := resp.Data["createTeam"].(map[string]interface{})["id"]
+
+		resp = execute(t, r, `mutation($input: TeamMemberInput!) { createTeamMember(input: $input) { id } }`, map[string]interface{}{
+			"input": map[string]interface{}{"name": "Jane", "email": "jane@example.com", "picture": "https://example.com/j.png"},
+		})
+		assert.Empty(t, resp.Errors)
+		memberID := resp.Data["createTeamMember"].(map[string]interface{})["id"]
+
+		resp = execute(t, r, `mutation($m: ID!, $t: ID!) { assignMemberToTeam(memberId: $m, teamId: $t) { team { name } } }`,
+			map[string]interface{}{"m": memberID, "t": teamID})
+		assert.Empty(t, resp.Errors)
+		assert.Equal(t, "Platform", resp.Data["assignMemberToTeam"].(map[string]interface{})["team"].(map[string]interface{})["name"])
+
+		resp = execute(t, r, fmt.Sprintf(`mutation { createFeedback(input: {content: "Nice", targetType: "member", targetId: %s}) { targetName } }`, memberID), nil)
+		assert.Empty(t, resp.Errors)
+		assert.Equal(t, "Jane", resp.Data["createFeedback"].(map[string]interface{})["targetName"])
+
+		var count int64
+		db.Model(&models.Feedback{}).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Validation Failure", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		resp := execute(t, r, `mutation { createTeamMember(input: {name: "Jane", email: "not-an-email", picture: "https://example.com/j.png"}) { id } }`, nil)
+
+		assert.Len(t, resp.Errors, 1)
+		assert.Equal(t, "validation_failed", resp.Errors[0].Extensions["code"])
+		fields := resp.Errors[0].Extensions["errors"].([]interface{})
+		assert.Equal(t, "email", fields[0].(map[string]interface{})["field"])
+	})
+
+	t.Run("Duplicate Team Name", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		team := testutils.CreateTestTeam(db)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		resp := execute(t, r, fmt.Sprintf(`mutation { createTeam(input: {name: %q, logo: "https://example.com/l.png"}) { id } }`, team.Name), nil)
+
+		assert.Len(t, resp.Errors, 1)
+		assert.Equal(t, "conflict", resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("Unknown Feedback Target", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		resp := execute(t, r, `mutation { createFeedback(input: {content: "Hi", targetType: "team", targetId: 7}) { id } }`, nil)
+
+		assert.Len(t, resp.Errors, 1)
+		assert.Equal(t, "not_found", resp.Errors[0].Extensions["code"])
+	})
+}
+
+func TestLimits(t *testing.T) {
+	t.Run("Too Deep", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(Limits{MaxDepth: 3, MaxComplexity: 1000, ListFactor: 10}))
+
+		resp := execute(t, r, `{ teams { members { team { name } } } }`, nil)
+
+		assert.Nil(t, resp.Data)
+		assert.Len(t, resp.Errors, 1)
+		assert.Equal(t, "query_too_deep", resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("Too Complex", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(Limits{MaxDepth: 8, MaxComplexity: 1000, ListFactor: 10}))
+
+		// 1 + 10*(1 + 10*(1 + 10*2)) = 2111
+		resp := execute(t, r, `{ teams { members { feedback { id content } } } }`, nil)
+
+		assert.Nil(t, resp.Data)
+		assert.Len(t, resp.Errors, 1)
+		assert.Equal(t, "query_too_complex", resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("Fragments Are Counted", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(Limits{MaxDepth: 8, MaxComplexity: 20, ListFactor: 10}))
+
+		resp := execute(t, r, `{ teams { ...T } } fragment T on Team { members { name } }`, nil)
+
+		assert.Len(t, resp.Errors, 1)
+		assert.Equal(t, "query_too_complex", resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("Syntax Error", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupGin()
+		r.POST("/graphql", Handler(DefaultLimits))
+
+		resp := execute(t, r, `{ teams { `, nil)
+
+		assert.Len(t, resp.Errors, 1)
+	})
+}
+
+func TestLoader(t *testing.T) {
+	t.Run("Batches Pending Keys", func(t *testing.T) {
+		var calls [][]int
+		l := newLoader(func(keys []int) (map[int]string, error) {
+			calls = append(calls, keys)
+			out := map[int]string{}
+			for _, k := range keys {
+				out[k] = fmt.Sprint(k)
+			}
+			return out, nil
+		})
+
+		a, b, again := l.Load(1), l.Load(2), l.Load(1)
+		v, err := b()
+		assert.NoError(t, err)
+		assert.Equal(t, "2", v)
+		v, _ = a()
+		assert.Equal(t, "1", v)
+		v, _ = again()
+		assert.Equal(t, "1", v)
+		assert.Equal(t, [][]int{{1, 2}}, calls)
+
+		v, _ = l.Load(1)()
+		assert.Equal(t, "1", v)
+		assert.Len(t, calls, 1)
+	})
+}
+
+func TestScopes(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	team := testutils.CreateTestTeam(db)
+	member := testutils.CreateTestTeamMember(db)
+	member.TeamID = &team.ID
+	db.Save(member)
+
+	serve := func(scopes ...string) *gin.Engine {
+		r := setupGin()
+		r.POST("/graphql", func(c *gin.Context) {
+			c.Request = c.Request.WithContext(services.WithScopes(c.Request.Context(), scopes))
+		}, Handler(DefaultLimits))
+		return r
+	}
+	code := func(resp testResponse) string {
+		if len(resp.Errors) == 0 {
+			return ""
+		}
+		code, _ := resp.Errors[0].Extensions["code"].(string)
+		return code
+	}
+
+	t.Run("Query With Read Scopes", func(t *testing.T) {
+		resp := execute(t, serve("graphql:read", "teams:read", "members:read"), `{ teams { name members { name } } }`, nil)
+		assert.Empty(t, resp.Errors)
+		assert.Len(t, resp.Data["teams"], 1)
+	})
+
+	t.Run("Query Without Resource Scope", func(t *testing.T) {
+		resp := execute(t, serve("graphql:read", "teams:read"), `{ members { name } }`, nil)
+		assert.Equal(t, "forbidden", code(resp))
+		assert.Equal(t, "This requires the members:read scope", resp.Errors[0].Message)
+	})
+
+	t.Run("Nested Field Without Resource Scope", func(t *testing.T) {
+		resp := execute(t, serve("graphql:read", "teams:read"), `{ team(id: "`+fmt.Sprint(team.ID)+`") { name members { email } } }`, nil)
+		assert.Equal(t, "forbidden", code(resp))
+	})
+
+	t.Run("Mutation Needs The Resource Scope", func(t *testing.T) {
+		resp := execute(t, serve("graphql:write", "feedback:write"), `mutation { deleteTeamMember(id: "`+fmt.Sprint(member.ID)+`") }`, nil)
+		assert.Equal(t, "forbidden", code(resp))
+		var count int64
+		db.Model(&models.TeamMember{}).Where("id = ?", member.ID).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Mutation Needs GraphQL Write", func(t *testing.T) {
+		resp := execute(t, serve("graphql:read", "teams:write"), `mutation { createTeam(input: {name: "Ops"}) { id } }`, nil)
+		assert.Equal(t, "forbidden", code(resp))
+		assert.Equal(t, "This requires the graphql:write scope", resp.Errors[0].Message)
+	})
+
+	t.Run("Member Role Gives Feedback", func(t *testing.T) {
+		resp := execute(t, serve(models.RoleScopes(models.RoleMember)...),
+			`mutation { createFeedback(input: {content: "Thanks", targetType: "team", targetId: "`+fmt.Sprint(team.ID)+`"}) { id } }`, nil)
+		assert.Empty(t, resp.Errors)
+
+		resp = execute(t, serve(models.RoleScopes(models.RoleMember)...), `mutation { createTeam(input: {name: "Ops"}) { id } }`, nil)
+		assert.Equal(t, "forbidden", code(resp))
+	})
+
+	t.Run("Every Root Field Is Guarded", func(t *testing.T) {
+		schema, err := NewSchema()
+		assert.NoError(t, err)
+		for name := range schema.QueryType().Fields() {
+			assert.Contains(t, queryResources, name)
+		}
+		for name := range schema.MutationType().Fields() {
+			assert.Contains(t, mutationResources, name)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"coaching-backend/database"
+	"coaching-backend/problem"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+type Response struct {
+	Data   map[string]interface{}     `json:"data"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+// Handler serves GraphQL queries and mutations over POST. As with
+// application/json GraphQL-over-HTTP, query errors (syntax, validation,
+// limits, resolver failures) are reported in "errors" with status 200.
+func Handler(limits Limits) gin.HandlerFunc {
+	schema, err := NewSchema()
+	if err != nil {
+		panic(fmt.Sprintf("graph: invalid schema: %v", err))
+	}
+
+	return func(c *gin.Context) {
+		var req Request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Binding(c, err)
+			return
+		}
+
+		doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query)})})
+		if err != nil {
+			c.JSON(http.StatusOK, Response{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+
+		cost, depth, err := analyze(schema, doc, req.OperationName, limits)
+		if err != nil {
+			c.JSON(http.StatusOK, Response{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		if depth > limits.MaxDepth {
+			c.JSON(http.StatusOK, Response{Errors: requestError(&Error{
+				Message: fmt.Sprintf("Query depth %d exceeds the limit of %d", depth, limits.MaxDepth),
+				Code:    "query_too_deep",
+			})})
+			return
+		}
+		if cost > limits.MaxComplexity {
+			c.JSON(http.StatusOK, Response{Errors: requestError(&Error{
+				Message: fmt.Sprintf("Query complexity %d exceeds the limit of %d", cost, limits.MaxComplexity),
+				Code:    "query_too_complex",
+			})})
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        withLoaders(c.Request.Context(), newLoaders(database.DB)),
+		})
+
+		data, _ := result.Data.(map[string]interface{})
+		c.JSON(http.StatusOK, Response{Data: data, Errors: result.Errors})
+	}
+}
@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"context"
+	"sync"
+)
+
+// batchFunc fetches the values for many keys in one query. Keys missing
+// from the result resolve to the zero value.
+type batchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// loader batches lookups made while the executor resolves one level of the
+// query. Resolvers call Load, which returns a thunk; graphql-go runs all
+// thunks of a level after collecting them, so the first thunk to run fetches
+// every key queued so far in a single query.
+type loader[K comparable, V any] struct {
+	fetch batchFunc[K, V]
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch batchFunc[K, V]) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		queued:  map[K]bool{},
+		results: map[K]V{},
+		errs:    map[K]error{},
+	}
+}
+
+func (l *loader[K, V]) Load(key K) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, done := l.results[key]; !done && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.queued[key] {
+			l.flush()
+		}
+		return l.results[key], l.errs[key]
+	}
+}
+
+// flush must be called with mu held.
+func (l *loader[K, V]) flush() {
+	keys := l.pending
+	l.pending = nil
+
+	values, err := l.fetch(keys)
+	for _, k := range keys {
+		delete(l.queued, k)
+		if err != nil {
+			l.errs[k] = err
+			continue
+		}
+		l.results[k] = values[k]
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+	return l
+}
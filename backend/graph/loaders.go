@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"coaching-backend/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type targetKey struct {
+	Type string
+	ID   uint32
+}
+
+// loaders holds the per-request batch loaders for relationship fields.
+type loaders struct {
+	teams            *loader[uint32, *models.Team]
+	membersByTeam    *loader[uint32, []*models.TeamMember]
+	feedbackByTarget *loader[targetKey, []*models.Feedback]
+}
+
+func newLoaders(db *gorm.DB) *loaders {
+	return &loaders{
+		teams: newLoader(func(ids []uint32) (map[uint32]*models.Team, error) {
+			var teams []*models.Team
+			if err := db.Where("id IN ?", ids).Find(&teams).Error; err != nil {
+				return nil, err
+			}
+
+			byID := make(map[uint32]*models.Team, len(teams))
+			for _, team := range teams {
+				byID[team.ID] = team
+			}
+			return byID, nil
+		}),
+
+		membersByTeam: newLoader(func(teamIDs []uint32) (map[uint32][]*models.TeamMember, error) {
+			var members []*models.TeamMember
+			if err := db.Where("team_id IN ? AND deactivated_at IS NULL", teamIDs).Order("id").Find(&members).Error; err != nil {
+				return nil, err
+			}
+
+			byTeam := make(map[uint32][]*models.TeamMember, len(teamIDs))
+			for _, id := range teamIDs {
+				byTeam[id] = []*models.TeamMember{}
+			}
+			for _, member := range members {
+				byTeam[*member.TeamID] = append(byTeam[*member.TeamID], member)
+			}
+			return byTeam, nil
+		}),
+
+		feedbackByTarget: newLoader(func(keys []targetKey) (map[targetKey][]*models.Feedback, error) {
+			var types []string
+			idsByType := map[string][]uint32{}
+			byTarget := make(map[targetKey][]*models.Feedback, len(keys))
+			for _, k := range keys {
+				if _, ok := idsByType[k.Type]; !ok {
+					types = append(types, k.Type)
+				}
+				idsByType[k.Type] = append(idsByType[k.Type], k.ID)
+				byTarget[k] = []*models.Feedback{}
+			}
+
+			// A batch can mix target types (team and member feedback in the
+			// same level), so fetch them all in one query.
+			conds := make([]string, 0, len(types))
+			args := make([]interface{}, 0, 2*len(types))
+			for _, targetType := range types {
+				conds = append(conds, "(target_type = ? AND target_id IN ?)")
+				args = append(args, targetType, idsByType[targetType])
+			}
+
+			var feedback []*models.Feedback
+			err := db.Where(strings.Join(conds, " OR "), args...).Order("created_at DESC").Find(&feedback).Error
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range feedback {
+				k := targetKey{Type: f.TargetType, ID: f.TargetID}
+				byTarget[k] = append(byTarget[k], f)
+			}
+			return byTarget, nil
+		}),
+	}
+}
@@ -0,0 +1,274 @@
+package graph
+
+import (
+	"coaching-backend/models"
+
+	"github.com/graphql-go/graphql"
+)
+
+var memberInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "TeamMemberInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"email":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"picture": &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+var teamInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "TeamInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"logo": &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+var feedbackInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "FeedbackInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"content":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"targetType": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"targetId":   &graphql.InputObjectFieldConfig{Type: graphql.ID},
+	},
+})
+
+// Inputs are all-optional so create and update share them; create relies on
+// the service's validation to reject missing fields, like the REST handlers.
+func inputArgs(input *graphql.InputObject) graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(input)},
+	}
+}
+
+func updateArgs(input *graphql.InputObject) graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+		"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(input)},
+	}
+}
+
+func mutationType() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: guard(graphql.Fields{
+			"createTeamMember": &graphql.Field{
+				Type: memberType,
+				Args: inputArgs(memberInputType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var member models.TeamMember
+					applyMemberInput(&member, p.Args["input"])
+					if err := service().CreateMember(p.Context, &member); err != nil {
+						return nil, serviceError(err)
+					}
+					return &member, nil
+				},
+			},
+			"updateTeamMember": &graphql.Field{
+				Type: memberType,
+				Args: updateArgs(memberInputType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					member, err := service().UpdateMember(p.Context, id, func(member *models.TeamMember) error {
+						applyMemberInput(member, p.Args["input"])
+						return nil
+					})
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return member, nil
+				},
+			},
+			"deleteTeamMember": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					if err := service().DeleteMember(p.Context, id); err != nil {
+						return nil, serviceError(err)
+					}
+					return true, nil
+				},
+			},
+
+			"createTeam": &graphql.Field{
+				Type: teamType,
+				Args: inputArgs(teamInputType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var team models.Team
+					applyTeamInput(&team, p.Args["input"])
+					if err := service().CreateTeam(p.Context, &team); err != nil {
+						return nil, serviceError(err)
+					}
+					return &team, nil
+				},
+			},
+			"updateTeam": &graphql.Field{
+				Type: teamType,
+				Args: updateArgs(teamInputType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					team, err := service().UpdateTeam(p.Context, id, func(team *models.Team) error {
+						applyTeamInput(team, p.Args["input"])
+						return nil
+					})
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return team, nil
+				},
+			},
+			"deleteTeam": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					if err := service().DeleteTeam(p.Context, id); err != nil {
+						return nil, serviceError(err)
+					}
+					return true, nil
+				},
+			},
+
+			"assignMemberToTeam": &graphql.Field{
+				Type: memberType,
+				Args: graphql.FieldConfigArgument{
+					"memberId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"teamId":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					memberID, err := idArg(p, "memberId")
+					if err != nil {
+						return nil, err
+					}
+					teamID, err := idArg(p, "teamId")
+					if err != nil {
+						return nil, err
+					}
+
+					member, err := service().AssignMember(p.Context, memberID, teamID)
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return member, nil
+				},
+			},
+			"removeMemberFromTeam": &graphql.Field{
+				Type: memberType,
+				Args: graphql.FieldConfigArgument{
+					"memberId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					memberID, err := idArg(p, "memberId")
+					if err != nil {
+						return nil, err
+					}
+
+					member, err := service().UnassignMember(p.Context, memberID)
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return member, nil
+				},
+			},
+
+			"createFeedback": &graphql.Field{
+				Type: feedbackType,
+				Args: inputArgs(feedbackInputType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var feedback models.Feedback
+					if err := applyFeedbackInput(&feedback, p.Args["input"]); err != nil {
+						return nil, err
+					}
+					if err := service().CreateFeedback(p.Context, &feedback); err != nil {
+						return nil, serviceError(err)
+					}
+					return &feedback, nil
+				},
+			},
+			"updateFeedback": &graphql.Field{
+				Type: feedbackType,
+				Args: updateArgs(feedbackInputType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					feedback, err := service().UpdateFeedback(p.Context, id, func(feedback *models.Feedback) error {
+						return applyFeedbackInput(feedback, p.Args["input"])
+					})
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return feedback, nil
+				},
+			},
+			"deleteFeedback": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					if err := service().DeleteFeedback(p.Context, id); err != nil {
+						return nil, serviceError(err)
+					}
+					return true, nil
+				},
+			},
+		}, models.AccessWrite, mutationResources, true),
+	})
+}
+
+func applyMemberInput(member *models.TeamMember, input interface{}) {
+	fields, _ := input.(map[string]interface{})
+	if v, ok := fields["name"].(string); ok {
+		member.Name = v
+	}
+	if v, ok := fields["email"].(string); ok {
+		member.Email = v
+	}
+	if v, ok := fields["picture"].(string); ok {
+		member.Picture = v
+	}
+}
+
+func applyTeamInput(team *models.Team, input interface{}) {
+	fields, _ := input.(map[string]interface{})
+	if v, ok := fields["name"].(string); ok {
+		team.Name = v
+	}
+	if v, ok := fields["logo"].(string); ok {
+		team.Logo = v
+	}
+}
+
+func applyFeedbackInput(feedback *models.Feedback, input interface{}) error {
+	fields, _ := input.(map[string]interface{})
+	if v, ok := fields["content"].(string); ok {
+		feedback.Content = v
+	}
+	if v, ok := fields["targetType"].(string); ok {
+		feedback.TargetType = v
+	}
+	if _, ok := fields["targetId"]; ok {
+		id, err := idArg(graphql.ResolveParams{Args: fields}, "targetId")
+		if err != nil {
+			return err
+		}
+		feedback.TargetID = id
+	}
+	return nil
+}
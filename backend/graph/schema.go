@@ -0,0 +1,261 @@
+// Package graph serves a GraphQL view of teams, members and feedback.
+// Relationship fields are resolved through per-request batch loaders so a
+// query costs one database round trip per level, not one per object.
+package graph
+
+import (
+	"coaching-backend/database"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+)
+
+var feedbackType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Feedback",
+	Fields: graphql.Fields{
+		"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"content":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"targetType": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"targetId":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"targetName": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"createdAt":  &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"updatedAt":  &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+	},
+})
+
+var targetTypeType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "FeedbackTargetType",
+	Fields: graphql.Fields{
+		"type":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"label": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var teamType, memberType *graphql.Object
+
+func init() {
+	memberType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "TeamMember",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return guard(graphql.Fields{
+				"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"email":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"picture": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"teamId": &graphql.Field{
+					Type: graphql.ID,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						member := p.Source.(*models.TeamMember)
+						if member.TeamID == nil {
+							return nil, nil
+						}
+						return *member.TeamID, nil
+					},
+				},
+				"team": &graphql.Field{
+					Type: teamType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						member := p.Source.(*models.TeamMember)
+						if member.TeamID == nil {
+							return nil, nil
+						}
+						return loadersFrom(p.Context).teams.Load(*member.TeamID), nil
+					},
+				},
+				"feedback": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(feedbackType))),
+					Description: "Feedback received by this member, newest first.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						member := p.Source.(*models.TeamMember)
+						return loadersFrom(p.Context).feedbackByTarget.Load(targetKey{Type: "member", ID: member.ID}), nil
+					},
+				},
+				"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			}, models.AccessRead, memberResources, false)
+		}),
+	})
+
+	teamType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Team",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return guard(graphql.Fields{
+				"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"logo": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"members": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(memberType))),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						team := p.Source.(*models.Team)
+						return loadersFrom(p.Context).membersByTeam.Load(team.ID), nil
+					},
+				},
+				"feedback": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(feedbackType))),
+					Description: "Feedback received by this team, newest first.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						team := p.Source.(*models.Team)
+						return loadersFrom(p.Context).feedbackByTarget.Load(targetKey{Type: "team", ID: team.ID}), nil
+					},
+				},
+				"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			}, models.AccessRead, teamResources, false)
+		}),
+	})
+}
+
+var idArgs = graphql.FieldConfigArgument{
+	"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+}
+
+func queryType() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: guard(graphql.Fields{
+			"teams": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(teamType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var teams []*models.Team
+					if err := database.DB.Find(&teams).Error; err != nil {
+						return nil, databaseError(err, "Failed to fetch teams")
+					}
+					return teams, nil
+				},
+			},
+			"team": &graphql.Field{
+				Type: teamType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					team, err := service().GetTeam(p.Context, id)
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return team, nil
+				},
+			},
+			"members": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(memberType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var members []*models.TeamMember
+					if err := database.DB.Where("deactivated_at IS NULL").Find(&members).Error; err != nil {
+						return nil, databaseError(err, "Failed to fetch team members")
+					}
+					return members, nil
+				},
+			},
+			"member": &graphql.Field{
+				Type: memberType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					member, err := service().GetMember(p.Context, id)
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return member, nil
+				},
+			},
+			"unassignedMembers": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(memberType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					members, err := service().ListUnassignedMembers(p.Context)
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return pointers(members), nil
+				},
+			},
+			"feedback": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(feedbackType))),
+				Args: graphql.FieldConfigArgument{
+					"targetType": &graphql.ArgumentConfig{Type: graphql.String},
+					"targetId":   &graphql.ArgumentConfig{Type: graphql.ID},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var filter services.FeedbackFilter
+					if targetType, ok := p.Args["targetType"].(string); ok {
+						filter.TargetType = targetType
+					}
+					if _, ok := p.Args["targetId"]; ok {
+						targetID, err := idArg(p, "targetId")
+						if err != nil {
+							return nil, err
+						}
+						filter.TargetID = targetID
+					}
+
+					feedback, err := service().ListFeedback(p.Context, filter)
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return feedback, nil
+				},
+			},
+			"feedbackById": &graphql.Field{
+				Type: feedbackType,
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					feedback, err := service().GetFeedback(p.Context, id)
+					if err != nil {
+						return nil, serviceError(err)
+					}
+					return feedback, nil
+				},
+			},
+			"feedbackTargetTypes": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(targetTypeType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return service().FeedbackTargetTypes(), nil
+				},
+			},
+		}, models.AccessRead, queryResources, true),
+	})
+}
+
+// service returns the shared service layer. Relationship fields bypass it
+// and go through the batch loaders instead.
+func service() *services.Service {
+	return services.New(database.DB)
+}
+
+// pointers adapts a service result to the *models types the object
+// resolvers expect.
+func pointers[T any](items []T) []*T {
+	out := make([]*T, len(items))
+	for i := range items {
+		out[i] = &items[i]
+	}
+	return out
+}
+
+// idArg parses an ID argument into a database key.
+func idArg(p graphql.ResolveParams, name string) (uint32, error) {
+	raw, _ := p.Args[name].(string)
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || id == 0 {
+		return 0, invalidArgument(name, "must be a positive integer")
+	}
+	return uint32(id), nil
+}
+
+// NewSchema builds the executable schema.
+func NewSchema() (graphql.Schema, error) {
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType(),
+		Mutation: mutationType(),
+	})
+}
@@ -0,0 +1,188 @@
+package grpcapi
+
+import (
+	coachingv1 "coaching-backend/gen/coaching/v1"
+	"coaching-backend/handlers"
+	"coaching-backend/models"
+	"coaching-backend/ratelimit"
+	"coaching-backend/services"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// scope is the access a method needs, like the scope of its REST route.
+type scope struct {
+	resource string
+	access   string
+}
+
+func read(resource string) scope  { return scope{resource, models.AccessRead} }
+func write(resource string) scope { return scope{resource, models.AccessWrite} }
+
+// methodScopes lists every method of the coaching services. Methods not
+// listed are refused, so that a new method cannot go unguarded.
+var methodScopes = map[string]scope{
+	coachingv1.MemberService_CreateMember_FullMethodName: write("members"),
+	coachingv1.MemberService_GetMember_FullMethodName:    read("members"),
+	coachingv1.MemberService_ListMembers_FullMethodName:  read("members"),
+	coachingv1.MemberService_UpdateMember_FullMethodName: write("members"),
+	coachingv1.MemberService_DeleteMember_FullMethodName: write("members"),
+
+	coachingv1.TeamService_CreateTeam_FullMethodName: write("teams"),
+	coachingv1.TeamService_GetTeam_FullMethodName:    read("teams"),
+	coachingv1.TeamService_ListTeams_FullMethodName:  read("teams"),
+	coachingv1.TeamService_UpdateTeam_FullMethodName: write("teams"),
+	coachingv1.TeamService_DeleteTeam_FullMethodName: write("teams"),
+
+	coachingv1.AssignmentService_AssignMember_FullMethodName:          write("assignments"),
+	coachingv1.AssignmentService_UnassignMember_FullMethodName:        write("assignments"),
+	coachingv1.AssignmentService_ListAssignedMembers_FullMethodName:   read("assignments"),
+	coachingv1.AssignmentService_ListUnassignedMembers_FullMethodName: read("assignments"),
+
+	coachingv1.FeedbackService_CreateFeedback_FullMethodName:  write("feedback"),
+	coachingv1.FeedbackService_GetFeedback_FullMethodName:     read("feedback"),
+	coachingv1.FeedbackService_ListFeedback_FullMethodName:    read("feedback"),
+	coachingv1.FeedbackService_UpdateFeedback_FullMethodName:  write("feedback"),
+	coachingv1.FeedbackService_DeleteFeedback_FullMethodName:  write("feedback"),
+	coachingv1.FeedbackService_ListTargetTypes_FullMethodName: read("feedback"),
+	coachingv1.FeedbackService_WatchFeedback_FullMethodName:   read("feedback"),
+}
+
+// guard authenticates and authorizes calls like the REST API does: an API
+// key is sent as "authorization: Bearer TOKEN" metadata, and the method
+// needs the scope in methodScopes. Calls are rate limited by peer address,
+// by API key and by scope, with the REST API's limits.
+type guard struct {
+	svc    *services.Service
+	config Config
+}
+
+func (g *guard) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := g.check(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (g *guard) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := g.check(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// check returns ctx acting for the call's principal, or the status to fail
+// the call with.
+func (g *guard) check(ctx context.Context, method string) (context.Context, error) {
+	limits := g.config.Limits
+	if limits == nil {
+		limits = &handlers.RateLimits{}
+	}
+	client := "ip:" + peerHost(ctx)
+	if err := g.rateLimit(ctx, limits, client, limits.IP); err != nil {
+		return nil, err
+	}
+
+	required, known := methodScopes[method]
+	reflecting := g.config.Reflection && isReflection(method)
+	if !known && !reflecting {
+		return nil, status.Error(codes.PermissionDenied, "This method is not available")
+	}
+
+	token := bearerToken(ctx)
+	if token == "" {
+		if g.config.AuthRequired {
+			return nil, status.Error(codes.Unauthenticated, "An API key is required")
+		}
+	} else {
+		key, err := g.svc.AuthenticateAPIKey(ctx, token)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		ctx = services.WithScopes(ctx, key.Scopes)
+		client = fmt.Sprintf("key:%d", key.ID)
+		if err := g.rateLimit(ctx, limits, client, limits.APIKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if reflecting {
+		return ctx, nil
+	}
+	if err := services.Authorize(ctx, required.resource, required.access); err != nil {
+		return nil, toStatus(err)
+	}
+	group := models.Scope(required.resource, required.access)
+	if limit, ok := limits.Groups[group]; ok {
+		if err := g.rateLimit(ctx, limits, client+":"+group, limit); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+// rateLimit counts the call against the bucket at key. Should the store
+// fail, calls are let through, as for REST.
+func (g *guard) rateLimit(ctx context.Context, limits *handlers.RateLimits, key string, limit ratelimit.Limit) error {
+	if limits.Limiter == nil || !limit.Enabled() {
+		return nil
+	}
+	result, err := limits.Limiter.Allow(ctx, key, limit)
+	if err != nil {
+		log.Printf("ratelimit: %v", err)
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+	retryAfter := strconv.Itoa(max(1, int(math.Ceil(result.RetryAfter.Seconds()))))
+	return status.Error(codes.ResourceExhausted, "Too many requests; the limit is "+limit.String()+", retry in "+retryAfter+"s")
+}
+
+func bearerToken(ctx context.Context) string {
+	for _, value := range metadata.ValueFromIncomingContext(ctx, "authorization") {
+		if scheme, token, _ := strings.Cut(value, " "); strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
+
+func isReflection(method string) bool {
+	return strings.HasPrefix(method, "/grpc.reflection.v1.ServerReflection/") ||
+		strings.HasPrefix(method, "/grpc.reflection.v1alpha.ServerReflection/")
+}
+
+// serverStream is a stream whose handler acts for the call's principal.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,125 @@
+package grpcapi
+
+import (
+	coachingv1 "coaching-backend/gen/coaching/v1"
+	"coaching-backend/handlers"
+	"coaching-backend/models"
+	"coaching-backend/ratelimit"
+	"coaching-backend/services"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func createKey(t *testing.T, db *gorm.DB, scopes ...string) context.Context {
+	key := &models.APIKey{Name: "Client", Scopes: scopes}
+	require.NoError(t, services.New(db).CreateAPIKey(services.AsOperator(context.Background()), key))
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key.Token)
+}
+
+func TestAccess(t *testing.T) {
+	t.Run("Anonymous Calls When Not Required", func(t *testing.T) {
+		_, c := setupServer(t)
+		_, err := c.members.ListMembers(context.Background(), &coachingv1.ListMembersRequest{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Requires A Key", func(t *testing.T) {
+		db, c := setupServerWith(t, Config{AuthRequired: true})
+		_, err := c.members.ListMembers(context.Background(), &coachingv1.ListMembersRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		bad := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer ck_nonsense_key")
+		_, err = c.members.ListMembers(bad, &coachingv1.ListMembersRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = c.members.ListMembers(createKey(t, db, "members:read"), &coachingv1.ListMembersRequest{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Enforces Method Scopes", func(t *testing.T) {
+		db, c := setupServer(t)
+		reader := createKey(t, db, "members:read")
+
+		_, err := c.members.ListMembers(reader, &coachingv1.ListMembersRequest{})
+		assert.NoError(t, err)
+		_, err = c.members.CreateMember(reader, &coachingv1.CreateMemberRequest{Name: "Jane", Email: "jane@example.com"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, "forbidden", errorReason(err))
+		_, err = c.teams.ListTeams(reader, &coachingv1.ListTeamsRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		var count int64
+		db.Model(&models.TeamMember{}).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Enforces Scopes On Streams", func(t *testing.T) {
+		db, c := setupServer(t)
+		stream, err := c.feedback.WatchFeedback(createKey(t, db, "teams:read"), &coachingv1.WatchFeedbackRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Rate Limits Keys", func(t *testing.T) {
+		limit, err := ratelimit.ParseLimit("2/1m")
+		require.NoError(t, err)
+		db, c := setupServerWith(t, Config{Limits: &handlers.RateLimits{
+			Limiter: ratelimit.New(ratelimit.NewMemoryStore()),
+			APIKey:  limit,
+		}})
+		ctx := createKey(t, db, "members:read")
+
+		for range 2 {
+			_, err := c.members.ListMembers(ctx, &coachingv1.ListMembersRequest{})
+			require.NoError(t, err)
+		}
+		_, err = c.members.ListMembers(ctx, &coachingv1.ListMembersRequest{})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		_, err = c.members.ListMembers(context.Background(), &coachingv1.ListMembersRequest{})
+		assert.NoError(t, err, "anonymous calls count by address, not against the key")
+	})
+
+	t.Run("Reflection Is Off By Default", func(t *testing.T) {
+		_, c := setupServer(t)
+		stream, err := reflectionpb.NewServerReflectionClient(c.conn).ServerReflectionInfo(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}))
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("Reflection When Configured", func(t *testing.T) {
+		_, c := setupServerWith(t, Config{Reflection: true})
+		stream, err := reflectionpb.NewServerReflectionClient(c.conn).ServerReflectionInfo(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}))
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.GetListServicesResponse().GetService())
+	})
+
+	t.Run("Every Method Is Guarded", func(t *testing.T) {
+		s := grpc.NewServer()
+		Register(s, nil)
+		for name, info := range s.GetServiceInfo() {
+			for _, method := range info.Methods {
+				assert.Contains(t, methodScopes, "/"+name+"/"+method.Name)
+			}
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package grpcapi
+
+import (
+	coachingv1 "coaching-backend/gen/coaching/v1"
+	"coaching-backend/services"
+	"context"
+)
+
+type assignmentServer struct {
+	coachingv1.UnimplementedAssignmentServiceServer
+	svc *services.Service
+}
+
+func (s *assignmentServer) AssignMember(ctx context.Context, req *coachingv1.AssignMemberRequest) (*coachingv1.TeamMember, error) {
+	member, err := s.svc.AssignMember(ctx, req.GetMemberId(), req.GetTeamId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return memberToProto(member), nil
+}
+
+func (s *assignmentServer) UnassignMember(ctx context.Context, req *coachingv1.UnassignMemberRequest) (*coachingv1.TeamMember, error) {
+	member, err := s.svc.UnassignMember(ctx, req.GetMemberId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return memberToProto(member), nil
+}
+
+func (s *assignmentServer) ListAssignedMembers(ctx context.Context, req *coachingv1.ListAssignedMembersRequest) (*coachingv1.ListAssignedMembersResponse, error) {
+	members, err := s.svc.ListAssignedMembers(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &coachingv1.ListAssignedMembersResponse{Members: membersToProto(members)}, nil
+}
+
+func (s *assignmentServer) ListUnassignedMembers(ctx context.Context, req *coachingv1.ListUnassignedMembersRequest) (*coachingv1.ListUnassignedMembersResponse, error) {
+	members, err := s.svc.ListUnassignedMembers(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &coachingv1.ListUnassignedMembersResponse{Members: membersToProto(members)}, nil
+}
@@ -0,0 +1,58 @@
+package grpcapi
+
+import (
+	"coaching-backend/events"
+	coachingv1 "coaching-backend/gen/coaching/v1"
+	"coaching-backend/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func memberToProto(m *models.TeamMember) *coachingv1.TeamMember {
+	return &coachingv1.TeamMember{
+		Id:        m.ID,
+		Name:      m.Name,
+		Email:     m.Email,
+		Picture:   m.Picture,
+		TeamId:    m.TeamID,
+		CreatedAt: timestamppb.New(m.CreatedAt),
+		UpdatedAt: timestamppb.New(m.UpdatedAt),
+	}
+}
+
+func membersToProto(members []models.TeamMember) []*coachingv1.TeamMember {
+	out := make([]*coachingv1.TeamMember, len(members))
+	for i := range members {
+		out[i] = memberToProto(&members[i])
+	}
+	return out
+}
+
+func teamToProto(t *models.Team) *coachingv1.Team {
+	return &coachingv1.Team{
+		Id:        t.ID,
+		Name:      t.Name,
+		Logo:      t.Logo,
+		Members:   membersToProto(t.Members),
+		CreatedAt: timestamppb.New(t.CreatedAt),
+		UpdatedAt: timestamppb.New(t.UpdatedAt),
+	}
+}
+
+func feedbackToProto(f *models.Feedback) *coachingv1.Feedback {
+	return &coachingv1.Feedback{
+		Id:         f.ID,
+		Content:    f.Content,
+		TargetType: f.TargetType,
+		TargetId:   f.TargetID,
+		TargetName: f.TargetName,
+		CreatedAt:  timestamppb.New(f.CreatedAt),
+		UpdatedAt:  timestamppb.New(f.UpdatedAt),
+	}
+}
+
+var eventKinds = map[events.Type]coachingv1.FeedbackEvent_Kind{
+	events.FeedbackCreated: coachingv1.FeedbackEvent_KIND_CREATED,
+	events.FeedbackUpdated: coachingv1.FeedbackEvent_KIND_UPDATED,
+	events.FeedbackDeleted: coachingv1.FeedbackEvent_KIND_DELETED,
+}
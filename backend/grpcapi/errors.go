@@ -0,0 +1,67 @@
+package grpcapi
+
+import (
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"errors"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain identifies this API in google.rpc.ErrorInfo.
+const errorDomain = "coaching-backend"
+
+func grpcCode(code string) codes.Code {
+	switch code {
+	case problem.CodeValidation, problem.CodeMalformedBody, problem.CodeInvalidParam, problem.CodeTooLarge, problem.CodeUnsupported:
+		return codes.InvalidArgument
+	case problem.CodeUnauthorized:
+		return codes.Unauthenticated
+	case problem.CodeForbidden:
+		return codes.PermissionDenied
+	case problem.CodeNotFound:
+		return codes.NotFound
+	case problem.CodeConflict:
+		return codes.AlreadyExists
+	case problem.CodeRateLimited:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+// toStatus converts a service error into a gRPC status carrying the stable
+// error code as ErrorInfo.Reason and any field errors as BadRequest.
+func toStatus(err error) error {
+	var serviceErr *services.Error
+	if !errors.As(err, &serviceErr) {
+		log.Printf("grpc: unexpected error: %v", err)
+		return status.Error(codes.Internal, "Internal server error")
+	}
+	if serviceErr.Err != nil {
+		log.Printf("grpc: %s: %v", serviceErr.Message, serviceErr.Err)
+	}
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: serviceErr.Code, Domain: errorDomain}}
+	if len(serviceErr.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, f := range serviceErr.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+				Reason:      f.Rule,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	st := status.New(grpcCode(serviceErr.Code), serviceErr.Message)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails.Err()
+	}
+	return st.Err()
+}
@@ -0,0 +1,107 @@
+package grpcapi
+
+import (
+	coachingv1 "coaching-backend/gen/coaching/v1"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type feedbackServer struct {
+	coachingv1.UnimplementedFeedbackServiceServer
+	svc *services.Service
+}
+
+func (s *feedbackServer) CreateFeedback(ctx context.Context, req *coachingv1.CreateFeedbackRequest) (*coachingv1.Feedback, error) {
+	feedback := models.Feedback{Content: req.GetContent(), TargetType: req.GetTargetType(), TargetID: req.GetTargetId()}
+	if err := s.svc.CreateFeedback(ctx, &feedback); err != nil {
+		return nil, toStatus(err)
+	}
+	return feedbackToProto(&feedback), nil
+}
+
+func (s *feedbackServer) GetFeedback(ctx context.Context, req *coachingv1.GetFeedbackRequest) (*coachingv1.Feedback, error) {
+	feedback, err := s.svc.GetFeedback(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return feedbackToProto(feedback), nil
+}
+
+func (s *feedbackServer) ListFeedback(ctx context.Context, req *coachingv1.ListFeedbackRequest) (*coachingv1.ListFeedbackResponse, error) {
+	feedback, err := s.svc.ListFeedback(ctx, services.FeedbackFilter{TargetType: req.GetTargetType(), TargetID: req.GetTargetId()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	out := make([]*coachingv1.Feedback, len(feedback))
+	for i := range feedback {
+		out[i] = feedbackToProto(&feedback[i])
+	}
+	return &coachingv1.ListFeedbackResponse{Feedback: out}, nil
+}
+
+func (s *feedbackServer) UpdateFeedback(ctx context.Context, req *coachingv1.UpdateFeedbackRequest) (*coachingv1.Feedback, error) {
+	feedback, err := s.svc.UpdateFeedback(ctx, req.GetId(), func(feedback *models.Feedback) error {
+		if req.Content != nil {
+			feedback.Content = req.GetContent()
+		}
+		if req.TargetType != nil {
+			feedback.TargetType = req.GetTargetType()
+		}
+		if req.TargetId != nil {
+			feedback.TargetID = req.GetTargetId()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return feedbackToProto(feedback), nil
+}
+
+func (s *feedbackServer) DeleteFeedback(ctx context.Context, req *coachingv1.DeleteFeedbackRequest) (*coachingv1.DeleteFeedbackResponse, error) {
+	if err := s.svc.DeleteFeedback(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &coachingv1.DeleteFeedbackResponse{}, nil
+}
+
+func (s *feedbackServer) ListTargetTypes(ctx context.Context, req *coachingv1.ListTargetTypesRequest) (*coachingv1.ListTargetTypesResponse, error) {
+	resp := &coachingv1.ListTargetTypesResponse{}
+	for _, info := range s.svc.FeedbackTargetTypes() {
+		resp.TargetTypes = append(resp.TargetTypes, &coachingv1.TargetType{Type: info.Type, Label: info.Label})
+	}
+	return resp, nil
+}
+
+func (s *feedbackServer) WatchFeedback(req *coachingv1.WatchFeedbackRequest, stream grpc.ServerStreamingServer[coachingv1.FeedbackEvent]) error {
+	ctx := stream.Context()
+	sub := s.svc.WatchFeedback(ctx, services.FeedbackFilter{TargetType: req.GetTargetType(), TargetID: req.GetTargetId()})
+
+	// Send headers now so clients know the watch is registered before any
+	// event arrives.
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for event := range sub.Events {
+		feedback := event.Data.(models.Feedback)
+		err := stream.Send(&coachingv1.FeedbackEvent{
+			Kind:     eventKinds[event.Type],
+			Feedback: feedbackToProto(&feedback),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.ResourceExhausted, "Watcher fell too far behind; reconnect to resume")
+}
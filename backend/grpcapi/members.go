@@ -0,0 +1,63 @@
+package grpcapi
+
+import (
+	coachingv1 "coaching-backend/gen/coaching/v1"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"context"
+)
+
+type memberServer struct {
+	coachingv1.UnimplementedMemberServiceServer
+	svc *services.Service
+}
+
+func (s *memberServer) CreateMember(ctx context.Context, req *coachingv1.CreateMemberRequest) (*coachingv1.TeamMember, error) {
+	member := models.TeamMember{Name: req.GetName(), Email: req.GetEmail(), Picture: req.GetPicture()}
+	if err := s.svc.CreateMember(ctx, &member); err != nil {
+		return nil, toStatus(err)
+	}
+	return memberToProto(&member), nil
+}
+
+func (s *memberServer) GetMember(ctx context.Context, req *coachingv1.GetMemberRequest) (*coachingv1.TeamMember, error) {
+	member, err := s.svc.GetMember(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return memberToProto(member), nil
+}
+
+func (s *memberServer) ListMembers(ctx context.Context, req *coachingv1.ListMembersRequest) (*coachingv1.ListMembersResponse, error) {
+	members, err := s.svc.ListMembers(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &coachingv1.ListMembersResponse{Members: membersToProto(members)}, nil
+}
+
+func (s *memberServer) UpdateMember(ctx context.Context, req *coachingv1.UpdateMemberRequest) (*coachingv1.TeamMember, error) {
+	member, err := s.svc.UpdateMember(ctx, req.GetId(), func(member *models.TeamMember) error {
+		if req.Name != nil {
+			member.Name = req.GetName()
+		}
+		if req.Email != nil {
+			member.Email = req.GetEmail()
+		}
+		if req.Picture != nil {
+			member.Picture = req.GetPicture()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return memberToProto(member), nil
+}
+
+func (s *memberServer) DeleteMember(ctx context.Context, req *coachingv1.DeleteMemberRequest) (*coachingv1.DeleteMemberResponse, error) {
+	if err := s.svc.DeleteMember(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &coachingv1.DeleteMemberResponse{}, nil
+}
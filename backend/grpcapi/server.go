@@ -0,0 +1,77 @@
+// Package grpcapi serves the coaching API over gRPC. It is a transport over
+// the services package, so it behaves like the REST API.
+package grpcapi
+
+//go:generate protoc -I ../proto --go_out=../gen --go_opt=paths=source_relative --go-grpc_out=../gen --go-grpc_opt=paths=source_relative coaching/v1/coaching.proto
+
+import (
+	coachingv1 "coaching-backend/gen/coaching/v1"
+	"coaching-backend/handlers"
+	"coaching-backend/services"
+	"context"
+	"log"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Config is how the server admits calls.
+type Config struct {
+	// AuthRequired turns away calls without an API key; otherwise they
+	// are let through, like anonymous REST requests.
+	AuthRequired bool
+	// Limits are the REST API's rate limits, applied to calls too; nil
+	// for none.
+	Limits *handlers.RateLimits
+	// Reflection serves the reflection service, for tools like grpcurl.
+	Reflection bool
+}
+
+// NewServer returns a gRPC server with every coaching service registered,
+// authenticating, authorizing and rate limiting calls as cfg says.
+func NewServer(svc *services.Service, cfg Config, opts ...grpc.ServerOption) *grpc.Server {
+	g := &guard{svc: svc, config: cfg}
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(recoverUnary, g.unary),
+		grpc.ChainStreamInterceptor(recoverStream, g.stream),
+	}, opts...)
+
+	s := grpc.NewServer(opts...)
+	Register(s, svc)
+	if cfg.Reflection {
+		reflection.Register(s)
+	}
+	return s
+}
+
+func Register(s grpc.ServiceRegistrar, svc *services.Service) {
+	coachingv1.RegisterMemberServiceServer(s, &memberServer{svc: svc})
+	coachingv1.RegisterTeamServiceServer(s, &teamServer{svc: svc})
+	coachingv1.RegisterAssignmentServiceServer(s, &assignmentServer{svc: svc})
+	coachingv1.RegisterFeedbackServiceServer(s, &feedbackServer{svc: svc})
+}
+
+// recoverUnary and recoverStream turn panics into INTERNAL errors, like
+// problem.Recovery does for REST.
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("grpc: panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+			err = status.Error(codes.Internal, "Internal server error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("grpc: panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+			err = status.Error(codes.Internal, "Internal server error")
+		}
+	}()
+	return handler(srv, ss)
+}
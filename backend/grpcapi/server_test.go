@@ -0,0 +1,287 @@
+package grpcapi
+
+import (
+	"coaching-backend/events"
+	coachingv1 "coaching-backend/gen/coaching/v1"
+	"coaching-backend/outbox"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+)
+
+type clients struct {
+	conn        *grpc.ClientConn
+	members     coachingv1.MemberServiceClient
+	teams       coachingv1.TeamServiceClient
+	assignments coachingv1.AssignmentServiceClient
+	feedback    coachingv1.FeedbackServiceClient
+}
+
+// setupServer serves the API over an in-memory listener, with an outbox
+// relay publishing changes to a fresh event bus.
+func setupServer(t *testing.T) (*gorm.DB, clients) {
+	return setupServerWith(t, Config{})
+}
+
+func setupServerWith(t *testing.T, cfg Config) (*gorm.DB, clients) {
+	db := testutils.SetupTestDB(t)
+
+	events.Default = events.NewBus(events.DefaultHistory)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	relay := outbox.NewRelay(db, outbox.Config{PollInterval: 10 * time.Millisecond, RetryDelay: time.Second, BatchSize: 10},
+		outbox.BusSink{Bus: events.Default})
+	go relay.Run(ctx)
+
+	lis := bufconn.Listen(1 << 20)
+	s := NewServer(services.New(db), cfg)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return db, clients{
+		conn:        conn,
+		members:     coachingv1.NewMemberServiceClient(conn),
+		teams:       coachingv1.NewTeamServiceClient(conn),
+		assignments: coachingv1.NewAssignmentServiceClient(conn),
+		feedback:    coachingv1.NewFeedbackServiceClient(conn),
+	}
+}
+
+func errorReason(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestMemberService(t *testing.T) {
+	t.Run("Create Get Update Delete", func(t *testing.T) {
+		_, c := setupServer(t)
+		ctx := context.Background()
+
+		created, err := c.members.CreateMember(ctx, &coachingv1.CreateMemberRequest{Name: "Jane", Email: "jane@example.com"})
+		require.NoError(t, err)
+		assert.NotZero(t, created.Id)
+		assert.Nil(t, created.TeamId)
+
+		updated, err := c.members.UpdateMember(ctx, &coachingv1.UpdateMemberRequest{Id: created.Id, Name: proto.String("Jane Doe")})
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", updated.Name)
+		assert.Equal(t, "jane@example.com", updated.Email)
+
+		got, err := c.members.GetMember(ctx, &coachingv1.GetMemberRequest{Id: created.Id})
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", got.Name)
+
+		_, err = c.members.DeleteMember(ctx, &coachingv1.DeleteMemberRequest{Id: created.Id})
+		require.NoError(t, err)
+
+		_, err = c.members.GetMember(ctx, &coachingv1.GetMemberRequest{Id: created.Id})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, "not_found", errorReason(err))
+	})
+
+	t.Run("Validation Error", func(t *testing.T) {
+		_, c := setupServer(t)
+
+		_, err := c.members.CreateMember(context.Background(), &coachingv1.CreateMemberRequest{Name: "Jane", Email: "not-an-email"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "validation_failed", errorReason(err))
+		var violations []*errdetails.BadRequest_FieldViolation
+		for _, d := range status.Convert(err).Details() {
+			if br, ok := d.(*errdetails.BadRequest); ok {
+				violations = br.FieldViolations
+			}
+		}
+		require.Len(t, violations, 1)
+		assert.Equal(t, "email", violations[0].Field)
+		assert.Equal(t, "email", violations[0].Reason)
+	})
+
+	t.Run("Duplicate Email", func(t *testing.T) {
+		db, c := setupServer(t)
+		member := testutils.CreateTestTeamMember(db)
+
+		_, err := c.members.CreateMember(context.Background(), &coachingv1.CreateMemberRequest{Name: "Other", Email: member.Email})
+
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		assert.Equal(t, "conflict", errorReason(err))
+	})
+}
+
+func TestTeamAndAssignmentServices(t *testing.T) {
+	t.Run("Assign And Unassign", func(t *testing.T) {
+		db, c := setupServer(t)
+		ctx := context.Background()
+		member := testutils.CreateTestTeamMember(db)
+
+		team, err := c.teams.CreateTeam(ctx, &coachingv1.CreateTeamRequest{Name: "Platform"})
+		require.NoError(t, err)
+
+		assigned, err := c.assignments.AssignMember(ctx, &coachingv1.AssignMemberRequest{MemberId: member.ID, TeamId: team.Id})
+		require.NoError(t, err)
+		assert.Equal(t, team.Id, assigned.GetTeamId())
+
+		got, err := c.teams.GetTeam(ctx, &coachingv1.GetTeamRequest{Id: team.Id})
+		require.NoError(t, err)
+		require.Len(t, got.Members, 1)
+		assert.Equal(t, member.Email, got.Members[0].Email)
+
+		assignedList, err := c.assignments.ListAssignedMembers(ctx, &coachingv1.ListAssignedMembersRequest{})
+		require.NoError(t, err)
+		assert.Len(t, assignedList.Members, 1)
+
+		unassigned, err := c.assignments.UnassignMember(ctx, &coachingv1.UnassignMemberRequest{MemberId: member.ID})
+		require.NoError(t, err)
+		assert.Nil(t, unassigned.TeamId)
+
+		unassignedList, err := c.assignments.ListUnassignedMembers(ctx, &coachingv1.ListUnassignedMembersRequest{})
+		require.NoError(t, err)
+		assert.Len(t, unassignedList.Members, 1)
+	})
+
+	t.Run("Assign To Missing Team", func(t *testing.T) {
+		db, c := setupServer(t)
+		member := testutils.CreateTestTeamMember(db)
+
+		_, err := c.assignments.AssignMember(context.Background(), &coachingv1.AssignMemberRequest{MemberId: member.ID, TeamId: 999})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, "Team not found", status.Convert(err).Message())
+	})
+
+	t.Run("Update Keeps Unset Fields", func(t *testing.T) {
+		db, c := setupServer(t)
+		team := testutils.CreateTestTeam(db)
+
+		updated, err := c.teams.UpdateTeam(context.Background(), &coachingv1.UpdateTeamRequest{Id: team.ID, Name: proto.String("Renamed")})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", updated.Name)
+		assert.Equal(t, team.Logo, updated.Logo)
+	})
+}
+
+func TestFeedbackService(t *testing.T) {
+	t.Run("Create And List", func(t *testing.T) {
+		db, c := setupServer(t)
+		ctx := context.Background()
+		team := testutils.CreateTestTeam(db)
+
+		created, err := c.feedback.CreateFeedback(ctx, &coachingv1.CreateFeedbackRequest{Content: "Great sprint", TargetType: "team", TargetId: team.ID})
+		require.NoError(t, err)
+		assert.Equal(t, team.Name, created.TargetName)
+
+		list, err := c.feedback.ListFeedback(ctx, &coachingv1.ListFeedbackRequest{TargetType: proto.String("team")})
+		require.NoError(t, err)
+		assert.Len(t, list.Feedback, 1)
+
+		list, err = c.feedback.ListFeedback(ctx, &coachingv1.ListFeedbackRequest{TargetType: proto.String("member")})
+		require.NoError(t, err)
+		assert.Empty(t, list.Feedback)
+	})
+
+	t.Run("Unknown Target Type", func(t *testing.T) {
+		_, c := setupServer(t)
+
+		_, err := c.feedback.CreateFeedback(context.Background(), &coachingv1.CreateFeedbackRequest{Content: "Hi", TargetType: "planet", TargetId: 1})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "validation_failed", errorReason(err))
+	})
+
+	t.Run("Missing Target", func(t *testing.T) {
+		_, c := setupServer(t)
+
+		_, err := c.feedback.CreateFeedback(context.Background(), &coachingv1.CreateFeedbackRequest{Content: "Hi", TargetType: "team", TargetId: 999})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, "Team not found", status.Convert(err).Message())
+	})
+
+	t.Run("List Target Types", func(t *testing.T) {
+		_, c := setupServer(t)
+
+		resp, err := c.feedback.ListTargetTypes(context.Background(), &coachingv1.ListTargetTypesRequest{})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.TargetTypes)
+	})
+}
+
+func TestWatchFeedback(t *testing.T) {
+	t.Run("Streams Matching Changes", func(t *testing.T) {
+		db, c := setupServer(t)
+		team := testutils.CreateTestTeam(db)
+		member := testutils.CreateTestTeamMember(db)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stream, err := c.feedback.WatchFeedback(ctx, &coachingv1.WatchFeedbackRequest{TargetType: proto.String("team")})
+		require.NoError(t, err)
+		_, err = stream.Header()
+		require.NoError(t, err)
+
+		created, err := c.feedback.CreateFeedback(ctx, &coachingv1.CreateFeedbackRequest{Content: "Nice", TargetType: "team", TargetId: team.ID})
+		require.NoError(t, err)
+		_, err = c.feedback.CreateFeedback(ctx, &coachingv1.CreateFeedbackRequest{Content: "Ignored", TargetType: "member", TargetId: member.ID})
+		require.NoError(t, err)
+		_, err = c.feedback.UpdateFeedback(ctx, &coachingv1.UpdateFeedbackRequest{Id: created.Id, Content: proto.String("Very nice")})
+		require.NoError(t, err)
+		_, err = c.feedback.DeleteFeedback(ctx, &coachingv1.DeleteFeedbackRequest{Id: created.Id})
+		require.NoError(t, err)
+
+		var kinds []coachingv1.FeedbackEvent_Kind
+		var contents []string
+		for i := 0; i < 3; i++ {
+			event, err := stream.Recv()
+			require.NoError(t, err)
+			kinds = append(kinds, event.Kind)
+			contents = append(contents, event.Feedback.Content)
+		}
+		assert.Equal(t, []coachingv1.FeedbackEvent_Kind{
+			coachingv1.FeedbackEvent_KIND_CREATED,
+			coachingv1.FeedbackEvent_KIND_UPDATED,
+			coachingv1.FeedbackEvent_KIND_DELETED,
+		}, kinds)
+		assert.Equal(t, []string{"Nice", "Very nice", "Very nice"}, contents)
+	})
+
+	t.Run("Cancel Ends Stream", func(t *testing.T) {
+		_, c := setupServer(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := c.feedback.WatchFeedback(ctx, &coachingv1.WatchFeedbackRequest{})
+		require.NoError(t, err)
+		_, err = stream.Header()
+		require.NoError(t, err)
+
+		cancel()
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Canceled, status.Code(err))
+	})
+}
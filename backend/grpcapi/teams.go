@@ -0,0 +1,65 @@
+package grpcapi
+
+import (
+	coachingv1 "coaching-backend/gen/coaching/v1"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"context"
+)
+
+type teamServer struct {
+	coachingv1.UnimplementedTeamServiceServer
+	svc *services.Service
+}
+
+func (s *teamServer) CreateTeam(ctx context.Context, req *coachingv1.CreateTeamRequest) (*coachingv1.Team, error) {
+	team := models.Team{Name: req.GetName(), Logo: req.GetLogo()}
+	if err := s.svc.CreateTeam(ctx, &team); err != nil {
+		return nil, toStatus(err)
+	}
+	return teamToProto(&team), nil
+}
+
+func (s *teamServer) GetTeam(ctx context.Context, req *coachingv1.GetTeamRequest) (*coachingv1.Team, error) {
+	team, err := s.svc.GetTeam(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return teamToProto(team), nil
+}
+
+func (s *teamServer) ListTeams(ctx context.Context, req *coachingv1.ListTeamsRequest) (*coachingv1.ListTeamsResponse, error) {
+	teams, err := s.svc.ListTeams(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	out := make([]*coachingv1.Team, len(teams))
+	for i := range teams {
+		out[i] = teamToProto(&teams[i])
+	}
+	return &coachingv1.ListTeamsResponse{Teams: out}, nil
+}
+
+func (s *teamServer) UpdateTeam(ctx context.Context, req *coachingv1.UpdateTeamRequest) (*coachingv1.Team, error) {
+	team, err := s.svc.UpdateTeam(ctx, req.GetId(), func(team *models.Team) error {
+		if req.Name != nil {
+			team.Name = req.GetName()
+		}
+		if req.Logo != nil {
+			team.Logo = req.GetLogo()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return teamToProto(team), nil
+}
+
+func (s *teamServer) DeleteTeam(ctx context.Context, req *coachingv1.DeleteTeamRequest) (*coachingv1.DeleteTeamResponse, error) {
+	if err := s.svc.DeleteTeam(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &coachingv1.DeleteTeamResponse{}, nil
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	principalKey    = "principal"
+	authRequiredKey = "auth_required"
+)
+
+// Principal is who a request acts for: a service account's API key or a
+// signed-in member.
+type Principal struct {
+	APIKey *models.APIKey
+	Member *models.TeamMember
+	Scopes []string
+}
+
+// CurrentPrincipal returns the request's principal, or nil for anonymous
+// requests.
+func CurrentPrincipal(c *gin.Context) *Principal {
+	p, _ := c.Get(principalKey)
+	principal, _ := p.(*Principal)
+	return principal
+}
+
+// Authenticate resolves the request's principal from a bearer API key or
+// else from the session cookie. An invalid API key is rejected; a stale
+// session cookie is ignored. With required, RequireScope turns anonymous
+// requests away; otherwise they are let through so existing clients keep
+// working.
+func Authenticate(required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(authRequiredKey, required)
+		ctx := c.Request.Context()
+
+		if scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " "); strings.EqualFold(scheme, "Bearer") {
+			key, err := service().AuthenticateAPIKey(ctx, strings.TrimSpace(token))
+			if err != nil {
+				c.Header("WWW-Authenticate", `Bearer realm="api"`)
+				writeError(c, err)
+				return
+			}
+			setPrincipal(c, &Principal{APIKey: key, Scopes: key.Scopes})
+		} else if token, err := c.Cookie(SessionCookie); err == nil {
+			session, err := service().GetSession(ctx, token)
+			var serviceErr *services.Error
+			switch {
+			case err == nil:
+				setPrincipal(c, &Principal{Member: session.Member, Scopes: models.RoleScopes(session.Member.Role)})
+			case !errors.As(err, &serviceErr) || serviceErr.Code != problem.CodeUnauthorized:
+				writeError(c, err)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// setPrincipal records the request's principal, also in the request's
+// context for the services to authorize with.
+func setPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(principalKey, principal)
+	c.Request = c.Request.WithContext(services.WithScopes(c.Request.Context(), principal.Scopes))
+}
+
+// RequireScope admits requests whose principal has read access to resource
+// for GET and HEAD, and write access for other methods.
+func RequireScope(resource string) gin.HandlerFunc {
+	return requireScope(resource, func(c *gin.Context) string {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return models.AccessRead
+		}
+		return models.AccessWrite
+	})
+}
+
+// RequireReadScope admits requests whose principal has read access to
+// resource, whatever the method. It is for routes that check further
+// scopes themselves, like GraphQL, where queries are posted too.
+func RequireReadScope(resource string) gin.HandlerFunc {
+	return requireScope(resource, func(*gin.Context) string { return models.AccessRead })
+}
+
+func requireScope(resource string, accessOf func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := CurrentPrincipal(c)
+		if principal == nil {
+			if c.GetBool(authRequiredKey) {
+				c.Header("WWW-Authenticate", `Bearer realm="api"`)
+				problem.Unauthorized(c, "An API key or a signed-in session is required")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		access := accessOf(c)
+		if !models.AllowsScope(principal.Scopes, resource, access) {
+			problem.Forbidden(c, "This requires the "+models.Scope(resource, access)+" scope")
+			return
+		}
+		c.Next()
+	}
+}
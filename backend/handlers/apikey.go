@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKey creates a service account key. The response is the only one
+// that includes the token.
+func CreateAPIKey(c *gin.Context) {
+	var key models.APIKey
+	if err := c.ShouldBindJSON(&key); err != nil {
+		problem.Binding(c, err)
+		return
+	}
+
+	if err := service().CreateAPIKey(c.Request.Context(), &key); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+func GetAPIKeys(c *gin.Context) {
+	keys, err := service().ListAPIKeys(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func GetAPIKey(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	key, err := service().GetAPIKey(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// RotateAPIKey replaces the key's token and returns the new one.
+func RotateAPIKey(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	key, err := service().RotateAPIKey(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+func RevokeAPIKey(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := service().RevokeAPIKey(c.Request.Context(), id); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
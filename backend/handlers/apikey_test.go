@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAPIKeyRoutes mounts the key routes for an admin, who holds every
+// scope.
+func setupAPIKeyRoutes() *gin.Engine {
+	r := setupGin()
+	r.Use(func(c *gin.Context) {
+		scopes := models.RoleScopes(models.RoleAdmin)
+		c.Request = c.Request.WithContext(services.WithScopes(c.Request.Context(), scopes))
+	})
+	r.POST("/api-keys", CreateAPIKey)
+	r.GET("/api-keys", GetAPIKeys)
+	r.GET("/api-keys/:id", GetAPIKey)
+	r.POST("/api-keys/:id/rotate", RotateAPIKey)
+	r.DELETE("/api-keys/:id", RevokeAPIKey)
+	return r
+}
+
+// setupScopedRoutes mounts a read and a write route on members behind the
+// authentication middleware.
+func setupScopedRoutes(required bool) *gin.Engine {
+	r := setupGin()
+	api := r.Group("/api", Authenticate(required))
+	members := api.Group("/members", RequireScope("members"))
+	members.GET("", GetTeamMembers)
+	members.POST("", CreateTeamMember)
+	api.GET("/open", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return r
+}
+
+func createAPIKey(t *testing.T, r *gin.Engine, body string) models.APIKey {
+	w := serve(r, "POST", "/api-keys", body)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var key models.APIKey
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &key))
+	return key
+}
+
+func withBearer(r *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(`{"name":"Ada","email":"ada@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeys(t *testing.T) {
+	r := setupAPIKeyRoutes()
+
+	t.Run("Create Returns Token Once", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		key := createAPIKey(t, r, `{"name":"Reporting","scopes":["feedback:read","members:write"]}`)
+		assert.True(t, strings.HasPrefix(key.Token, key.Prefix+"_"), key.Token)
+		assert.True(t, strings.HasPrefix(key.Prefix, models.APIKeyPrefix))
+		assert.Equal(t, models.StringList{"feedback:read", "members:write"}, key.Scopes)
+
+		var stored models.APIKey
+		require.NoError(t, db.Take(&stored).Error)
+		assert.NotContains(t, stored.SecretHash, key.Token[len(key.Prefix)+1:], "only a hash of the token is stored")
+
+		w := serve(r, "GET", "/api-keys", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), key.Token)
+		w = serve(r, "GET", fmt.Sprintf("/api-keys/%d", key.ID), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"token"`)
+	})
+
+	t.Run("Invalid Keys", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		for _, body := range []string{
+			`{"scopes":["feedback:read"]}`,
+			`{"name":"No scopes","scopes":[]}`,
+			`{"name":"Unknown","scopes":["feedback:delete"]}`,
+			`{"name":"Unknown","scopes":["payroll:read"]}`,
+			`{"name":"Expired","scopes":["feedback:read"],"expires_at":"2020-01-01T00:00:00Z"}`,
+		} {
+			w := serve(r, "POST", "/api-keys", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("Keys Cannot Grant More Than Their Creator", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		scoped := setupGin()
+		scoped.POST("/api/api-keys", Authenticate(true), RequireScope("api_keys"), CreateAPIKey)
+		creator := createAPIKey(t, r, `{"name":"Provisioner","scopes":["api_keys:write","members:read"]}`)
+
+		create := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/api/api-keys", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+creator.Token)
+			w := httptest.NewRecorder()
+			scoped.ServeHTTP(w, req)
+			return w
+		}
+
+		w := create(`{"name":"Broader","scopes":["members:write"]}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "members:write")
+		assert.Equal(t, http.StatusForbidden, create(`{"name":"Other","scopes":["feedback:read"]}`).Code)
+		assert.Equal(t, http.StatusCreated, create(`{"name":"Narrower","scopes":["members:read"]}`).Code)
+	})
+
+	t.Run("Rotate Replaces Token", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		key := createAPIKey(t, r, `{"name":"CI","scopes":["members:read"]}`)
+
+		w := serve(r, "POST", fmt.Sprintf("/api-keys/%d/rotate", key.ID), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var rotated models.APIKey
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+		assert.Equal(t, key.Prefix, rotated.Prefix)
+		assert.NotEqual(t, key.Token, rotated.Token)
+		assert.NotNil(t, rotated.RotatedAt)
+
+		s := services.New(db)
+		_, err := s.AuthenticateAPIKey(t.Context(), key.Token)
+		assert.Error(t, err, "the old token stops working")
+		_, err = s.AuthenticateAPIKey(t.Context(), rotated.Token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Anonymous Callers Cannot Create Or Rotate", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		key := createAPIKey(t, r, `{"name":"CI","scopes":["members:read"]}`)
+		anonymous := setupGin()
+		anonymous.POST("/api-keys", CreateAPIKey)
+		anonymous.POST("/api-keys/:id/rotate", RotateAPIKey)
+
+		w := serve(anonymous, "POST", "/api-keys", `{"name":"Escalate","scopes":["api_keys:write"]}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+		w = serve(anonymous, "POST", fmt.Sprintf("/api-keys/%d/rotate", key.ID), "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		key := createAPIKey(t, r, `{"name":"CI","scopes":["members:read"]}`)
+
+		assert.Equal(t, http.StatusOK, serve(r, "DELETE", fmt.Sprintf("/api-keys/%d", key.ID), "").Code)
+		assert.Equal(t, http.StatusOK, serve(r, "DELETE", fmt.Sprintf("/api-keys/%d", key.ID), "").Code, "revoking twice is harmless")
+		assert.Equal(t, http.StatusConflict, serve(r, "POST", fmt.Sprintf("/api-keys/%d/rotate", key.ID), "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(r, "DELETE", "/api-keys/999", "").Code)
+
+		_, err := services.New(db).AuthenticateAPIKey(t.Context(), key.Token)
+		assert.Error(t, err)
+	})
+}
+
+func TestRequireScope(t *testing.T) {
+	keys := setupAPIKeyRoutes()
+
+	t.Run("Enforces Scopes", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupScopedRoutes(true)
+		reader := createAPIKey(t, keys, `{"name":"Reader","scopes":["members:read"]}`)
+		writer := createAPIKey(t, keys, `{"name":"Writer","scopes":["members:write"]}`)
+		other := createAPIKey(t, keys, `{"name":"Other","scopes":["feedback:write"]}`)
+
+		assert.Equal(t, http.StatusOK, withBearer(r, "GET", "/api/members", reader.Token).Code)
+		w := withBearer(r, "POST", "/api/members", reader.Token)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "members:write")
+		assert.Equal(t, http.StatusCreated, withBearer(r, "POST", "/api/members", writer.Token).Code)
+		assert.Equal(t, http.StatusOK, withBearer(r, "GET", "/api/members", writer.Token).Code, "write includes read")
+		assert.Equal(t, http.StatusForbidden, withBearer(r, "GET", "/api/members", other.Token).Code)
+	})
+
+	t.Run("Rejects Bad Keys", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		r := setupScopedRoutes(false)
+		key := createAPIKey(t, keys, `{"name":"CI","scopes":["members:read"]}`)
+		expired := createAPIKey(t, keys, `{"name":"Old","scopes":["members:read"],"expires_at":"2999-01-01T00:00:00Z"}`)
+		db.Model(&models.APIKey{}).Where("id = ?", expired.ID).Update("expires_at", time.Now().Add(-time.Minute))
+
+		for _, token := range []string{"nonsense", key.Token + "x", key.Prefix + "_wrong", expired.Token} {
+			w := withBearer(r, "GET", "/api/members", token)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, token)
+			assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+		}
+		assert.Equal(t, http.StatusUnauthorized, withBearer(r, "GET", "/api/open", "nonsense").Code, "bad keys are rejected on every route")
+	})
+
+	t.Run("Tracks Last Use", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		r := setupScopedRoutes(true)
+		key := createAPIKey(t, keys, `{"name":"CI","scopes":["members:read"]}`)
+		assert.Nil(t, key.LastUsedAt)
+
+		require.Equal(t, http.StatusOK, withBearer(r, "GET", "/api/members", key.Token).Code)
+		var stored models.APIKey
+		require.NoError(t, db.First(&stored, key.ID).Error)
+		require.NotNil(t, stored.LastUsedAt)
+		first := *stored.LastUsedAt
+
+		require.Equal(t, http.StatusOK, withBearer(r, "GET", "/api/members", key.Token).Code)
+		require.NoError(t, db.First(&stored, key.ID).Error)
+		assert.True(t, first.Equal(*stored.LastUsedAt), "uses within a minute are not written")
+	})
+
+	t.Run("Anonymous Requests", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		assert.Equal(t, http.StatusOK, withBearer(setupScopedRoutes(false), "GET", "/api/members", "").Code)
+
+		r := setupScopedRoutes(true)
+		w := withBearer(r, "GET", "/api/members", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, http.StatusNoContent, withBearer(r, "GET", "/api/open", "").Code, "unscoped routes stay open")
+	})
+
+	t.Run("Sessions Use Role Scopes", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		r := setupScopedRoutes(true)
+		member := testutils.CreateTestTeamMember(db)
+		token, _, err := services.New(db).CreateSession(t.Context(), member.ID, time.Hour)
+		require.NoError(t, err)
+
+		request := func(method string) int {
+			req := httptest.NewRequest(method, "/api/members", strings.NewReader(`{"name":"Grace","email":"grace@example.com"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: SessionCookie, Value: token})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+		assert.Equal(t, http.StatusOK, request("GET"))
+		assert.Equal(t, http.StatusForbidden, request("POST"), "members may only read members")
+
+		db.Model(member).Update("role", models.RoleAdmin)
+		assert.Equal(t, http.StatusCreated, request("POST"))
+	})
+}
@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"coaching-backend/database"
 	"coaching-backend/models"
+	"coaching-backend/problem"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,25 +11,13 @@ import (
 func AssignMemberToTeam(c *gin.Context) {
 	var request models.AssignRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Binding(c, err)
 		return
 	}
 
-	var member models.TeamMember
-	if err := database.DB.First(&member, request.MemberID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
-		return
-	}
-
-	var team models.Team
-	if err := database.DB.First(&team, request.TeamID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
-		return
-	}
-
-	member.TeamID = &request.TeamID
-	if err := database.DB.Save(&member).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign member to team"})
+	member, err := service().AssignMember(c.Request.Context(), request.MemberID, request.TeamID)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -38,21 +25,14 @@ func AssignMemberToTeam(c *gin.Context) {
 }
 
 func RemoveMemberFromTeam(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return
-	}
-
-	var member models.TeamMember
-	if err := database.DB.First(&member, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	member.TeamID = nil
-	if err := database.DB.Save(&member).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member from team"})
+	member, err := service().UnassignMember(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -60,9 +40,9 @@ func RemoveMemberFromTeam(c *gin.Context) {
 }
 
 func GetAssignments(c *gin.Context) {
-	var members []models.TeamMember
-	if err := database.DB.Preload("Team").Where("team_id IS NOT NULL").Find(&members).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignments"})
+	members, err := service().ListAssignedMembers(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -70,9 +50,9 @@ func GetAssignments(c *gin.Context) {
 }
 
 func GetUnassignedMembers(c *gin.Context) {
-	var members []models.TeamMember
-	if err := database.DB.Where("team_id IS NULL").Find(&members).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch unassigned members"})
+	members, err := service().ListUnassignedMembers(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
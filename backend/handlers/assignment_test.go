@@ -9,7 +9,6 @@ import (
 	"strconv"
 	"testing"
 
-	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -197,6 +196,8 @@ func TestGetUnassignedMembers(t *testing.T) {
 	})
 
 	t.Run("Get Empty Unassigned Members", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+
 		req, _ := http.NewRequest("GET", "/assignments/unassigned", nil)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
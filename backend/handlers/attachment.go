@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"coaching-backend/attachments"
+	"coaching-backend/problem"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadAttachment attaches the file sent as the "file" field of a
+// multipart/form-data body to the feedback.
+func UploadAttachment(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	limit := attachments.Default.MaxFileSize
+	limitBody(c, limit+64<<10)
+	allowSlowBody(c)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Write(c, problem.New(http.StatusRequestEntityTooLarge, problem.CodeTooLarge,
+				"Attachment is larger than "+strconv.FormatInt(limit>>20, 10)+" MB"))
+			return
+		}
+		problem.BadRequest(c, problem.CodeMalformedBody, "Request body must be multipart/form-data with a file field")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		problem.BadRequest(c, problem.CodeMalformedBody, "Uploaded file could not be read")
+		return
+	}
+	defer file.Close()
+
+	attachment, err := service().AddAttachment(c.Request.Context(), id, header.Filename, file)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+func GetAttachments(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	list, err := service().ListAttachments(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// DownloadAttachment sends the file as a download under its original
+// name. It is never rendered inline, so an uploaded page cannot run in
+// the API's origin.
+func DownloadAttachment(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	attachmentID, ok := parseIDParam(c, "attachment_id")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	attachment, err := service().GetAttachment(ctx, id, attachmentID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	body, err := service().OpenAttachment(ctx, attachment)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, map[string]string{
+		"Content-Disposition":     mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "sandbox",
+		"Cache-Control":           "private, no-cache",
+	})
+}
+
+func DeleteAttachment(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	attachmentID, ok := parseIDParam(c, "attachment_id")
+	if !ok {
+		return
+	}
+
+	if err := service().DeleteAttachment(c.Request.Context(), id, attachmentID); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"bytes"
+	"coaching-backend/attachments"
+	"coaching-backend/models"
+	"coaching-backend/tests/testutils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAttachmentRoutes(t *testing.T) (*gin.Engine, string) {
+	r, root := setupImageRoutes(t)
+	previous := attachments.Default
+	t.Cleanup(func() { attachments.Default = previous })
+
+	r.GET("/feedback/:id", GetFeedbackByID)
+	r.PUT("/feedback/:id", UpdateFeedback)
+	r.DELETE("/feedback/:id", DeleteFeedback)
+	r.POST("/feedback/:id/attachments", UploadAttachment)
+	r.GET("/feedback/:id/attachments", GetAttachments)
+	r.GET("/feedback/:id/attachments/:attachment_id", DownloadAttachment)
+	r.DELETE("/feedback/:id/attachments/:attachment_id", DeleteAttachment)
+	return r, root
+}
+
+func postFile(r *gin.Engine, path, filename string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", filename)
+	part.Write(data)
+	form.Close()
+
+	req, _ := http.NewRequest("POST", path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+type rejectingScanner struct{}
+
+func (rejectingScanner) Scan(ctx context.Context, name string, r io.Reader) error {
+	data, _ := io.ReadAll(r)
+	if bytes.Contains(data, []byte("EICAR")) {
+		return fmt.Errorf("signature found: %w", attachments.ErrInfected)
+	}
+	return nil
+}
+
+func TestAttachments(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r, root := setupAttachmentRoutes(t)
+	member := testutils.CreateTestTeamMember(db)
+	testutils.CreateTestFeedback(db, "member", member.ID)
+	testutils.CreateTestFeedback(db, "member", member.ID)
+	pdf := []byte("%PDF-1.7\nretro notes")
+
+	var stored models.Attachment
+	t.Run("Upload", func(t *testing.T) {
+		w := postFile(r, "/feedback/1/attachments", "../Retro notes.pdf", pdf)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+
+		assert.Equal(t, uint32(1), stored.FeedbackID)
+		assert.Equal(t, "Retro notes.pdf", stored.Filename)
+		assert.Equal(t, "application/pdf", stored.ContentType)
+		assert.Equal(t, int64(len(pdf)), stored.Size)
+		assert.NotContains(t, w.Body.String(), "attachments/", "the blob key is not exposed")
+		assert.Equal(t, 1, blobCount(t, root))
+	})
+
+	t.Run("Listed With Feedback", func(t *testing.T) {
+		w := serve(r, "GET", "/feedback/1", "")
+		var feedback models.Feedback
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &feedback))
+		require.Len(t, feedback.Attachments, 1)
+		assert.Equal(t, stored.ID, feedback.Attachments[0].ID)
+
+		w = serve(r, "GET", "/feedback/1/attachments", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"filename":"Retro notes.pdf"`)
+		assert.JSONEq(t, "[]", serve(r, "GET", "/feedback/2/attachments", "").Body.String())
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		w := serve(r, "GET", fmt.Sprintf("/feedback/1/attachments/%d", stored.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, pdf, w.Body.Bytes())
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="Retro notes.pdf"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	})
+
+	t.Run("Non-ASCII Filename", func(t *testing.T) {
+		w := postFile(r, "/feedback/1/attachments", "Résumé.txt", []byte("notes"))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var a models.Attachment
+		json.Unmarshal(w.Body.Bytes(), &a)
+
+		w = serve(r, "GET", fmt.Sprintf("/feedback/1/attachments/%d", a.ID), "")
+		assert.Equal(t, "attachment; filename*=utf-8''R%C3%A9sum%C3%A9.txt", w.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("Only Through Its Feedback", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(r, "GET", fmt.Sprintf("/feedback/2/attachments/%d", stored.ID), "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(r, "DELETE", fmt.Sprintf("/feedback/2/attachments/%d", stored.ID), "").Code)
+		assert.Equal(t, http.StatusNotFound, postFile(r, "/feedback/999/attachments", "a.txt", []byte("x")).Code)
+		assert.Equal(t, http.StatusNotFound, serve(r, "GET", "/feedback/999/attachments", "").Code)
+	})
+
+	t.Run("Rejects Disallowed Types", func(t *testing.T) {
+		w := postFile(r, "/feedback/1/attachments", "notes.txt", []byte("<html><script>alert(1)</script></html>"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"unsupported_media_type"`)
+	})
+
+	t.Run("Rejects Large Files", func(t *testing.T) {
+		attachments.Default.MaxFileSize = 1 << 20
+		defer func() { attachments.Default.MaxFileSize = 10 << 20 }()
+
+		w := postFile(r, "/feedback/1/attachments", "big.txt", bytes.Repeat([]byte("x"), 1<<20+1))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "larger than 1 MB")
+	})
+
+	t.Run("Enforces Quota", func(t *testing.T) {
+		attachments.Default.MaxTotalSize = 64
+		defer func() { attachments.Default.MaxTotalSize = 25 << 20 }()
+
+		w := postFile(r, "/feedback/1/attachments", "more.txt", bytes.Repeat([]byte("x"), 40))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "may total at most")
+		assert.Equal(t, http.StatusCreated, postFile(r, "/feedback/2/attachments", "more.txt", bytes.Repeat([]byte("x"), 40)).Code,
+			"the quota is per feedback")
+	})
+
+	t.Run("Virus Scan", func(t *testing.T) {
+		attachments.Default.Scanner = rejectingScanner{}
+		defer func() { attachments.Default.Scanner = attachments.NoopScanner{} }()
+
+		w := postFile(r, "/feedback/1/attachments", "eicar.txt", []byte("X5O EICAR test"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"rule":"scan"`)
+	})
+
+	t.Run("Update Keeps Attachments", func(t *testing.T) {
+		w := serve(r, "PUT", "/feedback/1", `{"content":"Edited","attachments":[{"id":99,"filename":"evil.exe"}]}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.NotContains(t, w.Body.String(), "evil.exe")
+
+		var count int64
+		db.Model(&models.Attachment{}).Where("feedback_id = 1").Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		before := blobCount(t, root)
+		w := serve(r, "DELETE", fmt.Sprintf("/feedback/1/attachments/%d", stored.ID), "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, before-1, blobCount(t, root))
+		assert.Equal(t, http.StatusNotFound, serve(r, "GET", fmt.Sprintf("/feedback/1/attachments/%d", stored.ID), "").Code)
+	})
+
+	t.Run("Deleting Feedback Removes Attachments", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, serve(r, "DELETE", "/feedback/1", "").Code)
+		require.Equal(t, http.StatusOK, serve(r, "DELETE", "/feedback/2", "").Code)
+
+		var count int64
+		db.Model(&models.Attachment{}).Count(&count)
+		assert.Zero(t, count)
+		assert.Equal(t, 0, blobCount(t, root))
+	})
+
+	t.Run("Requires Multipart", func(t *testing.T) {
+		testutils.CreateTestFeedback(db, "member", member.ID)
+		w := serve(r, "POST", "/feedback/3/attachments", `{"file":"x"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.True(t, strings.Contains(w.Body.String(), "multipart/form-data"))
+	})
+}
+
+func TestConcurrentAttachmentQuota(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	// Each connection to an in-memory database is a database of its own.
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	r, _ := setupAttachmentRoutes(t)
+	member := testutils.CreateTestTeamMember(db)
+	feedback := testutils.CreateTestFeedback(db, "member", member.ID)
+	attachments.Default.MaxTotalSize = 100
+
+	const uploads = 8
+	codes := make(chan int, uploads)
+	var wg sync.WaitGroup
+	for i := range uploads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := fmt.Sprintf("/feedback/%d/attachments", feedback.ID)
+			codes <- postFile(r, path, fmt.Sprintf("part%d.txt", i), bytes.Repeat([]byte("x"), 30)).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	created := 0
+	for code := range codes {
+		if code == http.StatusCreated {
+			created++
+		} else {
+			assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+		}
+	}
+	assert.Equal(t, 3, created, "only three 30 byte files fit in 100 bytes")
+
+	var total int64
+	require.NoError(t, db.Model(&models.Attachment{}).Where("feedback_id = ?", feedback.ID).
+		Select("COALESCE(SUM(size), 0)").Scan(&total).Error)
+	assert.LessOrEqual(t, total, int64(100))
+}
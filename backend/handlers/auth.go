@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"coaching-backend/oidc"
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// SessionCookie holds the token of a signed-in browser's session.
+	SessionCookie = "coaching_session"
+	// loginCookie holds the oidc.AuthRequest of a sign-in in progress.
+	loginCookie = "coaching_login"
+	// loginTimeout is how long someone has to sign in at the provider.
+	loginTimeout = 10 * time.Minute
+)
+
+// SSO configures single sign-on with an OpenID Connect provider.
+type SSO struct {
+	// Client is the provider's client, or nil when single sign-on is off.
+	Client  *oidc.Client
+	Mapping services.SSOMapping
+	// SessionTTL is how long a session lasts.
+	SessionTTL time.Duration
+	// AppURL is where people are sent once signed in or out; return_to
+	// paths are relative to it.
+	AppURL string
+	// SecureCookies marks cookies as HTTPS only.
+	SecureCookies bool
+}
+
+// pendingLogin is the content of the login cookie.
+type pendingLogin struct {
+	oidc.AuthRequest
+	ReturnTo string `json:"return_to"`
+}
+
+func (sso *SSO) setCookie(c *gin.Context, cookie *http.Cookie) {
+	cookie.HttpOnly = true
+	cookie.Secure = sso.SecureCookies
+	cookie.SameSite = http.SameSiteLaxMode
+	http.SetCookie(c.Writer, cookie)
+}
+
+// returnPath accepts only paths on the app, so the login cannot be used
+// to redirect elsewhere.
+func returnPath(s string) string {
+	if !strings.HasPrefix(s, "/") || strings.HasPrefix(s, "//") || strings.HasPrefix(s, "/\\") {
+		return "/"
+	}
+	return s
+}
+
+// Login sends the browser to the provider to sign in, remembering the
+// request in a short-lived cookie.
+func Login(sso *SSO) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sso.Client == nil {
+			problem.NotFound(c, "Single sign-on is not configured")
+			return
+		}
+		req, err := oidc.NewAuthRequest()
+		if err != nil {
+			_ = c.Error(err)
+			problem.Internal(c, "Failed to start sign-in")
+			return
+		}
+		authURL, err := sso.Client.AuthCodeURL(c.Request.Context(), req)
+		if err != nil {
+			_ = c.Error(err)
+			problem.Internal(c, "The identity provider is unavailable")
+			return
+		}
+
+		value, _ := json.Marshal(pendingLogin{AuthRequest: req, ReturnTo: returnPath(c.Query("return_to"))})
+		sso.setCookie(c, &http.Cookie{
+			Name:   loginCookie,
+			Value:  base64.RawURLEncoding.EncodeToString(value),
+			Path:   "/api/auth",
+			MaxAge: int(loginTimeout.Seconds()),
+		})
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// LoginCallback completes a sign-in: it redeems the provider's code,
+// verifies the ID token, signs the member in and sets the session cookie.
+func LoginCallback(sso *SSO) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sso.Client == nil {
+			problem.NotFound(c, "Single sign-on is not configured")
+			return
+		}
+		var pending pendingLogin
+		cookie, err := c.Cookie(loginCookie)
+		if err == nil {
+			var data []byte
+			if data, err = base64.RawURLEncoding.DecodeString(cookie); err == nil {
+				err = json.Unmarshal(data, &pending)
+			}
+		}
+		sso.setCookie(c, &http.Cookie{Name: loginCookie, Path: "/api/auth", MaxAge: -1})
+		if err != nil || pending.State == "" {
+			problem.BadRequest(c, problem.CodeInvalidParam, "Sign-in expired or was started in another browser")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.Query("state")), []byte(pending.State)) != 1 {
+			problem.InvalidParam(c, "state", "does not match the sign-in started in this browser")
+			return
+		}
+		if reason := c.Query("error"); reason != "" {
+			problem.Unauthorized(c, "The identity provider refused sign-in: "+reason)
+			return
+		}
+
+		ctx := c.Request.Context()
+		rawIDToken, err := sso.Client.Exchange(ctx, c.Query("code"), pending.Verifier)
+		if err != nil {
+			_ = c.Error(err)
+			problem.Unauthorized(c, "The sign-in code could not be redeemed")
+			return
+		}
+		claims, err := sso.Client.Verify(ctx, rawIDToken, pending.Nonce)
+		if err != nil {
+			_ = c.Error(err)
+			problem.Unauthorized(c, "The identity provider's token is invalid")
+			return
+		}
+
+		member, err := service().SignIn(ctx, services.SSOIdentity{
+			Subject:       claims.Subject,
+			Email:         claims.Email,
+			EmailVerified: claims.EmailVerified,
+			Name:          claims.Name,
+			Groups:        claims.Groups,
+		}, sso.Mapping)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		token, _, err := service().CreateSession(ctx, member.ID, sso.SessionTTL)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		sso.setCookie(c, &http.Cookie{
+			Name:   SessionCookie,
+			Value:  token,
+			Path:   "/",
+			MaxAge: int(sso.SessionTTL.Seconds()),
+		})
+		c.Redirect(http.StatusFound, strings.TrimSuffix(sso.AppURL, "/")+pending.ReturnTo)
+	}
+}
+
+// GetSession describes the member the session cookie signs in.
+func GetSession(c *gin.Context) {
+	token, err := c.Cookie(SessionCookie)
+	if err != nil {
+		problem.Unauthorized(c, "Not signed in")
+		return
+	}
+	session, err := service().GetSession(c.Request.Context(), token)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"member": session.Member, "role": session.Member.Role, "expires_at": session.ExpiresAt})
+}
+
+// Logout ends the session and clears its cookie. Signing out without a
+// session succeeds too.
+func Logout(sso *SSO) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token, err := c.Cookie(SessionCookie); err == nil {
+			if err := service().DeleteSession(c.Request.Context(), token); err != nil {
+				writeError(c, err)
+				return
+			}
+		}
+		sso.setCookie(c, &http.Cookie{Name: SessionCookie, Path: "/", MaxAge: -1})
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/oidc"
+	"coaching-backend/oidc/oidctest"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const callbackURL = "http://api.example.com/api/auth/callback"
+
+func setupAuthRoutes(sso *SSO) *gin.Engine {
+	r := setupGin()
+	g := r.Group("/api/auth")
+	g.GET("/login", Login(sso))
+	g.GET("/callback", LoginCallback(sso))
+	g.GET("/session", GetSession)
+	g.POST("/logout", Logout(sso))
+	return r
+}
+
+func newSSO(provider *oidctest.Server) *SSO {
+	return &SSO{
+		Client: oidc.New(oidc.Config{
+			Issuer:       provider.URL,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  callbackURL,
+		}),
+		Mapping: services.SSOMapping{
+			Teams:       map[string]string{"eng-platform": "Platform", "eng-design": "Design"},
+			AdminGroups: []string{"coaching-admins"},
+		},
+		SessionTTL: time.Hour,
+		AppURL:     "http://app.example.com",
+	}
+}
+
+// cookieNamed returns the cookie a response sets, or nil.
+func cookieNamed(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// signIn starts a sign-in, lets the provider approve it and completes the
+// callback, returning the callback's response.
+func signIn(t *testing.T, r *gin.Engine, returnTo string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/login?return_to="+url.QueryEscape(returnTo), nil))
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	login := cookieNamed(w, loginCookie)
+	require.NotNil(t, login)
+	assert.True(t, login.HttpOnly)
+
+	noRedirects := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirects.Get(w.Header().Get("Location"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(callback.String(), callbackURL))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/callback?"+callback.RawQuery, nil)
+	req.AddCookie(login)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func getSession(r *gin.Engine, session *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/session", nil)
+	if session != nil {
+		req.AddCookie(session)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLogin(t *testing.T) {
+	provider := oidctest.NewServer("coaching", "client-secret")
+	defer provider.Close()
+	sso := newSSO(provider)
+	r := setupAuthRoutes(sso)
+
+	t.Run("Creates Member On First Sign-In", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		provider.SetClaims(map[string]interface{}{
+			"sub": "ada-1", "email": "ada@example.com", "email_verified": true, "name": "Ada Lovelace",
+			"groups": []string{"everyone", "eng-platform", "eng-design", "coaching-admins"},
+		})
+
+		w := signIn(t, r, "/teams?sort=name")
+		require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+		assert.Equal(t, "http://app.example.com/teams?sort=name", w.Header().Get("Location"))
+		session := cookieNamed(w, SessionCookie)
+		require.NotNil(t, session)
+		assert.True(t, session.HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, session.SameSite)
+		assert.Equal(t, 3600, session.MaxAge)
+		assert.Equal(t, -1, cookieNamed(w, loginCookie).MaxAge, "the login cookie is cleared")
+
+		var member models.TeamMember
+		require.NoError(t, db.Preload("Team").Take(&member).Error)
+		assert.Equal(t, "Ada Lovelace", member.Name)
+		assert.Equal(t, "ada-1", member.SSOSubject)
+		assert.Equal(t, models.RoleAdmin, member.Role)
+		require.NotNil(t, member.Team)
+		assert.Equal(t, "Platform", member.Team.Name, "the first mapped group wins")
+
+		w = getSession(r, session)
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Member models.TeamMember `json:"member"`
+			Role   string            `json:"role"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, member.ID, body.Member.ID)
+		assert.Equal(t, models.RoleAdmin, body.Role)
+
+		var stored models.Session
+		require.NoError(t, db.Take(&stored).Error)
+		assert.NotEqual(t, session.Value, stored.TokenHash, "only a hash of the token is stored")
+	})
+
+	t.Run("Links Existing Member By Email", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		existing := testutils.CreateTestTeamMember(db)
+		provider.SetClaims(map[string]interface{}{
+			"sub": "john-1", "email": "JOHN@example.com", "email_verified": true, "name": "John Doe",
+		})
+
+		w := signIn(t, r, "")
+		require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+		assert.Equal(t, "http://app.example.com/", w.Header().Get("Location"))
+
+		var members []models.TeamMember
+		require.NoError(t, db.Find(&members).Error)
+		require.Len(t, members, 1)
+		assert.Equal(t, existing.ID, members[0].ID)
+		assert.Equal(t, "john-1", members[0].SSOSubject)
+		assert.Equal(t, models.RoleMember, members[0].Role)
+		assert.Nil(t, members[0].TeamID, "a member in no mapped group keeps their team")
+
+		// The subject finds the member after their email changes.
+		provider.SetClaims(map[string]interface{}{
+			"sub": "john-1", "email": "john.doe@example.com", "email_verified": true, "name": "John Doe",
+		})
+		require.Equal(t, http.StatusFound, signIn(t, r, "").Code)
+		require.NoError(t, db.Find(&members).Error)
+		require.Len(t, members, 1)
+		assert.Equal(t, "john.doe@example.com", members[0].Email)
+	})
+
+	t.Run("Refuses Email Linked To Another Identity", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		require.NoError(t, db.Create(&models.TeamMember{Name: "Ada", Email: "ada@example.com", SSOSubject: "ada-1"}).Error)
+		provider.SetClaims(map[string]interface{}{"sub": "impostor", "email": "ada@example.com", "email_verified": true})
+
+		w := signIn(t, r, "/")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Nil(t, cookieNamed(w, SessionCookie))
+	})
+
+	t.Run("Refuses Unverified Email", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		provider.SetClaims(map[string]interface{}{"sub": "grace-1", "email": "grace@example.com", "email_verified": false})
+
+		w := signIn(t, r, "/")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "verified email")
+		var count int64
+		db.Model(&models.TeamMember{}).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Only Returns To App Paths", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		provider.SetClaims(map[string]interface{}{"sub": "ada-1", "email": "ada@example.com", "email_verified": true})
+
+		w := signIn(t, r, "//evil.example.com/")
+		assert.Equal(t, "http://app.example.com/", w.Header().Get("Location"))
+		w = signIn(t, r, "https://evil.example.com/")
+		assert.Equal(t, "http://app.example.com/", w.Header().Get("Location"))
+	})
+
+	t.Run("Rejects Forged Callbacks", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/callback?code=x&state=y", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, "no sign-in was started in this browser")
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/login", nil))
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/callback?code=x&state=other", nil)
+		req.AddCookie(cookieNamed(w, loginCookie))
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "state")
+	})
+
+	t.Run("Sign Out", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		provider.SetClaims(map[string]interface{}{"sub": "ada-1", "email": "ada@example.com", "email_verified": true})
+		session := cookieNamed(signIn(t, r, "/"), SessionCookie)
+		require.NotNil(t, session)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+		req.AddCookie(session)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, -1, cookieNamed(w, SessionCookie).MaxAge)
+
+		assert.Equal(t, http.StatusUnauthorized, getSession(r, session).Code)
+		assert.Equal(t, http.StatusUnauthorized, getSession(r, nil).Code)
+	})
+
+	t.Run("Expired Session", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		member := testutils.CreateTestTeamMember(db)
+		token, _, err := services.New(db).CreateSession(t.Context(), member.ID, time.Hour)
+		require.NoError(t, err)
+		db.Model(&models.Session{}).Where("member_id = ?", member.ID).Update("expires_at", time.Now().Add(-time.Minute))
+
+		assert.Equal(t, http.StatusUnauthorized, getSession(r, &http.Cookie{Name: SessionCookie, Value: token}).Code)
+	})
+
+	t.Run("Not Configured", func(t *testing.T) {
+		r := setupAuthRoutes(&SSO{SessionTTL: time.Hour})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/login", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"coaching-backend/chatops"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCommandBody caps the slash command form read before verification.
+const maxCommandBody = 64 << 10
+
+// SlashCommand handles "/kudos @handle message" from Slack or Mattermost:
+// it verifies the request, resolves the mention to a team member and
+// gives them the message as feedback. Problems the user can fix are
+// answered with a message only they see, as chat clients expect a 200.
+func SlashCommand(v chatops.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCommandBody))
+		if err != nil {
+			problem.BadRequest(c, problem.CodeMalformedBody, "Request body could not be read")
+			return
+		}
+		if err := v.Verify(c.Request.Header, body, time.Now()); err != nil {
+			log.Printf("chatops: rejected slash command: %v", err)
+			problem.Unauthorized(c, "Slash command could not be verified")
+			return
+		}
+		cmd, err := chatops.ParseCommand(body)
+		if err != nil {
+			problem.BadRequest(c, problem.CodeMalformedBody, "Request body is not form-encoded")
+			return
+		}
+
+		mention, content, err := chatops.ParseKudos(cmd.Text)
+		if err != nil {
+			c.JSON(http.StatusOK, chatops.Usage(cmd.Command))
+			return
+		}
+
+		ctx := c.Request.Context()
+		member, err := service().ResolveMember(ctx, mention)
+		if err != nil {
+			replyError(c, err)
+			return
+		}
+
+		feedback := models.Feedback{Content: content, TargetType: "member", TargetID: member.ID}
+		if err := service().CreateFeedback(ctx, &feedback); err != nil {
+			replyError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, chatops.KudosMessage(member.Name, cmd.UserName, content))
+	}
+}
+
+// replyError answers not found and validation errors in chat and writes
+// a problem for anything else.
+func replyError(c *gin.Context, err error) {
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		switch serviceErr.Code {
+		case problem.CodeNotFound:
+			c.JSON(http.StatusOK, chatops.Reply(serviceErr.Message+"."))
+			return
+		case problem.CodeValidation:
+			message := serviceErr.Message
+			if len(serviceErr.Fields) > 0 {
+				message = serviceErr.Fields[0].Message
+			}
+			c.JSON(http.StatusOK, chatops.Reply("Could not give kudos: "+message+"."))
+			return
+		}
+	}
+	writeError(c, err)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"coaching-backend/chatops"
+	"coaching-backend/models"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const (
+	testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+	testCommandToken  = "q8ef1s4uqjdb8yeb5wcztfk6sr"
+)
+
+func setupChatOpsRoutes() *gin.Engine {
+	r := setupGin()
+	r.POST("/chatops/kudos", SlashCommand(chatops.Verifier{SigningSecret: testSigningSecret, Token: testCommandToken}))
+	return r
+}
+
+// recordedBody returns the form body of a recorded slash command.
+func recordedBody(t *testing.T, name string) string {
+	data, err := os.ReadFile("../chatops/testdata/" + name)
+	require.NoError(t, err)
+	var rec struct {
+		Body string `json:"body"`
+	}
+	require.NoError(t, json.Unmarshal(data, &rec))
+	return rec.Body
+}
+
+// sendSlack posts body signed as Slack would sign it now.
+func sendSlack(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, _ := http.NewRequest("POST", "/chatops/kudos", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(chatops.TimestampHeader, timestamp)
+	req.Header.Set(chatops.SignatureHeader, chatops.Sign(testSigningSecret, timestamp, []byte(body)))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func sendForm(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/chatops/kudos", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeMessage(t *testing.T, w *httptest.ResponseRecorder) chatops.Message {
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var msg chatops.Message
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &msg))
+	return msg
+}
+
+func createMember(t *testing.T, db *gorm.DB, name, email string) *models.TeamMember {
+	member := &models.TeamMember{Name: name, Email: email}
+	require.NoError(t, db.Create(member).Error)
+	return member
+}
+
+func feedbackFor(db *gorm.DB, member *models.TeamMember) []models.Feedback {
+	var feedback []models.Feedback
+	db.Where("target_type = ? AND target_id = ?", "member", member.ID).Find(&feedback)
+	return feedback
+}
+
+func TestSlashCommand(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupChatOpsRoutes()
+	alice := createMember(t, db, "Alice Smith", "Alice@example.com")
+	carol := createMember(t, db, "Carol Jones", "carol@example.com")
+
+	t.Run("Slack Mention", func(t *testing.T) {
+		msg := decodeMessage(t, sendSlack(r, recordedBody(t, "slack_kudos.json")))
+
+		assert.Equal(t, chatops.InChannel, msg.ResponseType)
+		assert.Equal(t, ":tada: Kudos for *Alice Smith* from @bob\n> great demo today!\n> The charts were clear.", msg.Text)
+
+		feedback := feedbackFor(db, alice)
+		require.Len(t, feedback, 1)
+		assert.Equal(t, "great demo today!\nThe charts were clear.", feedback[0].Content)
+		assert.Equal(t, "Alice Smith", feedback[0].TargetName, "created through the feedback service")
+	})
+
+	t.Run("Slack Email Mention", func(t *testing.T) {
+		msg := decodeMessage(t, sendSlack(r, recordedBody(t, "slack_kudos_email.json")))
+
+		assert.Contains(t, msg.Text, "Kudos for *Carol Jones*")
+		assert.Len(t, feedbackFor(db, carol), 1)
+	})
+
+	t.Run("Mattermost Token", func(t *testing.T) {
+		msg := decodeMessage(t, sendForm(r, recordedBody(t, "mattermost_kudos.json")))
+
+		assert.Contains(t, msg.Text, "Kudos for *Alice Smith*")
+		assert.Len(t, feedbackFor(db, alice), 2)
+	})
+
+	t.Run("Unknown Member", func(t *testing.T) {
+		body := url.Values{"command": {"/kudos"}, "text": {"@dave nice"}, "user_name": {"bob"}}.Encode()
+		msg := decodeMessage(t, sendSlack(r, body))
+
+		assert.Equal(t, chatops.Ephemeral, msg.ResponseType)
+		assert.Equal(t, "No team member matches dave.", msg.Text)
+	})
+
+	t.Run("Ambiguous Handle", func(t *testing.T) {
+		createMember(t, db, "Alice Other", "alice@other.example.com")
+		defer db.Where("email = ?", "alice@other.example.com").Delete(&models.TeamMember{})
+
+		body := url.Values{"command": {"/kudos"}, "text": {"@alice nice"}}.Encode()
+		msg := decodeMessage(t, sendSlack(r, body))
+
+		assert.Equal(t, chatops.Ephemeral, msg.ResponseType)
+		assert.Equal(t, "Could not give kudos: alice matches several team members.", msg.Text)
+	})
+
+	t.Run("Handle Wildcards Are Literal", func(t *testing.T) {
+		body := url.Values{"command": {"/kudos"}, "text": {"@%ar% nice"}}.Encode()
+		msg := decodeMessage(t, sendSlack(r, body))
+		assert.Equal(t, chatops.Ephemeral, msg.ResponseType)
+	})
+
+	t.Run("Usage", func(t *testing.T) {
+		body := url.Values{"command": {"/kudos"}, "text": {"help"}}.Encode()
+		msg := decodeMessage(t, sendSlack(r, body))
+
+		assert.Equal(t, chatops.Ephemeral, msg.ResponseType)
+		assert.Contains(t, msg.Text, "Usage: `/kudos @handle message`")
+	})
+
+	t.Run("Rejects Bad Signature", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/chatops/kudos", bytes.NewBufferString(recordedBody(t, "slack_kudos.json")))
+		req.Header.Set(chatops.TimestampHeader, strconv.FormatInt(time.Now().Unix(), 10))
+		req.Header.Set(chatops.SignatureHeader, "v0=deadbeef")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"unauthorized"`)
+	})
+
+	t.Run("Rejects Wrong Token", func(t *testing.T) {
+		body := url.Values{"token": {"wrong"}, "text": {"@alice nice"}}.Encode()
+		assert.Equal(t, http.StatusUnauthorized, sendForm(r, body).Code)
+		assert.Len(t, feedbackFor(db, alice), 2)
+	})
+}
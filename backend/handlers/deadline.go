@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transferIdleTimeout is how long an upload may go without sending a byte
+// once the server's read timeout is lifted for it.
+var transferIdleTimeout = time.Minute
+
+// stream lifts the server's write timeout for a response that lasts as
+// long as the client wants it to, or that is written only after a long
+// upload.
+func stream(c *gin.Context) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
+
+// progressBody pushes the connection's read deadline forward as the body
+// arrives.
+type progressBody struct {
+	io.ReadCloser
+	controller *http.ResponseController
+}
+
+func (b *progressBody) Read(p []byte) (int, error) {
+	_ = b.controller.SetReadDeadline(time.Now().Add(transferIdleTimeout))
+	return b.ReadCloser.Read(p)
+}
+
+// allowSlowBody lifts the server's read and write timeouts for a large
+// request body, such as an import or an upload, which a slow connection
+// may take longer than the read timeout to send. The body is cut off only
+// once it stalls for transferIdleTimeout.
+func allowSlowBody(c *gin.Context) {
+	stream(c)
+	controller := http.NewResponseController(c.Writer)
+	_ = controller.SetReadDeadline(time.Now().Add(transferIdleTimeout))
+	c.Request.Body = &progressBody{ReadCloser: c.Request.Body, controller: controller}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"coaching-backend/tests/testutils"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveWithTimeouts runs r on a real server, whose timeouts a recorder
+// would not apply.
+func serveWithTimeouts(t *testing.T, r *gin.Engine, timeout time.Duration) string {
+	server := httptest.NewUnstartedServer(r)
+	server.Config.ReadTimeout = timeout
+	server.Config.WriteTimeout = timeout
+	server.Start()
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// postSlowly sends a members CSV as a multipart upload, one row per pause.
+func postSlowly(url string, rows int, pause time.Duration) (*http.Response, error) {
+	body, pipe := io.Pipe()
+	form := multipart.NewWriter(pipe)
+	go func() {
+		part, _ := form.CreateFormFile("file", "members.csv")
+		io.WriteString(part, "Name,Email\n")
+		for i := range rows {
+			time.Sleep(pause)
+			io.WriteString(part, "Member "+string(rune('A'+i))+",member"+string(rune('a'+i))+"@example.com\n")
+		}
+		pipe.CloseWithError(form.Close())
+	}()
+	req, _ := http.NewRequest("POST", url, body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return http.DefaultClient.Do(req)
+}
+
+func TestSlowBodies(t *testing.T) {
+	testutils.SetupTestDB(t)
+	r := setupGin()
+	r.POST("/members/import", ImportMembers)
+	r.POST("/echo", func(c *gin.Context) {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.String(http.StatusOK, "%d", len(data))
+	})
+	url := serveWithTimeouts(t, r, 300*time.Millisecond)
+
+	t.Run("Imports Outlast The Server Timeouts", func(t *testing.T) {
+		resp, err := postSlowly(url+"/members/import", 6, 100*time.Millisecond)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(data))
+		assert.Contains(t, string(data), `"created":6`)
+	})
+
+	t.Run("Other Bodies Are Cut Off", func(t *testing.T) {
+		resp, err := postSlowly(url+"/echo", 6, 100*time.Millisecond)
+		if err == nil {
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		}
+	})
+
+	t.Run("Stalled Bodies Are Cut Off", func(t *testing.T) {
+		defer func(timeout time.Duration) { transferIdleTimeout = timeout }(transferIdleTimeout)
+		transferIdleTimeout = 200 * time.Millisecond
+
+		resp, err := postSlowly(url+"/members/import", 2, 500*time.Millisecond)
+		if err == nil {
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		}
+	})
+}
+
+func TestSlowExports(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	testutils.CreateTestTeamMember(db)
+	r := setupGin()
+	r.GET("/members/export", func(c *gin.Context) {
+		time.Sleep(500 * time.Millisecond)
+		ExportMembers(c)
+	})
+	url := serveWithTimeouts(t, r, 300*time.Millisecond)
+
+	t.Run("Exports Outlast The Write Timeout", func(t *testing.T) {
+		resp, err := http.Get(url + "/members/export")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, strings.HasPrefix(string(data), "id,"), string(data))
+	})
+}
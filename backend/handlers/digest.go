@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"coaching-backend/digest"
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultDigestPeriod is the period previewed when from is omitted.
+const defaultDigestPeriod = 7 * 24 * time.Hour
+
+func GetMemberDigest(c *gin.Context) {
+	writeDigest(c, (*services.Service).MemberDigest)
+}
+
+func GetTeamDigest(c *gin.Context) {
+	writeDigest(c, (*services.Service).TeamDigest)
+}
+
+// writeDigest compiles the digest for the :id in the path and the from, to
+// and format query parameters, and renders it.
+func writeDigest(c *gin.Context, compile func(*services.Service, context.Context, uint32, time.Time, time.Time) (*digest.Digest, error)) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	format := digest.JSON
+	if raw := c.Query("format"); raw != "" {
+		if format, ok = digest.ParseFormat(raw); !ok {
+			problem.InvalidParam(c, "format", "must be json, html or markdown")
+			return
+		}
+	}
+
+	to := time.Now().UTC()
+	if raw := c.Query("to"); raw != "" {
+		if to, ok = parseTime(raw); !ok {
+			problem.InvalidParam(c, "to", "must be an RFC 3339 time or a YYYY-MM-DD date")
+			return
+		}
+	}
+	from := to.Add(-defaultDigestPeriod)
+	if raw := c.Query("from"); raw != "" {
+		if from, ok = parseTime(raw); !ok {
+			problem.InvalidParam(c, "from", "must be an RFC 3339 time or a YYYY-MM-DD date")
+			return
+		}
+	}
+
+	d, err := compile(service(), c.Request.Context(), id, from, to)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	if format == digest.JSON {
+		c.JSON(http.StatusOK, d)
+		return
+	}
+	body, err := digest.RenderString(format, d)
+	if err != nil {
+		_ = c.Error(err)
+		problem.Internal(c, "Failed to render digest")
+		return
+	}
+	c.Data(http.StatusOK, format.ContentType(), []byte(body))
+}
+
+// parseTime accepts an RFC 3339 time or a date, which means midnight UTC.
+func parseTime(raw string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
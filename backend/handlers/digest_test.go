@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDigestRoutes() *gin.Engine {
+	r := setupGin()
+	r.POST("/assignments", AssignMemberToTeam)
+	r.DELETE("/assignments/member/:id", RemoveMemberFromTeam)
+	r.GET("/members/:id/digest", GetMemberDigest)
+	r.GET("/teams/:id/digest", GetTeamDigest)
+	return r
+}
+
+func TestDigests(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupDigestRoutes()
+
+	member := testutils.CreateTestTeamMember(db)
+	first, second := testutils.CreateTestTeam(db), testutils.CreateTestTeam(db)
+	for _, teamID := range []uint32{first.ID, second.ID} {
+		w := serve(r, "POST", "/assignments", fmt.Sprintf(`{"member_id":%d,"team_id":%d}`, member.ID, teamID))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	testutils.CreateTestFeedback(db, "member", member.ID)
+	testutils.CreateTestFeedback(db, "team", second.ID)
+
+	t.Run("Member Digest As JSON", func(t *testing.T) {
+		w := serve(r, "GET", fmt.Sprintf("/members/%d/digest", member.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var d struct {
+			Kind     string                    `json:"kind"`
+			Name     string                    `json:"name"`
+			Feedback []models.Feedback         `json:"feedback"`
+			Changes  []models.AssignmentChange `json:"changes"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &d))
+		assert.Equal(t, "member", d.Kind)
+		assert.Equal(t, "John Doe", d.Name)
+		require.Len(t, d.Feedback, 1)
+		assert.Equal(t, "Great work!", d.Feedback[0].Content)
+
+		require.Len(t, d.Changes, 3, "joining the first team, then moving to the second")
+		assert.Equal(t, []string{"joined", "left", "joined"}, []string{d.Changes[0].Action, d.Changes[1].Action, d.Changes[2].Action})
+		assert.Equal(t, first.Name, d.Changes[1].TeamName)
+		assert.Equal(t, second.Name, d.Changes[2].TeamName)
+	})
+
+	t.Run("Team Digest As Markdown", func(t *testing.T) {
+		w := serve(r, "GET", fmt.Sprintf("/teams/%d/digest?format=markdown", second.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+
+		body := w.Body.String()
+		assert.Contains(t, body, "# Team "+second.Name)
+		assert.Contains(t, body, "> Great work!")
+		assert.Contains(t, body, "John Doe joined "+second.Name)
+		assert.Contains(t, body, "## Members\n\n- John Doe")
+	})
+
+	t.Run("Team Digest As HTML", func(t *testing.T) {
+		w := serve(r, "GET", fmt.Sprintf("/teams/%d/digest?format=html", first.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "No feedback this period.")
+		assert.Contains(t, w.Body.String(), "John Doe left "+first.Name)
+	})
+
+	t.Run("Unassigning Records Leaving", func(t *testing.T) {
+		w := serve(r, "DELETE", fmt.Sprintf("/assignments/member/%d", member.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var changes []models.AssignmentChange
+		db.Where("team_id = ?", second.ID).Order("id").Find(&changes)
+		require.Len(t, changes, 2)
+		assert.Equal(t, "left", changes[1].Action)
+	})
+
+	t.Run("Period Outside Activity", func(t *testing.T) {
+		w := serve(r, "GET", fmt.Sprintf("/members/%d/digest?from=2020-01-01&to=2020-01-08", member.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var d map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &d))
+		assert.Empty(t, d["feedback"])
+		assert.Empty(t, d["changes"])
+		assert.Equal(t, "2020-01-01T00:00:00Z", d["from"])
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		tests := []struct{ query, field string }{
+			{"format=pdf", "format"},
+			{"from=yesterday", "from"},
+			{"to=2020-13-01", "to"},
+			{"from=2020-02-01&to=2020-01-01", "from"},
+			{"from=2018-01-01&to=2020-01-01", "from"},
+		}
+		for _, tt := range tests {
+			w := serve(r, "GET", fmt.Sprintf("/members/%d/digest?%s", member.ID, tt.query), "")
+			assert.Equal(t, http.StatusBadRequest, w.Code, tt.query)
+			assert.Contains(t, w.Body.String(), `"field":"`+tt.field+`"`, tt.query)
+		}
+	})
+
+	t.Run("Non-existent Member And Team", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(r, "GET", "/members/999/digest", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(r, "GET", "/teams/999/digest", "").Code)
+	})
+}
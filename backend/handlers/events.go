@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/problem"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// keepAliveInterval keeps proxies from closing idle event streams.
+	keepAliveInterval = 15 * time.Second
+	wsWriteTimeout    = 10 * time.Second
+)
+
+// StreamEvents streams domain events as Server-Sent Events. EventSource
+// clients resume automatically: the Last-Event-ID header they send on
+// reconnect replays the events they missed.
+func StreamEvents(c *gin.Context) {
+	filter, lastID, ok := eventParams(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	sub := events.Default.Subscribe(ctx, filter, lastID)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	stream(c)
+
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+	if sub.Reset {
+		fmt.Fprint(c.Writer, "event: reset\ndata: {}\n\n")
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, open := <-sub.Events:
+			if !open {
+				// Context done, too far behind or shutting down; either
+				// way the client reconnects and resumes from its last
+				// event ID.
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				_ = c.Error(err)
+				return
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			c.Writer.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// EventsWebSocket streams the same events as StreamEvents over a WebSocket.
+// Each message is an event as JSON; a {"type":"reset"} message means the
+// requested resume point was lost. Browsers cannot set Last-Event-ID on a
+// WebSocket, so pass last_event_id as a query parameter instead.
+func EventsWebSocket(allowedOrigins []string) gin.HandlerFunc {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return originAllowed(r, allowedOrigins)
+		},
+	}
+
+	return func(c *gin.Context) {
+		filter, lastID, ok := eventParams(c)
+		if !ok {
+			return
+		}
+
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// The upgrader has already written the error response.
+			return
+		}
+		defer conn.Close()
+
+		ctx := c.Request.Context()
+		sub := events.Default.Subscribe(ctx, filter, lastID)
+
+		// Clients send nothing, but reading is how close frames and dead
+		// connections are noticed.
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		if sub.Reset {
+			if err := writeJSON(conn, gin.H{"type": "reset"}); err != nil {
+				return
+			}
+		}
+
+		keepAlive := time.NewTicker(keepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case event, open := <-sub.Events:
+				if !open {
+					message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fell behind; reconnect with last_event_id")
+					if events.Default.Closed() {
+						message = websocket.FormatCloseMessage(websocket.CloseServiceRestart, "shutting down; reconnect with last_event_id")
+					}
+					conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteTimeout))
+					return
+				}
+				if err := writeJSON(conn, event); err != nil {
+					return
+				}
+			case <-keepAlive.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+					return
+				}
+			case <-closed:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func writeJSON(conn *websocket.Conn, v interface{}) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(v)
+}
+
+// originAllowed accepts same-origin requests, requests without an Origin
+// header (non-browser clients) and the configured origins.
+func originAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, o := range allowed {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventParams reads the subscription filter and resume point shared by
+// both event endpoints, writing a problem response when they are invalid.
+func eventParams(c *gin.Context) (events.Filter, uint64, bool) {
+	var filter events.Filter
+
+	for _, p := range []struct {
+		name string
+		dest *uint32
+	}{{"team_id", &filter.TeamID}, {"target_id", &filter.TargetID}} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			problem.InvalidParam(c, p.name, "must be a positive integer")
+			return filter, 0, false
+		}
+		*p.dest = uint32(id)
+	}
+
+	filter.TargetType = c.Query("target_type")
+
+	if raw := c.Query("types"); raw != "" {
+		for _, name := range strings.Split(raw, ",") {
+			t := events.Type(strings.TrimSpace(name))
+			if !events.Known(t) {
+				problem.InvalidParam(c, "types", "unknown event type "+strconv.Quote(string(t)))
+				return filter, 0, false
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+
+	rawLastID := c.GetHeader("Last-Event-ID")
+	if rawLastID == "" {
+		rawLastID = c.Query("last_event_id")
+	}
+	var lastID uint64
+	if rawLastID != "" {
+		id, err := strconv.ParseUint(rawLastID, 10, 64)
+		if err != nil {
+			problem.InvalidParam(c, "last_event_id", "must be an event ID")
+			return filter, 0, false
+		}
+		lastID = id
+	}
+
+	return filter, lastID, true
+}
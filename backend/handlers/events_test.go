@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/outbox"
+	"coaching-backend/tests/testutils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type sseFrame struct {
+	id    string
+	event string
+	data  string
+}
+
+// readFrame returns the next SSE frame that carries an event, skipping
+// comments and the retry hint.
+func readFrame(t *testing.T, r *bufio.Reader) sseFrame {
+	var f sseFrame
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+
+		switch {
+		case line == "":
+			if f.event != "" {
+				return f
+			}
+		case strings.HasPrefix(line, "id: "):
+			f.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			f.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			f.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func openStream(t *testing.T, ctx context.Context, url, lastEventID string) *bufio.Reader {
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(resp.Body)
+	// The retry hint is flushed once the subscription is registered.
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "retry: 3000\n", line)
+	return r
+}
+
+func postJSON(t *testing.T, url string, body interface{}) {
+	jsonBody, _ := json.Marshal(body)
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonBody))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Less(t, resp.StatusCode, 300)
+}
+
+// setupEventServer serves the event endpoints from a fresh bus, fed by an
+// outbox relay over db.
+func setupEventServer(t *testing.T, db *gorm.DB) *httptest.Server {
+	events.Default = events.NewBus(events.DefaultHistory)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	relay := outbox.NewRelay(db, outbox.Config{PollInterval: 10 * time.Millisecond, RetryDelay: time.Second, BatchSize: 10},
+		outbox.BusSink{Bus: events.Default})
+	go relay.Run(ctx)
+
+	r := setupGin()
+	r.GET("/events", StreamEvents)
+	r.GET("/events/ws", EventsWebSocket([]string{"http://allowed.example"}))
+	r.POST("/feedback", CreateFeedback)
+	r.POST("/assignments", AssignMemberToTeam)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStreamEvents(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	srv := setupEventServer(t, db)
+
+	t.Run("Streams Filtered Events", func(t *testing.T) {
+		team := testutils.CreateTestTeam(db)
+		member := testutils.CreateTestTeamMember(db)
+		otherTeam := testutils.CreateTestTeam(db)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stream := openStream(t, ctx, fmt.Sprintf("%s/events?team_id=%d", srv.URL, team.ID), "")
+
+		postJSON(t, srv.URL+"/feedback", testutils.TestFeedbackRequest{Content: "Elsewhere", TargetType: "team", TargetID: otherTeam.ID})
+		postJSON(t, srv.URL+"/assignments", map[string]uint32{"member_id": member.ID, "team_id": team.ID})
+		postJSON(t, srv.URL+"/feedback", testutils.TestFeedbackRequest{Content: "Welcome!", TargetType: "member", TargetID: member.ID})
+
+		assigned := readFrame(t, stream)
+		assert.Equal(t, "member.assigned", assigned.event)
+
+		feedback := readFrame(t, stream)
+		assert.Equal(t, "feedback.created", feedback.event)
+		var event map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(feedback.data), &event))
+		assert.Equal(t, feedback.id, strconv.Itoa(int(event["id"].(float64))))
+		assert.NotEmpty(t, event["key"])
+		assert.Equal(t, float64(team.ID), event["team_id"], "feedback on a member carries the member's team")
+		assert.Equal(t, "Welcome!", event["data"].(map[string]interface{})["content"])
+	})
+
+	t.Run("Resumes From Last Event ID", func(t *testing.T) {
+		team := testutils.CreateTestTeam(db)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stream := openStream(t, ctx, fmt.Sprintf("%s/events?team_id=%d", srv.URL, team.ID), "")
+
+		postJSON(t, srv.URL+"/feedback", testutils.TestFeedbackRequest{Content: "First", TargetType: "team", TargetID: team.ID})
+		postJSON(t, srv.URL+"/feedback", testutils.TestFeedbackRequest{Content: "Second", TargetType: "team", TargetID: team.ID})
+		first := readFrame(t, stream)
+		second := readFrame(t, stream)
+		cancel()
+
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		resumed := openStream(t, ctx, fmt.Sprintf("%s/events?team_id=%d", srv.URL, team.ID), first.id)
+
+		frame := readFrame(t, resumed)
+		assert.Equal(t, second.id, frame.id)
+		assert.Contains(t, frame.data, "Second")
+	})
+
+	t.Run("Reset On Unknown Event ID", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stream := openStream(t, ctx, srv.URL+"/events", "999999999")
+
+		assert.Equal(t, "reset", readFrame(t, stream).event)
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		for _, query := range []string{"team_id=abc", "types=member.exploded", "last_event_id=x"} {
+			resp, err := http.Get(srv.URL + "/events?" + query)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		}
+	})
+}
+
+func TestEventsWebSocket(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	srv := setupEventServer(t, db)
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/events/ws"
+
+	t.Run("Streams Filtered Events", func(t *testing.T) {
+		team := testutils.CreateTestTeam(db)
+		member := testutils.CreateTestTeamMember(db)
+
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?types=member.assigned", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		postJSON(t, srv.URL+"/feedback", testutils.TestFeedbackRequest{Content: "Ignored", TargetType: "team", TargetID: team.ID})
+		postJSON(t, srv.URL+"/assignments", map[string]uint32{"member_id": member.ID, "team_id": team.ID})
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var event events.Event
+		require.NoError(t, conn.ReadJSON(&event))
+		assert.Equal(t, events.MemberAssigned, event.Type)
+		assert.Equal(t, team.ID, *event.TeamID)
+		assert.Equal(t, member.ID, event.TargetID)
+	})
+
+	t.Run("Resumes From Query Parameter", func(t *testing.T) {
+		team := testutils.CreateTestTeam(db)
+		first := events.Default.Publish(events.Event{ID: 1000, Type: events.MemberAssigned, TeamID: &team.ID})
+		second := events.Default.Publish(events.Event{ID: 1001, Type: events.MemberUnassigned, TeamID: &team.ID})
+
+		conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("%s?team_id=%d&last_event_id=%d", wsURL, team.ID, first.ID), nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var event events.Event
+		require.NoError(t, conn.ReadJSON(&event))
+		assert.Equal(t, second.ID, event.ID)
+	})
+
+	t.Run("Rejects Unknown Origin", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"http://evil.example"}})
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"http://allowed.example"}})
+		require.NoError(t, err)
+		conn.Close()
+	})
+}
+
+func TestMutationsRecordEvents(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupGin()
+	r.POST("/members", CreateTeamMember)
+	r.DELETE("/members/:id", DeleteTeamMember)
+	r.DELETE("/teams/:id", DeleteTeam)
+
+	count := func() int64 {
+		var n int64
+		db.Model(&models.OutboxMessage{}).Count(&n)
+		return n
+	}
+
+	t.Run("Committed Change Records Event", func(t *testing.T) {
+		w := serve(r, "POST", "/members", `{"name":"Jane","email":"jane@example.com"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var msg models.OutboxMessage
+		require.NoError(t, db.Last(&msg).Error)
+		assert.Equal(t, string(events.MemberCreated), msg.Type)
+		assert.Contains(t, msg.Data, "jane@example.com")
+	})
+
+	t.Run("Failed Change Records Nothing", func(t *testing.T) {
+		before := count()
+		w := serve(r, "POST", "/members", `{"name":"Jane","email":"jane@example.com"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, before, count())
+	})
+
+	t.Run("Deleting A Member Records Them Leaving Their Team", func(t *testing.T) {
+		team := testutils.CreateTestTeam(db)
+		member := models.TeamMember{Name: "Leaver", Email: "leaver@example.com", TeamID: &team.ID}
+		require.NoError(t, db.Create(&member).Error)
+
+		w := serve(r, "DELETE", fmt.Sprintf("/members/%d", member.ID), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var msg models.OutboxMessage
+		require.NoError(t, db.Last(&msg).Error)
+		assert.Equal(t, string(events.MemberUnassigned), msg.Type)
+		assert.Equal(t, member.ID, msg.TargetID)
+		assert.Equal(t, &team.ID, msg.TeamID)
+
+		var change models.AssignmentChange
+		require.NoError(t, db.Where("member_id = ?", member.ID).Last(&change).Error)
+		assert.Equal(t, models.MemberLeft, change.Action)
+		assert.Equal(t, team.ID, change.TeamID)
+	})
+
+	t.Run("Deleting A Team Records Its Members Leaving", func(t *testing.T) {
+		team := testutils.CreateTestTeam(db)
+		alice := models.TeamMember{Name: "Alice", Email: "alice@example.com", TeamID: &team.ID}
+		bob := models.TeamMember{Name: "Bob", Email: "bob@example.com", TeamID: &team.ID}
+		require.NoError(t, db.Create(&alice).Error)
+		require.NoError(t, db.Create(&bob).Error)
+		before := count()
+
+		w := serve(r, "DELETE", fmt.Sprintf("/teams/%d", team.ID), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var msgs []models.OutboxMessage
+		require.NoError(t, db.Order("id").Offset(int(before)).Find(&msgs).Error)
+		require.Len(t, msgs, 2)
+		for i, member := range []models.TeamMember{alice, bob} {
+			assert.Equal(t, string(events.MemberUnassigned), msgs[i].Type)
+			assert.Equal(t, member.ID, msgs[i].TargetID)
+			assert.Contains(t, msgs[i].Data, `"team_id":null`)
+		}
+
+		var changes int64
+		db.Model(&models.AssignmentChange{}).Where("team_id = ? AND action = ?", team.ID, models.MemberLeft).Count(&changes)
+		assert.Equal(t, int64(2), changes)
+
+		require.NoError(t, db.First(&alice, alice.ID).Error)
+		assert.Nil(t, alice.TeamID, "members are left without a team")
+	})
+}
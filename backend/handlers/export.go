@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/spreadsheet"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFormat is a file format exports are written in. NDJSON has no
+// table format: each line is a record as the list endpoints return it.
+type exportFormat struct {
+	contentType string
+	table       spreadsheet.Format
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv":    {"text/csv; charset=utf-8", spreadsheet.CSV},
+	"ndjson": {"application/x-ndjson", ""},
+	"xlsx":   {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", spreadsheet.XLSX},
+}
+
+// exportColumn is a column of a CSV or XLSX export and how to fill it from
+// a record.
+type exportColumn[T any] struct {
+	spreadsheet.Column
+	value func(*T) string
+}
+
+func textColumn[T any](name string, value func(*T) string) exportColumn[T] {
+	return exportColumn[T]{spreadsheet.Column{Name: name}, value}
+}
+
+func numberColumn[T any](name string, value func(*T) string) exportColumn[T] {
+	return exportColumn[T]{spreadsheet.Column{Name: name, Numeric: true}, value}
+}
+
+func formatID(id uint32) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func formatOptionalID(id *uint32) string {
+	if id == nil {
+		return ""
+	}
+	return formatID(*id)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+var memberColumns = []exportColumn[models.TeamMember]{
+	numberColumn("id", func(m *models.TeamMember) string { return formatID(m.ID) }),
+	textColumn("name", func(m *models.TeamMember) string { return m.Name }),
+	textColumn("email", func(m *models.TeamMember) string { return m.Email }),
+	textColumn("picture", func(m *models.TeamMember) string { return m.Picture }),
+	numberColumn("team_id", func(m *models.TeamMember) string { return formatOptionalID(m.TeamID) }),
+	textColumn("team_name", func(m *models.TeamMember) string {
+		if m.Team == nil {
+			return ""
+		}
+		return m.Team.Name
+	}),
+	textColumn("created_at", func(m *models.TeamMember) string { return formatTime(m.CreatedAt) }),
+	textColumn("updated_at", func(m *models.TeamMember) string { return formatTime(m.UpdatedAt) }),
+}
+
+var teamColumns = []exportColumn[models.Team]{
+	numberColumn("id", func(t *models.Team) string { return formatID(t.ID) }),
+	textColumn("name", func(t *models.Team) string { return t.Name }),
+	textColumn("logo", func(t *models.Team) string { return t.Logo }),
+	textColumn("created_at", func(t *models.Team) string { return formatTime(t.CreatedAt) }),
+	textColumn("updated_at", func(t *models.Team) string { return formatTime(t.UpdatedAt) }),
+}
+
+var assignmentColumns = []exportColumn[models.TeamMember]{
+	numberColumn("member_id", func(m *models.TeamMember) string { return formatID(m.ID) }),
+	textColumn("member_name", func(m *models.TeamMember) string { return m.Name }),
+	textColumn("member_email", func(m *models.TeamMember) string { return m.Email }),
+	numberColumn("team_id", func(m *models.TeamMember) string { return formatOptionalID(m.TeamID) }),
+	textColumn("team_name", func(m *models.TeamMember) string {
+		if m.Team == nil {
+			return ""
+		}
+		return m.Team.Name
+	}),
+}
+
+var feedbackColumns = []exportColumn[models.Feedback]{
+	numberColumn("id", func(f *models.Feedback) string { return formatID(f.ID) }),
+	textColumn("target_type", func(f *models.Feedback) string { return f.TargetType }),
+	numberColumn("target_id", func(f *models.Feedback) string { return formatID(f.TargetID) }),
+	textColumn("target_name", func(f *models.Feedback) string { return f.TargetName }),
+	textColumn("content", func(f *models.Feedback) string { return f.Content }),
+	numberColumn("attachments", func(f *models.Feedback) string { return strconv.Itoa(len(f.Attachments)) }),
+	textColumn("created_at", func(f *models.Feedback) string { return formatTime(f.CreatedAt) }),
+	textColumn("updated_at", func(f *models.Feedback) string { return formatTime(f.UpdatedAt) }),
+}
+
+func ExportMembers(c *gin.Context) {
+	export(c, "members", memberColumns, service().EachMember)
+}
+
+func ExportTeams(c *gin.Context) {
+	export(c, "teams", teamColumns, service().EachTeam)
+}
+
+func ExportAssignments(c *gin.Context) {
+	export(c, "assignments", assignmentColumns, service().EachAssignedMember)
+}
+
+// ExportFeedback exports the feedback GetFeedback would list, with the
+// same filters.
+func ExportFeedback(c *gin.Context) {
+	filter, ok := feedbackFilter(c)
+	if !ok {
+		return
+	}
+	export(c, "feedback", feedbackColumns, func(ctx context.Context, fn func(*models.Feedback) error) error {
+		return service().EachFeedback(ctx, filter, fn)
+	})
+}
+
+// export streams the records each visits as a download in the format
+// named by ?format=, CSV by default. The response starts with the first
+// record, so an error before it is reported as a problem. A later error
+// aborts the connection, so a client cannot mistake a partial export for
+// a complete one.
+func export[T any](c *gin.Context, name string, columns []exportColumn[T], each func(context.Context, func(*T) error) error) {
+	formatName := c.DefaultQuery("format", "csv")
+	format, ok := exportFormats[formatName]
+	if !ok {
+		problem.InvalidParam(c, "format", "must be csv, ndjson or xlsx")
+		return
+	}
+
+	// The first record may take a while to come, and the rest as long as
+	// the client takes to read them.
+	stream(c)
+
+	var write func(*T) error
+	finish := func() error { return nil }
+	start := func() error {
+		filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("2006-01-02"), formatName)
+		c.Header("Content-Type", format.contentType)
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Header("Cache-Control", "no-store")
+		c.Status(http.StatusOK)
+
+		if format.table == "" {
+			encoder := json.NewEncoder(c.Writer)
+			write = func(record *T) error { return encoder.Encode(record) }
+			return nil
+		}
+		table := make([]spreadsheet.Column, len(columns))
+		for i, column := range columns {
+			table[i] = column.Column
+		}
+		w, err := spreadsheet.NewWriter(format.table, c.Writer, table)
+		if err != nil {
+			return err
+		}
+		values := make([]string, len(columns))
+		write = func(record *T) error {
+			for i, column := range columns {
+				values[i] = column.value(record)
+			}
+			return w.Write(values)
+		}
+		finish = w.Close
+		return nil
+	}
+
+	started := false
+	err := each(c.Request.Context(), func(record *T) error {
+		if !started {
+			started = true
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return write(record)
+	})
+	if err == nil && !started {
+		started = true
+		err = start()
+	}
+	if err == nil {
+		err = finish()
+	}
+	if err == nil {
+		return
+	}
+	if !started {
+		writeError(c, err)
+		return
+	}
+	_ = c.Error(err)
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"coaching-backend/models"
+	"coaching-backend/spreadsheet"
+	"coaching-backend/tests/testutils"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupExportRoutes() *gin.Engine {
+	r := setupGin()
+	r.GET("/members/export", ExportMembers)
+	r.GET("/teams/export", ExportTeams)
+	r.GET("/assignments/export", ExportAssignments)
+	r.GET("/feedback/export", ExportFeedback)
+	return r
+}
+
+func readCSV(t *testing.T, w *httptest.ResponseRecorder) [][]string {
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err, w.Body.String())
+	return rows
+}
+
+func TestExports(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupExportRoutes()
+
+	team := testutils.CreateTestTeam(db)
+	member := testutils.CreateTestTeamMember(db)
+	member.TeamID = &team.ID
+	db.Save(member)
+	unassigned := testutils.CreateTestTeamMember(db)
+	db.Model(unassigned).Update("name", "=cmd|' /C calc'!A0")
+	testutils.CreateTestFeedback(db, "team", team.ID)
+	testutils.CreateTestFeedback(db, "member", member.ID)
+
+	t.Run("Members As CSV", func(t *testing.T) {
+		w := serve(r, "GET", "/members/export", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Regexp(t, `^attachment; filename="members-\d{4}-\d{2}-\d{2}\.csv"$`, w.Header().Get("Content-Disposition"))
+
+		rows := readCSV(t, w)
+		require.Len(t, rows, 3)
+		assert.Equal(t, []string{"id", "name", "email", "picture", "team_id", "team_name", "created_at", "updated_at"}, rows[0])
+		assert.Equal(t, []string{"1", "John Doe", member.Email, member.Picture, "1", team.Name}, rows[1][:6])
+		assert.Equal(t, "'=cmd|' /C calc'!A0", rows[2][1], "formulas are not exported as formulas")
+		assert.Equal(t, "", rows[2][4])
+	})
+
+	t.Run("Teams As NDJSON", func(t *testing.T) {
+		w := serve(r, "GET", "/teams/export?format=ndjson", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		var exported models.Team
+		require.NoError(t, json.Unmarshal(bytes.TrimSpace(w.Body.Bytes()), &exported))
+		assert.Equal(t, team.Name, exported.Name)
+	})
+
+	t.Run("Assignments As XLSX", func(t *testing.T) {
+		w := serve(r, "GET", "/assignments/export?format=xlsx", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", w.Header().Get("Content-Type"))
+
+		rows, err := spreadsheet.Read(spreadsheet.XLSX, w.Body.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"member_id", "member_name", "member_email", "team_id", "team_name"},
+			{"1", "John Doe", member.Email, "1", team.Name},
+		}, rows)
+	})
+
+	t.Run("Feedback With Filters", func(t *testing.T) {
+		w := serve(r, "GET", "/feedback/export?target_type=member&target_id=1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		rows := readCSV(t, w)
+		require.Len(t, rows, 2)
+		assert.Equal(t, []string{"2", "member", "1"}, rows[1][:3])
+
+		w = serve(r, "GET", "/feedback/export?target_type=project", "")
+		assert.Equal(t, [][]string{{"id", "target_type", "target_id", "target_name", "content", "attachments", "created_at", "updated_at"}},
+			readCSV(t, w), "an empty export still has its header")
+	})
+
+	t.Run("Streams Every Batch", func(t *testing.T) {
+		feedback := make([]models.Feedback, 1200)
+		for i := range feedback {
+			feedback[i] = models.Feedback{Content: fmt.Sprintf("Note %d", i), TargetType: "team", TargetID: team.ID, TargetName: team.Name}
+		}
+		require.NoError(t, db.CreateInBatches(feedback, 200).Error)
+
+		w := serve(r, "GET", "/feedback/export?format=ndjson&target_type=team", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		lines := 0
+		previous := uint32(0)
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var f models.Feedback
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &f))
+			assert.Greater(t, f.ID, previous, "oldest first")
+			previous = f.ID
+			lines++
+		}
+		assert.Equal(t, 1201, lines)
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		w := serve(r, "GET", "/members/export?format=pdf", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"format"`)
+
+		w = serve(r, "GET", "/feedback/export?target_id=abc", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Error Before The First Record", func(t *testing.T) {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+
+		w := serve(r, "GET", "/members/export", "")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+	})
+}
+
+func TestExportAbortsAfterPartialOutput(t *testing.T) {
+	r := setupGin()
+	r.GET("/export", func(c *gin.Context) {
+		export(c, "teams", teamColumns, func(ctx context.Context, fn func(*models.Team) error) error {
+			if err := fn(&models.Team{ID: 1, Name: "First"}); err != nil {
+				return err
+			}
+			return errors.New("connection lost")
+		})
+	})
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/export", nil))
+	})
+}
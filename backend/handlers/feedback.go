@@ -1,8 +1,9 @@
 package handlers
 
 import (
-	"coaching-backend/database"
 	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/services"
 	"net/http"
 	"strconv"
 
@@ -12,28 +13,12 @@ import (
 func CreateFeedback(c *gin.Context) {
 	var feedback models.Feedback
 	if err := c.ShouldBindJSON(&feedback); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Binding(c, err)
 		return
 	}
 
-	if feedback.TargetType == "team" {
-		var team models.Team
-		if err := database.DB.First(&team, feedback.TargetID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
-			return
-		}
-		feedback.TargetName = team.Name
-	} else if feedback.TargetType == "member" {
-		var member models.TeamMember
-		if err := database.DB.First(&member, feedback.TargetID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
-			return
-		}
-		feedback.TargetName = member.Name
-	}
-
-	if err := database.DB.Create(&feedback).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create feedback"})
+	if err := service().CreateFeedback(c.Request.Context(), &feedback); err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -41,21 +26,14 @@ func CreateFeedback(c *gin.Context) {
 }
 
 func GetFeedback(c *gin.Context) {
-	var feedback []models.Feedback
-	query := database.DB.Order("created_at DESC")
-
-	targetType := c.Query("target_type")
-	if targetType != "" {
-		query = query.Where("target_type = ?", targetType)
-	}
-
-	targetID := c.Query("target_id")
-	if targetID != "" {
-		query = query.Where("target_id = ?", targetID)
+	filter, ok := feedbackFilter(c)
+	if !ok {
+		return
 	}
 
-	if err := query.Find(&feedback).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feedback"})
+	feedback, err := service().ListFeedback(c.Request.Context(), filter)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -63,15 +41,14 @@ func GetFeedback(c *gin.Context) {
 }
 
 func GetFeedbackByID(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	var feedback models.Feedback
-	if err := database.DB.First(&feedback, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Feedback not found"})
+	feedback, err := service().GetFeedback(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -79,25 +56,16 @@ func GetFeedbackByID(c *gin.Context) {
 }
 
 func UpdateFeedback(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-		return
-	}
-
-	var feedback models.Feedback
-	if err := database.DB.First(&feedback, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Feedback not found"})
-		return
-	}
-
-	if err := c.ShouldBindJSON(&feedback); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	if err := database.DB.Save(&feedback).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update feedback"})
+	feedback, err := service().UpdateFeedback(c.Request.Context(), id, func(feedback *models.Feedback) error {
+		return c.ShouldBindJSON(feedback)
+	})
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -105,16 +73,34 @@ func UpdateFeedback(c *gin.Context) {
 }
 
 func DeleteFeedback(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	if err := database.DB.Delete(&models.Feedback{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete feedback"})
+	if err := service().DeleteFeedback(c.Request.Context(), id); err != nil {
+		writeError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Feedback deleted successfully"})
 }
+
+func GetFeedbackTargetTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, service().FeedbackTargetTypes())
+}
+
+// feedbackFilter reads the target_type and target_id query parameters.
+func feedbackFilter(c *gin.Context) (services.FeedbackFilter, bool) {
+	filter := services.FeedbackFilter{TargetType: c.Query("target_type")}
+
+	if raw := c.Query("target_id"); raw != "" {
+		targetID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			problem.InvalidParam(c, "target_id", "must be a positive integer")
+			return filter, false
+		}
+		filter.TargetID = uint32(targetID)
+	}
+	return filter, true
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"coaching-backend/models"
 	"coaching-backend/tests/testutils"
 	"encoding/json"
 	"net/http"
@@ -9,7 +10,6 @@ import (
 	"strconv"
 	"testing"
 
-	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -104,6 +104,49 @@ func TestCreateFeedback(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 
+	t.Run("Create Feedback for Project", func(t *testing.T) {
+		project := models.Project{Name: "Coaching Platform"}
+		db.Create(&project)
+
+		reqBody := testutils.TestFeedbackRequest{
+			Content:    "Smooth launch!",
+			TargetType: "project",
+			TargetID:   project.ID,
+		}
+
+		jsonBody, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/feedback", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "project", response["target_type"])
+		assert.Equal(t, "Coaching Platform", response["target_name"])
+	})
+
+	t.Run("Create Feedback for Organization", func(t *testing.T) {
+		reqBody := testutils.TestFeedbackRequest{
+			Content:    "Great place to work",
+			TargetType: "organization",
+			TargetID:   1,
+		}
+
+		jsonBody, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/feedback", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
 	t.Run("Create Feedback with Invalid Target Type", func(t *testing.T) {
 		reqBody := map[string]interface{}{
 			"content":     "Invalid feedback",
@@ -285,6 +328,44 @@ func TestUpdateFeedback(t *testing.T) {
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
+
+	t.Run("Update Feedback to Non-existent Target", func(t *testing.T) {
+		feedback := testutils.CreateTestFeedback(db, "member", 1)
+
+		updateBody := map[string]interface{}{
+			"content":     "Moved feedback",
+			"target_type": "team",
+			"target_id":   999,
+		}
+
+		jsonBody, _ := json.Marshal(updateBody)
+		req, _ := http.NewRequest("PUT", "/feedback/"+strconv.Itoa(int(feedback.ID)), bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetFeedbackTargetTypes(t *testing.T) {
+	r := setupGin()
+	r.GET("/feedback/target-types", GetFeedbackTargetTypes)
+
+	t.Run("List Target Types", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/feedback/target-types", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Contains(t, response, map[string]interface{}{"type": "member", "label": "Team member"})
+		assert.Contains(t, response, map[string]interface{}{"type": "project", "label": "Project"})
+	})
 }
 
 func TestDeleteFeedback(t *testing.T) {
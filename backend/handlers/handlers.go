@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"coaching-backend/database"
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func service() *services.Service {
+	return services.New(database.DB)
+}
+
+func parseID(c *gin.Context) (uint32, bool) {
+	return parseIDParam(c, "id")
+}
+
+func parseIDParam(c *gin.Context, name string) (uint32, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		problem.InvalidID(c)
+		return 0, false
+	}
+	return uint32(id), true
+}
+
+// writeError writes the problem response for a service error. Any other
+// error came from binding the request body inside an update.
+func writeError(c *gin.Context, err error) {
+	var serviceErr *services.Error
+	if !errors.As(err, &serviceErr) {
+		problem.Binding(c, err)
+		return
+	}
+
+	if serviceErr.Err != nil {
+		_ = c.Error(serviceErr.Err)
+	}
+	p := problem.New(problem.Status(serviceErr.Code), serviceErr.Code, serviceErr.Message)
+	p.Errors = serviceErr.Fields
+	problem.Write(c, p)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"coaching-backend/avatar"
+	"coaching-backend/images"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxUploadBody leaves room for multipart headers around a maximum size
+// image.
+const maxUploadBody = images.MaxBytes + 64<<10
+
+const (
+	// immutableCache is sent when the request's v matches the image, as a
+	// new upload always changes the URL.
+	immutableCache  = "public, max-age=31536000, immutable"
+	revalidateCache = "public, no-cache"
+)
+
+func UploadMemberAvatar(c *gin.Context) { uploadImage(c, models.ImageMember) }
+func GetMemberAvatar(c *gin.Context)    { serveImage(c, models.ImageMember) }
+func DeleteMemberAvatar(c *gin.Context) { deleteImage(c, models.ImageMember) }
+
+func UploadTeamLogo(c *gin.Context) { uploadImage(c, models.ImageTeam) }
+func GetTeamLogo(c *gin.Context)    { serveImage(c, models.ImageTeam) }
+func DeleteTeamLogo(c *gin.Context) { deleteImage(c, models.ImageTeam) }
+
+// uploadImage stores the image sent as the "file" field of a
+// multipart/form-data body.
+func uploadImage(c *gin.Context, ownerType string) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	limitBody(c, maxUploadBody)
+	allowSlowBody(c)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Write(c, problem.New(http.StatusRequestEntityTooLarge, problem.CodeTooLarge, "Image is larger than 5 MB"))
+			return
+		}
+		problem.BadRequest(c, problem.CodeMalformedBody, "Request body must be multipart/form-data with a file field")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		problem.BadRequest(c, problem.CodeMalformedBody, "Uploaded file could not be read")
+		return
+	}
+	defer file.Close()
+
+	img, err := service().SetImage(c.Request.Context(), ownerType, id, file)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, img)
+}
+
+// serveImage writes the uploaded image, or with ?size= one of its
+// thumbnails. Without an upload it redirects to the stored picture or logo
+// URL, or draws a default in the requested format and style. Responses
+// carry an ETag; uploads are cacheable forever when ?v= names the current
+// version.
+func serveImage(c *gin.Context, ownerType string) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	size := 0
+	if raw := c.Query("size"); raw != "" {
+		var err error
+		if size, err = strconv.Atoi(raw); err != nil || !slices.Contains(images.Sizes, size) {
+			problem.InvalidParam(c, "size", "must be 64 or 256")
+			return
+		}
+	}
+	format := c.DefaultQuery("format", "svg")
+	if format != "svg" && format != "png" {
+		problem.InvalidParam(c, "format", "must be svg or png")
+		return
+	}
+	var style avatar.Style
+	if raw := c.Query("style"); raw != "" {
+		if style, ok = avatar.ParseStyle(raw); !ok {
+			problem.InvalidParam(c, "style", "must be initials or identicon")
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	source, err := service().GetImageSource(ctx, ownerType, id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	switch {
+	case source.Image != nil:
+		serveUploaded(c, source.Image, size)
+	case source.URL != "":
+		c.Redirect(http.StatusFound, source.URL)
+	default:
+		generated := *source.Generated
+		if style != "" {
+			generated.Style = style
+		}
+		if size == 0 {
+			size = slices.Max(images.Sizes)
+		}
+		serveGenerated(c, generated, size, format)
+	}
+}
+
+func serveUploaded(c *gin.Context, img *models.Image, size int) {
+	etag := `"` + img.Hash + "-" + strconv.Itoa(size) + `"`
+	c.Header("ETag", etag)
+	if c.Query("v") == services.ImageVersion(img) {
+		c.Header("Cache-Control", immutableCache)
+	} else {
+		c.Header("Cache-Control", revalidateCache)
+	}
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body, info, err := service().OpenImage(c.Request.Context(), img, size)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	defer body.Close()
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, body, nil)
+}
+
+// serveGenerated draws a default picture. It changes with the owner's
+// name, so clients revalidate it.
+func serveGenerated(c *gin.Context, a avatar.Avatar, size int, format string) {
+	etag := a.ETag(size, format)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", revalidateCache)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	if format == "svg" {
+		c.Data(http.StatusOK, "image/svg+xml", a.SVG(size))
+		return
+	}
+	data, err := a.PNG(size)
+	if err != nil {
+		_ = c.Error(err)
+		problem.Internal(c, "Failed to draw image")
+		return
+	}
+	c.Data(http.StatusOK, "image/png", data)
+}
+
+func deleteImage(c *gin.Context, ownerType string) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := service().DeleteImage(c.Request.Context(), ownerType, id); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Image deleted successfully"})
+}
+
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"bytes"
+	"coaching-backend/blob"
+	"coaching-backend/models"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupImageRoutes(t *testing.T) (*gin.Engine, string) {
+	root := t.TempDir()
+	previous := blob.Default
+	blob.Default = &blob.FileStore{Root: root}
+	t.Cleanup(func() { blob.Default = previous })
+
+	r := setupGin()
+	r.PUT("/members/:id/avatar", UploadMemberAvatar)
+	r.GET("/members/:id/avatar", GetMemberAvatar)
+	r.DELETE("/members/:id/avatar", DeleteMemberAvatar)
+	r.DELETE("/members/:id", DeleteTeamMember)
+	r.PUT("/teams/:id/logo", UploadTeamLogo)
+	r.GET("/teams/:id/logo", GetTeamLogo)
+	return r, root
+}
+
+func testPNG(t *testing.T, w, h int, c color.Color) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func upload(r *gin.Engine, path string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	// Clients may claim any type; the server sniffs the content.
+	part, _ := form.CreateFormFile("file", "avatar.jpg")
+	part.Write(data)
+	form.Close()
+
+	req, _ := http.NewRequest("PUT", path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func blobCount(t *testing.T, root string) int {
+	count := 0
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+func TestMemberAvatar(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r, root := setupImageRoutes(t)
+	member := testutils.CreateTestTeamMember(db)
+	red := testPNG(t, 400, 300, color.RGBA{R: 255, A: 255})
+
+	var stored models.Image
+	t.Run("Upload", func(t *testing.T) {
+		w := upload(r, "/members/1/avatar", red)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+
+		assert.Equal(t, "image/png", stored.ContentType)
+		assert.Equal(t, 400, stored.Width)
+		assert.Equal(t, 300, stored.Height)
+		assert.Equal(t, 3, blobCount(t, root), "original and two thumbnails")
+
+		db.First(member, member.ID)
+		assert.Equal(t, "/api/members/1/avatar?v="+stored.Hash[:12], member.Picture)
+	})
+
+	t.Run("Serve Original", func(t *testing.T) {
+		w := serve(r, "GET", "/members/1/avatar", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, red, w.Body.Bytes())
+		assert.Equal(t, "public, no-cache", w.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("Serve Thumbnail", func(t *testing.T) {
+		w := serve(r, "GET", "/members/1/avatar?size=64&v="+stored.Hash[:12], "")
+		require.Equal(t, http.StatusOK, w.Code)
+		config, err := png.DecodeConfig(w.Body)
+		require.NoError(t, err)
+		assert.Equal(t, 64, config.Width)
+		assert.Equal(t, 64, config.Height)
+		assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Not Modified", func(t *testing.T) {
+		etag := serve(r, "GET", "/members/1/avatar?size=256", "").Header().Get("ETag")
+
+		req, _ := http.NewRequest("GET", "/members/1/avatar?size=256", nil)
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.Bytes())
+	})
+
+	t.Run("Invalid Size", func(t *testing.T) {
+		w := serve(r, "GET", "/members/1/avatar?size=100", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"size"`)
+	})
+
+	t.Run("Replace Removes Old Files", func(t *testing.T) {
+		w := upload(r, "/members/1/avatar", testPNG(t, 50, 50, color.RGBA{B: 255, A: 255}))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var count int64
+		db.Model(&models.Image{}).Count(&count)
+		assert.Equal(t, int64(1), count)
+		assert.Equal(t, 3, blobCount(t, root))
+
+		etag := serve(r, "GET", "/members/1/avatar", "").Header().Get("ETag")
+		assert.NotContains(t, etag, stored.Hash)
+	})
+
+	t.Run("Rejects Non-Images", func(t *testing.T) {
+		w := upload(r, "/members/1/avatar", []byte("%PDF-1.4 not an image"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"unsupported_media_type"`)
+	})
+
+	t.Run("Rejects Corrupt Images", func(t *testing.T) {
+		w := upload(r, "/members/1/avatar", red[:40])
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"file"`)
+	})
+
+	t.Run("Rejects Large Uploads", func(t *testing.T) {
+		w := upload(r, "/members/1/avatar", append(red, make([]byte, 6<<20)...))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"payload_too_large"`)
+	})
+
+	t.Run("Requires Multipart", func(t *testing.T) {
+		w := serve(r, "PUT", "/members/1/avatar", `{"file":"x"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"malformed_body"`)
+	})
+
+	t.Run("Unknown Member", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, upload(r, "/members/999/avatar", red).Code)
+		assert.Equal(t, http.StatusNotFound, serve(r, "GET", "/members/999/avatar", "").Code)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		w := serve(r, "DELETE", "/members/1/avatar", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		db.First(member, member.ID)
+		assert.Empty(t, member.Picture)
+		assert.Equal(t, 0, blobCount(t, root))
+		assert.Equal(t, "image/svg+xml", serve(r, "GET", "/members/1/avatar", "").Header().Get("Content-Type"), "falls back to a default")
+		assert.Equal(t, http.StatusOK, serve(r, "DELETE", "/members/1/avatar", "").Code, "deleting twice succeeds")
+	})
+
+	t.Run("Delete Keeps External Picture", func(t *testing.T) {
+		other := testutils.CreateTestTeamMember(db)
+		require.Equal(t, http.StatusOK, serve(r, "DELETE", "/members/2/avatar", "").Code)
+		db.First(other, other.ID)
+		assert.Equal(t, "https://example.com/john.jpg", other.Picture)
+	})
+
+	t.Run("Deleting Member Removes Avatar", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, upload(r, "/members/1/avatar", red).Code)
+		require.Equal(t, http.StatusOK, serve(r, "DELETE", "/members/1", "").Code)
+
+		var count int64
+		db.Model(&models.Image{}).Count(&count)
+		assert.Zero(t, count)
+		assert.Equal(t, 0, blobCount(t, root))
+	})
+}
+
+func TestTeamLogo(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r, _ := setupImageRoutes(t)
+	team := testutils.CreateTestTeam(db)
+
+	w := upload(r, "/teams/1/logo", testPNG(t, 32, 32, color.White))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	db.First(team, team.ID)
+	assert.Contains(t, team.Logo, "/api/teams/1/logo?v=")
+
+	w = serve(r, "GET", "/teams/1/logo?size=256", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	config, err := png.DecodeConfig(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 32, config.Width, "small logos are not upscaled")
+}
+
+func TestDefaultImages(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r, _ := setupImageRoutes(t)
+	require.NoError(t, db.Create(&models.TeamMember{Name: "Ada Lovelace", Email: "ada@example.com"}).Error)
+	require.NoError(t, db.Create(&models.TeamMember{Name: "Bob", Email: "bob@example.com", Picture: "https://cdn.example.com/bob.png"}).Error)
+	require.NoError(t, db.Create(&models.TeamMember{Name: "Eve", Email: "eve@example.com", Picture: "javascript:alert(1)"}).Error)
+	require.NoError(t, db.Create(&models.Team{Name: "Platform"}).Error)
+
+	t.Run("Initials SVG", func(t *testing.T) {
+		w := serve(r, "GET", "/members/1/avatar", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `width="256"`)
+		assert.Contains(t, w.Body.String(), ">AL</text>")
+		assert.Equal(t, "public, no-cache", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("PNG", func(t *testing.T) {
+		w := serve(r, "GET", "/members/1/avatar?format=png&size=64", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		config, err := png.DecodeConfig(w.Body)
+		require.NoError(t, err)
+		assert.Equal(t, 64, config.Width)
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		first := serve(r, "GET", "/members/1/avatar?format=png", "")
+		second := serve(r, "GET", "/members/1/avatar?format=png", "")
+		assert.Equal(t, first.Body.Bytes(), second.Body.Bytes())
+		assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+
+		req, _ := http.NewRequest("GET", "/members/1/avatar?format=png", nil)
+		req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("Changes With Name", func(t *testing.T) {
+		before := serve(r, "GET", "/members/1/avatar", "").Header().Get("ETag")
+		db.Model(&models.TeamMember{}).Where("id = 1").Update("name", "Ada King")
+		w := serve(r, "GET", "/members/1/avatar", "")
+		assert.NotEqual(t, before, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), ">AK</text>")
+	})
+
+	t.Run("Redirects To Stored URL", func(t *testing.T) {
+		w := serve(r, "GET", "/members/2/avatar", "")
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "https://cdn.example.com/bob.png", w.Header().Get("Location"))
+	})
+
+	t.Run("Ignores Unsafe Stored URL", func(t *testing.T) {
+		w := serve(r, "GET", "/members/3/avatar", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), ">E</text>")
+	})
+
+	t.Run("Team Identicon", func(t *testing.T) {
+		w := serve(r, "GET", "/teams/1/logo", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `fill="#f0f0f0"`)
+		assert.NotContains(t, w.Body.String(), "<text")
+	})
+
+	t.Run("Style Override", func(t *testing.T) {
+		w := serve(r, "GET", "/teams/1/logo?style=initials", "")
+		assert.Contains(t, w.Body.String(), ">P</text>")
+		assert.Equal(t, http.StatusBadRequest, serve(r, "GET", "/teams/1/logo?style=robot", "").Code)
+	})
+}
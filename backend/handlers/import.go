@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportMembers creates or updates members from the CSV or XLSX file sent
+// as the "file" field of a multipart/form-data body. ?dry_run=true only
+// reports what would change; ?create_teams=true creates missing teams.
+func ImportMembers(c *gin.Context) {
+	var opts services.ImportOptions
+	for _, p := range []struct {
+		name  string
+		value *bool
+	}{{"dry_run", &opts.DryRun}, {"create_teams", &opts.CreateTeams}} {
+		raw, ok := c.GetQuery(p.name)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			problem.InvalidParam(c, p.name, "must be true or false")
+			return
+		}
+		*p.value = value
+	}
+
+	limitBody(c, services.MaxImportBytes+64<<10)
+	allowSlowBody(c)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Write(c, problem.New(http.StatusRequestEntityTooLarge, problem.CodeTooLarge, "Import file is larger than 10 MB"))
+			return
+		}
+		problem.BadRequest(c, problem.CodeMalformedBody, "Request body must be multipart/form-data with a file field")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		problem.BadRequest(c, problem.CodeMalformedBody, "Uploaded file could not be read")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxImportBytes+1))
+	if err != nil {
+		problem.BadRequest(c, problem.CodeMalformedBody, "Uploaded file could not be read")
+		return
+	}
+	if len(data) > services.MaxImportBytes {
+		problem.Write(c, problem.New(http.StatusRequestEntityTooLarge, problem.CodeTooLarge, "Import file is larger than 10 MB"))
+		return
+	}
+
+	report, err := service().ImportMembers(c.Request.Context(), header.Filename, data, opts)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// xlsxOf builds a single-sheet workbook with the rows as inline strings.
+func xlsxOf(t *testing.T, rows [][]string) []byte {
+	var sheet strings.Builder
+	for _, row := range rows {
+		sheet.WriteString("<row>")
+		for _, value := range row {
+			fmt.Fprintf(&sheet, `<c t="inlineStr"><is><t>%s</t></is></c>`, value)
+		}
+		sheet.WriteString("</row>")
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Members" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   "<worksheet><sheetData>" + sheet.String() + "</sheetData></worksheet>",
+	} {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		f.Write([]byte(content))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func decodeReport(t *testing.T, body []byte) services.ImportReport {
+	var report services.ImportReport
+	require.NoError(t, json.Unmarshal(body, &report), string(body))
+	return report
+}
+
+func TestImportMembers(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupGin()
+	r.POST("/members/import", ImportMembers)
+
+	existing := testutils.CreateTestTeamMember(db)
+	team := testutils.CreateTestTeam(db)
+	csv := "Name,Email,Picture,Team Name\n" +
+		"Johnny Doe,JOHN@example.com,,development team\n" +
+		"Ada Lovelace,ada@example.com,https://example.com/ada.png,Platform\n" +
+		"\n" +
+		"Grace Hopper,grace@example.com,,\n"
+
+	count := func() int64 {
+		var n int64
+		db.Model(&models.TeamMember{}).Count(&n)
+		return n
+	}
+
+	t.Run("Dry Run Reports Errors", func(t *testing.T) {
+		w := postFile(r, "/members/import?dry_run=true", "members.csv", []byte(csv))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		report := decodeReport(t, w.Body.Bytes())
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, []services.ImportError{
+			{Row: 3, Field: "team", Rule: "exists", Message: "names a team that does not exist: Platform"},
+		}, report.Errors)
+		assert.Equal(t, []services.ImportRowResult{
+			{Row: 2, Email: existing.Email, Action: services.ImportUpdate, MemberID: existing.ID},
+			{Row: 5, Email: "grace@example.com", Action: services.ImportCreate},
+		}, report.Rows)
+		assert.Equal(t, int64(1), count(), "a dry run changes nothing")
+	})
+
+	t.Run("Rejects The Whole Import", func(t *testing.T) {
+		w := postFile(r, "/members/import", "members.csv", []byte(csv))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"rows[3].team"`)
+		assert.Equal(t, int64(1), count())
+	})
+
+	t.Run("Creates Teams", func(t *testing.T) {
+		w := postFile(r, "/members/import?create_teams=true", "members.csv", []byte(csv))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		report := decodeReport(t, w.Body.Bytes())
+		assert.False(t, report.DryRun)
+		assert.Equal(t, []string{"Platform"}, report.TeamsCreated)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Empty(t, report.Errors)
+
+		var john, ada models.TeamMember
+		db.First(&john, existing.ID)
+		assert.Equal(t, "Johnny Doe", john.Name)
+		assert.Equal(t, existing.Picture, john.Picture, "a blank picture keeps the current one")
+		require.NotNil(t, john.TeamID)
+		assert.Equal(t, team.ID, *john.TeamID)
+
+		db.Preload("Team").Where("email = ?", "ada@example.com").First(&ada)
+		require.NotNil(t, ada.Team)
+		assert.Equal(t, "Platform", ada.Team.Name)
+		assert.Equal(t, "https://example.com/ada.png", ada.Picture)
+
+		var history int64
+		db.Model(&models.AssignmentChange{}).Where("action = ?", models.MemberJoined).Count(&history)
+		assert.Equal(t, int64(2), history)
+	})
+
+	t.Run("Importing Again Changes Nothing", func(t *testing.T) {
+		w := postFile(r, "/members/import", "members.csv", []byte(csv))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		report := decodeReport(t, w.Body.Bytes())
+		assert.Equal(t, 3, report.Unchanged)
+		assert.Zero(t, report.Created+report.Updated)
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		data := xlsxOf(t, [][]string{
+			{"email", "name", "team"},
+			{"linus@example.com", "Linus", "Platform"},
+		})
+		w := postFile(r, "/members/import", "members.xlsx", data)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, 1, decodeReport(t, w.Body.Bytes()).Created)
+	})
+
+	t.Run("Validates Rows", func(t *testing.T) {
+		data := "name,email\n" +
+			",nobody@example.com\n" +
+			"Eve,not-an-email\n" +
+			"Ann,ann@example.com\n" +
+			"Ann Again,ANN@example.com\n"
+		w := postFile(r, "/members/import?dry_run=1", "members.csv", []byte(data))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, []services.ImportError{
+			{Row: 2, Field: "name", Rule: "required", Message: "is required"},
+			{Row: 3, Field: "email", Rule: "email", Message: "must be a valid email address"},
+			{Row: 5, Field: "email", Rule: "unique", Message: "duplicates row 4"},
+		}, decodeReport(t, w.Body.Bytes()).Errors)
+	})
+
+	t.Run("Rejects Bad Files", func(t *testing.T) {
+		w := postFile(r, "/members/import", "members.csv", []byte("full name,team\nAda,Platform\n"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "has no email column")
+
+		w = postFile(r, "/members/import", "members.ods", []byte("name,email\n"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		w = postFile(r, "/members/import?dry_run=maybe", "members.csv", []byte(csv))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"dry_run"`)
+
+		w = serve(r, "POST", "/members/import", `{"file":"x"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
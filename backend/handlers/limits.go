@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/ratelimit"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	bodyLimitKey = "body_limit"
+	// rateLimitKey holds the ratelimit.Result whose headers the response
+	// carries: the one with the fewest requests remaining.
+	rateLimitKey = "rate_limit"
+)
+
+// limitedBody fails reads past limit with an *http.MaxBytesError, like
+// http.MaxBytesReader, but its limit can be raised by the handler.
+type limitedBody struct {
+	io.ReadCloser
+	read  int64
+	limit int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.limit {
+		return 0, &http.MaxBytesError{Limit: b.limit}
+	}
+	if max := b.limit - b.read + 1; int64(len(p)) > max {
+		p = p[:max]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n - int(b.read-b.limit), &http.MaxBytesError{Limit: b.limit}
+	}
+	return n, err
+}
+
+// LimitBody caps request bodies at max bytes. Handlers accepting uploads
+// raise the cap with limitBody.
+func LimitBody(max int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		body := &limitedBody{ReadCloser: c.Request.Body, limit: max}
+		c.Request.Body = body
+		c.Set(bodyLimitKey, body)
+		c.Next()
+	}
+}
+
+// limitBody caps the request body at max bytes, in place of the cap set
+// by LimitBody.
+func limitBody(c *gin.Context, max int64) {
+	if body, ok := c.Get(bodyLimitKey); ok {
+		body.(*limitedBody).limit = max
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+}
+
+// RateLimits are the API's rate limits. Zero limits are off.
+type RateLimits struct {
+	Limiter *ratelimit.Limiter
+	// IP limits each client address across the API.
+	IP ratelimit.Limit
+	// APIKey limits each API key across the API, wherever it is used from.
+	APIKey ratelimit.Limit
+	// Groups limit each client, by API key, signed-in member or else
+	// address, within a route group. Keys are written like scopes: the
+	// group, which is the path segment after /api, and the access, so
+	// feedback:write limits POST, PUT and DELETE requests under
+	// /api/feedback.
+	Groups map[string]ratelimit.Limit
+}
+
+// LimitByIP applies the per-address limit. It goes before Authenticate,
+// so that guessing API keys is limited too.
+func LimitByIP(limits *RateLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rateLimit(c, limits, "ip:"+c.ClientIP(), limits.IP) {
+			c.Next()
+		}
+	}
+}
+
+// LimitByClient applies the per-key limit and the route group limits. It
+// goes after Authenticate.
+func LimitByClient(limits *RateLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := "ip:" + c.ClientIP()
+		if principal := CurrentPrincipal(c); principal != nil {
+			switch {
+			case principal.APIKey != nil:
+				client = fmt.Sprintf("key:%d", principal.APIKey.ID)
+				if !rateLimit(c, limits, client, limits.APIKey) {
+					return
+				}
+			case principal.Member != nil:
+				client = fmt.Sprintf("member:%d", principal.Member.ID)
+			}
+		}
+
+		group, _, _ := strings.Cut(strings.TrimPrefix(c.FullPath(), "/api/"), "/")
+		access := models.AccessWrite
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			access = models.AccessRead
+		}
+		scope := models.Scope(group, access)
+		if limit, ok := limits.Groups[scope]; ok && !rateLimit(c, limits, client+":"+scope, limit) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimit counts the request against the bucket at key. It sets the
+// RateLimit headers and reports whether the request may go on; refused
+// requests get a 429. Should the store fail, requests are let through.
+func rateLimit(c *gin.Context, limits *RateLimits, key string, limit ratelimit.Limit) bool {
+	if !limit.Enabled() {
+		return true
+	}
+	result, err := limits.Limiter.Allow(c.Request.Context(), key, limit)
+	if err != nil {
+		log.Printf("ratelimit: %v", err)
+		return true
+	}
+
+	if previous, ok := c.Get(rateLimitKey); !ok || result.Remaining <= previous.(ratelimit.Result).Remaining || !result.Allowed {
+		c.Set(rateLimitKey, result)
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+		c.Header("RateLimit-Policy", limit.Policy())
+	}
+	if result.Allowed {
+		return true
+	}
+	retryAfter := strconv.Itoa(max(1, seconds(result.RetryAfter)))
+	c.Header("Retry-After", retryAfter)
+	problem.Write(c, problem.New(http.StatusTooManyRequests, problem.CodeRateLimited,
+		"Too many requests; the limit is "+limit.String()+", retry in "+retryAfter+"s"))
+	return false
+}
+
+// seconds rounds d up to whole seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"coaching-backend/ratelimit"
+	"coaching-backend/tests/testutils"
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLimitedRoutes(limits *RateLimits) *gin.Engine {
+	r := setupGin()
+	api := r.Group("/api", LimitByIP(limits), Authenticate(false), LimitByClient(limits))
+	api.GET("/members", RequireScope("members"), GetTeamMembers)
+	api.POST("/feedback", RequireScope("feedback"), CreateFeedback)
+	api.GET("/feedback", RequireScope("feedback"), GetFeedback)
+	return r
+}
+
+func limitedRequest(r *gin.Engine, method, path, ip, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":50000"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit, time.Time) (float64, bool, error) {
+	return 0, false, errors.New("store is down")
+}
+
+func TestRateLimits(t *testing.T) {
+	t.Run("Limits Each Address", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupLimitedRoutes(&RateLimits{
+			Limiter: ratelimit.New(ratelimit.NewMemoryStore()),
+			IP:      ratelimit.Limit{Requests: 2, Per: time.Minute},
+		})
+
+		w := limitedRequest(r, "GET", "/api/members", "192.0.2.1", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, http.StatusOK, limitedRequest(r, "GET", "/api/members", "192.0.2.1", "").Code)
+
+		w = limitedRequest(r, "GET", "/api/members", "192.0.2.1", "")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Contains(t, w.Body.String(), `"code":"rate_limited"`)
+
+		assert.Equal(t, http.StatusOK, limitedRequest(r, "GET", "/api/members", "192.0.2.2", "").Code, "other addresses have their own bucket")
+		assert.Equal(t, http.StatusTooManyRequests, limitedRequest(r, "GET", "/api/members", "192.0.2.1", "nonsense").Code,
+			"addresses are limited before keys are checked")
+	})
+
+	t.Run("Limits Each API Key", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		key := createAPIKey(t, setupAPIKeyRoutes(), `{"name":"CI","scopes":["members:read"]}`)
+		r := setupLimitedRoutes(&RateLimits{
+			Limiter: ratelimit.New(ratelimit.NewMemoryStore()),
+			APIKey:  ratelimit.Limit{Requests: 1, Per: time.Minute},
+		})
+
+		assert.Equal(t, http.StatusOK, limitedRequest(r, "GET", "/api/members", "192.0.2.1", key.Token).Code)
+		assert.Equal(t, http.StatusTooManyRequests, limitedRequest(r, "GET", "/api/members", "192.0.2.2", key.Token).Code,
+			"a key's bucket is shared by every address")
+		assert.Equal(t, http.StatusOK, limitedRequest(r, "GET", "/api/members", "192.0.2.1", "").Code)
+	})
+
+	t.Run("Limits Route Groups", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupLimitedRoutes(&RateLimits{
+			Limiter: ratelimit.New(ratelimit.NewMemoryStore()),
+			IP:      ratelimit.Limit{Requests: 100, Per: time.Minute},
+			Groups:  map[string]ratelimit.Limit{"feedback:write": {Requests: 1, Per: time.Hour}},
+		})
+
+		w := limitedRequest(r, "POST", "/api/feedback", "192.0.2.1", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, "the request itself is invalid but counted")
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"), "headers describe the tightest limit")
+		w = limitedRequest(r, "POST", "/api/feedback", "192.0.2.1", "")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+		assert.Equal(t, http.StatusOK, limitedRequest(r, "GET", "/api/feedback", "192.0.2.1", "").Code, "reads are not limited by the write limit")
+		assert.Equal(t, http.StatusBadRequest, limitedRequest(r, "POST", "/api/feedback", "192.0.2.2", "").Code)
+	})
+
+	t.Run("Fails Open", func(t *testing.T) {
+		testutils.SetupTestDB(t)
+		r := setupLimitedRoutes(&RateLimits{
+			Limiter: ratelimit.New(failingStore{}),
+			IP:      ratelimit.Limit{Requests: 1, Per: time.Minute},
+		})
+		for range 3 {
+			assert.Equal(t, http.StatusOK, limitedRequest(r, "GET", "/api/members", "192.0.2.1", "").Code)
+		}
+	})
+}
+
+func TestLimitBody(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	setupImageRoutes(t) // for its blob store
+	limited := setupGin()
+	limited.Use(LimitBody(64))
+	limited.POST("/members", CreateTeamMember)
+	limited.PUT("/members/:id/avatar", UploadMemberAvatar)
+
+	t.Run("Refuses Large JSON", func(t *testing.T) {
+		body := `{"name":"` + strings.Repeat("a", 100) + `","email":"ada@example.com"}`
+		w := serve(limited, "POST", "/members", body)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "larger than 64 bytes")
+
+		assert.Equal(t, http.StatusCreated, serve(limited, "POST", "/members", `{"name":"Ada","email":"ada@example.com"}`).Code)
+	})
+
+	t.Run("Uploads Allow More", func(t *testing.T) {
+		member := testutils.CreateTestTeamMember(db)
+		w := upload(limited, fmt.Sprintf("/members/%d/avatar", member.ID), testPNG(t, 64, 64, color.White))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+}
@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/scim"
+	"coaching-backend/services"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// maxSCIMBody caps SCIM request bodies; a group with thousands of members
+// still fits.
+const maxSCIMBody = 1 << 20
+
+// SCIMAuth admits requests carrying token as a bearer token. With no
+// token configured, every request is rejected.
+func SCIMAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !scim.Authorized(c.GetHeader("Authorization"), token) {
+			c.Header("WWW-Authenticate", `Bearer realm="SCIM"`)
+			writeSCIMError(c, http.StatusUnauthorized, "", "A valid bearer token is required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func writeSCIM(c *gin.Context, status int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		_ = c.Error(err)
+		status, data = http.StatusInternalServerError, nil
+	}
+	c.Data(status, scim.ContentType, data)
+}
+
+func writeSCIMError(c *gin.Context, status int, scimType, detail string) {
+	writeSCIM(c, status, scim.NewError(status, scimType, detail))
+}
+
+// writeSCIMServiceError writes the SCIM error for a service or request
+// error.
+func writeSCIMServiceError(c *gin.Context, err error) {
+	var reqErr *scim.RequestError
+	if errors.As(err, &reqErr) {
+		writeSCIMError(c, http.StatusBadRequest, reqErr.ScimType, reqErr.Detail)
+		return
+	}
+	var serviceErr *services.Error
+	if !errors.As(err, &serviceErr) {
+		_ = c.Error(err)
+		writeSCIMError(c, http.StatusInternalServerError, "", "Internal error")
+		return
+	}
+
+	if serviceErr.Err != nil {
+		_ = c.Error(serviceErr.Err)
+	}
+	detail, scimType := serviceErr.Message, ""
+	switch serviceErr.Code {
+	case problem.CodeConflict:
+		scimType = scim.Uniqueness
+	case problem.CodeValidation:
+		scimType = scim.InvalidValue
+		if len(serviceErr.Fields) > 0 {
+			detail = serviceErr.Fields[0].Field + " " + serviceErr.Fields[0].Message
+		}
+	}
+	writeSCIMError(c, problem.Status(serviceErr.Code), scimType, detail)
+}
+
+// readSCIM decodes a JSON request body into v, writing the error if it
+// cannot.
+func readSCIM(c *gin.Context, v interface{}) bool {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSCIMBody+1))
+	if err != nil {
+		writeSCIMError(c, http.StatusBadRequest, scim.InvalidSyntax, "Request body could not be read")
+		return false
+	}
+	if len(body) > maxSCIMBody {
+		writeSCIMError(c, http.StatusRequestEntityTooLarge, "", "Request body is too large")
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		writeSCIMError(c, http.StatusBadRequest, scim.InvalidSyntax, "Request body is not a valid resource: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// requireSchema writes an error unless schemas lists the core schema.
+func requireSchema(c *gin.Context, schemas []string, schema string) bool {
+	for _, s := range schemas {
+		if s == schema {
+			return true
+		}
+	}
+	writeSCIMError(c, http.StatusBadRequest, scim.InvalidSyntax, "schemas must contain "+schema)
+	return false
+}
+
+// scimPage reads the filter, startIndex and count of a list request.
+// startIndex is 1-based; values below 1 count as 1, and count is capped
+// at scim.MaxCount.
+func scimPage(c *gin.Context, attrs scim.Attributes) (cond clause.Expression, startIndex, count int, ok bool) {
+	startIndex, count = 1, scim.DefaultCount
+	for name, target := range map[string]*int{"startIndex": &startIndex, "count": &count} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeSCIMError(c, http.StatusBadRequest, scim.InvalidValue, name+" must be an integer")
+			return nil, 0, 0, false
+		}
+		*target = n
+	}
+	startIndex = max(startIndex, 1)
+	count = min(max(count, 0), scim.MaxCount)
+
+	if raw := c.Query("filter"); raw != "" {
+		f, err := scim.ParseFilter(raw)
+		if err != nil {
+			writeSCIMServiceError(c, err)
+			return nil, 0, 0, false
+		}
+		expr, err := scim.SQL(f, attrs)
+		if err != nil {
+			writeSCIMServiceError(c, err)
+			return nil, 0, 0, false
+		}
+		cond = expr
+	}
+	return cond, startIndex, count, true
+}
+
+// parseSCIMID parses the resource ID in the path. IDs that are not
+// numbers name no resource.
+func parseSCIMID(c *gin.Context, resource string) (uint32, bool) {
+	id, ok := scim.ParseID(c.Param("id"))
+	if !ok {
+		writeSCIMError(c, http.StatusNotFound, "", resource+" not found")
+	}
+	return id, ok
+}
+
+func GetSCIMServiceProviderConfig(c *gin.Context) {
+	writeSCIM(c, http.StatusOK, scim.Config)
+}
+
+func GetSCIMResourceTypes(c *gin.Context) {
+	writeSCIM(c, http.StatusOK, scim.NewListResponse(scim.ResourceTypes, int64(len(scim.ResourceTypes)), 1))
+}
+
+func GetSCIMSchemas(c *gin.Context) {
+	writeSCIM(c, http.StatusOK, scim.NewListResponse(scim.Schemas, int64(len(scim.Schemas)), 1))
+}
+
+func GetSCIMUsers(c *gin.Context) {
+	cond, startIndex, count, ok := scimPage(c, scim.UserAttributes)
+	if !ok {
+		return
+	}
+
+	members, total, err := service().PageMembers(c.Request.Context(), cond, startIndex-1, count)
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+
+	users := make([]scim.User, len(members))
+	for i := range members {
+		users[i] = scim.FromMember(&members[i])
+	}
+	writeSCIM(c, http.StatusOK, scim.NewListResponse(users, total, startIndex))
+}
+
+// CreateSCIMUser provisions a team member. An inactive user is created as
+// a deactivated member.
+func CreateSCIMUser(c *gin.Context) {
+	var user scim.User
+	if !readSCIM(c, &user) || !requireSchema(c, user.Schemas, scim.UserSchema) {
+		return
+	}
+	if user.UserName == "" {
+		writeSCIMError(c, http.StatusBadRequest, scim.InvalidValue, "userName is required")
+		return
+	}
+	var member models.TeamMember
+	scim.ApplyUser(&user, &member)
+	if err := service().CreateMember(c.Request.Context(), &member); err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+
+	c.Header("Location", scim.UserLocation(member.ID))
+	writeSCIM(c, http.StatusCreated, scim.FromMember(&member))
+}
+
+func GetSCIMUser(c *gin.Context) {
+	id, ok := parseSCIMID(c, "User")
+	if !ok {
+		return
+	}
+
+	member, err := service().GetMember(c.Request.Context(), id)
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, scim.FromMember(member))
+}
+
+// ReplaceSCIMUser replaces a user's attributes. Setting active to false
+// deprovisions the user: the member is deactivated, keeping their records,
+// and setting it back to true reactivates them.
+func ReplaceSCIMUser(c *gin.Context) {
+	id, ok := parseSCIMID(c, "User")
+	if !ok {
+		return
+	}
+	var user scim.User
+	if !readSCIM(c, &user) || !requireSchema(c, user.Schemas, scim.UserSchema) {
+		return
+	}
+
+	saveSCIMUser(c, id, func(*models.TeamMember) (*scim.User, error) {
+		return &user, nil
+	})
+}
+
+// PatchSCIMUser applies a PATCH to a user. Like ReplaceSCIMUser, making
+// the user inactive deactivates the member.
+func PatchSCIMUser(c *gin.Context) {
+	id, ok := parseSCIMID(c, "User")
+	if !ok {
+		return
+	}
+	var req scim.PatchRequest
+	if !readSCIM(c, &req) {
+		return
+	}
+
+	saveSCIMUser(c, id, func(member *models.TeamMember) (*scim.User, error) {
+		user := scim.FromMember(member)
+		if err := scim.Patch(&user, req); err != nil {
+			return nil, err
+		}
+		return &user, nil
+	})
+}
+
+// saveSCIMUser stores the user that change makes of the member with id.
+func saveSCIMUser(c *gin.Context, id uint32, change func(*models.TeamMember) (*scim.User, error)) {
+	ctx := c.Request.Context()
+	member, err := service().GetMember(ctx, id)
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+	user, err := change(member)
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+
+	if user.UserName == "" {
+		writeSCIMError(c, http.StatusBadRequest, scim.InvalidValue, "userName is required")
+		return
+	}
+	_, err = service().UpdateMember(ctx, id, func(member *models.TeamMember) error {
+		scim.ApplyUser(user, member)
+		return nil
+	})
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+	if member, err = service().GetMember(ctx, id); err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+
+	writeSCIM(c, http.StatusOK, scim.FromMember(member))
+}
+
+func DeleteSCIMUser(c *gin.Context) {
+	id, ok := parseSCIMID(c, "User")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := service().GetMember(ctx, id); err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+	if err := service().DeleteMember(ctx, id); err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func GetSCIMGroups(c *gin.Context) {
+	cond, startIndex, count, ok := scimPage(c, scim.GroupAttributes)
+	if !ok {
+		return
+	}
+
+	teams, total, err := service().PageTeams(c.Request.Context(), cond, startIndex-1, count)
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+
+	groups := make([]scim.Group, len(teams))
+	for i := range teams {
+		groups[i] = scim.FromTeam(&teams[i])
+	}
+	writeSCIM(c, http.StatusOK, scim.NewListResponse(groups, total, startIndex))
+}
+
+// CreateSCIMGroup creates a team and assigns the group's members to it,
+// moving them from any team they were in.
+func CreateSCIMGroup(c *gin.Context) {
+	var group scim.Group
+	if !readSCIM(c, &group) || !requireSchema(c, group.Schemas, scim.GroupSchema) {
+		return
+	}
+
+	team := models.Team{}
+	if !saveSCIMGroup(c, &team, &group) {
+		return
+	}
+
+	c.Header("Location", scim.GroupLocation(team.ID))
+	writeSCIMGroup(c, http.StatusCreated, team.ID)
+}
+
+func GetSCIMGroup(c *gin.Context) {
+	id, ok := parseSCIMID(c, "Group")
+	if !ok {
+		return
+	}
+	writeSCIMGroup(c, http.StatusOK, id)
+}
+
+// ReplaceSCIMGroup renames the team and makes the group's members its
+// only members.
+func ReplaceSCIMGroup(c *gin.Context) {
+	id, ok := parseSCIMID(c, "Group")
+	if !ok {
+		return
+	}
+	var group scim.Group
+	if !readSCIM(c, &group) || !requireSchema(c, group.Schemas, scim.GroupSchema) {
+		return
+	}
+
+	team, err := service().GetTeam(c.Request.Context(), id)
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+	if saveSCIMGroup(c, team, &group) {
+		writeSCIMGroup(c, http.StatusOK, id)
+	}
+}
+
+// PatchSCIMGroup applies a PATCH to a group; adding and removing members
+// assigns them to the team and removes them from it.
+func PatchSCIMGroup(c *gin.Context) {
+	id, ok := parseSCIMID(c, "Group")
+	if !ok {
+		return
+	}
+	var req scim.PatchRequest
+	if !readSCIM(c, &req) {
+		return
+	}
+
+	team, err := service().GetTeam(c.Request.Context(), id)
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+	group := scim.FromTeam(team)
+	if err := scim.Patch(&group, req); err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+	if saveSCIMGroup(c, team, &group) {
+		writeSCIMGroup(c, http.StatusOK, id)
+	}
+}
+
+// saveSCIMGroup stores the group's name and members onto team.
+func saveSCIMGroup(c *gin.Context, team *models.Team, group *scim.Group) bool {
+	if group.DisplayName == "" {
+		writeSCIMError(c, http.StatusBadRequest, scim.InvalidValue, "displayName is required")
+		return false
+	}
+	memberIDs, err := group.MemberIDs()
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return false
+	}
+
+	team.Name = group.DisplayName
+	if err := service().SaveTeamMembers(c.Request.Context(), team, memberIDs); err != nil {
+		writeSCIMServiceError(c, err)
+		return false
+	}
+	return true
+}
+
+func writeSCIMGroup(c *gin.Context, status int, id uint32) {
+	team, err := service().GetTeam(c.Request.Context(), id)
+	if err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+	writeSCIM(c, status, scim.FromTeam(team))
+}
+
+func DeleteSCIMGroup(c *gin.Context) {
+	id, ok := parseSCIMID(c, "Group")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := service().GetTeam(ctx, id); err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+	if err := service().DeleteTeam(ctx, id); err != nil {
+		writeSCIMServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"bytes"
+	"coaching-backend/models"
+	"coaching-backend/scim"
+	"coaching-backend/tests/testutils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSCIMToken = "scim-test-token"
+
+func setupSCIMRoutes() *gin.Engine {
+	r := setupGin()
+	g := r.Group(scim.Base, SCIMAuth(testSCIMToken))
+	g.GET("/ServiceProviderConfig", GetSCIMServiceProviderConfig)
+	g.GET("/ResourceTypes", GetSCIMResourceTypes)
+	g.GET("/Schemas", GetSCIMSchemas)
+	g.GET("/Users", GetSCIMUsers)
+	g.POST("/Users", CreateSCIMUser)
+	g.GET("/Users/:id", GetSCIMUser)
+	g.PUT("/Users/:id", ReplaceSCIMUser)
+	g.PATCH("/Users/:id", PatchSCIMUser)
+	g.DELETE("/Users/:id", DeleteSCIMUser)
+	g.GET("/Groups", GetSCIMGroups)
+	g.POST("/Groups", CreateSCIMGroup)
+	g.GET("/Groups/:id", GetSCIMGroup)
+	g.PUT("/Groups/:id", ReplaceSCIMGroup)
+	g.PATCH("/Groups/:id", PatchSCIMGroup)
+	g.DELETE("/Groups/:id", DeleteSCIMGroup)
+	return r
+}
+
+func serveSCIM(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", scim.ContentType)
+	req.Header.Set("Authorization", "Bearer "+testSCIMToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// recording is a sequence of SCIM exchanges captured from an identity
+// provider. Expected bodies list only the attributes that matter.
+type recording struct {
+	Description string `json:"description"`
+	Exchanges   []struct {
+		Request struct {
+			Method string          `json:"method"`
+			Path   string          `json:"path"`
+			Body   json.RawMessage `json:"body"`
+		} `json:"request"`
+		Response struct {
+			Status  int               `json:"status"`
+			Headers map[string]string `json:"headers"`
+			Body    interface{}       `json:"body"`
+		} `json:"response"`
+	} `json:"exchanges"`
+}
+
+// assertSubset checks that got has every attribute of want. Arrays must
+// have the same length, with each entry a subset of its counterpart, and
+// a null in want requires the attribute to be absent or null.
+func assertSubset(t *testing.T, want, got interface{}, path string) {
+	switch want := want.(type) {
+	case map[string]interface{}:
+		object, ok := got.(map[string]interface{})
+		if !assert.True(t, ok, "%s: expected an object, got %v", path, got) {
+			return
+		}
+		for key, value := range want {
+			assertSubset(t, value, object[key], path+"."+key)
+		}
+	case []interface{}:
+		entries, ok := got.([]interface{})
+		if !assert.True(t, ok, "%s: expected an array, got %v", path, got) || !assert.Len(t, entries, len(want), path) {
+			return
+		}
+		for i := range want {
+			assertSubset(t, want[i], entries[i], fmt.Sprintf("%s[%d]", path, i))
+		}
+	default:
+		assert.Equal(t, want, got, path)
+	}
+}
+
+func replay(t *testing.T, name string) {
+	data, err := os.ReadFile("../scim/testdata/" + name)
+	require.NoError(t, err)
+	var rec recording
+	require.NoError(t, json.Unmarshal(data, &rec))
+
+	testutils.SetupTestDB(t)
+	r := setupSCIMRoutes()
+	for i, ex := range rec.Exchanges {
+		step := fmt.Sprintf("%d %s %s", i+1, ex.Request.Method, ex.Request.Path)
+		w := serveSCIM(r, ex.Request.Method, ex.Request.Path, string(ex.Request.Body))
+		require.Equal(t, ex.Response.Status, w.Code, "%s: %s", step, w.Body.String())
+		for header, value := range ex.Response.Headers {
+			assert.Equal(t, value, w.Header().Get(header), step)
+		}
+		if ex.Response.Body == nil {
+			continue
+		}
+		assert.Equal(t, scim.ContentType, w.Header().Get("Content-Type"), step)
+		var got interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got), step)
+		assertSubset(t, ex.Response.Body, got, step)
+	}
+}
+
+func TestSCIMRecordings(t *testing.T) {
+	t.Run("Okta", func(t *testing.T) {
+		replay(t, "okta.json")
+	})
+
+	t.Run("Microsoft Entra ID", func(t *testing.T) {
+		replay(t, "azure.json")
+	})
+}
+
+func TestSCIMAuth(t *testing.T) {
+	testutils.SetupTestDB(t)
+	r := setupSCIMRoutes()
+
+	for name, header := range map[string]string{
+		"Missing Token": "",
+		"Wrong Token":   "Bearer nope",
+		"Wrong Scheme":  "Basic " + testSCIMToken,
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/scim/v2/Users", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, scim.ContentType, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+		})
+	}
+
+	t.Run("No Token Configured", func(t *testing.T) {
+		r := setupGin()
+		r.GET("/scim/v2/Users", SCIMAuth(""), GetSCIMUsers)
+		req, _ := http.NewRequest("GET", "/scim/v2/Users", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestGetSCIMUsers(t *testing.T) {
+	testutils.SetupTestDB(t)
+	r := setupSCIMRoutes()
+	for _, name := range []string{"ada", "grace", "linus"} {
+		w := serveSCIM(r, "POST", "/scim/v2/Users", `{"schemas":["`+scim.UserSchema+`"],"userName":"`+name+`@example.com"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	list := func(t *testing.T, query string) scim.ListResponse[scim.User] {
+		w := serveSCIM(r, "GET", "/scim/v2/Users"+query, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var page scim.ListResponse[scim.User]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return page
+	}
+
+	t.Run("Pages", func(t *testing.T) {
+		page := list(t, "?startIndex=2&count=1")
+		assert.Equal(t, int64(3), page.TotalResults)
+		assert.Equal(t, 2, page.StartIndex)
+		require.Len(t, page.Resources, 1)
+		assert.Equal(t, "grace@example.com", page.Resources[0].UserName)
+	})
+
+	t.Run("Count Zero Returns Only The Total", func(t *testing.T) {
+		page := list(t, "?count=0")
+		assert.Equal(t, int64(3), page.TotalResults)
+		assert.Empty(t, page.Resources)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		page := list(t, `?filter=userName+sw+"g"+or+emails[value+ew+"linus@example.com"]`)
+		assert.Equal(t, int64(2), page.TotalResults)
+	})
+
+	t.Run("Invalid Filters", func(t *testing.T) {
+		for _, filter := range []string{`userName+eq`, `nickName+eq+"ada"`, `(userName+pr`} {
+			w := serveSCIM(r, "GET", "/scim/v2/Users?filter="+filter, "")
+			assert.Equal(t, http.StatusBadRequest, w.Code, filter)
+			assert.Contains(t, w.Body.String(), `"scimType":"invalidFilter"`, filter)
+		}
+	})
+
+	t.Run("Invalid Count", func(t *testing.T) {
+		w := serveSCIM(r, "GET", "/scim/v2/Users?count=many", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCreateSCIMUser(t *testing.T) {
+	testutils.SetupTestDB(t)
+	r := setupSCIMRoutes()
+
+	for name, body := range map[string]string{
+		"Missing Schema":   `{"userName":"ada@example.com"}`,
+		"Missing UserName": `{"schemas":["` + scim.UserSchema + `"]}`,
+		"Invalid Email":    `{"schemas":["` + scim.UserSchema + `"],"userName":"ada"}`,
+		"Malformed":        `{"schemas":`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := serveSCIM(r, "POST", "/scim/v2/Users", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("Inactive Users Are Created Deactivated", func(t *testing.T) {
+		w := serveSCIM(r, "POST", "/scim/v2/Users", `{"schemas":["`+scim.UserSchema+`"],"userName":"grace@example.com","active":false}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var user scim.User
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+		require.NotNil(t, user.Active)
+		assert.False(t, *user.Active)
+
+		members, err := service().ListMembers(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, members, "deactivated members are left out of lists")
+	})
+
+	t.Run("Email From Emails When UserName Is Not An Address", func(t *testing.T) {
+		w := serveSCIM(r, "POST", "/scim/v2/Users", `{"schemas":["`+scim.UserSchema+`"],"userName":"ada","emails":[{"value":"other@example.com"},{"value":"ada@example.com","primary":true}]}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var user scim.User
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+		assert.Equal(t, "ada@example.com", user.UserName)
+	})
+}
+
+func TestDeactivateSCIMUser(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupSCIMRoutes()
+	member := testutils.CreateTestTeamMember(db)
+	_, _, err := service().CreateSession(context.Background(), member.ID, time.Hour)
+	require.NoError(t, err)
+	path := "/scim/v2/Users/" + strconv.Itoa(int(member.ID))
+	patch := func(active string) *httptest.ResponseRecorder {
+		return serveSCIM(r, "PATCH", path, `{"schemas":["`+scim.PatchOpSchema+`"],"Operations":[{"op":"replace","path":"active","value":`+active+`}]}`)
+	}
+
+	t.Run("Inactive Keeps The Member", func(t *testing.T) {
+		w := patch("false")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var stored models.TeamMember
+		require.NoError(t, db.First(&stored, member.ID).Error)
+		assert.NotNil(t, stored.DeactivatedAt)
+		var sessions int64
+		db.Model(&models.Session{}).Where("member_id = ?", member.ID).Count(&sessions)
+		assert.Zero(t, sessions, "deactivated members are signed out")
+		assert.Contains(t, serveSCIM(r, "GET", path, "").Body.String(), `"active":false`)
+	})
+
+	t.Run("Active Reactivates", func(t *testing.T) {
+		w := patch("true")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var stored models.TeamMember
+		require.NoError(t, db.First(&stored, member.ID).Error)
+		assert.Nil(t, stored.DeactivatedAt)
+	})
+}
+
+func TestSCIMDiscovery(t *testing.T) {
+	testutils.SetupTestDB(t)
+	r := setupSCIMRoutes()
+
+	for _, path := range []string{"/scim/v2/ServiceProviderConfig", "/scim/v2/ResourceTypes", "/scim/v2/Schemas"} {
+		t.Run(path, func(t *testing.T) {
+			w := serveSCIM(r, "GET", path, "")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, scim.ContentType, w.Header().Get("Content-Type"))
+		})
+	}
+}
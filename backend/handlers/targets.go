@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"coaching-backend/problem"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateProject(c *gin.Context) {
+	createTarget(c, service().CreateProject)
+}
+
+func GetProjects(c *gin.Context) {
+	listTargets(c, service().ListProjects)
+}
+
+func GetProject(c *gin.Context) {
+	getTarget(c, service().GetProject)
+}
+
+func UpdateProject(c *gin.Context) {
+	updateTarget(c, service().UpdateProject)
+}
+
+func DeleteProject(c *gin.Context) {
+	deleteTarget(c, service().DeleteProject, "Project deleted successfully")
+}
+
+func CreateRelease(c *gin.Context) {
+	createTarget(c, service().CreateRelease)
+}
+
+func GetReleases(c *gin.Context) {
+	listTargets(c, service().ListReleases)
+}
+
+func GetRelease(c *gin.Context) {
+	getTarget(c, service().GetRelease)
+}
+
+func UpdateRelease(c *gin.Context) {
+	updateTarget(c, service().UpdateRelease)
+}
+
+func DeleteRelease(c *gin.Context) {
+	deleteTarget(c, service().DeleteRelease, "Release deleted successfully")
+}
+
+func CreateMeeting(c *gin.Context) {
+	createTarget(c, service().CreateMeeting)
+}
+
+func GetMeetings(c *gin.Context) {
+	listTargets(c, service().ListMeetings)
+}
+
+func GetMeeting(c *gin.Context) {
+	getTarget(c, service().GetMeeting)
+}
+
+func UpdateMeeting(c *gin.Context) {
+	updateTarget(c, service().UpdateMeeting)
+}
+
+func DeleteMeeting(c *gin.Context) {
+	deleteTarget(c, service().DeleteMeeting, "Meeting deleted successfully")
+}
+
+// The functions below serve the feedback targets that are plain records:
+// projects, releases and meetings.
+
+func createTarget[T any](c *gin.Context, create func(context.Context, *T) error) {
+	var target T
+	if err := c.ShouldBindJSON(&target); err != nil {
+		problem.Binding(c, err)
+		return
+	}
+
+	if err := create(c.Request.Context(), &target); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, target)
+}
+
+func listTargets[T any](c *gin.Context, list func(context.Context) ([]T, error)) {
+	targets, err := list(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+func getTarget[T any](c *gin.Context, get func(context.Context, uint32) (*T, error)) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	target, err := get(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+func updateTarget[T any](c *gin.Context, update func(context.Context, uint32, func(*T) error) (*T, error)) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	target, err := update(c.Request.Context(), id, func(target *T) error {
+		return c.ShouldBindJSON(target)
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+func deleteTarget(c *gin.Context, remove func(context.Context, uint32) error, message string) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := remove(c.Request.Context(), id); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
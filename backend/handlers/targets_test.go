@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjects(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupGin()
+	r.POST("/projects", CreateProject)
+	r.GET("/projects", GetProjects)
+	r.GET("/projects/:id", GetProject)
+	r.PUT("/projects/:id", UpdateProject)
+	r.DELETE("/projects/:id", DeleteProject)
+	r.POST("/feedback", CreateFeedback)
+
+	var project models.Project
+	t.Run("Create", func(t *testing.T) {
+		w := serve(r, "POST", "/projects", `{"name":"Apollo"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &project))
+		assert.Equal(t, "Apollo", project.Name)
+		assert.NotZero(t, project.ID)
+	})
+
+	t.Run("Create Requires Name", func(t *testing.T) {
+		w := serve(r, "POST", "/projects", `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Duplicate Name Conflicts", func(t *testing.T) {
+		w := serve(r, "POST", "/projects", `{"name":"Apollo"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("List By Name", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, serve(r, "POST", "/projects", `{"name":"Artemis"}`).Code)
+		w := serve(r, "GET", "/projects", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var projects []models.Project
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &projects))
+		require.Len(t, projects, 2)
+		assert.Equal(t, "Apollo", projects[0].Name)
+		assert.Equal(t, "Artemis", projects[1].Name)
+	})
+
+	t.Run("Feedback Targets Project", func(t *testing.T) {
+		w := serve(r, "POST", "/feedback", fmt.Sprintf(`{"content":"Great launch","target_type":"project","target_id":%d}`, project.ID))
+		require.Equal(t, http.StatusCreated, w.Code)
+		var feedback models.Feedback
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &feedback))
+		assert.Equal(t, "Apollo", feedback.TargetName)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		w := serve(r, "PUT", fmt.Sprintf("/projects/%d", project.ID), `{"name":"Apollo 11"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var stored models.Project
+		require.NoError(t, db.First(&stored, project.ID).Error)
+		assert.Equal(t, "Apollo 11", stored.Name)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		w := serve(r, "DELETE", fmt.Sprintf("/projects/%d", project.ID), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, http.StatusNotFound, serve(r, "GET", fmt.Sprintf("/projects/%d", project.ID), "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(r, "DELETE", fmt.Sprintf("/projects/%d", project.ID), "").Code)
+	})
+
+	t.Run("Changes Record Events", func(t *testing.T) {
+		var types []string
+		require.NoError(t, db.Model(&models.OutboxMessage{}).
+			Where("target_type = ? AND target_id = ? AND type LIKE ?", "project", project.ID, "project.%").
+			Order("id").Pluck("type", &types).Error)
+		assert.Equal(t, []string{"project.created", "project.updated", "project.deleted"}, types)
+
+		var deleted models.OutboxMessage
+		require.NoError(t, db.Where("type = ?", "project.deleted").First(&deleted).Error)
+		assert.Contains(t, deleted.Data, `"name":"Apollo 11"`, "deletions carry the record before it")
+	})
+}
+
+func TestReleasesAndMeetings(t *testing.T) {
+	testutils.SetupTestDB(t)
+	r := setupGin()
+	r.POST("/releases", CreateRelease)
+	r.GET("/releases", GetReleases)
+	r.POST("/meetings", CreateMeeting)
+	r.GET("/meetings/:id", GetMeeting)
+	r.PUT("/meetings/:id", UpdateMeeting)
+
+	t.Run("Releases Need A Version", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(r, "POST", "/releases", `{"name":"Mobile"}`).Code)
+	})
+
+	t.Run("Releases Newest First", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, serve(r, "POST", "/releases", `{"name":"Mobile","version":"1.0"}`).Code)
+		require.Equal(t, http.StatusCreated, serve(r, "POST", "/releases", `{"name":"Mobile","version":"1.1"}`).Code)
+		w := serve(r, "GET", "/releases", "")
+		var releases []models.Release
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &releases))
+		require.Len(t, releases, 2)
+		assert.Equal(t, "1.1", releases[0].Version)
+	})
+
+	t.Run("Meeting Lifecycle", func(t *testing.T) {
+		w := serve(r, "POST", "/meetings", `{"title":"Retro","scheduled_at":"2026-03-02T10:00:00Z"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var meeting models.Meeting
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &meeting))
+
+		w = serve(r, "PUT", fmt.Sprintf("/meetings/%d", meeting.ID), `{"title":"Sprint retro"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		w = serve(r, "GET", fmt.Sprintf("/meetings/%d", meeting.ID), "")
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &meeting))
+		assert.Equal(t, "Sprint retro", meeting.Title)
+	})
+
+	t.Run("Unknown Meeting", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(r, "GET", "/meetings/999", "").Code)
+	})
+}
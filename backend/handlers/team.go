@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"coaching-backend/database"
 	"coaching-backend/models"
+	"coaching-backend/problem"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,12 +11,12 @@ import (
 func CreateTeam(c *gin.Context) {
 	var team models.Team
 	if err := c.ShouldBindJSON(&team); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Binding(c, err)
 		return
 	}
 
-	if err := database.DB.Create(&team).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team"})
+	if err := service().CreateTeam(c.Request.Context(), &team); err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -25,9 +24,9 @@ func CreateTeam(c *gin.Context) {
 }
 
 func GetTeams(c *gin.Context) {
-	var teams []models.Team
-	if err := database.DB.Preload("Members").Find(&teams).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teams"})
+	teams, err := service().ListTeams(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -35,15 +34,14 @@ func GetTeams(c *gin.Context) {
 }
 
 func GetTeam(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	var team models.Team
-	if err := database.DB.Preload("Members").First(&team, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+	team, err := service().GetTeam(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -51,25 +49,16 @@ func GetTeam(c *gin.Context) {
 }
 
 func UpdateTeam(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	var team models.Team
-	if err := database.DB.First(&team, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
-		return
-	}
-
-	if err := c.ShouldBindJSON(&team); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	if err := database.DB.Save(&team).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team"})
+	team, err := service().UpdateTeam(c.Request.Context(), id, func(team *models.Team) error {
+		return c.ShouldBindJSON(team)
+	})
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -77,14 +66,13 @@ func UpdateTeam(c *gin.Context) {
 }
 
 func DeleteTeam(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	if err := database.DB.Delete(&models.Team{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete team"})
+	if err := service().DeleteTeam(c.Request.Context(), id); err != nil {
+		writeError(c, err)
 		return
 	}
 
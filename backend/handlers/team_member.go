@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"coaching-backend/database"
 	"coaching-backend/models"
+	"coaching-backend/problem"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,12 +11,12 @@ import (
 func CreateTeamMember(c *gin.Context) {
 	var member models.TeamMember
 	if err := c.ShouldBindJSON(&member); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Binding(c, err)
 		return
 	}
 
-	if err := database.DB.Create(&member).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team member"})
+	if err := service().CreateMember(c.Request.Context(), &member); err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -25,9 +24,9 @@ func CreateTeamMember(c *gin.Context) {
 }
 
 func GetTeamMembers(c *gin.Context) {
-	var members []models.TeamMember
-	if err := database.DB.Preload("Team").Find(&members).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team members"})
+	members, err := service().ListMembers(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -35,15 +34,14 @@ func GetTeamMembers(c *gin.Context) {
 }
 
 func GetTeamMember(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	var member models.TeamMember
-	if err := database.DB.Preload("Team").First(&member, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+	member, err := service().GetMember(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
@@ -51,42 +49,64 @@ func GetTeamMember(c *gin.Context) {
 }
 
 func UpdateTeamMember(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	var member models.TeamMember
-	if err := database.DB.First(&member, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+	member, err := service().UpdateMember(c.Request.Context(), id, func(member *models.TeamMember) error {
+		return c.ShouldBindJSON(member)
+	})
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
-	if err := c.ShouldBindJSON(&member); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	c.JSON(http.StatusOK, member)
+}
+
+func DeleteTeamMember(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	if err := database.DB.Save(&member).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team member"})
+	if err := service().DeleteMember(c.Request.Context(), id); err != nil {
+		writeError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, member)
+	c.JSON(http.StatusOK, gin.H{"message": "Team member deleted successfully"})
 }
 
-func DeleteTeamMember(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
+func GetNotificationPreferences(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := service().GetNotificationPreferences(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func UpdateNotificationPreferences(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
 
-	if err := database.DB.Delete(&models.TeamMember{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete team member"})
+	prefs, err := service().UpdateNotificationPreferences(c.Request.Context(), id, func(prefs *models.NotificationPreferences) error {
+		return c.ShouldBindJSON(prefs)
+	})
+	if err != nil {
+		writeError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Team member deleted successfully"})
+	c.JSON(http.StatusOK, prefs)
 }
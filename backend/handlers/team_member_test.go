@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"bytes"
+	"coaching-backend/models"
+	"coaching-backend/problem"
 	"coaching-backend/tests/testutils"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -20,7 +23,7 @@ func setupGin() *gin.Engine {
 }
 
 func TestCreateTeamMember(t *testing.T) {
-	db := testutils.SetupTestDB(t)
+	testutils.SetupTestDB(t)
 	r := setupGin()
 	r.POST("/members", CreateTeamMember)
 
@@ -94,6 +97,35 @@ func TestCreateTeamMember(t *testing.T) {
 		r.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+		var response problem.Problem
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, problem.CodeValidation, response.Code)
+		assert.NotEmpty(t, response.TraceID)
+		assert.Equal(t, []problem.FieldError{{Field: "email", Rule: "email", Message: "must be a valid email address"}}, response.Errors)
+	})
+
+	t.Run("Duplicate Email Conflict", func(t *testing.T) {
+		reqBody := testutils.TestTeamMemberRequest{
+			Name:  "Johnny Doe",
+			Email: "john@example.com",
+		}
+
+		jsonBody, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest("POST", "/members", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		var response problem.Problem
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, problem.CodeConflict, response.Code)
 	})
 }
 
@@ -130,6 +162,26 @@ func TestGetTeamMembers(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, response, 2)
 	})
+
+	t.Run("Leaves Out Deactivated Members", func(t *testing.T) {
+		team := testutils.CreateTestTeam(db)
+		leaver := testutils.CreateTestTeamMember(db)
+		db.Model(leaver).Updates(map[string]interface{}{"team_id": team.ID, "deactivated_at": time.Now()})
+		r.GET("/members/:id", GetTeamMember)
+		r.GET("/teams/:id", GetTeam)
+
+		var members []models.TeamMember
+		assert.NoError(t, json.Unmarshal(serve(r, "GET", "/members", "").Body.Bytes(), &members))
+		assert.Len(t, members, 2)
+
+		var got models.Team
+		assert.NoError(t, json.Unmarshal(serve(r, "GET", "/teams/"+strconv.Itoa(int(team.ID)), "").Body.Bytes(), &got))
+		assert.Empty(t, got.Members)
+
+		w := serve(r, "GET", "/members/"+strconv.Itoa(int(leaver.ID)), "")
+		assert.Equal(t, http.StatusOK, w.Code, "deactivated members can still be looked up")
+		assert.Contains(t, w.Body.String(), `"deactivated_at"`)
+	})
 }
 
 func TestGetTeamMember(t *testing.T) {
@@ -252,3 +304,47 @@ func TestDeleteTeamMember(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
+
+func TestNotificationPreferences(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupGin()
+	r.GET("/members/:id/notification-preferences", GetNotificationPreferences)
+	r.PUT("/members/:id/notification-preferences", UpdateNotificationPreferences)
+	member := testutils.CreateTestTeamMember(db)
+	path := "/members/" + strconv.Itoa(int(member.ID)) + "/notification-preferences"
+
+	t.Run("Defaults To Enabled", func(t *testing.T) {
+		w := serve(r, "GET", path, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(member.ID), response["member_id"])
+		assert.Equal(t, true, response["feedback_emails"])
+		assert.Equal(t, false, response["weekly_digest"])
+	})
+
+	t.Run("Turn Off Feedback Emails", func(t *testing.T) {
+		w := serve(r, "PUT", path, `{"member_id":999,"feedback_emails":false}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = serve(r, "GET", path, "")
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(member.ID), response["member_id"], "member_id comes from the path")
+		assert.Equal(t, false, response["feedback_emails"])
+	})
+
+	t.Run("Non-existent Team Member", func(t *testing.T) {
+		w := serve(r, "GET", "/members/999/notification-preferences", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = serve(r, "PUT", "/members/999/notification-preferences", `{"feedback_emails":false}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid Body", func(t *testing.T) {
+		w := serve(r, "PUT", path, `{"feedback_emails":"no"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
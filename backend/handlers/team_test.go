@@ -9,12 +9,11 @@ import (
 	"strconv"
 	"testing"
 
-	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateTeam(t *testing.T) {
-	db := testutils.SetupTestDB(t)
+	testutils.SetupTestDB(t)
 	r := setupGin()
 	r.POST("/teams", CreateTeam)
 
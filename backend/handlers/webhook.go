@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhook registers a webhook. The response is the only one that
+// includes the signing secret.
+func CreateWebhook(c *gin.Context) {
+	var hook models.Webhook
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		problem.Binding(c, err)
+		return
+	}
+
+	if err := service().CreateWebhook(c.Request.Context(), &hook); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+func GetWebhooks(c *gin.Context) {
+	hooks, err := service().ListWebhooks(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hooks)
+}
+
+func GetWebhook(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	hook, err := service().GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+func UpdateWebhook(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	hook, err := service().UpdateWebhook(c.Request.Context(), id, func(hook *models.Webhook) error {
+		return c.ShouldBindJSON(hook)
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+func DeleteWebhook(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := service().DeleteWebhook(c.Request.Context(), id); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+func GetWebhookDeliveries(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := service().ListWebhookDeliveries(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverWebhookDelivery queues a past delivery to be sent again.
+func RedeliverWebhookDelivery(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseIDParam(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := service().RedeliverWebhook(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
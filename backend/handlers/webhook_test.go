@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"bytes"
+	"coaching-backend/models"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWebhookRoutes() *gin.Engine {
+	r := setupGin()
+	r.POST("/webhooks", CreateWebhook)
+	r.GET("/webhooks", GetWebhooks)
+	r.GET("/webhooks/:id", GetWebhook)
+	r.PUT("/webhooks/:id", UpdateWebhook)
+	r.DELETE("/webhooks/:id", DeleteWebhook)
+	r.GET("/webhooks/:id/deliveries", GetWebhookDeliveries)
+	r.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", RedeliverWebhookDelivery)
+	return r
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateWebhook(t *testing.T) {
+	testutils.SetupTestDB(t)
+	r := setupWebhookRoutes()
+
+	t.Run("Generates Secret", func(t *testing.T) {
+		w := serve(r, "POST", "/webhooks", `{"url":"https://example.com/hook","events":["feedback.created"]}`)
+		assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var hook models.Webhook
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &hook))
+		assert.True(t, strings.HasPrefix(hook.Secret, "whsec_"))
+		assert.True(t, hook.Active)
+		assert.Equal(t, models.StringList{"feedback.created"}, hook.Events)
+	})
+
+	t.Run("Keeps Given Secret", func(t *testing.T) {
+		w := serve(r, "POST", "/webhooks", `{"url":"https://example.com/hook","secret":"mine"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var hook models.Webhook
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &hook))
+		assert.Equal(t, "mine", hook.Secret)
+		assert.Empty(t, hook.Events)
+	})
+
+	t.Run("Invalid Webhooks", func(t *testing.T) {
+		for _, body := range []string{
+			`{"events":["feedback.created"]}`,
+			`{"url":"not a url"}`,
+			`{"url":"ftp://example.com/hook"}`,
+			`{"url":"http://localhost:8080/hook"}`,
+			`{"url":"http://127.0.0.1/hook"}`,
+			`{"url":"http://169.254.169.254/latest/meta-data"}`,
+			`{"url":"http://[fd00::1]/hook"}`,
+			`{"url":"https://example.com/hook","events":["feedback.exploded"]}`,
+		} {
+			w := serve(r, "POST", "/webhooks", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+			assert.Contains(t, w.Body.String(), "validation_failed", body)
+		}
+	})
+}
+
+func TestGetWebhooks(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupWebhookRoutes()
+	hook := models.Webhook{URL: "https://example.com/hook", Secret: "s3cret", Active: true}
+	db.Create(&hook)
+
+	t.Run("Secrets Are Not Listed", func(t *testing.T) {
+		w := serve(r, "GET", "/webhooks", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "s3cret")
+		assert.Contains(t, w.Body.String(), "https://example.com/hook")
+	})
+
+	t.Run("Get Webhook", func(t *testing.T) {
+		w := serve(r, "GET", fmt.Sprintf("/webhooks/%d", hook.ID), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "s3cret")
+	})
+
+	t.Run("Unknown Webhook", func(t *testing.T) {
+		w := serve(r, "GET", "/webhooks/999", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupWebhookRoutes()
+
+	t.Run("Re-enabling Clears Failures", func(t *testing.T) {
+		disabledAt := time.Now()
+		hook := models.Webhook{URL: "https://example.com/hook", Secret: "s3cret", Failures: 20, DisabledAt: &disabledAt}
+		db.Create(&hook)
+
+		w := serve(r, "PUT", fmt.Sprintf("/webhooks/%d", hook.ID), `{"active":true,"failures":0}`)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var stored models.Webhook
+		db.First(&stored, hook.ID)
+		assert.True(t, stored.Active)
+		assert.Zero(t, stored.Failures)
+		assert.Nil(t, stored.DisabledAt)
+		assert.Equal(t, "s3cret", stored.Secret, "an omitted secret is kept")
+	})
+
+	t.Run("Server Fields Are Ignored", func(t *testing.T) {
+		hook := models.Webhook{URL: "https://example.com/hook", Secret: "s3cret", Active: true, Failures: 3}
+		db.Create(&hook)
+
+		w := serve(r, "PUT", fmt.Sprintf("/webhooks/%d", hook.ID), `{"url":"https://example.com/new","failures":0,"secret":"rotated"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "rotated")
+
+		var stored models.Webhook
+		db.First(&stored, hook.ID)
+		assert.Equal(t, "https://example.com/new", stored.URL)
+		assert.Equal(t, 3, stored.Failures)
+		assert.Equal(t, "rotated", stored.Secret)
+	})
+
+	t.Run("Disabling Records Time", func(t *testing.T) {
+		hook := models.Webhook{URL: "https://example.com/hook", Secret: "s3cret", Active: true}
+		db.Create(&hook)
+
+		w := serve(r, "PUT", fmt.Sprintf("/webhooks/%d", hook.ID), `{"active":false}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var stored models.Webhook
+		db.First(&stored, hook.ID)
+		assert.False(t, stored.Active)
+		assert.NotNil(t, stored.DisabledAt)
+	})
+
+	t.Run("Invalid Event Type", func(t *testing.T) {
+		hook := models.Webhook{URL: "https://example.com/hook", Secret: "s3cret", Active: true}
+		db.Create(&hook)
+
+		w := serve(r, "PUT", fmt.Sprintf("/webhooks/%d", hook.ID), `{"events":["nope"]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	r := setupWebhookRoutes()
+
+	hook := models.Webhook{URL: "https://example.com/hook", Secret: "s3cret", Active: true}
+	db.Create(&hook)
+	failed := models.WebhookDelivery{
+		WebhookID: hook.ID, EventID: 4, EventType: "feedback.created", Payload: `{"id":4}`,
+		Status: models.DeliveryFailed, Attempts: 8, ResponseCode: 503, NextAttemptAt: time.Now(),
+	}
+	db.Create(&failed)
+
+	t.Run("Delivery Log", func(t *testing.T) {
+		w := serve(r, "GET", fmt.Sprintf("/webhooks/%d/deliveries", hook.ID), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var deliveries []models.WebhookDelivery
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, 503, deliveries[0].ResponseCode)
+		assert.Equal(t, models.DeliveryFailed, deliveries[0].Status)
+	})
+
+	t.Run("Redeliver", func(t *testing.T) {
+		w := serve(r, "POST", fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", hook.ID, failed.ID), "")
+		assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+		var delivery models.WebhookDelivery
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &delivery))
+		assert.NotEqual(t, failed.ID, delivery.ID)
+		assert.Equal(t, models.DeliveryPending, delivery.Status)
+		assert.Equal(t, failed.Payload, delivery.Payload)
+		assert.Equal(t, failed.ID, *delivery.RedeliveryOf)
+		assert.Zero(t, delivery.Attempts)
+	})
+
+	t.Run("Redeliver Unknown Delivery", func(t *testing.T) {
+		other := models.Webhook{URL: "https://example.com/other", Secret: "s3cret", Active: true}
+		db.Create(&other)
+
+		w := serve(r, "POST", fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", other.ID, failed.ID), "")
+		assert.Equal(t, http.StatusNotFound, w.Code, "deliveries belong to their webhook")
+
+		w = serve(r, "POST", fmt.Sprintf("/webhooks/%d/deliveries/abc/redeliver", hook.ID), "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Delete Removes Log", func(t *testing.T) {
+		w := serve(r, "DELETE", fmt.Sprintf("/webhooks/%d", hook.ID), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var count int64
+		db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID).Count(&count)
+		assert.Zero(t, count)
+
+		w = serve(r, "GET", fmt.Sprintf("/webhooks/%d/deliveries", hook.ID), "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,143 @@
+// Package images validates uploaded avatars and logos and renders their
+// square thumbnail variants.
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	_ "image/gif" // register decoders for image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// MaxBytes caps the size of an upload.
+	MaxBytes = 5 << 20
+	// MaxDimension caps each side, so a small file cannot decode into a
+	// huge bitmap.
+	MaxDimension = 4096
+)
+
+// Sizes are the thumbnail edge lengths rendered for every upload.
+var Sizes = []int{64, 256}
+
+var (
+	ErrTooLarge    = errors.New("image is larger than 5 MB")
+	ErrUnsupported = errors.New("file is not a PNG, JPEG, GIF or WebP image")
+	ErrInvalid     = errors.New("image could not be decoded")
+	ErrDimensions  = errors.New("image is wider or taller than 4096 pixels")
+)
+
+// types maps the sniffed content types that are accepted to the file
+// extension blobs are stored under.
+var types = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Image is a validated upload.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+	// Hash is the hex SHA-256 of Data.
+	Hash string
+}
+
+// Read reads and validates an upload. The content type is sniffed from
+// the data; whatever the client claimed is ignored.
+func Read(r io.Reader) (*Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if _, ok := types[contentType]; !ok {
+		return nil, ErrUnsupported
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension {
+		return nil, ErrDimensions
+	}
+	if config.Width == 0 || config.Height == 0 {
+		return nil, ErrInvalid
+	}
+
+	sum := sha256.Sum256(data)
+	return &Image{
+		Data:        data,
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+		Hash:        hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// Extension returns the file extension for an accepted content type.
+func Extension(contentType string) string {
+	return types[contentType]
+}
+
+// ThumbnailType is the content type thumbnails of an image are encoded
+// in: JPEG for photos, PNG for everything else so transparency survives.
+func ThumbnailType(contentType string) string {
+	if contentType == "image/jpeg" {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// Thumbnails renders a square variant of img for each of Sizes, keyed by
+// size. The image is cropped to its centre square and scaled down, never
+// up. GIFs use their first frame.
+func Thumbnails(img *Image) (map[int][]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	square := centerSquare(src.Bounds())
+
+	variants := make(map[int][]byte, len(Sizes))
+	for _, size := range Sizes {
+		edge := min(size, square.Dx())
+		dst := image.NewRGBA(image.Rect(0, 0, edge, edge))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Src, nil)
+
+		var buf bytes.Buffer
+		if ThumbnailType(img.ContentType) == "image/jpeg" {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		} else {
+			err = png.Encode(&buf, dst)
+		}
+		if err != nil {
+			return nil, err
+		}
+		variants[size] = buf.Bytes()
+	}
+	return variants, nil
+}
+
+func centerSquare(r image.Rectangle) image.Rectangle {
+	edge := min(r.Dx(), r.Dy())
+	x := r.Min.X + (r.Dx()-edge)/2
+	y := r.Min.Y + (r.Dy()-edge)/2
+	return image.Rect(x, y, x+edge, y+edge)
+}
@@ -0,0 +1,133 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// picture draws a w×h image whose left half is red and right half blue.
+func picture(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	t.Run("PNG", func(t *testing.T) {
+		img, err := Read(bytes.NewReader(encodePNG(t, picture(300, 200))))
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", img.ContentType)
+		assert.Equal(t, 300, img.Width)
+		assert.Equal(t, 200, img.Height)
+		assert.Len(t, img.Hash, 64)
+	})
+
+	t.Run("JPEG", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, picture(40, 40), nil))
+		img, err := Read(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", img.ContentType)
+	})
+
+	t.Run("GIF", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gif.Encode(&buf, picture(40, 40), nil))
+		img, err := Read(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, "image/gif", img.ContentType)
+	})
+
+	t.Run("Ignores Claimed Type", func(t *testing.T) {
+		_, err := Read(bytes.NewReader([]byte("<svg xmlns='http://www.w3.org/2000/svg'/>")))
+		assert.ErrorIs(t, err, ErrUnsupported)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		data := encodePNG(t, picture(40, 40))
+		_, err := Read(bytes.NewReader(data[:20]))
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		data := append(encodePNG(t, picture(10, 10)), make([]byte, MaxBytes)...)
+		_, err := Read(bytes.NewReader(data))
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+
+	t.Run("Too Many Pixels", func(t *testing.T) {
+		_, err := Read(bytes.NewReader(encodePNG(t, image.NewGray(image.Rect(0, 0, MaxDimension+1, 1)))))
+		assert.ErrorIs(t, err, ErrDimensions)
+	})
+}
+
+func TestThumbnails(t *testing.T) {
+	t.Run("Crops And Scales", func(t *testing.T) {
+		img, err := Read(bytes.NewReader(encodePNG(t, picture(600, 300))))
+		require.NoError(t, err)
+
+		variants, err := Thumbnails(img)
+		require.NoError(t, err)
+		require.Len(t, variants, len(Sizes))
+		for _, size := range Sizes {
+			thumb, format, err := image.Decode(bytes.NewReader(variants[size]))
+			require.NoError(t, err)
+			assert.Equal(t, "png", format)
+			assert.Equal(t, image.Rect(0, 0, size, size), thumb.Bounds())
+
+			// The centre square of a half red, half blue image.
+			r, _, _, _ := thumb.At(1, size/2).RGBA()
+			_, _, b, _ := thumb.At(size-2, size/2).RGBA()
+			assert.Equal(t, uint32(0xffff), r)
+			assert.Equal(t, uint32(0xffff), b)
+		}
+	})
+
+	t.Run("Never Upscales", func(t *testing.T) {
+		img, err := Read(bytes.NewReader(encodePNG(t, picture(100, 120))))
+		require.NoError(t, err)
+
+		variants, err := Thumbnails(img)
+		require.NoError(t, err)
+		config, err := png.DecodeConfig(bytes.NewReader(variants[256]))
+		require.NoError(t, err)
+		assert.Equal(t, 100, config.Width)
+		assert.Equal(t, 100, config.Height)
+	})
+
+	t.Run("JPEG Stays JPEG", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, picture(80, 80), nil))
+		img, err := Read(&buf)
+		require.NoError(t, err)
+
+		variants, err := Thumbnails(img)
+		require.NoError(t, err)
+		_, format, err := image.DecodeConfig(bytes.NewReader(variants[64]))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, "image/jpeg", ThumbnailType(img.ContentType))
+	})
+}
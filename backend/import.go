@@ -0,0 +1,75 @@
+package main
+
+import (
+	"coaching-backend/services"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// runImport implements "coaching-backend import", which imports members
+// like POST /api/members/import. connect is only called once the
+// arguments are valid. The events the import records are published by the
+// server's outbox relay. It returns the process exit code.
+func runImport(ctx context.Context, args []string, stdout, stderr io.Writer, connect func() *services.Service) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: coaching-backend import [-dry-run] [-create-teams] FILE")
+		fmt.Fprintln(stderr, "Creates or updates members from a CSV or XLSX file with name, email, picture and team columns.")
+		flags.PrintDefaults()
+	}
+	var opts services.ImportOptions
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "only report what the import would change")
+	flags.BoolVar(&opts.CreateTeams, "create-teams", false, "create teams named in the file that do not exist")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := flags.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if len(data) > services.MaxImportBytes {
+		fmt.Fprintf(stderr, "%s is larger than 10 MB\n", path)
+		return 1
+	}
+
+	report, err := connect().ImportMembers(ctx, filepath.Base(path), data, opts)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		var serviceErr *services.Error
+		if errors.As(err, &serviceErr) {
+			for _, field := range serviceErr.Fields {
+				fmt.Fprintf(stderr, "  %s %s\n", field.Field, field.Message)
+			}
+		}
+		return 1
+	}
+
+	for _, e := range report.Errors {
+		fmt.Fprintf(stderr, "row %d: %s %s\n", e.Row, e.Field, e.Message)
+	}
+	summary, teamCreated := "Imported", "Created team"
+	if report.DryRun {
+		summary, teamCreated = "Dry run", "Would create team"
+	}
+	fmt.Fprintf(stdout, "%s: %d created, %d updated, %d unchanged\n", summary, report.Created, report.Updated, report.Unchanged)
+	for _, team := range report.TeamsCreated {
+		fmt.Fprintf(stdout, "%s %s\n", teamCreated, team)
+	}
+	if len(report.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
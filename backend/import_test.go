@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportCommand(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	connected := false
+	connect := func() *services.Service {
+		connected = true
+		return services.New(db)
+	}
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runImport(context.Background(), args, &stdout, &stderr, connect)
+		return code, stdout.String(), stderr.String()
+	}
+
+	path := filepath.Join(t.TempDir(), "members.csv")
+	os.WriteFile(path, []byte("name,email,team\nAda,ada@example.com,Platform\nGrace,not-an-email,\n"), 0o600)
+
+	t.Run("Usage", func(t *testing.T) {
+		code, _, stderr := run("-dry-run")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "Usage: coaching-backend import")
+		assert.False(t, connected, "bad arguments do not connect to the database")
+	})
+
+	t.Run("Dry Run", func(t *testing.T) {
+		code, stdout, stderr := run("-dry-run", "-create-teams", path)
+		assert.Equal(t, 1, code)
+		assert.Equal(t, "Dry run: 1 created, 0 updated, 0 unchanged\nWould create team Platform\n", stdout)
+		assert.Equal(t, "row 3: email must be a valid email address\n", stderr)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		code, _, stderr := run("-create-teams", path)
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "rows[3].email must be a valid email address")
+
+		var count int64
+		db.Model(&models.TeamMember{}).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		os.WriteFile(path, []byte("name,email,team\nAda,ada@example.com,Platform\n"), 0o600)
+		code, stdout, _ := run("-create-teams", path)
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "Imported: 1 created, 0 updated, 0 unchanged\nCreated team Platform\n", stdout)
+	})
+}
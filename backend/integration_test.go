@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"coaching-backend/config"
 	"coaching-backend/database"
 	"coaching-backend/handlers"
 	"coaching-backend/tests/testutils"
@@ -18,53 +19,15 @@ func setupTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
 
-	api := r.Group("/api")
-	{
-		members := api.Group("/members")
-		{
-			members.POST("", handlers.CreateTeamMember)
-			members.GET("", handlers.GetTeamMembers)
-			members.GET("/:id", handlers.GetTeamMember)
-			members.PUT("/:id", handlers.UpdateTeamMember)
-			members.DELETE("/:id", handlers.DeleteTeamMember)
-		}
-
-		teams := api.Group("/teams")
-		{
-			teams.POST("", handlers.CreateTeam)
-			teams.GET("", handlers.GetTeams)
-			teams.GET("/:id", handlers.GetTeam)
-			teams.PUT("/:id", handlers.UpdateTeam)
-			teams.DELETE("/:id", handlers.DeleteTeam)
-		}
-
-		assignments := api.Group("/assignments")
-		{
-			assignments.POST("", handlers.AssignMemberToTeam)
-			assignments.GET("", handlers.GetAssignments)
-			assignments.GET("/unassigned", handlers.GetUnassignedMembers)
-			assignments.DELETE("/member/:id", handlers.RemoveMemberFromTeam)
-		}
-
-		feedback := api.Group("/feedback")
-		{
-			feedback.POST("", handlers.CreateFeedback)
-			feedback.GET("", handlers.GetFeedback)
-			feedback.GET("/:id", handlers.GetFeedbackByID)
-			feedback.PUT("/:id", handlers.UpdateFeedback)
-			feedback.DELETE("/:id", handlers.DeleteFeedback)
-		}
+	if err := registerRoutes(r, config.Default(), &handlers.RateLimits{}); err != nil {
+		panic(err)
 	}
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok", "message": "Coaching API is running"})
-	})
-
 	return r
 }
 
 func TestCompleteWorkflow(t *testing.T) {
-	db := testutils.SetupTestDB(t)
+	testutils.SetupTestDB(t)
 	r := setupTestRouter()
 
 	t.Run("Complete Coaching Application Workflow", func(t *testing.T) {
@@ -83,7 +46,7 @@ func TestCompleteWorkflow(t *testing.T) {
 
 		var memberResponse map[string]interface{}
 		json.Unmarshal(w.Body.Bytes(), &memberResponse)
-		memberID := uint(memberResponse["id"].(float64))
+		memberID := uint32(memberResponse["id"].(float64))
 
 		// Step 2: Create a team
 		teamReq := testutils.TestTeamRequest{
@@ -99,7 +62,7 @@ func TestCompleteWorkflow(t *testing.T) {
 
 		var teamResponse map[string]interface{}
 		json.Unmarshal(w.Body.Bytes(), &teamResponse)
-		teamID := uint(teamResponse["id"].(float64))
+		teamID := uint32(teamResponse["id"].(float64))
 
 		// Step 3: Assign member to team
 		assignReq := testutils.TestAssignRequest{
@@ -236,7 +199,7 @@ func TestCORSHeaders(t *testing.T) {
 }
 
 func TestErrorHandling(t *testing.T) {
-	db := testutils.SetupTestDB(t)
+	testutils.SetupTestDB(t)
 	r := setupTestRouter()
 
 	t.Run("Database Connection Error Handling", func(t *testing.T) {
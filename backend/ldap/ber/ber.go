@@ -0,0 +1,199 @@
+// Package ber encodes and decodes the subset of ASN.1 Basic Encoding
+// Rules that LDAP messages use: definite lengths and single-octet
+// identifiers, which cover every tag number below 31.
+package ber
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Class and form bits of an identifier octet.
+const (
+	Universal   = 0x00
+	Application = 0x40
+	Context     = 0x80
+	Constructed = 0x20
+)
+
+// Identifiers of the universal types LDAP uses.
+const (
+	TagBoolean     = 0x01
+	TagInteger     = 0x02
+	TagOctetString = 0x04
+	TagNull        = 0x05
+	TagEnumerated  = 0x0a
+	TagSequence    = Constructed | 0x10
+	TagSet         = Constructed | 0x11
+)
+
+// ErrTooLarge is returned by Read for elements longer than its limit.
+var ErrTooLarge = errors.New("ber: element too large")
+
+// Element is one decoded type-length-value. Content holds the value's
+// encoding; constructed elements are split with Children.
+type Element struct {
+	Tag     byte
+	Content []byte
+}
+
+// Encode encodes an element with the concatenated contents.
+func Encode(tag byte, contents ...[]byte) []byte {
+	n := 0
+	for _, c := range contents {
+		n += len(c)
+	}
+	out := append([]byte{tag}, encodeLength(n)...)
+	for _, c := range contents {
+		out = append(out, c...)
+	}
+	return out
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var digits []byte
+	for ; n > 0; n >>= 8 {
+		digits = append([]byte{byte(n)}, digits...)
+	}
+	return append([]byte{0x80 | byte(len(digits))}, digits...)
+}
+
+// Sequence encodes a SEQUENCE of already encoded elements.
+func Sequence(elements ...[]byte) []byte {
+	return Encode(TagSequence, elements...)
+}
+
+// String encodes s with tag, TagOctetString for an OCTET STRING.
+func String(tag byte, s string) []byte {
+	return Encode(tag, []byte(s))
+}
+
+// Integer encodes n with tag, TagInteger or TagEnumerated, in the fewest
+// octets of two's complement.
+func Integer(tag byte, n int64) []byte {
+	content := []byte{byte(n)}
+	for rest := n >> 8; ; rest >>= 8 {
+		last := content[0]
+		if rest == 0 && last&0x80 == 0 || rest == -1 && last&0x80 != 0 {
+			break
+		}
+		content = append([]byte{byte(rest)}, content...)
+	}
+	return Encode(tag, content)
+}
+
+func Boolean(b bool) []byte {
+	if b {
+		return Encode(TagBoolean, []byte{0xff})
+	}
+	return Encode(TagBoolean, []byte{0x00})
+}
+
+// Read reads one element from r, rejecting elements whose content is
+// longer than max bytes.
+func Read(r io.Reader, max int) (Element, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return Element{}, err
+	}
+	if head[0]&0x1f == 0x1f {
+		return Element{}, errors.New("ber: multi-octet identifiers are not supported")
+	}
+	n := int(head[1])
+	if n&0x80 != 0 {
+		size := n & 0x7f
+		if size == 0 || size > 4 {
+			return Element{}, errors.New("ber: unsupported length")
+		}
+		digits := make([]byte, size)
+		if _, err := io.ReadFull(r, digits); err != nil {
+			return Element{}, unexpectedEOF(err)
+		}
+		n = 0
+		for _, d := range digits {
+			n = n<<8 | int(d)
+		}
+	}
+	if n > max {
+		return Element{}, ErrTooLarge
+	}
+	content := make([]byte, n)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return Element{}, unexpectedEOF(err)
+	}
+	return Element{Tag: head[0], Content: content}, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Parse decodes the element at the start of b and returns the bytes after
+// it.
+func Parse(b []byte) (Element, []byte, error) {
+	if len(b) < 2 {
+		return Element{}, nil, errors.New("ber: truncated element")
+	}
+	if b[0]&0x1f == 0x1f {
+		return Element{}, nil, errors.New("ber: multi-octet identifiers are not supported")
+	}
+	n, offset := int(b[1]), 2
+	if n&0x80 != 0 {
+		size := n & 0x7f
+		if size == 0 || size > 4 || len(b) < 2+size {
+			return Element{}, nil, errors.New("ber: invalid length")
+		}
+		n = 0
+		for _, d := range b[2 : 2+size] {
+			n = n<<8 | int(d)
+		}
+		offset += size
+	}
+	if n < 0 || len(b)-offset < n {
+		return Element{}, nil, errors.New("ber: truncated element")
+	}
+	return Element{Tag: b[0], Content: b[offset : offset+n]}, b[offset+n:], nil
+}
+
+// Children decodes the elements a constructed element holds.
+func (e Element) Children() ([]Element, error) {
+	var children []Element
+	for rest := e.Content; len(rest) > 0; {
+		child, next, err := Parse(rest)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+		rest = next
+	}
+	return children, nil
+}
+
+// Int decodes an INTEGER or ENUMERATED value.
+func (e Element) Int() (int64, error) {
+	if len(e.Content) == 0 || len(e.Content) > 8 {
+		return 0, fmt.Errorf("ber: invalid integer of %d octets", len(e.Content))
+	}
+	n := int64(int8(e.Content[0]))
+	for _, b := range e.Content[1:] {
+		n = n<<8 | int64(b)
+	}
+	return n, nil
+}
+
+// Bool decodes a BOOLEAN: any non-zero octet is true.
+func (e Element) Bool() bool {
+	return len(e.Content) == 1 && e.Content[0] != 0
+}
+
+// Text returns the content of an OCTET STRING as a string.
+func (e Element) Text() string {
+	return string(e.Content)
+}
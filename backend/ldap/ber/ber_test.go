@@ -0,0 +1,57 @@
+package ber
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegers(t *testing.T) {
+	for n, encoded := range map[int64][]byte{
+		0:      {0x02, 0x01, 0x00},
+		127:    {0x02, 0x01, 0x7f},
+		128:    {0x02, 0x02, 0x00, 0x80},
+		256:    {0x02, 0x02, 0x01, 0x00},
+		-1:     {0x02, 0x01, 0xff},
+		-129:   {0x02, 0x02, 0xff, 0x7f},
+		500000: {0x02, 0x03, 0x07, 0xa1, 0x20},
+	} {
+		assert.Equal(t, encoded, Integer(TagInteger, n), "%d", n)
+		e, rest, err := Parse(encoded)
+		require.NoError(t, err)
+		assert.Empty(t, rest)
+		got, err := e.Int()
+		require.NoError(t, err)
+		assert.Equal(t, n, got)
+	}
+}
+
+func TestLongLengths(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 300)
+	encoded := Encode(TagOctetString, content)
+	assert.Equal(t, []byte{0x04, 0x82, 0x01, 0x2c}, encoded[:4])
+
+	e, err := Read(bytes.NewReader(encoded), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, string(content), e.Text())
+
+	_, err = Read(bytes.NewReader(encoded), 100)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestChildren(t *testing.T) {
+	seq := Sequence(String(TagOctetString, "cn"), Boolean(true), Integer(TagEnumerated, 2))
+	e, _, err := Parse(seq)
+	require.NoError(t, err)
+	children, err := e.Children()
+	require.NoError(t, err)
+	require.Len(t, children, 3)
+	assert.Equal(t, "cn", children[0].Text())
+	assert.True(t, children[1].Bool())
+	assert.Equal(t, byte(TagEnumerated), children[2].Tag)
+
+	_, _, err = Parse(seq[:len(seq)-1])
+	assert.Error(t, err, "truncated")
+}
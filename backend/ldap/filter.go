@@ -0,0 +1,382 @@
+package ldap
+
+import (
+	"coaching-backend/ldap/ber"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter is a search filter (RFC 4515): a composite of And, Or and Not,
+// or a Match on one attribute.
+type Filter interface {
+	encode() []byte
+	// Matches evaluates the filter against an entry; the stand-in server
+	// in ldaptest uses it.
+	Matches(e *Entry) bool
+}
+
+type And []Filter
+type Or []Filter
+type Not struct{ Filter Filter }
+
+// Match operators.
+const (
+	Equal          = "="
+	Present        = "=*"
+	Substrings     = "*"
+	GreaterOrEqual = ">="
+	LessOrEqual    = "<="
+	Approx         = "~="
+)
+
+// Match compares an attribute. For Substrings, Value holds the pattern
+// with "*" separating the parts; values are otherwise unescaped.
+type Match struct {
+	Attr  string
+	Op    string
+	Value string
+	// parts are the unescaped pieces of a substrings pattern, split at
+	// its stars.
+	parts []string
+}
+
+// Filter choice tags.
+const (
+	tagAnd            = ber.Context | ber.Constructed | 0
+	tagOr             = ber.Context | ber.Constructed | 1
+	tagNot            = ber.Context | ber.Constructed | 2
+	tagEqual          = ber.Context | ber.Constructed | 3
+	tagSubstrings     = ber.Context | ber.Constructed | 4
+	tagGreaterOrEqual = ber.Context | ber.Constructed | 5
+	tagLessOrEqual    = ber.Context | ber.Constructed | 6
+	tagPresent        = ber.Context | 7
+	tagApprox         = ber.Context | ber.Constructed | 8
+)
+
+var matchTags = map[string]byte{
+	Equal: tagEqual, GreaterOrEqual: tagGreaterOrEqual, LessOrEqual: tagLessOrEqual, Approx: tagApprox,
+}
+
+func (f And) encode() []byte { return ber.Encode(tagAnd, encodeAll(f)...) }
+func (f Or) encode() []byte  { return ber.Encode(tagOr, encodeAll(f)...) }
+func (f Not) encode() []byte { return ber.Encode(tagNot, f.Filter.encode()) }
+
+func encodeAll(filters []Filter) [][]byte {
+	out := make([][]byte, len(filters))
+	for i, f := range filters {
+		out[i] = f.encode()
+	}
+	return out
+}
+
+func (f Match) encode() []byte {
+	switch f.Op {
+	case Present:
+		return ber.String(tagPresent, f.Attr)
+	case Substrings:
+		var parts [][]byte
+		for i, part := range f.parts {
+			tag := byte(ber.Context | 1)
+			switch {
+			case part == "":
+				continue
+			case i == 0:
+				tag = ber.Context | 0
+			case i == len(f.parts)-1:
+				tag = ber.Context | 2
+			}
+			parts = append(parts, ber.String(tag, part))
+		}
+		return ber.Encode(tagSubstrings, ber.String(ber.TagOctetString, f.Attr), ber.Sequence(parts...))
+	}
+	return ber.Encode(matchTags[f.Op], ber.String(ber.TagOctetString, f.Attr), ber.String(ber.TagOctetString, f.Value))
+}
+
+func (f And) Matches(e *Entry) bool {
+	for _, sub := range f {
+		if !sub.Matches(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Or) Matches(e *Entry) bool {
+	for _, sub := range f {
+		if sub.Matches(e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Not) Matches(e *Entry) bool {
+	return !f.Filter.Matches(e)
+}
+
+// Matches compares values case-insensitively, as most directory
+// attributes do; ordering compares strings.
+func (f Match) Matches(e *Entry) bool {
+	values := e.Values(f.Attr)
+	if f.Op == Present {
+		return len(values) > 0
+	}
+	want := strings.ToLower(f.Value)
+	for _, v := range values {
+		v = strings.ToLower(v)
+		switch f.Op {
+		case Equal, Approx:
+			if v == want {
+				return true
+			}
+		case GreaterOrEqual:
+			if v >= want {
+				return true
+			}
+		case LessOrEqual:
+			if v <= want {
+				return true
+			}
+		case Substrings:
+			if matchSubstrings(v, f.parts) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchSubstrings(v string, parts []string) bool {
+	first, last := strings.ToLower(parts[0]), strings.ToLower(parts[len(parts)-1])
+	if !strings.HasPrefix(v, first) {
+		return false
+	}
+	v = v[len(first):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(v, strings.ToLower(part))
+		if i < 0 {
+			return false
+		}
+		v = v[i+len(part):]
+	}
+	return strings.HasSuffix(v, last)
+}
+
+// ParseFilter parses a filter in its string form, such as
+// "(&(objectClass=person)(mail=*))". The outer parentheses may be left
+// out of a single comparison.
+func ParseFilter(s string) (Filter, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("ldap: empty filter")
+	}
+	if !strings.HasPrefix(s, "(") {
+		s = "(" + s + ")"
+	}
+	f, rest, err := parseFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("ldap: unexpected %q after filter", rest)
+	}
+	return f, nil
+}
+
+func parseFilter(s string) (Filter, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", fmt.Errorf("ldap: expected ( at %q", s)
+	}
+	s = s[1:]
+	if s == "" {
+		return nil, "", errors.New("ldap: filter ends unexpectedly")
+	}
+
+	var f Filter
+	switch s[0] {
+	case '&', '|':
+		var list []Filter
+		rest := s[1:]
+		for strings.HasPrefix(rest, "(") {
+			sub, next, err := parseFilter(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			list = append(list, sub)
+			rest = next
+		}
+		if len(list) == 0 {
+			return nil, "", fmt.Errorf("ldap: %c needs at least one filter", s[0])
+		}
+		if s[0] == '&' {
+			f = And(list)
+		} else {
+			f = Or(list)
+		}
+		s = rest
+	case '!':
+		sub, rest, err := parseFilter(s[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		f, s = Not{Filter: sub}, rest
+	default:
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return nil, "", errors.New("ldap: missing )")
+		}
+		m, err := parseMatch(s[:end])
+		if err != nil {
+			return nil, "", err
+		}
+		f, s = m, s[end:]
+	}
+
+	if !strings.HasPrefix(s, ")") {
+		return nil, "", errors.New("ldap: missing )")
+	}
+	return f, s[1:], nil
+}
+
+func parseMatch(item string) (Match, error) {
+	i := strings.IndexByte(item, '=')
+	if i <= 0 {
+		return Match{}, fmt.Errorf("ldap: invalid comparison %q", item)
+	}
+	attr, op, raw := item[:i], Equal, item[i+1:]
+	if c := attr[len(attr)-1]; c == '>' || c == '<' || c == '~' {
+		attr, op = attr[:len(attr)-1], string(c)+"="
+	}
+	if attr == "" || strings.ContainsAny(attr, "()*\\ ") {
+		return Match{}, fmt.Errorf("ldap: invalid attribute in %q", item)
+	}
+
+	if op == Equal && raw == "*" {
+		return Match{Attr: attr, Op: Present}, nil
+	}
+	if op == Equal && strings.Contains(raw, "*") {
+		m := Match{Attr: attr, Op: Substrings, Value: raw}
+		for _, part := range strings.Split(raw, "*") {
+			value, err := unescape(part)
+			if err != nil {
+				return Match{}, err
+			}
+			m.parts = append(m.parts, value)
+		}
+		return m, nil
+	}
+	value, err := unescape(raw)
+	if err != nil {
+		return Match{}, err
+	}
+	return Match{Attr: attr, Op: op, Value: value}, nil
+}
+
+// unescape decodes the \XX hex escapes of a filter value.
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("ldap: invalid escape in %q", s)
+		}
+		n, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("ldap: invalid escape in %q", s)
+		}
+		b.WriteByte(byte(n))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// EscapeFilter escapes a value for use in a filter string.
+func EscapeFilter(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, `\%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// DecodeFilter decodes a filter from a search request.
+func DecodeFilter(e ber.Element) (Filter, error) {
+	switch e.Tag {
+	case tagAnd, tagOr:
+		children, err := e.Children()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]Filter, len(children))
+		for i, child := range children {
+			if list[i], err = DecodeFilter(child); err != nil {
+				return nil, err
+			}
+		}
+		if e.Tag == tagAnd {
+			return And(list), nil
+		}
+		return Or(list), nil
+	case tagNot:
+		child, _, err := ber.Parse(e.Content)
+		if err != nil {
+			return nil, err
+		}
+		sub, err := DecodeFilter(child)
+		if err != nil {
+			return nil, err
+		}
+		return Not{Filter: sub}, nil
+	case tagPresent:
+		return Match{Attr: e.Text(), Op: Present}, nil
+	case tagSubstrings:
+		children, err := e.Children()
+		if err != nil || len(children) != 2 {
+			return nil, errors.New("ldap: invalid substrings filter")
+		}
+		pieces, err := children[1].Children()
+		if err != nil {
+			return nil, err
+		}
+		parts := []string{""}
+		for _, piece := range pieces {
+			switch piece.Tag & 0x1f {
+			case 0:
+				parts[0] = piece.Text()
+			case 1:
+				parts = append(parts, piece.Text())
+			}
+		}
+		parts = append(parts, "")
+		for _, piece := range pieces {
+			if piece.Tag&0x1f == 2 {
+				parts[len(parts)-1] = piece.Text()
+			}
+		}
+		return Match{Attr: children[0].Text(), Op: Substrings, Value: strings.Join(parts, "*"), parts: parts}, nil
+	}
+	for op, tag := range matchTags {
+		if e.Tag != tag {
+			continue
+		}
+		children, err := e.Children()
+		if err != nil || len(children) != 2 {
+			return nil, errors.New("ldap: invalid comparison")
+		}
+		return Match{Attr: children[0].Text(), Op: op, Value: children[1].Text()}, nil
+	}
+	return nil, fmt.Errorf("ldap: unsupported filter choice 0x%02x", e.Tag)
+}
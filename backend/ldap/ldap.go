@@ -0,0 +1,444 @@
+// Package ldap is a small LDAPv3 client (RFC 4511) with what directory
+// sync needs: simple binds and subtree searches, paged so that servers
+// capping result sizes, such as Active Directory, return every entry.
+package ldap
+
+import (
+	"bufio"
+	"coaching-backend/ldap/ber"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Protocol operation tags, exported for the stand-in server in ldaptest.
+const (
+	OpBindRequest     = ber.Application | ber.Constructed | 0
+	OpBindResponse    = ber.Application | ber.Constructed | 1
+	OpUnbindRequest   = ber.Application | 2
+	OpSearchRequest   = ber.Application | ber.Constructed | 3
+	OpSearchEntry     = ber.Application | ber.Constructed | 4
+	OpSearchDone      = ber.Application | ber.Constructed | 5
+	OpSearchReference = ber.Application | ber.Constructed | 19
+	OpExtendedResult  = ber.Application | ber.Constructed | 24
+
+	// TagControls marks the controls of a message.
+	TagControls = ber.Context | ber.Constructed | 0
+	// TagSimpleAuth marks the password of a simple bind.
+	TagSimpleAuth = ber.Context | 0
+)
+
+// PagedResultsOID identifies the simple paged results control (RFC 2696).
+const PagedResultsOID = "1.2.840.113556.1.4.319"
+
+// Result codes.
+const (
+	ResultSuccess            = 0
+	ResultOperationsError    = 1
+	ResultProtocolError      = 2
+	ResultSizeLimitExceeded  = 4
+	ResultNoSuchObject       = 32
+	ResultInvalidCredentials = 49
+	ResultUnwillingToPerform = 53
+)
+
+// Search scopes.
+const (
+	ScopeBase     = 0
+	ScopeOneLevel = 1
+	ScopeSubtree  = 2
+)
+
+// maxMessage caps the size of a message read from the server.
+const maxMessage = 16 << 20
+
+// DefaultTimeout bounds each request when the context has no deadline.
+const DefaultTimeout = 30 * time.Second
+
+// Error is a result the server reported as unsuccessful.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", e.Code)
+	}
+	return fmt.Sprintf("ldap: %s (result code %d)", e.Message, e.Code)
+}
+
+// Entry is a directory entry. Attribute names keep the case the server
+// sent; Values looks them up in any case.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Values returns the values of attr, matching its name case-insensitively.
+func (e *Entry) Values(attr string) []string {
+	if values, ok := e.Attributes[attr]; ok {
+		return values
+	}
+	for name, values := range e.Attributes {
+		if strings.EqualFold(name, attr) {
+			return values
+		}
+	}
+	return nil
+}
+
+// Value returns the first value of attr, or "".
+func (e *Entry) Value(attr string) string {
+	if values := e.Values(attr); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Conn is a connection to a directory server. It is not safe for
+// concurrent use.
+type Conn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	nextID int64
+}
+
+// Dial connects to an ldap:// or ldaps:// URL; the port defaults to 389
+// and 636. tlsConfig configures ldaps and may be nil.
+func Dial(ctx context.Context, rawURL string, tlsConfig *tls.Config) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: invalid URL: %w", err)
+	}
+	host, port := u.Hostname(), u.Port()
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		if port == "" {
+			port = "389"
+		}
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	case "ldaps":
+		if port == "" {
+			port = "636"
+		}
+		cfg := &tls.Config{}
+		if tlsConfig != nil {
+			cfg = tlsConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = host
+		}
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: cfg}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	default:
+		return nil, fmt.Errorf("ldap: unsupported URL scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{conn: conn, r: bufio.NewReader(conn), nextID: 1}, nil
+}
+
+// Close unbinds and closes the connection.
+func (c *Conn) Close() error {
+	c.conn.SetDeadline(time.Now().Add(time.Second))
+	c.send(ber.Encode(OpUnbindRequest))
+	return c.conn.Close()
+}
+
+// Bind authenticates with a simple bind. An empty password is refused,
+// as servers treat it as an unauthenticated bind that always succeeds.
+func (c *Conn) Bind(ctx context.Context, dn, password string) error {
+	if password == "" {
+		return errors.New("ldap: bind password is empty")
+	}
+	defer c.deadline(ctx)()
+
+	id, err := c.send(ber.Encode(OpBindRequest,
+		ber.Integer(ber.TagInteger, 3),
+		ber.String(ber.TagOctetString, dn),
+		ber.String(TagSimpleAuth, password),
+	))
+	if err != nil {
+		return err
+	}
+	op, _, err := c.receive(id)
+	if err != nil {
+		return err
+	}
+	if op.Tag != OpBindResponse {
+		return fmt.Errorf("ldap: unexpected response 0x%02x to bind", op.Tag)
+	}
+	return resultError(op)
+}
+
+// SearchRequest is a subtree search.
+type SearchRequest struct {
+	BaseDN     string
+	Filter     Filter
+	Attributes []string
+	// PageSize, if positive, asks for results in pages of this many
+	// entries with the paged results control.
+	PageSize int
+}
+
+// Search returns every entry below BaseDN that matches the filter.
+// Referrals are not followed.
+func (c *Conn) Search(ctx context.Context, req SearchRequest) ([]*Entry, error) {
+	defer c.deadline(ctx)()
+
+	attrs := make([][]byte, len(req.Attributes))
+	for i, attr := range req.Attributes {
+		attrs[i] = ber.String(ber.TagOctetString, attr)
+	}
+	op := ber.Encode(OpSearchRequest,
+		ber.String(ber.TagOctetString, req.BaseDN),
+		ber.Integer(ber.TagEnumerated, ScopeSubtree),
+		ber.Integer(ber.TagEnumerated, 0), // never dereference aliases
+		ber.Integer(ber.TagInteger, 0),    // no size limit
+		ber.Integer(ber.TagInteger, 0),    // no time limit
+		ber.Boolean(false),
+		req.Filter.encode(),
+		ber.Sequence(attrs...),
+	)
+
+	var entries []*Entry
+	cookie := ""
+	for {
+		var controls [][]byte
+		if req.PageSize > 0 {
+			controls = append(controls, PagedResultsControl(req.PageSize, cookie))
+		}
+		id, err := c.send(op, controls...)
+		if err != nil {
+			return nil, err
+		}
+		cookie = ""
+		for {
+			resp, responseControls, err := c.receive(id)
+			if err != nil {
+				return nil, err
+			}
+			switch resp.Tag {
+			case OpSearchEntry:
+				entry, err := parseEntry(resp)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, entry)
+				continue
+			case OpSearchReference:
+				continue
+			case OpSearchDone:
+			default:
+				return nil, fmt.Errorf("ldap: unexpected response 0x%02x to search", resp.Tag)
+			}
+
+			if err := resultError(resp); err != nil {
+				return nil, err
+			}
+			for _, control := range responseControls {
+				if oid, value, ok := ParseControl(control); ok && oid == PagedResultsOID {
+					_, cookie, _ = ParsePagedResults(value)
+				}
+			}
+			break
+		}
+		if cookie == "" {
+			return entries, nil
+		}
+	}
+}
+
+// deadline applies the context's deadline, or DefaultTimeout, to the
+// connection and returns a func that clears it.
+func (c *Conn) deadline(ctx context.Context) func() {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(DefaultTimeout)
+	}
+	c.conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { c.conn.SetDeadline(time.Now()) })
+	return func() {
+		stop()
+		c.conn.SetDeadline(time.Time{})
+	}
+}
+
+func (c *Conn) send(op []byte, controls ...[]byte) (int64, error) {
+	id := c.nextID
+	c.nextID++
+	parts := [][]byte{ber.Integer(ber.TagInteger, id), op}
+	if len(controls) > 0 {
+		parts = append(parts, ber.Encode(TagControls, controls...))
+	}
+	if _, err := c.conn.Write(ber.Sequence(parts...)); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// receive reads the next response to the request with id.
+func (c *Conn) receive(id int64) (ber.Element, []ber.Element, error) {
+	for {
+		msgID, op, controls, err := ReadMessage(c.r)
+		if err != nil {
+			return ber.Element{}, nil, err
+		}
+		if msgID == 0 && op.Tag == OpExtendedResult {
+			// An unsolicited notice, such as of disconnection.
+			if err := resultError(op); err != nil {
+				return ber.Element{}, nil, err
+			}
+			return ber.Element{}, nil, errors.New("ldap: server sent a notice of disconnection")
+		}
+		if msgID == id {
+			return op, controls, nil
+		}
+	}
+}
+
+// ReadMessage reads an LDAPMessage: its ID, protocol operation and
+// controls.
+func ReadMessage(r *bufio.Reader) (int64, ber.Element, []ber.Element, error) {
+	msg, err := ber.Read(r, maxMessage)
+	if err != nil {
+		return 0, ber.Element{}, nil, err
+	}
+	parts, err := msg.Children()
+	if err != nil || msg.Tag != ber.TagSequence || len(parts) < 2 {
+		return 0, ber.Element{}, nil, errors.New("ldap: malformed message")
+	}
+	id, err := parts[0].Int()
+	if err != nil {
+		return 0, ber.Element{}, nil, err
+	}
+	var controls []ber.Element
+	if len(parts) > 2 && parts[2].Tag == TagControls {
+		if controls, err = parts[2].Children(); err != nil {
+			return 0, ber.Element{}, nil, err
+		}
+	}
+	return id, parts[1], controls, nil
+}
+
+// Result encodes an LDAPResult with the operation tag op.
+func Result(op byte, code int, message string) []byte {
+	return ber.Encode(op,
+		ber.Integer(ber.TagEnumerated, int64(code)),
+		ber.String(ber.TagOctetString, ""),
+		ber.String(ber.TagOctetString, message),
+	)
+}
+
+func resultError(op ber.Element) error {
+	parts, err := op.Children()
+	if err != nil || len(parts) < 3 {
+		return errors.New("ldap: malformed result")
+	}
+	code, err := parts[0].Int()
+	if err != nil {
+		return err
+	}
+	if code == ResultSuccess {
+		return nil
+	}
+	return &Error{Code: int(code), Message: parts[2].Text()}
+}
+
+func parseEntry(op ber.Element) (*Entry, error) {
+	parts, err := op.Children()
+	if err != nil || len(parts) != 2 {
+		return nil, errors.New("ldap: malformed search entry")
+	}
+	attrs, err := parts[1].Children()
+	if err != nil {
+		return nil, err
+	}
+	entry := &Entry{DN: parts[0].Text(), Attributes: make(map[string][]string, len(attrs))}
+	for _, attr := range attrs {
+		pair, err := attr.Children()
+		if err != nil || len(pair) != 2 {
+			return nil, errors.New("ldap: malformed attribute")
+		}
+		values, err := pair[1].Children()
+		if err != nil {
+			return nil, err
+		}
+		name := pair[0].Text()
+		for _, v := range values {
+			entry.Attributes[name] = append(entry.Attributes[name], v.Text())
+		}
+	}
+	return entry, nil
+}
+
+// EncodeEntry encodes a search result entry with only the attributes
+// listed, or all of them when attrs is empty.
+func EncodeEntry(e *Entry, attrs []string) []byte {
+	var encoded [][]byte
+	add := func(name string, values []string) {
+		vals := make([][]byte, len(values))
+		for i, v := range values {
+			vals[i] = ber.String(ber.TagOctetString, v)
+		}
+		encoded = append(encoded, ber.Sequence(ber.String(ber.TagOctetString, name), ber.Encode(ber.TagSet, vals...)))
+	}
+	if len(attrs) == 0 {
+		for name, values := range e.Attributes {
+			add(name, values)
+		}
+	}
+	for _, attr := range attrs {
+		if values := e.Values(attr); len(values) > 0 {
+			add(attr, values)
+		}
+	}
+	return ber.Encode(OpSearchEntry, ber.String(ber.TagOctetString, e.DN), ber.Sequence(encoded...))
+}
+
+// PagedResultsControl encodes the paged results control asking for pages
+// of size entries, continuing after cookie.
+func PagedResultsControl(size int, cookie string) []byte {
+	value := ber.Sequence(ber.Integer(ber.TagInteger, int64(size)), ber.String(ber.TagOctetString, cookie))
+	return ber.Sequence(ber.String(ber.TagOctetString, PagedResultsOID), ber.Encode(ber.TagOctetString, value))
+}
+
+// ParseControl decodes a control's type and value.
+func ParseControl(control ber.Element) (oid string, value []byte, ok bool) {
+	parts, err := control.Children()
+	if err != nil || len(parts) == 0 {
+		return "", nil, false
+	}
+	for _, part := range parts[1:] {
+		if part.Tag == ber.TagOctetString {
+			value = part.Content
+		}
+	}
+	return parts[0].Text(), value, true
+}
+
+// ParsePagedResults decodes the value of a paged results control.
+func ParsePagedResults(value []byte) (size int, cookie string, err error) {
+	seq, _, err := ber.Parse(value)
+	if err != nil {
+		return 0, "", err
+	}
+	parts, err := seq.Children()
+	if err != nil || len(parts) != 2 {
+		return 0, "", errors.New("ldap: malformed paged results control")
+	}
+	n, err := parts[0].Int()
+	if err != nil {
+		return 0, "", err
+	}
+	return int(n), parts[1].Text(), nil
+}
@@ -0,0 +1,135 @@
+package ldap_test
+
+import (
+	"coaching-backend/ldap"
+	"coaching-backend/ldap/ldaptest"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	bindDN   = "cn=sync,ou=services,dc=example,dc=com"
+	password = "s3cret"
+)
+
+func TestParseFilter(t *testing.T) {
+	entry := ldaptest.NewEntry("uid=ada,ou=people,dc=example,dc=com",
+		"objectClass", "person", "objectClass", "inetOrgPerson",
+		"mail", "Ada@Example.com", "cn", "Ada (Countess) Lovelace", "employeeNumber", "42")
+
+	for filter, matches := range map[string]bool{
+		"(objectClass=person)":                       true,
+		"objectClass=PERSON":                         true,
+		"(&(objectClass=person)(mail=*))":            true,
+		"(&(objectClass=person)(telephoneNumber=*))": false,
+		"(|(mail=grace@example.com)(mail=ada@*))":    true,
+		"(!(mail=ada@example.com))":                  false,
+		`(cn=Ada \28Countess\29*)`:                   true,
+		"(cn=*count*love*)":                          true,
+		"(cn=*lace)":                                 true,
+		"(cn=Grace*)":                                false,
+		"(employeeNumber>=40)":                       true,
+		"(employeeNumber<=40)":                       false,
+	} {
+		f, err := ldap.ParseFilter(filter)
+		require.NoError(t, err, filter)
+		assert.Equal(t, matches, f.Matches(entry), filter)
+	}
+
+	for _, filter := range []string{"", "(mail=a", "(&)", "(=a)", "(mail=\\zz)", "(mail=a))", "(cn=a\\2)"} {
+		_, err := ldap.ParseFilter(filter)
+		assert.Error(t, err, filter)
+	}
+
+	assert.Equal(t, `a\2a\28b\29\5c`, ldap.EscapeFilter(`a*(b)\`))
+}
+
+func TestSearch(t *testing.T) {
+	var entries []*ldap.Entry
+	for i := 1; i <= 25; i++ {
+		entries = append(entries, ldaptest.NewEntry(fmt.Sprintf("uid=user%d,ou=people,dc=example,dc=com", i),
+			"objectClass", "person", "mail", fmt.Sprintf("user%d@example.com", i), "cn", fmt.Sprintf("User %d", i)))
+	}
+	entries = append(entries, ldaptest.NewEntry("cn=admins,ou=groups,dc=example,dc=com", "objectClass", "groupOfNames"))
+	server := ldaptest.NewServer(bindDN, password, entries...)
+	server.MaxResults = 10
+	defer server.Close()
+	ctx := context.Background()
+
+	connect := func(t *testing.T) *ldap.Conn {
+		conn, err := ldap.Dial(ctx, server.URL, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		require.NoError(t, conn.Bind(ctx, bindDN, password))
+		return conn
+	}
+	people, _ := ldap.ParseFilter("(objectClass=person)")
+
+	t.Run("Wrong Password", func(t *testing.T) {
+		conn, err := ldap.Dial(ctx, server.URL, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		err = conn.Bind(ctx, bindDN, "wrong")
+		var ldapErr *ldap.Error
+		require.ErrorAs(t, err, &ldapErr)
+		assert.Equal(t, ldap.ResultInvalidCredentials, ldapErr.Code)
+		assert.Error(t, conn.Bind(ctx, bindDN, ""), "empty passwords are refused")
+	})
+
+	t.Run("Pages Through Every Entry", func(t *testing.T) {
+		before := server.Searches()
+		entries, err := connect(t).Search(ctx, ldap.SearchRequest{
+			BaseDN:     "ou=people,dc=example,dc=com",
+			Filter:     people,
+			Attributes: []string{"mail", "CN"},
+			PageSize:   10,
+		})
+		require.NoError(t, err)
+		require.Len(t, entries, 25)
+		assert.Equal(t, 3, server.Searches()-before)
+		assert.Equal(t, "user1@example.com", entries[0].Value("MAIL"))
+		assert.Equal(t, "User 1", entries[0].Value("cn"))
+		assert.Empty(t, entries[0].Values("objectClass"), "only requested attributes are returned")
+	})
+
+	t.Run("Size Limit Without Paging", func(t *testing.T) {
+		_, err := connect(t).Search(ctx, ldap.SearchRequest{BaseDN: "dc=example,dc=com", Filter: people})
+		var ldapErr *ldap.Error
+		require.ErrorAs(t, err, &ldapErr)
+		assert.Equal(t, ldap.ResultSizeLimitExceeded, ldapErr.Code)
+	})
+
+	t.Run("Filters On The Server", func(t *testing.T) {
+		f, _ := ldap.ParseFilter("(|(mail=user2@example.com)(cn=user 1*))")
+		entries, err := connect(t).Search(ctx, ldap.SearchRequest{BaseDN: "dc=example,dc=com", Filter: f, PageSize: 100})
+		require.NoError(t, err)
+		assert.Len(t, entries, 12, "user2 and user1, user10 to user19")
+	})
+}
+
+func TestFilterEncoding(t *testing.T) {
+	for _, filter := range []string{
+		"(&(objectClass=person)(!(mail=*))(|(cn~=ada)(sn>=b)(sn<=c)))",
+		"(cn=a*b*c)", "(cn=*b*)", "(cn=a*)", "(cn=*c)",
+	} {
+		f, err := ldap.ParseFilter(filter)
+		require.NoError(t, err)
+		entry := ldaptest.NewEntry("cn=x", "cn", "abc", "objectClass", "person", "sn", "b")
+
+		// Round-trip through a search request the way the server sees it.
+		server := ldaptest.NewServer(bindDN, password, entry)
+		conn, err := ldap.Dial(context.Background(), server.URL, nil)
+		require.NoError(t, err)
+		require.NoError(t, conn.Bind(context.Background(), bindDN, password))
+		found, err := conn.Search(context.Background(), ldap.SearchRequest{Filter: f})
+		require.NoError(t, err, filter)
+		assert.Equal(t, f.Matches(entry), len(found) == 1, filter)
+		conn.Close()
+		server.Close()
+	}
+}
@@ -0,0 +1,205 @@
+// Package ldaptest runs an in-process directory server for tests. It
+// speaks enough LDAPv3 for the ldap client: simple binds and subtree
+// searches with the paged results control, over entries held in memory.
+package ldaptest
+
+import (
+	"bufio"
+	"coaching-backend/ldap"
+	"coaching-backend/ldap/ber"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server is a directory of Entries that accepts one bind DN and password.
+// Like Active Directory, it refuses to return more than MaxResults
+// entries to a search unless they are paged.
+type Server struct {
+	URL        string
+	BindDN     string
+	Password   string
+	MaxResults int
+
+	lis net.Listener
+
+	mu       sync.Mutex
+	entries  []*ldap.Entry
+	searches int
+}
+
+// NewServer starts a server on a loopback port. Close it when done.
+func NewServer(bindDN, password string, entries ...*ldap.Entry) *Server {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("ldaptest: " + err.Error())
+	}
+	s := &Server{
+		URL:        "ldap://" + lis.Addr().String(),
+		BindDN:     bindDN,
+		Password:   password,
+		MaxResults: 1000,
+		lis:        lis,
+		entries:    entries,
+	}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *Server) Close() {
+	s.lis.Close()
+}
+
+// SetEntries replaces the directory's entries.
+func (s *Server) SetEntries(entries ...*ldap.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = entries
+}
+
+// Searches reports how many search requests the server has answered,
+// counting each page.
+func (s *Server) Searches() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.searches
+}
+
+// NewEntry builds an entry from alternating attribute names and values;
+// a name given several times has several values.
+func NewEntry(dn string, attrs ...string) *ldap.Entry {
+	e := &ldap.Entry{DN: dn, Attributes: map[string][]string{}}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		e.Attributes[attrs[i]] = append(e.Attributes[attrs[i]], attrs[i+1])
+	}
+	return e
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	bound := false
+	for {
+		id, op, controls, err := ldap.ReadMessage(r)
+		if err != nil {
+			return
+		}
+		reply := func(op []byte, controls ...[]byte) {
+			parts := [][]byte{ber.Integer(ber.TagInteger, id), op}
+			if len(controls) > 0 {
+				parts = append(parts, ber.Encode(ldap.TagControls, controls...))
+			}
+			conn.Write(ber.Sequence(parts...))
+		}
+
+		switch op.Tag {
+		case ldap.OpUnbindRequest:
+			return
+		case ldap.OpBindRequest:
+			bound = s.bind(op)
+			if bound {
+				reply(ldap.Result(ldap.OpBindResponse, ldap.ResultSuccess, ""))
+			} else {
+				reply(ldap.Result(ldap.OpBindResponse, ldap.ResultInvalidCredentials, "invalid credentials"))
+			}
+		case ldap.OpSearchRequest:
+			if !bound {
+				reply(ldap.Result(ldap.OpSearchDone, ldap.ResultOperationsError, "bind required"))
+				continue
+			}
+			s.search(op, controls, reply)
+		default:
+			reply(ldap.Result(ldap.OpExtendedResult, ldap.ResultProtocolError, "unsupported operation"))
+			return
+		}
+	}
+}
+
+func (s *Server) bind(op ber.Element) bool {
+	parts, err := op.Children()
+	if err != nil || len(parts) != 3 || parts[2].Tag != ldap.TagSimpleAuth {
+		return false
+	}
+	return strings.EqualFold(parts[1].Text(), s.BindDN) && parts[2].Text() == s.Password
+}
+
+func (s *Server) search(op ber.Element, controls []ber.Element, reply func([]byte, ...[]byte)) {
+	parts, err := op.Children()
+	if err != nil || len(parts) != 8 {
+		reply(ldap.Result(ldap.OpSearchDone, ldap.ResultProtocolError, "malformed search"))
+		return
+	}
+	base := strings.ToLower(parts[0].Text())
+	filter, err := ldap.DecodeFilter(parts[6])
+	if err != nil {
+		reply(ldap.Result(ldap.OpSearchDone, ldap.ResultProtocolError, err.Error()))
+		return
+	}
+	attrElements, _ := parts[7].Children()
+	var attrs []string
+	for _, a := range attrElements {
+		attrs = append(attrs, a.Text())
+	}
+
+	pageSize, offset, paged := 0, 0, false
+	for _, control := range controls {
+		if oid, value, ok := ldap.ParseControl(control); ok && oid == ldap.PagedResultsOID {
+			var cookie string
+			if pageSize, cookie, err = ldap.ParsePagedResults(value); err != nil {
+				reply(ldap.Result(ldap.OpSearchDone, ldap.ResultProtocolError, err.Error()))
+				return
+			}
+			offset, _ = strconv.Atoi(cookie)
+			paged = true
+		}
+	}
+
+	s.mu.Lock()
+	s.searches++
+	var matched []*ldap.Entry
+	for _, e := range s.entries {
+		dn := strings.ToLower(e.DN)
+		if (dn == base || strings.HasSuffix(dn, ","+base) || base == "") && filter.Matches(e) {
+			matched = append(matched, e)
+		}
+	}
+	s.mu.Unlock()
+
+	if !paged {
+		if len(matched) > s.MaxResults {
+			for _, e := range matched[:s.MaxResults] {
+				reply(ldap.EncodeEntry(e, attrs))
+			}
+			reply(ldap.Result(ldap.OpSearchDone, ldap.ResultSizeLimitExceeded, "size limit exceeded"))
+			return
+		}
+		for _, e := range matched {
+			reply(ldap.EncodeEntry(e, attrs))
+		}
+		reply(ldap.Result(ldap.OpSearchDone, ldap.ResultSuccess, ""))
+		return
+	}
+
+	end := min(offset+pageSize, len(matched))
+	if pageSize <= 0 || pageSize > s.MaxResults {
+		end = min(offset+s.MaxResults, len(matched))
+	}
+	offset = min(offset, len(matched))
+	for _, e := range matched[offset:end] {
+		reply(ldap.EncodeEntry(e, attrs))
+	}
+	cookie := ""
+	if end < len(matched) {
+		cookie = strconv.Itoa(end)
+	}
+	reply(ldap.Result(ldap.OpSearchDone, ldap.ResultSuccess, ""), ldap.PagedResultsControl(0, cookie))
+}
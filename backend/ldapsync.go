@@ -0,0 +1,94 @@
+package main
+
+import (
+	"coaching-backend/config"
+	"coaching-backend/directory"
+	"coaching-backend/services"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// directoryConfig is the directory sync the ldap settings describe. The
+// directory is not synced when its URL is empty.
+func directoryConfig(cfg config.LDAP) directory.Config {
+	return directory.Config{
+		URL:                  cfg.URL,
+		BindDN:               cfg.BindDN,
+		BindPassword:         cfg.BindPassword,
+		BaseDN:               cfg.BaseDN,
+		UserFilter:           cfg.UserFilter,
+		EmailAttribute:       cfg.EmailAttribute,
+		NameAttribute:        cfg.NameAttribute,
+		TeamAttribute:        cfg.TeamAttribute,
+		GroupBaseDN:          cfg.GroupBaseDN,
+		GroupFilter:          cfg.GroupFilter,
+		GroupNameAttribute:   cfg.GroupNameAttribute,
+		GroupMemberAttribute: cfg.GroupMemberAttribute,
+		CreateTeams:          cfg.CreateTeams,
+		Interval:             cfg.SyncInterval,
+	}
+}
+
+// runDirectorySync implements "coaching-backend ldap-sync", which syncs
+// members from the directory once, as the server does every
+// LDAP_SYNC_INTERVAL, and prints what changed. It returns the process exit
+// code.
+func runDirectorySync(ctx context.Context, args []string, stdout, stderr io.Writer, cfg directory.Config, connect func() *services.Service) int {
+	flags := flag.NewFlagSet("ldap-sync", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: coaching-backend ldap-sync [-dry-run]")
+		fmt.Fprintln(stderr, "Creates, updates and deactivates members to match the LDAP directory set by the LDAP_* variables.")
+		flags.PrintDefaults()
+	}
+	dryRun := flags.Bool("dry-run", false, "only report what the sync would change")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	if cfg.URL == "" {
+		fmt.Fprintln(stderr, "LDAP_URL is not set")
+		return 2
+	}
+
+	report, err := directory.NewSyncer(cfg, connect()).Sync(ctx, *dryRun)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		var serviceErr *services.Error
+		if errors.As(err, &serviceErr) {
+			for _, field := range serviceErr.Fields {
+				fmt.Fprintf(stderr, "  %s %s\n", field.Field, field.Message)
+			}
+		}
+		return 1
+	}
+
+	for _, skip := range report.Skipped {
+		fmt.Fprintf(stderr, "skipped %s: %s\n", skip.DN, skip.Reason)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintln(stderr, warning)
+	}
+	summary, teamCreated := "Synced", "Created team"
+	if report.DryRun {
+		summary, teamCreated = "Dry run", "Would create team"
+	}
+	fmt.Fprintf(stdout, "%s: %d created, %d updated, %d deactivated, %d unchanged\n",
+		summary, report.Created, report.Updated, report.Deactivated, report.Unchanged)
+	for _, team := range report.TeamsCreated {
+		fmt.Fprintf(stdout, "%s %s\n", teamCreated, team)
+	}
+	for _, change := range report.Changes {
+		fmt.Fprintf(stdout, "%s %s\n", change.Action, change.Email)
+		for _, field := range change.Fields {
+			fmt.Fprintf(stdout, "  %s: %q -> %q\n", field.Field, field.From, field.To)
+		}
+	}
+	return 0
+}
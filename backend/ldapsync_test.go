@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"coaching-backend/config"
+	"coaching-backend/directory"
+	"coaching-backend/ldap/ldaptest"
+	"coaching-backend/models"
+	"coaching-backend/services"
+	"coaching-backend/tests/testutils"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectorySyncCommand(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	connected := false
+	connect := func() *services.Service {
+		connected = true
+		return services.New(db)
+	}
+
+	server := ldaptest.NewServer("cn=sync,dc=example,dc=com", "secret",
+		ldaptest.NewEntry("uid=ada,ou=people,dc=example,dc=com", "objectClass", "person", "mail", "ada@example.com", "cn", "Ada Lovelace", "department", "Platform"),
+		ldaptest.NewEntry("uid=bob,ou=people,dc=example,dc=com", "objectClass", "person", "mail", "bob", "cn", "Bob"),
+	)
+	defer server.Close()
+	cfg := directory.Config{
+		URL:           server.URL,
+		BindDN:        server.BindDN,
+		BindPassword:  server.Password,
+		BaseDN:        "dc=example,dc=com",
+		TeamAttribute: "department",
+		CreateTeams:   true,
+	}
+	run := func(cfg directory.Config, args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runDirectorySync(context.Background(), args, &stdout, &stderr, cfg, connect)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("Usage", func(t *testing.T) {
+		code, _, stderr := run(cfg, "extra")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "Usage: coaching-backend ldap-sync")
+
+		code, _, stderr = run(directory.Config{})
+		assert.Equal(t, 2, code)
+		assert.Equal(t, "LDAP_URL is not set\n", stderr)
+		assert.False(t, connected, "bad arguments do not connect to the database")
+	})
+
+	t.Run("Dry Run", func(t *testing.T) {
+		code, stdout, stderr := run(cfg, "-dry-run")
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "Dry run: 1 created, 0 updated, 0 deactivated, 0 unchanged\n"+
+			"Would create team Platform\n"+
+			"create ada@example.com\n"+
+			"  name: \"\" -> \"Ada Lovelace\"\n"+
+			"  team: \"\" -> \"Platform\"\n", stdout)
+		assert.Equal(t, "skipped uid=bob,ou=people,dc=example,dc=com: email must be a valid email address\n", stderr)
+
+		var count int64
+		db.Model(&models.TeamMember{}).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		code, stdout, _ := run(cfg)
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout, "Synced: 1 created, 0 updated, 0 deactivated, 0 unchanged\nCreated team Platform\n")
+
+		var member models.TeamMember
+		require.NoError(t, db.Preload("Team").Where("email = ?", "ada@example.com").Take(&member).Error)
+		assert.Equal(t, "Platform", member.Team.Name)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		bad := cfg
+		bad.BindPassword = "wrong"
+		code, _, stderr := run(bad)
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "invalid credentials")
+	})
+}
+
+func TestDirectoryConfig(t *testing.T) {
+	t.Setenv("LDAP_URL", "ldaps://ldap.example.com")
+	t.Setenv("LDAP_TEAM_ATTRIBUTE", "department")
+	t.Setenv("LDAP_CREATE_TEAMS", "true")
+	t.Setenv("LDAP_SYNC_INTERVAL", "15m")
+
+	cfg := directoryConfig(loadConfig(t).LDAP)
+	assert.Equal(t, "ldaps://ldap.example.com", cfg.URL)
+	assert.Equal(t, "department", cfg.TeamAttribute)
+	assert.True(t, cfg.CreateTeams)
+	assert.Equal(t, 15*time.Minute, cfg.Interval)
+
+	t.Setenv("LDAP_SYNC_INTERVAL", "hourly")
+	_, err := config.Load(nil, io.Discard)
+	assert.EqualError(t, err, `LDAP_SYNC_INTERVAL: invalid duration "hourly"`)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"coaching-backend/config"
+	"coaching-backend/handlers"
+	"coaching-backend/ratelimit"
+	"fmt"
+)
+
+// rateLimitConfig builds the limits the rate_limit settings describe.
+// Buckets are kept in memory unless RedisURL names a server to share them
+// on.
+func rateLimitConfig(cfg config.RateLimit) (*handlers.RateLimits, error) {
+	limits := &handlers.RateLimits{}
+	var err error
+	if limits.IP, err = ratelimit.ParseLimit(cfg.IP); err != nil {
+		return nil, fmt.Errorf("rate_limit.ip: %w", err)
+	}
+	if limits.APIKey, err = ratelimit.ParseLimit(cfg.APIKey); err != nil {
+		return nil, fmt.Errorf("rate_limit.api_key: %w", err)
+	}
+	if limits.Groups, err = cfg.GroupLimits(); err != nil {
+		return nil, fmt.Errorf("rate_limit.groups: %w", err)
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RedisURL != "" {
+		client, err := ratelimit.NewClient(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("rate_limit.redis_url: %w", err)
+		}
+		store = ratelimit.NewRedisStore(client, "coaching:ratelimit:")
+	}
+	limits.Limiter = ratelimit.New(store)
+	return limits, nil
+}
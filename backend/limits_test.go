@@ -0,0 +1,64 @@
+package main
+
+import (
+	"coaching-backend/config"
+	"coaching-backend/ratelimit"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.Load(nil, io.Discard)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		limits, err := rateLimitConfig(loadConfig(t).RateLimit)
+		require.NoError(t, err)
+		assert.Equal(t, ratelimit.Limit{Requests: 600, Per: time.Minute}, limits.IP)
+		assert.Equal(t, ratelimit.Limit{Requests: 1200, Per: time.Minute}, limits.APIKey)
+		assert.Equal(t, ratelimit.Limit{Requests: 60, Per: time.Minute}, limits.Groups["feedback:write"])
+		assert.NotNil(t, limits.Limiter)
+	})
+
+	t.Run("Overrides", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_IP", "off")
+		t.Setenv("RATE_LIMIT_API_KEY", "50/1s")
+		t.Setenv("RATE_LIMIT_GROUPS", "members:read=10/1m, webhooks:write=5/1h")
+		t.Setenv("RATE_LIMIT_REDIS_URL", "redis://localhost:6379/1")
+
+		limits, err := rateLimitConfig(loadConfig(t).RateLimit)
+		require.NoError(t, err)
+		assert.False(t, limits.IP.Enabled())
+		assert.Equal(t, ratelimit.Limit{Requests: 50, Per: time.Second}, limits.APIKey)
+		assert.Equal(t, map[string]ratelimit.Limit{
+			"members:read":   {Requests: 10, Per: time.Minute},
+			"webhooks:write": {Requests: 5, Per: time.Hour},
+		}, limits.Groups)
+	})
+
+	t.Run("No Group Limits", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_GROUPS", "off")
+		limits, err := rateLimitConfig(loadConfig(t).RateLimit)
+		require.NoError(t, err)
+		assert.Empty(t, limits.Groups)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := rateLimitConfig(config.RateLimit{Groups: []string{"feedback=10/1m"}})
+		assert.EqualError(t, err, `rate_limit.groups: "feedback=10/1m" is not group:read=N/period or group:write=N/period`)
+
+		_, err = rateLimitConfig(config.RateLimit{IP: "lots"})
+		assert.ErrorContains(t, err, "rate_limit.ip")
+
+		_, err = rateLimitConfig(config.RateLimit{RedisURL: "localhost:6379"})
+		assert.ErrorContains(t, err, "rate_limit.redis_url")
+	})
+}
@@ -1,77 +1,236 @@
 package main
 
 import (
+	"coaching-backend/blob"
+	"coaching-backend/chatops"
+	"coaching-backend/config"
 	"coaching-backend/database"
+	"coaching-backend/digest"
+	"coaching-backend/directory"
+	"coaching-backend/events"
+	"coaching-backend/grpcapi"
 	"coaching-backend/handlers"
+	"coaching-backend/notify"
+	"coaching-backend/openapi"
+	"coaching-backend/outbox"
+	"coaching-backend/problem"
+	"coaching-backend/services"
+	"coaching-backend/targets"
+	"coaching-backend/webhooks"
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net"
+	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	database.Connect()
+	if len(os.Args) > 1 {
+		load := func() *config.Config {
+			cfg, err := config.Load(nil, os.Stderr)
+			if err != nil {
+				log.Fatalf("config: %v", err)
+			}
+			return cfg
+		}
+		connect := func() *services.Service {
+			cfg := load()
+			database.Connect(cfg.Database)
+			configure(cfg)
+			return services.New(database.DB)
+		}
+		code := -1
+		switch os.Args[1] {
+		case "import":
+			code = runImport(context.Background(), os.Args[2:], os.Stdout, os.Stderr, connect)
+		case "backup":
+			code = runBackup(context.Background(), os.Args[2:], os.Stdout, os.Stderr, connect)
+		case "restore":
+			code = runRestore(context.Background(), os.Args[2:], os.Stdout, os.Stderr, connect)
+		case "create-api-key":
+			code = runCreateAPIKey(context.Background(), os.Args[2:], os.Stdout, os.Stderr, connect)
+		case "ldap-sync":
+			code = runDirectorySync(context.Background(), os.Args[2:], os.Stdout, os.Stderr, directoryConfig(load().LDAP), connect)
+		}
+		if code >= 0 {
+			if err := database.Close(); err != nil {
+				log.Printf("Failed to close the database: %v", err)
+			}
+			os.Exit(code)
+		}
+	}
+
+	cfg, err := config.Load(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	// SIGTERM, as Kubernetes sends, or Ctrl-C shuts the server down
+	// gracefully; a second one stops it at once.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, func() {
+		log.Printf("Shutting down, waiting up to %s for requests and background work", cfg.Server.ShutdownTimeout)
+		stop()
+	})
+
+	database.Connect(cfg.Database)
 
-	r := gin.Default()
+	configure(cfg)
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("server.trusted_proxies: %v", err)
+	}
+	r.Use(gin.Logger(), gin.CustomRecovery(problem.Recovery), problem.Trace(), handlers.LimitBody(int64(cfg.Server.MaxBodySize)))
+	r.NoRoute(problem.NoRoute)
 
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://frontend:3000"},
+		AllowOrigins:     cfg.CORS.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Cache-Control", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Cache-Control", "X-Requested-With", "Last-Event-ID", problem.TraceHeader},
+		ExposeHeaders:    []string{"Content-Length", problem.TraceHeader, "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy"},
 		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		MaxAge:           cfg.CORS.MaxAge,
 	}))
 
-	api := r.Group("/api")
-	{
-		members := api.Group("/members")
-		{
-			members.POST("", handlers.CreateTeamMember)
-			members.GET("", handlers.GetTeamMembers)
-			members.GET("/:id", handlers.GetTeamMember)
-			members.PUT("/:id", handlers.UpdateTeamMember)
-			members.DELETE("/:id", handlers.DeleteTeamMember)
-		}
+	if cfg.Server.OpenAPIValidation {
+		r.Use(openapi.Validator(openapi.ValidatorConfig{
+			ValidateResponses: gin.Mode() != gin.ReleaseMode,
+		}))
+	}
 
-		teams := api.Group("/teams")
-		{
-			teams.POST("", handlers.CreateTeam)
-			teams.GET("", handlers.GetTeams)
-			teams.GET("/:id", handlers.GetTeam)
-			teams.PUT("/:id", handlers.UpdateTeam)
-			teams.DELETE("/:id", handlers.DeleteTeam)
-		}
+	limits, err := rateLimitConfig(cfg.RateLimit)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := registerRoutes(r, cfg, limits); err != nil {
+		log.Fatal(err)
+	}
 
-		assignments := api.Group("/assignments")
-		{
-			assignments.POST("", handlers.AssignMemberToTeam)
-			assignments.GET("", handlers.GetAssignments)
-			assignments.GET("/unassigned", handlers.GetUnassignedMembers)
-			assignments.DELETE("/member/:id", handlers.RemoveMemberFromTeam)
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.Server.Port))
+	if err != nil {
+		log.Fatalf("Failed to listen on port %d: %v", cfg.Server.Port, err)
+	}
+	lis, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.Server.GRPCPort))
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port %d: %v", cfg.Server.GRPCPort, err)
+	}
+	grpcServer := grpcapi.NewServer(services.New(database.DB), grpcapi.Config{
+		AuthRequired: cfg.Server.AuthRequired,
+		Limits:       limits,
+		Reflection:   cfg.Server.GRPCReflection,
+	})
+	go func() {
+		log.Printf("Starting gRPC server on port %d", cfg.Server.GRPCPort)
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("gRPC server failed: %v", err)
 		}
+	}()
 
-		feedback := api.Group("/feedback")
-		{
-			feedback.POST("", handlers.CreateFeedback)
-			feedback.GET("", handlers.GetFeedback)
-			feedback.GET("/:id", handlers.GetFeedbackByID)
-			feedback.PUT("/:id", handlers.UpdateFeedback)
-			feedback.DELETE("/:id", handlers.DeleteFeedback)
+	background := newWorkers()
+	dispatcher := webhooks.New(database.DB, webhooks.DefaultConfig)
+	background.Go(dispatcher.Run)
+
+	sinks := []outbox.Sink{outbox.BusSink{Bus: events.Default}, dispatcher}
+	if cfg.SMTP.Host != "" {
+		sender := &notify.SMTPSender{
+			Addr:     net.JoinHostPort(cfg.SMTP.Host, strconv.Itoa(cfg.SMTP.Port)),
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+			Timeout:  cfg.SMTP.Timeout,
 		}
+		notifyCfg := notify.DefaultConfig
+		notifyCfg.AppURL = cfg.App.URL
+		notifier := notify.New(database.DB, sender, notifyCfg)
+		background.Go(notifier.Run)
+		sinks = append(sinks, notifier)
+		background.Go(digest.NewScheduler(database.DB, sender, digest.DefaultConfig).Run)
+	}
+	if cfg.Chat.WebhookURL != "" {
+		sinks = append(sinks, chatops.NewPoster(cfg.Chat.WebhookURL))
 	}
+	if cfg.Outbox.LogEvents {
+		sinks = append(sinks, outbox.LogSink{})
+	}
+	if cfg.LDAP.URL != "" {
+		background.Go(directory.NewSyncer(directoryConfig(cfg.LDAP), services.New(database.DB)).Run)
+	}
+	background.Go(outbox.NewRelay(database.DB, outbox.DefaultConfig, sinks...).Run)
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok", "message": "Coaching API is running"})
-	})
+	server := &http.Server{
+		Handler:           r.Handler(),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	// Event streams never go idle, so they are ended for Shutdown to
+	// finish; clients reconnect to another instance.
+	server.RegisterOnShutdown(events.Default.Close)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// The gRPC server and the background workers stop alongside the HTTP
+	// server, within the same timeout.
+	grpcStopped := make(chan struct{})
+	workersStopped := make(chan bool, 1)
+	go func() {
+		<-ctx.Done()
+		stopGRPC(grpcServer, cfg.Server.ShutdownTimeout)
+		close(grpcStopped)
+	}()
+	go func() {
+		<-ctx.Done()
+		workersStopped <- background.Stop(cfg.Server.ShutdownTimeout)
+	}()
+
+	log.Printf("Starting server on port %d", cfg.Server.Port)
+	err = serve(ctx, server, listener, cfg.Server.ShutdownTimeout)
+	if ctx.Err() == nil {
+		log.Fatal(err)
+	}
+	if err != nil {
+		log.Printf("Shutdown: %v", err)
+	}
+	<-grpcStopped
+	if !<-workersStopped {
+		log.Printf("Shutdown: background workers did not stop within %s", cfg.Server.ShutdownTimeout)
+	}
+	if err := database.Close(); err != nil {
+		log.Printf("Shutdown: failed to close the database: %v", err)
 	}
+	log.Println("Server stopped")
+}
 
-	log.Printf("Starting server on port %s", port)
-	log.Fatal(r.Run(":" + port))
+// configure points the package defaults the services use, blob.Default
+// and targets.OrganizationName, at what cfg sets.
+func configure(cfg *config.Config) {
+	targets.OrganizationName = cfg.App.OrganizationName
+	switch cfg.Blob.Store {
+	case "s3":
+		endpoint := cfg.Blob.S3Endpoint
+		if endpoint == "" {
+			endpoint = "https://s3." + cfg.Blob.S3Region + ".amazonaws.com"
+		}
+		blob.Default = &blob.S3Store{
+			Endpoint:        endpoint,
+			Bucket:          cfg.Blob.S3Bucket,
+			Region:          cfg.Blob.S3Region,
+			AccessKeyID:     cfg.Blob.S3AccessKeyID,
+			SecretAccessKey: cfg.Blob.S3SecretAccessKey,
+		}
+	default:
+		blob.Default = &blob.FileStore{Root: cfg.Blob.Dir}
+	}
 }
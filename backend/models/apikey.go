@@ -0,0 +1,84 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, so a leaked key is easy to spot.
+const APIKeyPrefix = "ck_"
+
+// ScopeResources are what API key scopes grant access to. A scope names a
+// resource and an access level, like feedback:read or members:write;
+// write access includes read.
+var ScopeResources = []string{"members", "teams", "projects", "releases", "meetings", "assignments", "feedback", "webhooks", "events", "graphql", "api_keys"}
+
+// Scope access levels.
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+// APIKey lets a script or integration call the API as a service account,
+// limited to its scopes. Only a hash of the secret part is stored.
+type APIKey struct {
+	ID   uint32 `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" binding:"required,max=100" gorm:"type:varchar(100)"`
+	// Prefix is the public start of the key, which identifies it in the
+	// key list and in logs.
+	Prefix     string `json:"prefix" gorm:"type:varchar(32);uniqueIndex"`
+	SecretHash string `json:"-" gorm:"type:varchar(64)"`
+	// Token is the whole key. It is only returned when the key is created
+	// or rotated.
+	Token      string     `json:"token,omitempty" gorm:"-"`
+	Scopes     StringList `json:"scopes" binding:"required,min=1" gorm:"type:text"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RotatedAt  *time.Time `json:"rotated_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Scope returns the scope for an access level on a resource.
+func Scope(resource, access string) string {
+	return resource + ":" + access
+}
+
+// KnownScope reports whether scope names a resource and access level.
+func KnownScope(scope string) bool {
+	resource, access, _ := strings.Cut(scope, ":")
+	if access != AccessRead && access != AccessWrite {
+		return false
+	}
+	return slices.Contains(ScopeResources, resource)
+}
+
+// AllowsScope reports whether scopes grant access to resource. Write
+// access also grants read.
+func AllowsScope(scopes []string, resource, access string) bool {
+	if slices.Contains(scopes, Scope(resource, AccessWrite)) {
+		return true
+	}
+	return access == AccessRead && slices.Contains(scopes, Scope(resource, AccessRead))
+}
+
+// RoleScopes are the scopes of a signed-in member. Admins may do
+// anything; members may read everything but API keys, and give feedback,
+// also through GraphQL mutations, which check the feedback scope too.
+func RoleScopes(role string) []string {
+	var scopes []string
+	for _, resource := range ScopeResources {
+		switch {
+		case role == RoleAdmin:
+			scopes = append(scopes, Scope(resource, AccessWrite))
+		case resource != "api_keys":
+			scopes = append(scopes, Scope(resource, AccessRead))
+		}
+	}
+	if role != RoleAdmin {
+		scopes = append(scopes, Scope("feedback", AccessWrite), Scope("graphql", AccessWrite))
+	}
+	return scopes
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	MemberJoined = "joined"
+	MemberLeft   = "left"
+)
+
+// AssignmentChange records a member joining or leaving a team. Names are
+// copied so the history reads the same after a rename or delete.
+type AssignmentChange struct {
+	ID         uint32    `json:"id" gorm:"primaryKey"`
+	MemberID   uint32    `json:"member_id" gorm:"type:int unsigned;index"`
+	MemberName string    `json:"member_name" gorm:"type:varchar(255)"`
+	TeamID     uint32    `json:"team_id" gorm:"type:int unsigned;index"`
+	TeamName   string    `json:"team_name" gorm:"type:varchar(255)"`
+	Action     string    `json:"action" gorm:"type:varchar(20)"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// DigestDelivery marks the weekly digest for the period ending at
+// PeriodEnd as sent to a member.
+type DigestDelivery struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	MemberID  uint32    `json:"member_id" gorm:"type:int unsigned;uniqueIndex:idx_digest_delivery"`
+	PeriodEnd time.Time `json:"period_end" gorm:"uniqueIndex:idx_digest_delivery"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Image owner types.
+const (
+	ImageMember = "member"
+	ImageTeam   = "team"
+)
+
+// Image is the uploaded avatar of a member or logo of a team. The original
+// and its thumbnails live in blob storage under keys derived from Hash.
+type Image struct {
+	ID          uint32    `json:"id" gorm:"primaryKey"`
+	OwnerType   string    `json:"owner_type" gorm:"type:varchar(20);uniqueIndex:idx_image_owner"`
+	OwnerID     uint32    `json:"owner_id" gorm:"type:int unsigned;uniqueIndex:idx_image_owner"`
+	Hash        string    `json:"hash" gorm:"type:char(64)"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(50)"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -5,7 +5,7 @@ import (
 )
 
 type TeamMember struct {
-	ID        uint32    `json:"id" gorm:"primaryKey;type:int unsigned"`
+	ID        uint32    `json:"id" gorm:"primaryKey"`
 	Name      string    `json:"name" binding:"required" gorm:"type:varchar(255);index"`
 	Email     string    `json:"email" binding:"required,email" gorm:"type:varchar(255);unique"`
 	Picture   string    `json:"picture" gorm:"type:text"`
@@ -13,10 +13,28 @@ type TeamMember struct {
 	Team      *Team     `json:"team,omitempty" gorm:"foreignKey:TeamID"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// DirectoryDN is the distinguished name of the directory entry the
+	// member is synced from, or empty for members managed here.
+	DirectoryDN string `json:"-" gorm:"type:varchar(512)"`
+	// Role is what the member may do when signed in, RoleMember or
+	// RoleAdmin; members who never signed in have none.
+	Role string `json:"-" gorm:"type:varchar(16)"`
+	// SSOSubject identifies the member at the identity provider they sign
+	// in with, so a changed email still finds them.
+	SSOSubject string `json:"-" gorm:"type:varchar(255);index"`
+	// DeactivatedAt is when the member left, as the directory or SCIM
+	// reported; nil for active members. Deactivated members keep their
+	// records and feedback but are left out of lists and cannot sign in.
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" gorm:"index"`
+}
+
+// Active reports whether the member has not been deactivated.
+func (m *TeamMember) Active() bool {
+	return m.DeactivatedAt == nil
 }
 
 type Team struct {
-	ID        uint32       `json:"id" gorm:"primaryKey;type:int unsigned"`
+	ID        uint32       `json:"id" gorm:"primaryKey"`
 	Name      string       `json:"name" binding:"required" gorm:"type:varchar(255);unique;index"`
 	Logo      string       `json:"logo" gorm:"type:text"`
 	Members   []TeamMember `json:"members,omitempty" gorm:"foreignKey:TeamID"`
@@ -25,13 +43,49 @@ type Team struct {
 }
 
 type Feedback struct {
-	ID         uint32    `json:"id" gorm:"primaryKey;type:int unsigned"`
-	Content    string    `json:"content" binding:"required" gorm:"type:text"`
-	TargetType string    `json:"target_type" binding:"required,oneof=team member" gorm:"type:varchar(50);index:idx_feedback_target"`
-	TargetID   uint32    `json:"target_id" binding:"required" gorm:"type:int unsigned;index:idx_feedback_target"`
-	TargetName string    `json:"target_name" gorm:"type:varchar(255)"`
-	CreatedAt  time.Time `json:"created_at" gorm:"index"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID          uint32       `json:"id" gorm:"primaryKey"`
+	Content     string       `json:"content" binding:"required" gorm:"type:text"`
+	TargetType  string       `json:"target_type" binding:"required" gorm:"type:varchar(50);index:idx_feedback_target"`
+	TargetID    uint32       `json:"target_id" binding:"required" gorm:"type:int unsigned;index:idx_feedback_target"`
+	TargetName  string       `json:"target_name" gorm:"type:varchar(255)"`
+	Attachments []Attachment `json:"attachments,omitempty" gorm:"foreignKey:FeedbackID"`
+	CreatedAt   time.Time    `json:"created_at" gorm:"index"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Attachment is a file attached to feedback. Its content is kept in blob
+// storage under Key.
+type Attachment struct {
+	ID          uint32    `json:"id" gorm:"primaryKey"`
+	FeedbackID  uint32    `json:"feedback_id" gorm:"type:int unsigned;index"`
+	Filename    string    `json:"filename" gorm:"type:varchar(255)"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(100)"`
+	Size        int64     `json:"size"`
+	Key         string    `json:"-" gorm:"type:varchar(255)"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Project struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" binding:"required" gorm:"type:varchar(255);unique;index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Release struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" binding:"required" gorm:"type:varchar(255);index"`
+	Version   string    `json:"version" binding:"required" gorm:"type:varchar(100)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Meeting struct {
+	ID          uint32    `json:"id" gorm:"primaryKey"`
+	Title       string    `json:"title" binding:"required" gorm:"type:varchar(255)"`
+	ScheduledAt time.Time `json:"scheduled_at" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type AssignRequest struct {
@@ -148,7 +148,7 @@ func TestFeedbackModel(t *testing.T) {
 		assert.NotZero(t, feedback.ID)
 		assert.Equal(t, "Great work on the project!", feedback.Content)
 		assert.Equal(t, "member", feedback.TargetType)
-		assert.Equal(t, uint(1), feedback.TargetID)
+		assert.Equal(t, uint32(1), feedback.TargetID)
 		assert.False(t, feedback.CreatedAt.IsZero())
 	})
 
@@ -187,8 +187,8 @@ func TestAssignRequest(t *testing.T) {
 			TeamID:   2,
 		}
 
-		assert.Equal(t, uint(1), request.MemberID)
-		assert.Equal(t, uint(2), request.TeamID)
+		assert.Equal(t, uint32(1), request.MemberID)
+		assert.Equal(t, uint32(2), request.TeamID)
 	})
 }
 
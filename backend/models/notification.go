@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// NotificationPreferences are a member's email settings. Members without
+// a stored row get DefaultNotificationPreferences: an email per new
+// feedback and no weekly digest.
+type NotificationPreferences struct {
+	MemberID       uint32    `json:"member_id" gorm:"primaryKey;autoIncrement:false"`
+	FeedbackEmails bool      `json:"feedback_emails"`
+	WeeklyDigest   bool      `json:"weekly_digest"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func DefaultNotificationPreferences(memberID uint32) NotificationPreferences {
+	return NotificationPreferences{MemberID: memberID, FeedbackEmails: true}
+}
+
+const (
+	NotificationFeedback = "feedback"
+
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationSkipped = "skipped"
+	NotificationFailed  = "failed"
+)
+
+// Notification is one item queued for a member's inbox. Pending items for
+// the same member are sent together in one email.
+type Notification struct {
+	ID       uint32 `json:"id" gorm:"primaryKey"`
+	MemberID uint32 `json:"member_id" gorm:"type:int unsigned;uniqueIndex:idx_notification_event"`
+	// EventKey is the key of the event that caused the notification, so
+	// an event relayed twice is queued once per member.
+	EventKey   string `json:"event_key" gorm:"type:varchar(64);uniqueIndex:idx_notification_event"`
+	Kind       string `json:"kind" gorm:"type:varchar(50)"`
+	FeedbackID uint32 `json:"feedback_id" gorm:"type:int unsigned"`
+	// TargetType and TargetName say whom the feedback was for: the member
+	// or their team.
+	TargetType    string     `json:"target_type" gorm:"type:varchar(50)"`
+	TargetName    string     `json:"target_name" gorm:"type:varchar(255)"`
+	Content       string     `json:"content" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"type:varchar(20);index:idx_notification_due"`
+	Attempts      int        `json:"attempts"`
+	Error         string     `json:"error" gorm:"type:text"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index:idx_notification_due"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
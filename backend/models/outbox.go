@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// OutboxMessage is a domain event recorded in the same transaction as the
+// change it describes. The relay publishes it after the commit, and marks
+// it published once every sink has it.
+type OutboxMessage struct {
+	ID uint64 `json:"id" gorm:"primaryKey"`
+	// Key is the idempotency key consumers deduplicate on.
+	Key        string  `json:"key" gorm:"type:varchar(64);uniqueIndex"`
+	Type       string  `json:"type" gorm:"type:varchar(50)"`
+	TeamID     *uint32 `json:"team_id" gorm:"type:int unsigned"`
+	TargetType string  `json:"target_type" gorm:"type:varchar(50)"`
+	TargetID   uint32  `json:"target_id" gorm:"type:int unsigned"`
+	// Data is the event's record as JSON.
+	Data        string     `json:"data" gorm:"type:text"`
+	PublishedAt *time.Time `json:"published_at" gorm:"index"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OutboxCursor is how far the outbox has been published to a sink that
+// all instances share, such as the webhook dispatcher. The relay holding
+// the lease feeds the sink; the others leave it alone until it expires.
+type OutboxCursor struct {
+	Sink string `json:"sink" gorm:"primaryKey;type:varchar(50)"`
+	// LastID is the last event the sink accepted.
+	LastID uint64 `json:"last_id"`
+	// Attempts counts the failures since the sink last accepted an event.
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error" gorm:"type:text"`
+	RetryAt   *time.Time `json:"retry_at"`
+	// Owner is the relay holding the lease until LeasedUntil.
+	Owner       string     `json:"owner" gorm:"type:varchar(64)"`
+	LeasedUntil *time.Time `json:"leased_until"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
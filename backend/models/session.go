@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Roles of signed-in members.
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+)
+
+// Session is a signed-in browser. Only a hash of the session token in its
+// cookie is stored, so the table cannot be used to sign in.
+type Session struct {
+	ID        uint32      `json:"-" gorm:"primaryKey"`
+	TokenHash string      `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+	MemberID  uint32      `json:"member_id" gorm:"type:int unsigned;index"`
+	Member    *TeamMember `json:"member,omitempty" gorm:"foreignKey:MemberID"`
+	ExpiresAt time.Time   `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StringList is a list of strings stored as a JSON array in a text column.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(l))
+	return string(data), err
+}
+
+func (l *StringList) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", src)
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+// Webhook is a subscription that receives domain events as signed HTTP
+// POSTs. An empty Events list subscribes to every event type.
+type Webhook struct {
+	ID     uint32     `json:"id" gorm:"primaryKey"`
+	URL    string     `json:"url" binding:"required,url" gorm:"type:text"`
+	Secret string     `json:"secret,omitempty" gorm:"type:varchar(255)"`
+	Events StringList `json:"events" gorm:"type:text"`
+	Active bool       `json:"active"`
+	// Failures counts consecutive failed delivery attempts. The webhook is
+	// disabled when it reaches the dispatcher's limit.
+	Failures   int        `json:"failures"`
+	DisabledAt *time.Time `json:"disabled_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event queued for one webhook, together with the
+// outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID            uint32     `json:"id" gorm:"primaryKey"`
+	WebhookID     uint32     `json:"webhook_id" gorm:"type:int unsigned;index"`
+	EventID       uint64     `json:"event_id"`
+	EventKey      string     `json:"event_key" gorm:"type:varchar(64);index"`
+	EventType     string     `json:"event_type" gorm:"type:varchar(50)"`
+	Payload       string     `json:"payload" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"type:varchar(20);index:idx_webhook_delivery_due"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"response_code"`
+	Error         string     `json:"error" gorm:"type:text"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_delivery_due"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	RedeliveryOf  *uint32    `json:"redelivery_of" gorm:"type:int unsigned"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
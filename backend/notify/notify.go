@@ -0,0 +1,363 @@
+// Package notify emails members about new feedback. The Notifier is an
+// outbox sink: each feedback.created event queues a notification in the
+// notifications table for the member it is for, or for every member of
+// the team it is for. A worker waits BatchDelay so feedback that arrives
+// close together goes out as one email, respects each member's
+// preferences and hourly limit, and retries failed sends.
+//
+// Run the worker in a single process; concurrent workers may send the
+// same notification twice.
+package notify
+
+import (
+	"bytes"
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/targets"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"net/mail"
+	texttemplate "text/template"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html.tmpl"))
+)
+
+type Config struct {
+	// PollInterval is how often the queue is checked for due notifications.
+	PollInterval time.Duration
+	// BatchDelay is how long a notification waits for others to the same
+	// member before it is sent.
+	BatchDelay time.Duration
+	// MaxPerHour caps the emails sent to one member per hour. Notifications
+	// over the limit wait for the next email.
+	MaxPerHour int
+	// MaxAttempts is how often an email is tried before its notifications
+	// are marked failed. Attempt n is retried after RetryDelay * n.
+	MaxAttempts int
+	RetryDelay  time.Duration
+	// BatchSize caps the members emailed per poll.
+	BatchSize int
+	// AppURL, when set, is linked from every email.
+	AppURL string
+}
+
+var DefaultConfig = Config{
+	PollInterval: 10 * time.Second,
+	BatchDelay:   5 * time.Minute,
+	MaxPerHour:   4,
+	MaxAttempts:  5,
+	RetryDelay:   5 * time.Minute,
+	BatchSize:    50,
+}
+
+type Notifier struct {
+	db     *gorm.DB
+	sender Sender
+	cfg    Config
+	now    func() time.Time
+}
+
+func New(db *gorm.DB, sender Sender, cfg Config) *Notifier {
+	return &Notifier{
+		db:     db,
+		sender: sender,
+		cfg:    cfg,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run sends due notifications until ctx is done.
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := n.SendDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("notify: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *Notifier) Name() string {
+	return "notifications"
+}
+
+// Publish queues a notification for every recipient of new feedback and
+// ignores other events. A notification that is already queued for the
+// event's key is not queued again.
+func (n *Notifier) Publish(ctx context.Context, e events.Event) error {
+	if e.Type != events.FeedbackCreated {
+		return nil
+	}
+	var feedback models.Feedback
+	switch data := e.Data.(type) {
+	case models.Feedback:
+		feedback = data
+	case *models.Feedback:
+		feedback = *data
+	default:
+		return fmt.Errorf("unexpected %s data %T", e.Type, e.Data)
+	}
+
+	recipients, err := targets.Recipients(n.db.WithContext(ctx), feedback.TargetType, feedback.TargetID)
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	key := e.Key
+	if key == "" {
+		key = fmt.Sprintf("event_%d", e.ID)
+	}
+	now := n.now()
+	notifications := make([]models.Notification, 0, len(recipients))
+	for _, memberID := range recipients {
+		notifications = append(notifications, models.Notification{
+			MemberID:      memberID,
+			EventKey:      key,
+			Kind:          models.NotificationFeedback,
+			FeedbackID:    feedback.ID,
+			TargetType:    feedback.TargetType,
+			TargetName:    feedback.TargetName,
+			Content:       feedback.Content,
+			Status:        models.NotificationPending,
+			NextAttemptAt: now.Add(n.cfg.BatchDelay),
+		})
+	}
+	return n.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&notifications).Error
+}
+
+// SendDue emails every member with due notifications, one email each,
+// and reports how many emails it sent.
+func (n *Notifier) SendDue(ctx context.Context) (int, error) {
+	now := n.now()
+
+	var members []uint32
+	err := n.db.WithContext(ctx).Model(&models.Notification{}).
+		Distinct("member_id").
+		Where("status = ? AND next_attempt_at <= ?", models.NotificationPending, now).
+		Limit(n.cfg.BatchSize).
+		Pluck("member_id", &members).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load due notifications: %w", err)
+	}
+
+	sent := 0
+	for _, memberID := range members {
+		ok, err := n.sendTo(ctx, memberID, now)
+		if err != nil {
+			return sent, fmt.Errorf("failed to notify member %d: %w", memberID, err)
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendTo emails the member's due notifications and reports whether an
+// email was sent. Send failures are recorded on the notifications rather
+// than returned.
+func (n *Notifier) sendTo(ctx context.Context, memberID uint32, now time.Time) (bool, error) {
+	db := n.db.WithContext(ctx)
+
+	var items []models.Notification
+	err := db.Where("member_id = ? AND status = ? AND next_attempt_at <= ?", memberID, models.NotificationPending, now).
+		Order("id").
+		Find(&items).Error
+	if err != nil {
+		return false, err
+	}
+
+	var member models.TeamMember
+	err = db.First(&member, memberID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, n.finish(ctx, items, models.NotificationSkipped, "member was deleted")
+	}
+	if err != nil {
+		return false, err
+	}
+	if !member.Active() {
+		return false, n.finish(ctx, items, models.NotificationSkipped, "member is deactivated")
+	}
+
+	prefs, err := Preferences(db, memberID)
+	if err != nil {
+		return false, err
+	}
+	if !prefs.FeedbackEmails {
+		return false, n.finish(ctx, items, models.NotificationSkipped, "feedback emails are turned off")
+	}
+
+	items, err = n.dropDeletedFeedback(ctx, items)
+	if err != nil || len(items) == 0 {
+		return false, err
+	}
+
+	next, limited, err := n.rateLimit(ctx, memberID, now)
+	if err != nil {
+		return false, err
+	}
+	if limited {
+		return false, db.Model(&models.Notification{}).Where("id IN ?", ids(items)).Update("next_attempt_at", next).Error
+	}
+
+	msg, err := render(&member, items, n.cfg.AppURL)
+	if err != nil {
+		return false, err
+	}
+	if sendErr := n.sender.Send(ctx, msg); sendErr != nil {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+		return false, n.retry(ctx, items, sendErr)
+	}
+
+	return true, db.Model(&models.Notification{}).Where("id IN ?", ids(items)).Updates(map[string]interface{}{
+		"status":   models.NotificationSent,
+		"attempts": gorm.Expr("attempts + 1"),
+		"error":    "",
+		"sent_at":  now,
+	}).Error
+}
+
+// dropDeletedFeedback skips notifications about feedback that was deleted
+// before the email went out and returns the rest.
+func (n *Notifier) dropDeletedFeedback(ctx context.Context, items []models.Notification) ([]models.Notification, error) {
+	feedbackIDs := make([]uint32, 0, len(items))
+	for _, item := range items {
+		feedbackIDs = append(feedbackIDs, item.FeedbackID)
+	}
+	var existing []uint32
+	err := n.db.WithContext(ctx).Model(&models.Feedback{}).Where("id IN ?", feedbackIDs).Pluck("id", &existing).Error
+	if err != nil {
+		return nil, err
+	}
+	found := map[uint32]bool{}
+	for _, id := range existing {
+		found[id] = true
+	}
+
+	var kept, deleted []models.Notification
+	for _, item := range items {
+		if found[item.FeedbackID] {
+			kept = append(kept, item)
+		} else {
+			deleted = append(deleted, item)
+		}
+	}
+	return kept, n.finish(ctx, deleted, models.NotificationSkipped, "feedback was deleted")
+}
+
+// rateLimit reports whether the member already got MaxPerHour emails in
+// the last hour and, if so, when the next one may be sent. Notifications
+// sent in one email share their sent_at, so each distinct value is one
+// email.
+func (n *Notifier) rateLimit(ctx context.Context, memberID uint32, now time.Time) (time.Time, bool, error) {
+	if n.cfg.MaxPerHour <= 0 {
+		return time.Time{}, false, nil
+	}
+	var sent []time.Time
+	err := n.db.WithContext(ctx).Model(&models.Notification{}).
+		Distinct("sent_at").
+		Where("member_id = ? AND status = ? AND sent_at > ?", memberID, models.NotificationSent, now.Add(-time.Hour)).
+		Order("sent_at").
+		Pluck("sent_at", &sent).Error
+	if err != nil || len(sent) < n.cfg.MaxPerHour {
+		return time.Time{}, false, err
+	}
+	return sent[len(sent)-n.cfg.MaxPerHour].Add(time.Hour), true, nil
+}
+
+func (n *Notifier) retry(ctx context.Context, items []models.Notification, sendErr error) error {
+	now := n.now()
+	for i := range items {
+		item := &items[i]
+		item.Attempts++
+		item.Error = sendErr.Error()
+		if item.Attempts >= n.cfg.MaxAttempts {
+			item.Status = models.NotificationFailed
+		} else {
+			item.NextAttemptAt = now.Add(n.cfg.RetryDelay * time.Duration(item.Attempts))
+		}
+		if err := n.db.WithContext(ctx).Save(item).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) finish(ctx context.Context, items []models.Notification, status, reason string) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return n.db.WithContext(ctx).Model(&models.Notification{}).Where("id IN ?", ids(items)).Updates(map[string]interface{}{
+		"status": status,
+		"error":  reason,
+	}).Error
+}
+
+// Preferences returns the member's notification preferences, or the
+// defaults when they never changed them.
+func Preferences(db *gorm.DB, memberID uint32) (models.NotificationPreferences, error) {
+	var prefs models.NotificationPreferences
+	err := db.Where("member_id = ?", memberID).Take(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultNotificationPreferences(memberID), nil
+	}
+	return prefs, err
+}
+
+type emailData struct {
+	Name   string
+	Items  []models.Notification
+	AppURL string
+}
+
+func render(member *models.TeamMember, items []models.Notification, appURL string) (Message, error) {
+	data := emailData{Name: member.Name, Items: items, AppURL: appURL}
+
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, "feedback.txt.tmpl", data); err != nil {
+		return Message{}, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, "feedback.html.tmpl", data); err != nil {
+		return Message{}, err
+	}
+
+	subject := "You have new feedback"
+	if len(items) > 1 {
+		subject = fmt.Sprintf("You have %d new pieces of feedback", len(items))
+	}
+	to := (&mail.Address{Name: member.Name, Address: member.Email}).String()
+	return Message{To: to, Subject: subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+func ids(items []models.Notification) []uint32 {
+	ids := make([]uint32, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
@@ -0,0 +1,287 @@
+package notify
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/tests/testutils"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// mailbox is a Sender that keeps the messages it was given and fails while
+// err is set.
+type mailbox struct {
+	err      error
+	messages []Message
+}
+
+func (o *mailbox) Send(ctx context.Context, msg Message) error {
+	if o.err != nil {
+		return o.err
+	}
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+var testConfig = Config{
+	PollInterval: 10 * time.Millisecond,
+	BatchDelay:   time.Minute,
+	MaxPerHour:   2,
+	MaxAttempts:  2,
+	RetryDelay:   time.Minute,
+	BatchSize:    10,
+	AppURL:       "https://coaching.example.com",
+}
+
+type clock struct{ now time.Time }
+
+func (c *clock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newNotifier(db *gorm.DB) (*Notifier, *mailbox, *clock) {
+	sender := &mailbox{}
+	n := New(db, sender, testConfig)
+	c := &clock{now: time.Now().UTC()}
+	n.now = func() time.Time { return c.now }
+	return n, sender, c
+}
+
+type fixture struct {
+	team         models.Team
+	alice, bob   models.TeamMember
+	nextEventKey int
+}
+
+func setupFixture(t *testing.T, db *gorm.DB) *fixture {
+	f := &fixture{team: models.Team{Name: "Platform"}}
+	require.NoError(t, db.Create(&f.team).Error)
+	f.alice = models.TeamMember{Name: "Alice", Email: "alice@example.com", TeamID: &f.team.ID}
+	f.bob = models.TeamMember{Name: "Bob", Email: "bob@example.com", TeamID: &f.team.ID}
+	require.NoError(t, db.Create(&f.alice).Error)
+	require.NoError(t, db.Create(&f.bob).Error)
+	return f
+}
+
+// feedback stores feedback and returns its feedback.created event.
+func (f *fixture) feedback(t *testing.T, db *gorm.DB, targetType string, targetID uint32, content string) events.Event {
+	feedback := models.Feedback{Content: content, TargetType: targetType, TargetID: targetID, TargetName: "Platform"}
+	require.NoError(t, db.Create(&feedback).Error)
+	f.nextEventKey++
+	return events.Event{
+		ID:         uint64(f.nextEventKey),
+		Key:        fmt.Sprintf("evt_%d", f.nextEventKey),
+		Type:       events.FeedbackCreated,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Data:       feedback,
+	}
+}
+
+func notifications(t *testing.T, db *gorm.DB, memberID uint32) []models.Notification {
+	var items []models.Notification
+	require.NoError(t, db.Where("member_id = ?", memberID).Order("id").Find(&items).Error)
+	return items
+}
+
+func TestNotifier(t *testing.T) {
+	t.Run("Batches Feedback Into One Email", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, sender, clock := newNotifier(db)
+		f := setupFixture(t, db)
+		ctx := context.Background()
+
+		require.NoError(t, n.Publish(ctx, f.feedback(t, db, "member", f.alice.ID, "Great demo!")))
+		require.NoError(t, n.Publish(ctx, f.feedback(t, db, "member", f.alice.ID, "Clear <b>slides</b>")))
+
+		sent, err := n.SendDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, sent, "notifications wait for the batch delay")
+
+		clock.advance(time.Minute)
+		sent, err = n.SendDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+
+		require.Len(t, sender.messages, 1)
+		msg := sender.messages[0]
+		assert.Equal(t, `"Alice" <alice@example.com>`, msg.To)
+		assert.Equal(t, "You have 2 new pieces of feedback", msg.Subject)
+		assert.Contains(t, msg.Text, "Great demo!")
+		assert.Contains(t, msg.Text, "Clear <b>slides</b>")
+		assert.Contains(t, msg.Text, "https://coaching.example.com")
+		assert.Contains(t, msg.HTML, "Clear &lt;b&gt;slides&lt;/b&gt;", "content is escaped in HTML")
+
+		for _, item := range notifications(t, db, f.alice.ID) {
+			assert.Equal(t, models.NotificationSent, item.Status)
+			assert.NotNil(t, item.SentAt)
+		}
+	})
+
+	t.Run("Team Feedback Reaches Every Member", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, sender, clock := newNotifier(db)
+		f := setupFixture(t, db)
+
+		require.NoError(t, n.Publish(context.Background(), f.feedback(t, db, "team", f.team.ID, "Shipped on time")))
+		clock.advance(time.Minute)
+		_, err := n.SendDue(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, sender.messages, 2)
+		assert.Equal(t, "You have new feedback", sender.messages[0].Subject)
+		assert.Contains(t, sender.messages[0].Text, "For your team Platform")
+	})
+
+	t.Run("Queues Each Event Once", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, _, _ := newNotifier(db)
+		f := setupFixture(t, db)
+
+		event := f.feedback(t, db, "member", f.alice.ID, "Twice")
+		require.NoError(t, n.Publish(context.Background(), event))
+		require.NoError(t, n.Publish(context.Background(), event))
+
+		assert.Len(t, notifications(t, db, f.alice.ID), 1)
+	})
+
+	t.Run("Ignores Other Events", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, _, _ := newNotifier(db)
+		f := setupFixture(t, db)
+
+		event := f.feedback(t, db, "member", f.alice.ID, "Edited")
+		event.Type = events.FeedbackUpdated
+		require.NoError(t, n.Publish(context.Background(), event))
+		require.NoError(t, n.Publish(context.Background(), events.Event{Type: events.MemberCreated, Data: f.alice}))
+
+		var count int64
+		db.Model(&models.Notification{}).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Respects Preferences", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, sender, clock := newNotifier(db)
+		f := setupFixture(t, db)
+		require.NoError(t, db.Create(&models.NotificationPreferences{MemberID: f.alice.ID, FeedbackEmails: false}).Error)
+
+		require.NoError(t, n.Publish(context.Background(), f.feedback(t, db, "team", f.team.ID, "Shipped")))
+		clock.advance(time.Minute)
+		_, err := n.SendDue(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, sender.messages, 1)
+		assert.Contains(t, sender.messages[0].To, "bob@example.com")
+		items := notifications(t, db, f.alice.ID)
+		require.Len(t, items, 1)
+		assert.Equal(t, models.NotificationSkipped, items[0].Status)
+		assert.Equal(t, "feedback emails are turned off", items[0].Error)
+	})
+
+	t.Run("Drops Deleted Feedback", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, sender, clock := newNotifier(db)
+		f := setupFixture(t, db)
+
+		event := f.feedback(t, db, "member", f.alice.ID, "Oops")
+		require.NoError(t, n.Publish(context.Background(), event))
+		require.NoError(t, db.Delete(&models.Feedback{}, event.Data.(models.Feedback).ID).Error)
+
+		clock.advance(time.Minute)
+		sent, err := n.SendDue(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+		assert.Empty(t, sender.messages)
+		assert.Equal(t, models.NotificationSkipped, notifications(t, db, f.alice.ID)[0].Status)
+	})
+
+	t.Run("Limits Emails Per Hour", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, sender, clock := newNotifier(db)
+		f := setupFixture(t, db)
+
+		for i := 0; i < testConfig.MaxPerHour+1; i++ {
+			require.NoError(t, n.Publish(context.Background(), f.feedback(t, db, "member", f.alice.ID, "More")))
+			clock.advance(time.Minute)
+			_, err := n.SendDue(context.Background())
+			require.NoError(t, err)
+		}
+		assert.Len(t, sender.messages, testConfig.MaxPerHour)
+
+		held := notifications(t, db, f.alice.ID)[testConfig.MaxPerHour]
+		assert.Equal(t, models.NotificationPending, held.Status)
+		first := notifications(t, db, f.alice.ID)[0]
+		assert.WithinDuration(t, first.SentAt.Add(time.Hour), held.NextAttemptAt, time.Second)
+
+		clock.now = held.NextAttemptAt
+		_, err := n.SendDue(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, sender.messages, testConfig.MaxPerHour+1)
+	})
+
+	t.Run("Retries Failed Sends", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, sender, clock := newNotifier(db)
+		f := setupFixture(t, db)
+		sender.err = errors.New("connection refused")
+
+		require.NoError(t, n.Publish(context.Background(), f.feedback(t, db, "member", f.alice.ID, "Hello")))
+		clock.advance(time.Minute)
+		_, err := n.SendDue(context.Background())
+		require.NoError(t, err)
+
+		item := notifications(t, db, f.alice.ID)[0]
+		assert.Equal(t, models.NotificationPending, item.Status)
+		assert.Equal(t, 1, item.Attempts)
+		assert.Equal(t, "connection refused", item.Error)
+		assert.WithinDuration(t, clock.now.Add(time.Minute), item.NextAttemptAt, time.Second)
+
+		clock.advance(time.Minute)
+		_, err = n.SendDue(context.Background())
+		require.NoError(t, err)
+		item = notifications(t, db, f.alice.ID)[0]
+		assert.Equal(t, models.NotificationFailed, item.Status)
+		assert.Equal(t, testConfig.MaxAttempts, item.Attempts)
+	})
+
+	t.Run("Skips Deleted Members", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, sender, clock := newNotifier(db)
+		f := setupFixture(t, db)
+
+		require.NoError(t, n.Publish(context.Background(), f.feedback(t, db, "member", f.alice.ID, "Bye")))
+		require.NoError(t, db.Delete(&f.alice).Error)
+		clock.advance(time.Minute)
+		_, err := n.SendDue(context.Background())
+		require.NoError(t, err)
+
+		assert.Empty(t, sender.messages)
+		assert.Equal(t, models.NotificationSkipped, notifications(t, db, f.alice.ID)[0].Status)
+	})
+
+	t.Run("Skips Deactivated Members", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		n, sender, clock := newNotifier(db)
+		f := setupFixture(t, db)
+
+		require.NoError(t, n.Publish(context.Background(), f.feedback(t, db, "member", f.alice.ID, "Bye")))
+		require.NoError(t, db.Model(&f.alice).Update("deactivated_at", time.Now()).Error)
+		clock.advance(time.Minute)
+		_, err := n.SendDue(context.Background())
+		require.NoError(t, err)
+
+		assert.Empty(t, sender.messages)
+		item := notifications(t, db, f.alice.ID)[0]
+		assert.Equal(t, models.NotificationSkipped, item.Status)
+		assert.Equal(t, "member is deactivated", item.Error)
+
+		require.NoError(t, n.Publish(context.Background(), f.feedback(t, db, "member", f.alice.ID, "Still there?")))
+		assert.Len(t, notifications(t, db, f.alice.ID), 1, "deactivated members are not queued for")
+	})
+}
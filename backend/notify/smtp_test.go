@@ -0,0 +1,239 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpServer is a minimal SMTP server that accepts every message, or
+// rejects recipients with rcptStatus when it is set.
+type smtpServer struct {
+	addr       string
+	rcptStatus string
+
+	mu       sync.Mutex
+	messages []received
+}
+
+type received struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+
+	s := &smtpServer{addr: lis.Addr().String()}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var msg received
+	reply("220 localhost ESMTP test")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			msg.auth = string(credentials)
+			reply("235 Authenticated")
+		case "MAIL":
+			msg.from = line
+			reply("250 OK")
+		case "RCPT":
+			if s.rcptStatus != "" {
+				reply(s.rcptStatus)
+				continue
+			}
+			msg.to = append(msg.to, line)
+			reply("250 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpServer) received() []received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]received(nil), s.messages...)
+}
+
+// newSilentServer listens on a port that accepts connections but never
+// greets, and returns its address.
+func newSilentServer(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func TestSMTPSender(t *testing.T) {
+	msg := Message{
+		To:      `"Jürgen Smith" <jurgen@example.com>`,
+		Subject: "Neue Rückmeldung",
+		Text:    "Great demo!",
+		HTML:    "<p>Great demo!</p>",
+	}
+
+	t.Run("Sends Multipart Message", func(t *testing.T) {
+		server := newSMTPServer(t)
+		sender := &SMTPSender{Addr: server.addr, From: "Coaching <coaching@example.com>"}
+
+		require.NoError(t, sender.Send(context.Background(), msg))
+
+		got := server.received()
+		require.Len(t, got, 1)
+		assert.Equal(t, "MAIL FROM:<coaching@example.com>", got[0].from)
+		assert.Equal(t, []string{"RCPT TO:<jurgen@example.com>"}, got[0].to)
+		assert.Empty(t, got[0].auth)
+
+		parsed, err := mail.ReadMessage(strings.NewReader(got[0].data))
+		require.NoError(t, err)
+		subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+		require.NoError(t, err)
+		assert.Equal(t, "Neue Rückmeldung", subject)
+		assert.NotEmpty(t, parsed.Header.Get("Message-ID"))
+
+		mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", mediaType)
+
+		parts := multipart.NewReader(parsed.Body, params["boundary"])
+		var bodies []string
+		for {
+			part, err := parts.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			body, _ := io.ReadAll(part)
+			bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(body))
+		}
+		assert.Equal(t, []string{
+			"text/plain; charset=utf-8: Great demo!",
+			"text/html; charset=utf-8: <p>Great demo!</p>",
+		}, bodies)
+	})
+
+	t.Run("Authenticates When Configured", func(t *testing.T) {
+		server := newSMTPServer(t)
+		sender := &SMTPSender{Addr: server.addr, Username: "user", Password: "pass", From: "coaching@example.com"}
+
+		require.NoError(t, sender.Send(context.Background(), msg))
+
+		got := server.received()
+		require.Len(t, got, 1)
+		assert.Equal(t, "\x00user\x00pass", got[0].auth)
+	})
+
+	t.Run("Returns Rejections", func(t *testing.T) {
+		server := newSMTPServer(t)
+		server.rcptStatus = "550 No such user"
+		sender := &SMTPSender{Addr: server.addr, From: "coaching@example.com"}
+
+		err := sender.Send(context.Background(), msg)
+		assert.ErrorContains(t, err, "No such user")
+		assert.Empty(t, server.received())
+	})
+
+	t.Run("Honours Context Deadline", func(t *testing.T) {
+		sender := &SMTPSender{Addr: newSilentServer(t), From: "coaching@example.com"}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.Error(t, sender.Send(ctx, msg))
+	})
+
+	t.Run("Times Out Without A Context Deadline", func(t *testing.T) {
+		sender := &SMTPSender{Addr: newSilentServer(t), From: "coaching@example.com", Timeout: 50 * time.Millisecond}
+
+		start := time.Now()
+		assert.Error(t, sender.Send(context.Background(), msg))
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("Stops When The Context Is Cancelled", func(t *testing.T) {
+		sender := &SMTPSender{Addr: newSilentServer(t), From: "coaching@example.com", Timeout: time.Hour}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		start := time.Now()
+		assert.Error(t, sender.Send(ctx, msg))
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("Rejects Invalid Addresses", func(t *testing.T) {
+		sender := &SMTPSender{Addr: "127.0.0.1:1", From: "not an address"}
+		assert.ErrorContains(t, sender.Send(context.Background(), msg), "invalid sender address")
+	})
+}
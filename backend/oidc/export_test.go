@@ -0,0 +1,8 @@
+package oidc
+
+import "time"
+
+// SetClock replaces the client's clock.
+func SetClock(c *Client, now func() time.Time) {
+	c.now = now
+}
@@ -0,0 +1,222 @@
+// Package oidc signs people in with an OpenID Connect provider: the
+// authorization code flow with PKCE (RFC 7636), and verification of the ID
+// tokens the provider returns.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultScopes ask for the claims sign-in needs.
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+type Config struct {
+	// Issuer is the provider's issuer URL; its metadata is discovered at
+	// Issuer/.well-known/openid-configuration.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback the provider sends people back to.
+	RedirectURL string
+	Scopes      []string
+	// GroupsClaim names the claim listing a person's groups (default
+	// "groups").
+	GroupsClaim string
+	HTTPClient  *http.Client
+}
+
+// Client talks to one provider. Its metadata and signing keys are fetched
+// on first use, so a provider that is down does not stop the server from
+// starting.
+type Client struct {
+	cfg Config
+	now func() time.Time
+
+	mu          sync.Mutex
+	metadata    *metadata
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func New(cfg Config) *Client {
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = DefaultScopes
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{cfg: cfg, now: time.Now}
+}
+
+// AuthRequest is what a login remembers between sending someone to the
+// provider and their return: State ties the callback to the browser that
+// started it, Nonce the ID token to the request, and Verifier proves the
+// code was asked for by this client.
+type AuthRequest struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+// NewAuthRequest generates a random state, nonce and PKCE verifier.
+func NewAuthRequest() (AuthRequest, error) {
+	var values [3]string
+	for i := range values {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return AuthRequest{}, err
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(b)
+	}
+	return AuthRequest{State: values[0], Nonce: values[1], Verifier: values[2]}, nil
+}
+
+// Challenge derives the S256 code challenge of a PKCE verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the provider's URL to send someone to for req.
+func (c *Client) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	m, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(m.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("oidc: invalid authorization endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.cfg.ClientID)
+	q.Set("redirect_uri", c.cfg.RedirectURL)
+	q.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	q.Set("state", req.State)
+	q.Set("nonce", req.Nonce)
+	q.Set("code_challenge", Challenge(req.Verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange redeems an authorization code and returns the raw ID token,
+// which must still be verified.
+func (c *Client) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	m, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"client_id":     {c.cfg.ClientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := c.fetchJSON(req, &body)
+	if err != nil {
+		return "", fmt.Errorf("oidc: token request failed: %w", err)
+	}
+	if body.Error != "" {
+		return "", fmt.Errorf("oidc: token request failed: %s %s", body.Error, body.ErrorDescription)
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("oidc: token request failed with status %d", status)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("oidc: token response has no ID token")
+	}
+	return body.IDToken, nil
+}
+
+// discover fetches and caches the provider's metadata.
+func (c *Client) discover(ctx context.Context) (*metadata, error) {
+	c.mu.Lock()
+	m := c.metadata
+	c.mu.Unlock()
+	if m != nil {
+		return m, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	m = &metadata{}
+	status, err := c.fetchJSON(req, m)
+	if err == nil && status != http.StatusOK {
+		err = fmt.Errorf("status %d", status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("oidc: discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(m.Issuer, "/") != c.cfg.Issuer {
+		return nil, fmt.Errorf("oidc: discovered issuer %q does not match %q", m.Issuer, c.cfg.Issuer)
+	}
+	if m.AuthorizationEndpoint == "" || m.TokenEndpoint == "" || m.JWKSURI == "" {
+		return nil, errors.New("oidc: provider metadata lacks an endpoint")
+	}
+
+	c.mu.Lock()
+	c.metadata = m
+	c.mu.Unlock()
+	return m, nil
+}
+
+// fetchJSON sends req and decodes a JSON response of at most 1 MB into v,
+// whatever its status.
+func (c *Client) fetchJSON(req *http.Request, v interface{}) (int, error) {
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(data, v); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,184 @@
+package oidc_test
+
+import (
+	"coaching-backend/oidc"
+	"coaching-backend/oidc/oidctest"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURL = "https://app.example.com/api/auth/callback"
+
+func newClient(provider *oidctest.Server) *oidc.Client {
+	return oidc.New(oidc.Config{
+		Issuer:       provider.URL,
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  redirectURL,
+	})
+}
+
+// authorize follows the client's authorization URL and returns the
+// parameters the provider redirects back with.
+func authorize(t *testing.T, client *oidc.Client, req oidc.AuthRequest) url.Values {
+	authURL, err := client.AuthCodeURL(context.Background(), req)
+	require.NoError(t, err)
+	noRedirects := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirects.Get(authURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(location.String(), redirectURL))
+	return location.Query()
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	provider := oidctest.NewServer("coaching", "secret")
+	defer provider.Close()
+
+	t.Run("Authorization Code With PKCE", func(t *testing.T) {
+		client := newClient(provider)
+		provider.SetClaims(map[string]interface{}{
+			"sub": "ada", "email": "ada@example.com", "email_verified": true, "name": "Ada Lovelace",
+			"groups": []string{"engineering", "coaches"},
+		})
+		req, err := oidc.NewAuthRequest()
+		require.NoError(t, err)
+
+		params := authorize(t, client, req)
+		assert.Equal(t, req.State, params.Get("state"))
+		rawIDToken, err := client.Exchange(ctx, params.Get("code"), req.Verifier)
+		require.NoError(t, err)
+		claims, err := client.Verify(ctx, rawIDToken, req.Nonce)
+		require.NoError(t, err)
+		assert.Equal(t, "ada", claims.Subject)
+		assert.Equal(t, "ada@example.com", claims.Email)
+		assert.True(t, claims.EmailVerified)
+		assert.Equal(t, "Ada Lovelace", claims.Name)
+		assert.Equal(t, []string{"engineering", "coaches"}, claims.Groups)
+	})
+
+	t.Run("Wrong Verifier", func(t *testing.T) {
+		client := newClient(provider)
+		req, _ := oidc.NewAuthRequest()
+		params := authorize(t, client, req)
+		_, err := client.Exchange(ctx, params.Get("code"), "another-verifier")
+		assert.ErrorContains(t, err, "invalid_grant")
+	})
+
+	t.Run("Code Used Twice", func(t *testing.T) {
+		client := newClient(provider)
+		req, _ := oidc.NewAuthRequest()
+		params := authorize(t, client, req)
+		_, err := client.Exchange(ctx, params.Get("code"), req.Verifier)
+		require.NoError(t, err)
+		_, err = client.Exchange(ctx, params.Get("code"), req.Verifier)
+		assert.ErrorContains(t, err, "invalid_grant")
+	})
+
+	t.Run("Wrong Client Secret", func(t *testing.T) {
+		client := oidc.New(oidc.Config{Issuer: provider.URL, ClientID: provider.ClientID, ClientSecret: "guess", RedirectURL: redirectURL})
+		req, _ := oidc.NewAuthRequest()
+		params := authorize(t, client, req)
+		_, err := client.Exchange(ctx, params.Get("code"), req.Verifier)
+		assert.ErrorContains(t, err, "invalid_client")
+	})
+
+	t.Run("Issuer Mismatch", func(t *testing.T) {
+		client := oidc.New(oidc.Config{Issuer: provider.URL + "/tenant", ClientID: provider.ClientID})
+		_, err := client.AuthCodeURL(ctx, oidc.AuthRequest{})
+		assert.Error(t, err)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	provider := oidctest.NewServer("coaching", "secret")
+	defer provider.Close()
+	client := newClient(provider)
+
+	claims := func(changes map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": provider.URL, "aud": "coaching", "sub": "ada", "nonce": "n",
+			"iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix(),
+		}
+		for k, v := range changes {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		got, err := client.Verify(ctx, provider.Sign(claims(map[string]interface{}{
+			"aud": []string{"other", "coaching"}, "azp": "coaching", "email_verified": "true", "groups": "admins",
+		})), "n")
+		require.NoError(t, err)
+		assert.True(t, got.EmailVerified)
+		assert.Equal(t, []string{"admins"}, got.Groups)
+	})
+
+	for name, tc := range map[string]struct {
+		changes map[string]interface{}
+		err     string
+	}{
+		"Wrong Issuer":         {map[string]interface{}{"iss": "https://evil.example.com"}, "issued by"},
+		"Wrong Audience":       {map[string]interface{}{"aud": "other"}, "another client"},
+		"Wrong Party":          {map[string]interface{}{"aud": []string{"coaching", "other"}, "azp": "other"}, "authorized for another client"},
+		"Expired":              {map[string]interface{}{"exp": time.Now().Add(-2 * time.Minute).Unix()}, "expired"},
+		"No Expiry":            {map[string]interface{}{"exp": nil}, "expired"},
+		"Issued In Future":     {map[string]interface{}{"iat": time.Now().Add(time.Hour).Unix()}, "future"},
+		"Wrong Nonce":          {map[string]interface{}{"nonce": "other"}, "nonce"},
+		"No Subject":           {map[string]interface{}{"sub": nil}, "subject"},
+		"Unverified Email":     {map[string]interface{}{"email_verified": false}, ""},
+		"Missing Verification": {map[string]interface{}{"email": "ada@example.com"}, ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := client.Verify(ctx, provider.Sign(claims(tc.changes)), "n")
+			if tc.err == "" {
+				require.NoError(t, err)
+				assert.False(t, got.EmailVerified)
+				return
+			}
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	t.Run("Tampered", func(t *testing.T) {
+		token := provider.Sign(claims(nil))
+		parts := strings.Split(token, ".")
+		forged := strings.Split(provider.Sign(claims(map[string]interface{}{"sub": "grace"})), ".")
+		_, err := client.Verify(ctx, parts[0]+"."+forged[1]+"."+parts[2], "n")
+		assert.ErrorContains(t, err, "invalid ID token signature")
+
+		_, err = client.Verify(ctx, "not-a-token", "n")
+		assert.ErrorContains(t, err, "malformed")
+	})
+
+	t.Run("Rotated Key", func(t *testing.T) {
+		client := newClient(provider)
+		_, err := client.Verify(ctx, provider.Sign(claims(nil)), "n")
+		require.NoError(t, err)
+
+		provider.RotateKey()
+		_, err = client.Verify(ctx, provider.Sign(claims(nil)), "n")
+		assert.ErrorContains(t, err, "unknown signing key", "keys are refetched at most once a minute")
+
+		oidc.SetClock(client, func() time.Time { return time.Now().Add(2 * time.Minute) })
+		_, err = client.Verify(ctx, provider.Sign(claims(map[string]interface{}{"exp": time.Now().Add(time.Hour).Unix()})), "n")
+		assert.NoError(t, err, "an unknown key ID refetches the keys")
+	})
+}
@@ -0,0 +1,228 @@
+// Package oidctest runs an in-process OpenID Connect provider for tests.
+// Its authorization endpoint signs in whoever Claims describe without
+// asking, and its token endpoint checks the client's secret and PKCE
+// verifier before issuing an RS256 ID token.
+package oidctest
+
+import (
+	"coaching-backend/oidc"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Server is a provider with one client.
+type Server struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+
+	srv *httptest.Server
+
+	mu     sync.Mutex
+	claims map[string]interface{}
+	key    *rsa.PrivateKey
+	kid    int
+	codes  map[string]grant
+}
+
+// grant is what an authorization code was issued for.
+type grant struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+// NewServer starts a provider that signs in a verified ada@example.com.
+// Close it when done.
+func NewServer(clientID, clientSecret string) *Server {
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		claims: map[string]interface{}{
+			"sub":            "ada",
+			"email":          "ada@example.com",
+			"email_verified": true,
+			"name":           "Ada Lovelace",
+		},
+		codes: make(map[string]grant),
+	}
+	s.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /token", s.token)
+	mux.HandleFunc("GET /keys", s.keys)
+	s.srv = httptest.NewServer(mux)
+	s.URL = s.srv.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// SetClaims replaces the claims of the person who signs in next. The
+// issuer, audience, nonce and times are added to them.
+func (s *Server) SetClaims(claims map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims = claims
+}
+
+// RotateKey replaces the signing key with a new one under a new key ID.
+func (s *Server) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("oidctest: " + err.Error())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+	s.kid++
+}
+
+// Sign issues an ID token with exactly the given claims.
+func (s *Server) Sign(claims map[string]interface{}) string {
+	s.mu.Lock()
+	key, kid := s.key, strconv.Itoa(s.kid)
+	s.mu.Unlock()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		panic("oidctest: " + err.Error())
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// IDToken issues the ID token the token endpoint would for nonce.
+func (s *Server) IDToken(nonce string) string {
+	s.mu.Lock()
+	claims := make(map[string]interface{}, len(s.claims)+5)
+	for k, v := range s.claims {
+		claims[k] = v
+	}
+	s.mu.Unlock()
+
+	now := time.Now()
+	claims["iss"] = s.URL
+	claims["aud"] = s.ClientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(5 * time.Minute).Unix()
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	return s.Sign(claims)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if q.Get("client_id") != s.ClientID || err != nil || !redirect.IsAbs() {
+		http.Error(w, "unknown client or redirect URI", http.StatusBadRequest)
+		return
+	}
+
+	params := redirect.Query()
+	params.Set("state", q.Get("state"))
+	switch {
+	case q.Get("response_type") != "code":
+		params.Set("error", "unsupported_response_type")
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		params.Set("error", "invalid_request")
+		params.Set("error_description", "PKCE with S256 is required")
+	default:
+		code := rand.Text()
+		s.mu.Lock()
+		s.codes[code] = grant{redirectURI: redirect.String(), challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+		s.mu.Unlock()
+		params.Set("code", code)
+	}
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	fail := func(status int, code string) {
+		writeJSON(w, status, map[string]string{"error": code})
+	}
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != s.ClientID || secret != s.ClientSecret {
+		fail(http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	if r.PostFormValue("grant_type") != "authorization_code" {
+		fail(http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	code := r.PostFormValue("code")
+	s.mu.Lock()
+	g, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+	if !ok || g.redirectURI != r.PostFormValue("redirect_uri") || oidc.Challenge(r.PostFormValue("code_verifier")) != g.challenge {
+		fail(http.StatusBadRequest, "invalid_grant")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     s.IDToken(g.nonce),
+	})
+}
+
+func (s *Server) keys(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	key, kid := s.key.PublicKey, strconv.Itoa(s.kid)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []oidc.JWK{{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+}
@@ -0,0 +1,273 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// leeway is how far the provider's clock may be from ours.
+const leeway = time.Minute
+
+// keyRefreshInterval limits how often an unknown key ID refetches the
+// provider's keys.
+const keyRefreshInterval = time.Minute
+
+// Claims are the verified claims of an ID token that sign-in uses.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+	Expiry        time.Time
+}
+
+// Verify checks an ID token's signature against the provider's keys, its
+// issuer, audience, lifetime and nonce, and returns its claims.
+func (c *Client) Verify(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	m, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("oidc: malformed ID token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("oidc: malformed ID token signature")
+	}
+	key, err := c.key(ctx, m, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Issuer        string          `json:"iss"`
+		Subject       string          `json:"sub"`
+		Audience      json.RawMessage `json:"aud"`
+		AuthorizedFor string          `json:"azp"`
+		Expiry        float64         `json:"exp"`
+		IssuedAt      float64         `json:"iat"`
+		Nonce         string          `json:"nonce"`
+		Email         string          `json:"email"`
+		EmailVerified json.RawMessage `json:"email_verified"`
+		Name          string          `json:"name"`
+	}
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	audience := stringList(payload.Audience)
+	expiry := time.Unix(int64(payload.Expiry), 0)
+	switch {
+	case payload.Issuer != m.Issuer:
+		return nil, fmt.Errorf("oidc: ID token issued by %q", payload.Issuer)
+	case !contains(audience, c.cfg.ClientID):
+		return nil, errors.New("oidc: ID token is for another client")
+	case len(audience) > 1 && payload.AuthorizedFor != c.cfg.ClientID:
+		return nil, errors.New("oidc: ID token is authorized for another client")
+	case payload.Expiry == 0 || now.After(expiry.Add(leeway)):
+		return nil, errors.New("oidc: ID token has expired")
+	case time.Unix(int64(payload.IssuedAt), 0).After(now.Add(leeway)):
+		return nil, errors.New("oidc: ID token is issued in the future")
+	case payload.Nonce != nonce:
+		return nil, errors.New("oidc: ID token nonce does not match")
+	case payload.Subject == "":
+		return nil, errors.New("oidc: ID token has no subject")
+	}
+
+	// Some providers send email_verified as a string.
+	verified := string(payload.EmailVerified)
+	return &Claims{
+		Subject:       payload.Subject,
+		Email:         payload.Email,
+		EmailVerified: verified == "true" || verified == `"true"`,
+		Name:          payload.Name,
+		Groups:        stringList(raw[c.cfg.GroupsClaim]),
+		Expiry:        expiry,
+	}, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("oidc: malformed ID token")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("oidc: malformed ID token")
+	}
+	return nil
+}
+
+// stringList decodes a claim that is a string or a list of strings.
+func stringList(raw json.RawMessage) []string {
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return list
+	}
+	var one string
+	if json.Unmarshal(raw, &one) == nil && one != "" {
+		return []string{one}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+	switch alg {
+	case "RS256":
+		if key, ok := key.(*rsa.PublicKey); ok && rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil {
+			return nil
+		}
+	case "ES256":
+		if key, ok := key.(*ecdsa.PublicKey); ok && len(signature) == 64 {
+			r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+			if ecdsa.Verify(key, digest[:], r, s) {
+				return nil
+			}
+		}
+	default:
+		return fmt.Errorf("oidc: unsupported signing algorithm %q", alg)
+	}
+	return errors.New("oidc: invalid ID token signature")
+}
+
+// key returns the provider's key with the ID kid, refetching the keys
+// when it is unknown, as providers rotate them. A token without a key ID
+// may use the only key.
+func (c *Client) key(ctx context.Context, m *metadata, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	keys, fetched := c.keys, c.keysFetched
+	c.mu.Unlock()
+	if key := pickKey(keys, kid); key != nil {
+		return key, nil
+	}
+	if !fetched.IsZero() && c.now().Sub(fetched) < keyRefreshInterval {
+		return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+	}
+
+	keys, err := c.fetchKeys(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.keys, c.keysFetched = keys, c.now()
+	c.mu.Unlock()
+	if key := pickKey(keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+}
+
+func pickKey(keys map[string]crypto.PublicKey, kid string) crypto.PublicKey {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return keys[kid]
+}
+
+// JWK is a public key in a JSON Web Key Set. Keys of other types, and
+// keys not for signing, are ignored.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func (c *Client) fetchKeys(ctx context.Context, m *metadata) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []JWK `json:"keys"`
+	}
+	status, err := c.fetchJSON(req, &set)
+	if err == nil && status != http.StatusOK {
+		err = fmt.Errorf("status %d", status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("oidc: failed to fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key := jwk.publicKey(); key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (k JWK) publicKey() crypto.PublicKey {
+	decode := func(s string) *big.Int {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(b) == 0 {
+			return nil
+		}
+		return new(big.Int).SetBytes(b)
+	}
+	switch k.Kty {
+	case "RSA":
+		n, e := decode(k.N), decode(k.E)
+		if n == nil || e == nil || !e.IsInt64() {
+			return nil
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		x, y := decode(k.X), decode(k.Y)
+		if k.Crv != "P-256" || x == nil || y == nil {
+			return nil
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsHTML []byte
+
+// DocsHandler serves a self-contained page that renders openapi.json.
+func DocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsHTML)
+}
@@ -0,0 +1,258 @@
+package openapi
+
+import (
+	"bytes"
+	"coaching-backend/problem"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ValidatorConfig struct {
+	// Document defaults to Spec().
+	Document *Document
+	// ValidateResponses checks every JSON response against the document.
+	// It buffers response bodies, so it is meant for dev and test only.
+	ValidateResponses bool
+	// OnResponseViolation is called for responses that break the contract.
+	// Defaults to logging the violations.
+	OnResponseViolation func(c *gin.Context, errs []problem.FieldError)
+}
+
+// Validator rejects requests whose path parameters, query parameters or
+// JSON body do not match the operation in the document, responding with a
+// 400 problem that lists every violation. Routes missing from the document
+// pass through untouched.
+func Validator(cfg ValidatorConfig) gin.HandlerFunc {
+	doc := cfg.Document
+	if doc == nil {
+		doc = Spec()
+	}
+	report := cfg.OnResponseViolation
+	if report == nil {
+		report = logViolations
+	}
+
+	return func(c *gin.Context) {
+		op := doc.operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		v := &schemaValidator{schemas: doc.Components.Schemas}
+		if p := validateParameters(c, op, v); p != nil {
+			problem.Write(c, p)
+			return
+		}
+		if p := validateBody(c, op, v); p != nil {
+			problem.Write(c, p)
+			return
+		}
+
+		if !cfg.ValidateResponses || streams(op) {
+			c.Next()
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if errs := validateResponse(c, op, v, w.body.Bytes()); len(errs) > 0 {
+			report(c, errs)
+		}
+	}
+}
+
+// streams reports whether the operation's response is a stream (SSE or a
+// protocol upgrade), which must not be buffered for validation.
+func streams(op *OperationObject) bool {
+	if _, ok := op.Responses[strconv.Itoa(http.StatusSwitchingProtocols)]; ok {
+		return true
+	}
+	for _, resp := range op.Responses {
+		if _, ok := resp.Content["text/event-stream"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Document) operation(method, ginPath string) *OperationObject {
+	if ginPath == "" {
+		return nil
+	}
+	item, ok := d.Paths[Path(ginPath)]
+	if !ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+func validateParameters(c *gin.Context, op *OperationObject, v *schemaValidator) *problem.Problem {
+	var errs []problem.FieldError
+
+	for _, param := range op.Parameters {
+		var raw string
+		var present bool
+		switch param.In {
+		case "path":
+			raw, present = c.Param(param.Name), true
+		case "query":
+			raw, present = c.GetQuery(param.Name)
+		default:
+			continue
+		}
+
+		if !present {
+			if param.Required {
+				errs = append(errs, problem.FieldError{Field: param.Name, Rule: "required", Message: "is required"})
+			}
+			continue
+		}
+		errs = v.validate(param.Schema, parameterValue(param.Schema, raw), param.Name, errs)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	p := problem.New(http.StatusBadRequest, problem.CodeInvalidParam, "Request parameters failed validation")
+	p.Errors = errs
+	return p
+}
+
+// parameterValue converts a raw parameter to the JSON value the schema
+// expects, so "12" validates as an integer and "abc" does not. Booleans
+// are parsed like strconv.ParseBool, as the handlers do.
+func parameterValue(s *Schema, raw string) interface{} {
+	for _, t := range schemaTypes(s) {
+		switch t {
+		case "integer", "number":
+			if _, err := strconv.ParseFloat(raw, 64); err == nil {
+				return json.Number(raw)
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(raw); err == nil {
+				return b
+			}
+		}
+	}
+	return raw
+}
+
+func validateBody(c *gin.Context, op *OperationObject, v *schemaValidator) *problem.Problem {
+	if op.RequestBody == nil {
+		return nil
+	}
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return problem.New(http.StatusRequestEntityTooLarge, problem.CodeTooLarge, "Request body is larger than "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes")
+	}
+	if err != nil {
+		return problem.New(http.StatusBadRequest, problem.CodeMalformedBody, "Request body could not be read")
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if !op.RequestBody.Required {
+			return nil
+		}
+		p := problem.New(http.StatusBadRequest, problem.CodeValidation, "Request body failed validation")
+		p.Errors = []problem.FieldError{{Field: "body", Rule: "required", Message: "is required"}}
+		return p
+	}
+
+	value, err := decodeJSON(body)
+	if err != nil {
+		return problem.New(http.StatusBadRequest, problem.CodeMalformedBody, "Request body is not valid JSON")
+	}
+
+	// PUT merges into the stored record, so required fields may be omitted.
+	v.partial = c.Request.Method == http.MethodPut
+	errs := v.validate(media.Schema, value, "", nil)
+	v.partial = false
+
+	if len(errs) == 0 {
+		return nil
+	}
+	p := problem.New(http.StatusBadRequest, problem.CodeValidation, "Request body failed validation")
+	p.Errors = errs
+	return p
+}
+
+func validateResponse(c *gin.Context, op *OperationObject, v *schemaValidator, body []byte) []problem.FieldError {
+	status := c.Writer.Status()
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return []problem.FieldError{{Field: "status", Rule: "documented", Message: "status " + strconv.Itoa(status) + " is not documented"}}
+	}
+	if len(resp.Content) == 0 {
+		return nil
+	}
+
+	contentType, _, _ := strings.Cut(c.Writer.Header().Get("Content-Type"), ";")
+	media, ok := resp.Content[strings.TrimSpace(contentType)]
+	if !ok {
+		return []problem.FieldError{{Field: "content-type", Rule: "documented", Message: "content type " + contentType + " is not documented for status " + strconv.Itoa(status)}}
+	}
+	// NDJSON is a stream of documents, not one, and is not checked.
+	if contentType != "application/json" && !strings.HasSuffix(contentType, "+json") {
+		return nil
+	}
+
+	value, err := decodeJSON(body)
+	if err != nil {
+		return []problem.FieldError{{Field: "body", Rule: "json", Message: "response body is not valid JSON"}}
+	}
+	return v.validate(media.Schema, value, "", nil)
+}
+
+func decodeJSON(body []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func logViolations(c *gin.Context, errs []problem.FieldError) {
+	for _, e := range errs {
+		log.Printf("openapi: response contract violation: %s %s [%d] %s %s (trace %s)",
+			c.Request.Method, c.FullPath(), c.Writer.Status(), e.Field, e.Message, problem.TraceID(c))
+	}
+}
+
+// recordingWriter keeps a copy of the response body for validation.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,155 @@
+package openapi
+
+import (
+	"bytes"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupValidatedGin(violations *[]problem.FieldError) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	doc := Build([]Operation{
+		{Method: http.MethodPost, Path: "/members", ID: "createMember", Request: models.TeamMember{}, Response: models.TeamMember{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/members/:id", ID: "updateMember", Request: models.TeamMember{}, Response: models.TeamMember{}},
+		{Method: http.MethodGet, Path: "/feedback", ID: "listFeedback", Response: []models.Feedback{},
+			Query: []Param{{Name: "target_id", Example: uint32(0)}, {Name: "dry_run", Example: false}}},
+	})
+
+	r := gin.New()
+	r.Use(Validator(ValidatorConfig{
+		Document:          doc,
+		ValidateResponses: true,
+		OnResponseViolation: func(c *gin.Context, errs []problem.FieldError) {
+			*violations = append(*violations, errs...)
+		},
+	}))
+
+	r.POST("/members", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"name": "John Doe", "email": "john@example.com", "nickname": "JD"})
+	})
+	r.PUT("/members/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, models.TeamMember{Name: "John Doe", Email: "john@example.com"})
+	})
+	r.GET("/feedback", func(c *gin.Context) {
+		c.JSON(http.StatusOK, []models.Feedback{})
+	})
+	r.GET("/undocumented", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func send(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) problem.Problem {
+	var p problem.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &p)
+	assert.NoError(t, err)
+	return p
+}
+
+func TestValidatorRequests(t *testing.T) {
+	var violations []problem.FieldError
+	r := setupValidatedGin(&violations)
+
+	t.Run("Non-numeric Query Parameter", func(t *testing.T) {
+		w := send(r, "GET", "/feedback?target_id=abc", "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		p := decodeProblem(t, w)
+		assert.Equal(t, problem.CodeInvalidParam, p.Code)
+		assert.Equal(t, "target_id", p.Errors[0].Field)
+		assert.Equal(t, "type", p.Errors[0].Rule)
+	})
+
+	t.Run("Valid Query Parameter", func(t *testing.T) {
+		w := send(r, "GET", "/feedback?target_id=12", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Boolean Query Parameter", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(r, "GET", "/feedback?dry_run=1", "").Code)
+		assert.Equal(t, http.StatusOK, send(r, "GET", "/feedback?dry_run=false", "").Code)
+
+		w := send(r, "GET", "/feedback?dry_run=maybe", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "dry_run", decodeProblem(t, w).Errors[0].Field)
+	})
+
+	t.Run("Invalid Path Parameter", func(t *testing.T) {
+		w := send(r, "PUT", "/members/0", `{"name":"John"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "id", decodeProblem(t, w).Errors[0].Field)
+	})
+
+	t.Run("Unknown And Invalid Body Fields", func(t *testing.T) {
+		w := send(r, "POST", "/members", `{"name":"John","email":"not-an-email","nickname":"JD"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		p := decodeProblem(t, w)
+		assert.Equal(t, problem.CodeValidation, p.Code)
+		assert.Equal(t, []problem.FieldError{
+			{Field: "email", Rule: "format", Message: "must be a valid email"},
+			{Field: "nickname", Rule: "unknown", Message: "is not a known field"},
+		}, p.Errors)
+	})
+
+	t.Run("Missing Required Body Field", func(t *testing.T) {
+		w := send(r, "POST", "/members", `{"name":"John"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "email", decodeProblem(t, w).Errors[0].Field)
+	})
+
+	t.Run("Partial PUT Body Allowed", func(t *testing.T) {
+		w := send(r, "PUT", "/members/1", `{"name":"John"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Malformed Body", func(t *testing.T) {
+		w := send(r, "POST", "/members", `{"name":`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, problem.CodeMalformedBody, decodeProblem(t, w).Code)
+	})
+
+	t.Run("Undocumented Route Passes Through", func(t *testing.T) {
+		w := send(r, "GET", "/undocumented", "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func TestValidatorResponses(t *testing.T) {
+	var violations []problem.FieldError
+	r := setupValidatedGin(&violations)
+
+	t.Run("Conforming Response", func(t *testing.T) {
+		violations = nil
+		w := send(r, "GET", "/feedback", "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("Response With Undocumented Field", func(t *testing.T) {
+		violations = nil
+		w := send(r, "POST", "/members", `{"name":"John","email":"john@example.com"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code, "violations are reported, not enforced")
+		assert.Equal(t, []problem.FieldError{{Field: "nickname", Rule: "unknown", Message: "is not a known field"}}, violations)
+	})
+}
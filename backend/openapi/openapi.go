@@ -0,0 +1,287 @@
+// Package openapi builds the OpenAPI 3.1 document for the API from the
+// operation catalog in operations.go and the model structs it references.
+package openapi
+
+import (
+	"coaching-backend/problem"
+	"coaching-backend/targets"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*ParameterObject   `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type ParameterObject struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Operation describes one route. Path uses Gin syntax (":id"); path
+// parameters are derived from it.
+type Operation struct {
+	Method  string
+	Path    string
+	ID      string
+	Summary string
+	Tag     string
+	Query   []Param
+	Request interface{}
+	// RequestContentType overrides application/json for the request body.
+	RequestContentType string
+	Response           interface{}
+	Status             int
+	// ContentType overrides application/json for non-JSON responses.
+	ContentType string
+	// Alternates are further content types the response may be rendered
+	// in, documented as plain strings.
+	Alternates []string
+	// Conditional documents a 304 response to requests with If-None-Match.
+	Conditional bool
+	// Redirect documents a 302 response pointing elsewhere.
+	Redirect bool
+	// Error overrides problem.Problem as the body of error responses, and
+	// ErrorContentType its media type, for routes that follow another
+	// protocol's error format.
+	Error            interface{}
+	ErrorContentType string
+}
+
+// Param is a query parameter. Schema is built from the Go type of Example.
+type Param struct {
+	Name        string
+	Description string
+	Example     interface{}
+	Required    bool
+}
+
+// Path converts a Gin route path to OpenAPI templating: /teams/:id -> /teams/{id}.
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Build assembles the document from ops.
+func Build(ops []Operation) *Document {
+	schemas := newSchemaRegistry()
+	problemRef := schemas.ref(problem.Problem{})
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "Coaching API",
+			Description: "Team members, teams, assignments and feedback.",
+			Version:     "1.0.0",
+		},
+		Servers: []Server{{URL: "/"}},
+		Paths:   map[string]*PathItem{},
+	}
+
+	tags := map[string]bool{}
+	for _, op := range ops {
+		path := Path(op.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(op.Method)] = buildOperation(op, schemas, problemRef)
+
+		if op.Tag != "" && !tags[op.Tag] {
+			tags[op.Tag] = true
+			doc.Tags = append(doc.Tags, Tag{Name: op.Tag})
+		}
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	doc.Components.Schemas = schemas.schemas
+	return doc
+}
+
+func buildOperation(op Operation, schemas *schemaRegistry, problemRef *Schema) *OperationObject {
+	o := &OperationObject{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Responses:   map[string]*Response{},
+	}
+	errorResponse := func(description string) *Response {
+		return problemResponse(description, problemRef)
+	}
+	if op.Error != nil {
+		errorRef := schemas.ref(op.Error)
+		errorResponse = func(description string) *Response {
+			return &Response{
+				Description: description,
+				Content:     map[string]*MediaType{op.ErrorContentType: {Schema: errorRef}},
+			}
+		}
+	}
+	if op.Tag != "" {
+		o.Tags = []string{op.Tag}
+	}
+
+	for _, seg := range strings.Split(op.Path, "/") {
+		if strings.HasPrefix(seg, ":") {
+			one := 1.0
+			o.Parameters = append(o.Parameters, &ParameterObject{
+				Name:     seg[1:],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "integer", Minimum: &one},
+			})
+		}
+	}
+	for _, q := range op.Query {
+		o.Parameters = append(o.Parameters, &ParameterObject{
+			Name:        q.Name,
+			In:          "query",
+			Description: q.Description,
+			Required:    q.Required,
+			Schema:      schemas.ref(q.Example),
+		})
+	}
+
+	if op.Request != nil {
+		contentType := op.RequestContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		o.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{contentType: {Schema: schemas.ref(op.Request)}},
+		}
+		o.Responses["400"] = errorResponse("Invalid request")
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	if op.Response != nil {
+		contentType := op.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		success.Content = map[string]*MediaType{contentType: {Schema: schemas.ref(op.Response)}}
+		alternate := &Schema{Type: "string"}
+		if _, ok := op.Response.(Binary); ok {
+			alternate = schemas.ref(op.Response)
+		}
+		for _, alt := range op.Alternates {
+			success.Content[alt] = &MediaType{Schema: alternate}
+		}
+	}
+	o.Responses[strconv.Itoa(status)] = success
+	if op.Conditional {
+		o.Responses[strconv.Itoa(http.StatusNotModified)] = &Response{Description: http.StatusText(http.StatusNotModified)}
+	}
+	if op.Redirect {
+		o.Responses[strconv.Itoa(http.StatusFound)] = &Response{Description: http.StatusText(http.StatusFound)}
+	}
+
+	if strings.Contains(op.Path, ":") {
+		o.Responses["400"] = errorResponse("Invalid request")
+		o.Responses["404"] = errorResponse("Not found")
+	}
+	o.Responses["default"] = errorResponse("Error")
+
+	return o
+}
+
+func problemResponse(description string, ref *Schema) *Response {
+	return &Response{
+		Description: description,
+		Content:     map[string]*MediaType{problem.ContentType: {Schema: ref}},
+	}
+}
+
+var (
+	specOnce sync.Once
+	spec     *Document
+)
+
+// Spec returns the document for the API's operation catalog.
+func Spec() *Document {
+	specOnce.Do(func() {
+		spec = Build(Operations)
+
+		// Target types come from the registry rather than a binding rule.
+		if feedback, ok := spec.Components.Schemas["Feedback"]; ok {
+			for _, info := range targets.Types() {
+				feedback.Properties["target_type"].Enum = append(feedback.Properties["target_type"].Enum, info.Type)
+			}
+		}
+	})
+	return spec
+}
+
+// Handler serves the OpenAPI document as JSON.
+func Handler(c *gin.Context) {
+	c.JSON(http.StatusOK, Spec())
+}
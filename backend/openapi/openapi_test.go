@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"coaching-backend/models"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "/api/members/{id}", Path("/api/members/:id"))
+	assert.Equal(t, "/api/assignments/member/{id}", Path("/api/assignments/member/:id"))
+	assert.Equal(t, "/health", Path("/health"))
+}
+
+func TestModelSchemas(t *testing.T) {
+	doc := Build([]Operation{
+		{Method: http.MethodPost, Path: "/members", ID: "createMember", Request: models.TeamMember{}, Response: models.TeamMember{}},
+		{Method: http.MethodPost, Path: "/assignments", ID: "assign", Request: models.AssignRequest{}},
+	})
+	schemas := doc.Components.Schemas
+
+	t.Run("TeamMember Binding Rules", func(t *testing.T) {
+		member := schemas["TeamMember"]
+		assert.NotNil(t, member)
+		assert.ElementsMatch(t, []string{"name", "email"}, member.Required)
+		assert.Equal(t, "email", member.Properties["email"].Format)
+		assert.True(t, member.Properties["id"].ReadOnly)
+		assert.Equal(t, "date-time", member.Properties["created_at"].Format)
+		assert.Equal(t, []string{"integer", "null"}, member.Properties["team_id"].Type)
+		assert.Equal(t, "#/components/schemas/Team", member.Properties["team"].Ref)
+	})
+
+	t.Run("Nested Schemas Registered", func(t *testing.T) {
+		team := schemas["Team"]
+		assert.NotNil(t, team)
+		assert.Equal(t, []string{"name"}, team.Required)
+		assert.Equal(t, "#/components/schemas/TeamMember", team.Properties["members"].Items.Ref)
+		assert.NotNil(t, schemas["Problem"])
+	})
+
+	t.Run("AssignRequest Required Fields", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"member_id", "team_id"}, schemas["AssignRequest"].Required)
+	})
+
+	t.Run("Enum From oneof", func(t *testing.T) {
+		s := &Schema{Type: "string"}
+		required := applyBindingRules(s, "required,oneof=asc desc,max=4")
+		assert.True(t, required)
+		assert.Equal(t, []interface{}{"asc", "desc"}, s.Enum)
+		assert.Equal(t, 4, *s.MaxLength)
+	})
+
+	t.Run("Operation Parameters And Responses", func(t *testing.T) {
+		doc := Build([]Operation{{Method: http.MethodGet, Path: "/teams/:id", ID: "getTeam", Response: models.Team{}}})
+		op := (*doc.Paths["/teams/{id}"])["get"]
+
+		assert.Equal(t, "id", op.Parameters[0].Name)
+		assert.Equal(t, "path", op.Parameters[0].In)
+		assert.Contains(t, op.Responses, "200")
+		assert.Contains(t, op.Responses, "404")
+	})
+}
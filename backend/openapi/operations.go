@@ -0,0 +1,306 @@
+package openapi
+
+import (
+	"coaching-backend/attachments"
+	"coaching-backend/chatops"
+	"coaching-backend/digest"
+	"coaching-backend/events"
+	"coaching-backend/graph"
+	"coaching-backend/models"
+	"coaching-backend/scim"
+	"coaching-backend/services"
+	"coaching-backend/targets"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+type AssignmentResponse struct {
+	Message string            `json:"message"`
+	Member  models.TeamMember `json:"member"`
+}
+
+// SessionResponse describes the signed-in member.
+type SessionResponse struct {
+	Member    models.TeamMember `json:"member"`
+	Role      string            `json:"role" binding:"oneof=member admin"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// FileUpload is the multipart/form-data body of a file upload.
+type FileUpload struct {
+	File Binary `json:"file" binding:"required"`
+}
+
+var scimQuery = []Param{
+	{Name: "filter", Description: `SCIM filter, e.g. userName eq "ada@example.com"`, Example: ""},
+	{Name: "startIndex", Description: "1-based index of the first result (default: 1)", Example: 0},
+	{Name: "count", Description: "Results per page, at most 500 (default: 100)", Example: 0},
+}
+
+// scimOperation fills in what every SCIM route shares: its tag and the
+// application/scim+json media type of bodies and errors.
+func scimOperation(op Operation) Operation {
+	op.Path = scim.Base + op.Path
+	op.Tag = "SCIM"
+	if op.Request != nil {
+		op.RequestContentType = scim.ContentType
+	}
+	if op.Response != nil {
+		op.ContentType = scim.ContentType
+	}
+	op.Error, op.ErrorContentType = scim.Error{}, scim.ContentType
+	return op
+}
+
+var imageQuery = []Param{
+	{Name: "size", Description: "Thumbnail edge length, 64 or 256 (default: the original upload, or 256 for a default picture)", Example: 0},
+	{Name: "v", Description: "Image version from the picture or logo URL; makes the response cacheable forever", Example: ""},
+	{Name: "format", Description: "svg or png, for default pictures drawn when nothing was uploaded (default: svg)", Example: ""},
+	{Name: "style", Description: "initials or identicon, for default pictures (default: initials for members, identicon for teams)", Example: ""},
+}
+
+var imageFormats = []string{"image/jpeg", "image/gif", "image/webp", "image/svg+xml"}
+
+var eventQuery = []Param{
+	{Name: "team_id", Description: "Only events concerning this team", Example: uint32(0)},
+	{Name: "target_type", Description: "Only events for this target type", Example: ""},
+	{Name: "target_id", Description: "Only events for this target ID", Example: uint32(0)},
+	{Name: "types", Description: "Comma-separated event types", Example: ""},
+}
+
+var digestQuery = []Param{
+	{Name: "from", Description: "Start of the period, RFC 3339 or YYYY-MM-DD (default: 7 days before to)", Example: ""},
+	{Name: "to", Description: "End of the period, exclusive, RFC 3339 or YYYY-MM-DD (default: now)", Example: ""},
+	{Name: "format", Description: "json, html or markdown (default: json)", Example: ""},
+}
+
+var digestFormats = []string{"text/html", "text/markdown"}
+
+var exportQuery = []Param{
+	{Name: "format", Description: "csv, ndjson or xlsx (default: csv)", Example: ""},
+}
+
+var exportFormats = []string{"application/x-ndjson", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}
+
+var feedbackQuery = []Param{
+	{Name: "target_type", Description: "Only feedback for this target type", Example: ""},
+	{Name: "target_id", Description: "Only feedback for this target ID", Example: uint32(0)},
+}
+
+// Operations is the catalog of every route registered in routes.go.
+var Operations = []Operation{
+	{Method: http.MethodPost, Path: "/api/members", ID: "createTeamMember", Summary: "Create team member", Tag: "Members",
+		Request: models.TeamMember{}, Response: models.TeamMember{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/members", ID: "listTeamMembers", Summary: "List team members", Tag: "Members",
+		Response: []models.TeamMember{}},
+	{Method: http.MethodGet, Path: "/api/members/export", ID: "exportTeamMembers", Summary: "Download all team members as CSV, NDJSON or XLSX", Tag: "Members",
+		Query: exportQuery, Response: Binary{}, ContentType: "text/csv", Alternates: exportFormats},
+	{Method: http.MethodPost, Path: "/api/members/import", ID: "importTeamMembers", Summary: "Create or update members from a CSV or XLSX file (at most 10 MB)", Tag: "Members",
+		Query: []Param{
+			{Name: "dry_run", Description: "Only report what the import would change", Example: false},
+			{Name: "create_teams", Description: "Create teams named in the file that do not exist", Example: false},
+		},
+		Request: FileUpload{}, RequestContentType: "multipart/form-data", Response: services.ImportReport{}},
+	{Method: http.MethodGet, Path: "/api/members/:id", ID: "getTeamMember", Summary: "Get team member", Tag: "Members",
+		Response: models.TeamMember{}},
+	{Method: http.MethodPut, Path: "/api/members/:id", ID: "updateTeamMember", Summary: "Update team member", Tag: "Members",
+		Request: models.TeamMember{}, Response: models.TeamMember{}},
+	{Method: http.MethodDelete, Path: "/api/members/:id", ID: "deleteTeamMember", Summary: "Delete team member", Tag: "Members",
+		Response: MessageResponse{}},
+	{Method: http.MethodGet, Path: "/api/members/:id/notification-preferences", ID: "getNotificationPreferences", Summary: "Get member's email notification preferences", Tag: "Members",
+		Response: models.NotificationPreferences{}},
+	{Method: http.MethodPut, Path: "/api/members/:id/notification-preferences", ID: "updateNotificationPreferences", Summary: "Update member's email notification preferences", Tag: "Members",
+		Request: models.NotificationPreferences{}, Response: models.NotificationPreferences{}},
+	{Method: http.MethodGet, Path: "/api/members/:id/digest", ID: "getMemberDigest", Summary: "Preview member's digest of feedback and team changes", Tag: "Members",
+		Query: digestQuery, Response: digest.Digest{}, Alternates: digestFormats},
+	{Method: http.MethodPut, Path: "/api/members/:id/avatar", ID: "uploadMemberAvatar", Summary: "Upload member's avatar (PNG, JPEG, GIF or WebP, at most 5 MB)", Tag: "Members",
+		Request: FileUpload{}, RequestContentType: "multipart/form-data", Response: models.Image{}},
+	{Method: http.MethodGet, Path: "/api/members/:id/avatar", ID: "getMemberAvatar", Summary: "Get member's avatar, its stored picture URL or a default picture", Tag: "Members",
+		Query: imageQuery, Response: Binary{}, ContentType: "image/png", Alternates: imageFormats, Conditional: true, Redirect: true},
+	{Method: http.MethodDelete, Path: "/api/members/:id/avatar", ID: "deleteMemberAvatar", Summary: "Delete member's avatar", Tag: "Members",
+		Response: MessageResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/teams", ID: "createTeam", Summary: "Create team", Tag: "Teams",
+		Request: models.Team{}, Response: models.Team{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/teams", ID: "listTeams", Summary: "List teams with members", Tag: "Teams",
+		Response: []models.Team{}},
+	{Method: http.MethodGet, Path: "/api/teams/export", ID: "exportTeams", Summary: "Download all teams as CSV, NDJSON or XLSX", Tag: "Teams",
+		Query: exportQuery, Response: Binary{}, ContentType: "text/csv", Alternates: exportFormats},
+	{Method: http.MethodGet, Path: "/api/teams/:id", ID: "getTeam", Summary: "Get team with members", Tag: "Teams",
+		Response: models.Team{}},
+	{Method: http.MethodPut, Path: "/api/teams/:id", ID: "updateTeam", Summary: "Update team", Tag: "Teams",
+		Request: models.Team{}, Response: models.Team{}},
+	{Method: http.MethodDelete, Path: "/api/teams/:id", ID: "deleteTeam", Summary: "Delete team", Tag: "Teams",
+		Response: MessageResponse{}},
+	{Method: http.MethodGet, Path: "/api/teams/:id/digest", ID: "getTeamDigest", Summary: "Preview team's digest of feedback and membership changes", Tag: "Teams",
+		Query: digestQuery, Response: digest.Digest{}, Alternates: digestFormats},
+	{Method: http.MethodPut, Path: "/api/teams/:id/logo", ID: "uploadTeamLogo", Summary: "Upload team's logo (PNG, JPEG, GIF or WebP, at most 5 MB)", Tag: "Teams",
+		Request: FileUpload{}, RequestContentType: "multipart/form-data", Response: models.Image{}},
+	{Method: http.MethodGet, Path: "/api/teams/:id/logo", ID: "getTeamLogo", Summary: "Get team's logo, its stored logo URL or a default picture", Tag: "Teams",
+		Query: imageQuery, Response: Binary{}, ContentType: "image/png", Alternates: imageFormats, Conditional: true, Redirect: true},
+	{Method: http.MethodDelete, Path: "/api/teams/:id/logo", ID: "deleteTeamLogo", Summary: "Delete team's logo", Tag: "Teams",
+		Response: MessageResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/projects", ID: "createProject", Summary: "Create project, a feedback target", Tag: "Projects",
+		Request: models.Project{}, Response: models.Project{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/projects", ID: "listProjects", Summary: "List projects by name", Tag: "Projects",
+		Response: []models.Project{}},
+	{Method: http.MethodGet, Path: "/api/projects/:id", ID: "getProject", Summary: "Get project", Tag: "Projects",
+		Response: models.Project{}},
+	{Method: http.MethodPut, Path: "/api/projects/:id", ID: "updateProject", Summary: "Update project", Tag: "Projects",
+		Request: models.Project{}, Response: models.Project{}},
+	{Method: http.MethodDelete, Path: "/api/projects/:id", ID: "deleteProject", Summary: "Delete project; its feedback keeps the name", Tag: "Projects",
+		Response: MessageResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/releases", ID: "createRelease", Summary: "Create release, a feedback target", Tag: "Releases",
+		Request: models.Release{}, Response: models.Release{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/releases", ID: "listReleases", Summary: "List releases, newest first", Tag: "Releases",
+		Response: []models.Release{}},
+	{Method: http.MethodGet, Path: "/api/releases/:id", ID: "getRelease", Summary: "Get release", Tag: "Releases",
+		Response: models.Release{}},
+	{Method: http.MethodPut, Path: "/api/releases/:id", ID: "updateRelease", Summary: "Update release", Tag: "Releases",
+		Request: models.Release{}, Response: models.Release{}},
+	{Method: http.MethodDelete, Path: "/api/releases/:id", ID: "deleteRelease", Summary: "Delete release; its feedback keeps the name", Tag: "Releases",
+		Response: MessageResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/meetings", ID: "createMeeting", Summary: "Create meeting, a feedback target", Tag: "Meetings",
+		Request: models.Meeting{}, Response: models.Meeting{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/meetings", ID: "listMeetings", Summary: "List meetings, latest scheduled first", Tag: "Meetings",
+		Response: []models.Meeting{}},
+	{Method: http.MethodGet, Path: "/api/meetings/:id", ID: "getMeeting", Summary: "Get meeting", Tag: "Meetings",
+		Response: models.Meeting{}},
+	{Method: http.MethodPut, Path: "/api/meetings/:id", ID: "updateMeeting", Summary: "Update meeting", Tag: "Meetings",
+		Request: models.Meeting{}, Response: models.Meeting{}},
+	{Method: http.MethodDelete, Path: "/api/meetings/:id", ID: "deleteMeeting", Summary: "Delete meeting; its feedback keeps the name", Tag: "Meetings",
+		Response: MessageResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/assignments", ID: "assignMemberToTeam", Summary: "Assign member to team", Tag: "Assignments",
+		Request: models.AssignRequest{}, Response: AssignmentResponse{}},
+	{Method: http.MethodGet, Path: "/api/assignments", ID: "listAssignments", Summary: "List members assigned to a team", Tag: "Assignments",
+		Response: []models.TeamMember{}},
+	{Method: http.MethodGet, Path: "/api/assignments/export", ID: "exportAssignments", Summary: "Download members assigned to a team as CSV, NDJSON or XLSX", Tag: "Assignments",
+		Query: exportQuery, Response: Binary{}, ContentType: "text/csv", Alternates: exportFormats},
+	{Method: http.MethodGet, Path: "/api/assignments/unassigned", ID: "listUnassignedMembers", Summary: "List members without a team", Tag: "Assignments",
+		Response: []models.TeamMember{}},
+	{Method: http.MethodDelete, Path: "/api/assignments/member/:id", ID: "removeMemberFromTeam", Summary: "Remove member from team", Tag: "Assignments",
+		Response: AssignmentResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/feedback", ID: "createFeedback", Summary: "Create feedback", Tag: "Feedback",
+		Request: models.Feedback{}, Response: models.Feedback{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/feedback", ID: "listFeedback", Summary: "List feedback, newest first", Tag: "Feedback",
+		Query: feedbackQuery, Response: []models.Feedback{}},
+	{Method: http.MethodGet, Path: "/api/feedback/export", ID: "exportFeedback", Summary: "Download feedback as CSV, NDJSON or XLSX, oldest first", Tag: "Feedback",
+		Query: append(slices.Clone(feedbackQuery), exportQuery...), Response: Binary{}, ContentType: "text/csv", Alternates: exportFormats},
+	{Method: http.MethodGet, Path: "/api/feedback/target-types", ID: "listFeedbackTargetTypes", Summary: "List feedback target types", Tag: "Feedback",
+		Response: []targets.TypeInfo{}},
+	{Method: http.MethodGet, Path: "/api/feedback/:id", ID: "getFeedback", Summary: "Get feedback", Tag: "Feedback",
+		Response: models.Feedback{}},
+	{Method: http.MethodPut, Path: "/api/feedback/:id", ID: "updateFeedback", Summary: "Update feedback", Tag: "Feedback",
+		Request: models.Feedback{}, Response: models.Feedback{}},
+	{Method: http.MethodDelete, Path: "/api/feedback/:id", ID: "deleteFeedback", Summary: "Delete feedback", Tag: "Feedback",
+		Response: MessageResponse{}},
+	{Method: http.MethodPost, Path: "/api/feedback/:id/attachments", ID: "uploadAttachment", Summary: "Attach a file to feedback", Tag: "Feedback",
+		Request: FileUpload{}, RequestContentType: "multipart/form-data", Response: models.Attachment{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/feedback/:id/attachments", ID: "listAttachments", Summary: "List feedback's attachments", Tag: "Feedback",
+		Response: []models.Attachment{}},
+	{Method: http.MethodGet, Path: "/api/feedback/:id/attachments/:attachment_id", ID: "downloadAttachment", Summary: "Download attachment", Tag: "Feedback",
+		Response: Binary{}, ContentType: attachments.Default.AllowedTypes[0], Alternates: attachments.Default.AllowedTypes[1:]},
+	{Method: http.MethodDelete, Path: "/api/feedback/:id/attachments/:attachment_id", ID: "deleteAttachment", Summary: "Delete attachment", Tag: "Feedback",
+		Response: MessageResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/webhooks", ID: "createWebhook", Summary: "Create webhook; the response includes the signing secret", Tag: "Webhooks",
+		Request: models.Webhook{}, Response: models.Webhook{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/webhooks", ID: "listWebhooks", Summary: "List webhooks", Tag: "Webhooks",
+		Response: []models.Webhook{}},
+	{Method: http.MethodGet, Path: "/api/webhooks/:id", ID: "getWebhook", Summary: "Get webhook", Tag: "Webhooks",
+		Response: models.Webhook{}},
+	{Method: http.MethodPut, Path: "/api/webhooks/:id", ID: "updateWebhook", Summary: "Update webhook; set active to re-enable it", Tag: "Webhooks",
+		Request: models.Webhook{}, Response: models.Webhook{}},
+	{Method: http.MethodDelete, Path: "/api/webhooks/:id", ID: "deleteWebhook", Summary: "Delete webhook and its delivery log", Tag: "Webhooks",
+		Response: MessageResponse{}},
+	{Method: http.MethodGet, Path: "/api/webhooks/:id/deliveries", ID: "listWebhookDeliveries", Summary: "List recent deliveries, newest first", Tag: "Webhooks",
+		Response: []models.WebhookDelivery{}},
+	{Method: http.MethodPost, Path: "/api/webhooks/:id/deliveries/:delivery_id/redeliver", ID: "redeliverWebhookDelivery", Summary: "Queue a delivery to be sent again", Tag: "Webhooks",
+		Response: models.WebhookDelivery{}, Status: http.StatusAccepted},
+
+	{Method: http.MethodPost, Path: "/api/api-keys", ID: "createAPIKey", Summary: "Create API key; the response includes the token", Tag: "API Keys",
+		Request: models.APIKey{}, Response: models.APIKey{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/api-keys", ID: "listAPIKeys", Summary: "List API keys, newest first", Tag: "API Keys",
+		Response: []models.APIKey{}},
+	{Method: http.MethodGet, Path: "/api/api-keys/:id", ID: "getAPIKey", Summary: "Get API key", Tag: "API Keys",
+		Response: models.APIKey{}},
+	{Method: http.MethodPost, Path: "/api/api-keys/:id/rotate", ID: "rotateAPIKey", Summary: "Replace the key's token; the response includes the new one", Tag: "API Keys",
+		Response: models.APIKey{}},
+	{Method: http.MethodDelete, Path: "/api/api-keys/:id", ID: "revokeAPIKey", Summary: "Revoke API key", Tag: "API Keys",
+		Response: MessageResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/auth/login", ID: "login", Summary: "Sign in with the identity provider, then return to the app", Tag: "Auth",
+		Query: []Param{{Name: "return_to", Description: "Path on the app to return to (default: /)", Example: ""}}, Status: http.StatusFound},
+	{Method: http.MethodGet, Path: "/api/auth/callback", ID: "loginCallback", Summary: "Complete sign-in; the identity provider redirects here", Tag: "Auth",
+		Query: []Param{{Name: "code", Example: ""}, {Name: "state", Example: "", Required: true}, {Name: "error", Example: ""}}, Status: http.StatusFound},
+	{Method: http.MethodGet, Path: "/api/auth/session", ID: "getSession", Summary: "Get the signed-in member", Tag: "Auth",
+		Response: SessionResponse{}},
+	{Method: http.MethodPost, Path: "/api/auth/logout", ID: "logout", Summary: "Sign out", Tag: "Auth",
+		Status: http.StatusNoContent},
+
+	{Method: http.MethodPost, Path: "/api/chatops/kudos", ID: "slashCommandKudos", Summary: "Give feedback from a Slack or Mattermost slash command", Tag: "Chat",
+		Request: chatops.Command{}, RequestContentType: "application/x-www-form-urlencoded", Response: chatops.Message{}},
+
+	scimOperation(Operation{Method: http.MethodGet, Path: "/ServiceProviderConfig", ID: "getSCIMServiceProviderConfig", Summary: "SCIM features this server supports",
+		Response: scim.ServiceProviderConfig{}}),
+	scimOperation(Operation{Method: http.MethodGet, Path: "/ResourceTypes", ID: "listSCIMResourceTypes", Summary: "SCIM resource types",
+		Response: scim.ListResponse[scim.ResourceType]{}}),
+	scimOperation(Operation{Method: http.MethodGet, Path: "/Schemas", ID: "listSCIMSchemas", Summary: "SCIM schemas of Users and Groups",
+		Response: scim.ListResponse[scim.Schema]{}}),
+	scimOperation(Operation{Method: http.MethodGet, Path: "/Users", ID: "listSCIMUsers", Summary: "List team members as SCIM Users",
+		Query: scimQuery, Response: scim.ListResponse[scim.User]{}}),
+	scimOperation(Operation{Method: http.MethodPost, Path: "/Users", ID: "createSCIMUser", Summary: "Provision a team member",
+		Request: scim.User{}, Response: scim.User{}, Status: http.StatusCreated}),
+	scimOperation(Operation{Method: http.MethodGet, Path: "/Users/:id", ID: "getSCIMUser", Summary: "Get a team member as a SCIM User",
+		Response: scim.User{}}),
+	scimOperation(Operation{Method: http.MethodPut, Path: "/Users/:id", ID: "replaceSCIMUser", Summary: "Replace a team member; active false deletes them",
+		Request: scim.User{}, Response: scim.User{}}),
+	scimOperation(Operation{Method: http.MethodPatch, Path: "/Users/:id", ID: "patchSCIMUser", Summary: "Patch a team member; active false deletes them",
+		Request: scim.PatchRequest{}, Response: scim.User{}}),
+	scimOperation(Operation{Method: http.MethodDelete, Path: "/Users/:id", ID: "deleteSCIMUser", Summary: "Deprovision a team member",
+		Status: http.StatusNoContent}),
+	scimOperation(Operation{Method: http.MethodGet, Path: "/Groups", ID: "listSCIMGroups", Summary: "List teams as SCIM Groups",
+		Query: scimQuery, Response: scim.ListResponse[scim.Group]{}}),
+	scimOperation(Operation{Method: http.MethodPost, Path: "/Groups", ID: "createSCIMGroup", Summary: "Create a team and assign its members",
+		Request: scim.Group{}, Response: scim.Group{}, Status: http.StatusCreated}),
+	scimOperation(Operation{Method: http.MethodGet, Path: "/Groups/:id", ID: "getSCIMGroup", Summary: "Get a team as a SCIM Group",
+		Response: scim.Group{}}),
+	scimOperation(Operation{Method: http.MethodPut, Path: "/Groups/:id", ID: "replaceSCIMGroup", Summary: "Rename a team and replace its members",
+		Request: scim.Group{}, Response: scim.Group{}}),
+	scimOperation(Operation{Method: http.MethodPatch, Path: "/Groups/:id", ID: "patchSCIMGroup", Summary: "Patch a team's name and members",
+		Request: scim.PatchRequest{}, Response: scim.Group{}}),
+	scimOperation(Operation{Method: http.MethodDelete, Path: "/Groups/:id", ID: "deleteSCIMGroup", Summary: "Delete a team",
+		Status: http.StatusNoContent}),
+
+	{Method: http.MethodPost, Path: "/api/graphql", ID: "graphql", Summary: "Execute a GraphQL query or mutation", Tag: "GraphQL",
+		Request: graph.Request{}, Response: graph.Response{}},
+
+	{Method: http.MethodGet, Path: "/api/events", ID: "streamEvents", Summary: "Stream domain events (Server-Sent Events)", Tag: "Events",
+		Query:    eventQuery,
+		Response: events.Event{}, ContentType: "text/event-stream"},
+	{Method: http.MethodGet, Path: "/api/events/ws", ID: "streamEventsWebSocket", Summary: "Stream domain events over a WebSocket", Tag: "Events",
+		Query:  append(eventQuery, Param{Name: "last_event_id", Description: "Resume after this event ID", Example: uint64(0)}),
+		Status: http.StatusSwitchingProtocols},
+
+	{Method: http.MethodGet, Path: "/api/openapi.json", ID: "getOpenAPI", Summary: "OpenAPI document", Tag: "Meta",
+		Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: "/api/docs", ID: "getDocs", Summary: "API documentation UI", Tag: "Meta",
+		Response: "", ContentType: "text/html"},
+	{Method: http.MethodGet, Path: "/health", ID: "getHealth", Summary: "Health check", Tag: "Meta",
+		Response: HealthResponse{}},
+}
@@ -0,0 +1,211 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of JSON Schema 2020-12 used by the document.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Binary is file content, such as an uploaded or served image.
+type Binary []byte
+
+var binaryType = reflect.TypeOf(Binary{})
+
+// readOnlyFields are set by the server and ignored when sent by clients.
+var readOnlyFields = map[string]bool{
+	"ID":          true,
+	"CreatedAt":   true,
+	"UpdatedAt":   true,
+	"TargetName":  true,
+	"Failures":    true,
+	"DisabledAt":  true,
+	"Attachments": true,
+}
+
+// schemaRegistry collects named component schemas while reflecting over
+// model types, so nested structs become $refs instead of inline copies.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: map[string]*Schema{}}
+}
+
+// ref returns a schema for v, registering named struct types as components.
+func (r *schemaRegistry) ref(v interface{}) *Schema {
+	return r.schemaFor(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	if t == binaryType {
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := r.schemaFor(t.Elem())
+		return nullable(s)
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := r.schemas[name]; !ok {
+			// Reserve the name first so self-referencing types terminate.
+			r.schemas[name] = &Schema{}
+			*r.schemas[name] = *r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		return &Schema{}
+	}
+}
+
+// schemaName names the schema of a named type. Instances of generic types
+// are named after the type and its arguments, so ListResponse[scim.User]
+// is ListResponseUser.
+func schemaName(t reflect.Type) string {
+	name, args, ok := strings.Cut(t.Name(), "[")
+	if !ok {
+		return name
+	}
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		name += arg[strings.LastIndex(arg, ".")+1:]
+	}
+	return name
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := r.schemaFor(field.Type)
+		if readOnlyFields[field.Name] {
+			prop.ReadOnly = true
+		}
+		if applyBindingRules(prop, field.Tag.Get("binding")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+
+	return s
+}
+
+// applyBindingRules turns gin binding rules into schema constraints and
+// reports whether the field is required.
+func applyBindingRules(s *Schema, binding string) bool {
+	required := false
+	if binding == "" {
+		return required
+	}
+
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+			// The validator treats zero as missing for numbers.
+			if s.Type == "integer" {
+				one := 1.0
+				s.Minimum = &one
+			}
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, v)
+			}
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			applyBound(s, name, n)
+		}
+	}
+
+	return required
+}
+
+func applyBound(s *Schema, rule string, n int) {
+	if s.Type == "string" {
+		if rule == "min" {
+			s.MinLength = &n
+		} else {
+			s.MaxLength = &n
+		}
+		return
+	}
+
+	f := float64(n)
+	if rule == "min" {
+		s.Minimum = &f
+	} else {
+		s.Maximum = &f
+	}
+}
+
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return s
+	}
+	if t, ok := s.Type.(string); ok {
+		s.Type = []string{t, "null"}
+	}
+	return s
+}
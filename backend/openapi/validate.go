@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"coaching-backend/problem"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaValidator checks decoded JSON values against document schemas.
+type schemaValidator struct {
+	schemas map[string]*Schema
+	// partial skips "required", for PUT bodies merged into a stored record.
+	partial bool
+}
+
+func (v *schemaValidator) resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = v.schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// validate appends a FieldError for every violation of s found in value.
+// path is the dotted location used as the error's field name.
+func (v *schemaValidator) validate(s *Schema, value interface{}, path string, errs []problem.FieldError) []problem.FieldError {
+	s = v.resolve(s)
+	if s == nil {
+		return errs
+	}
+
+	fail := func(rule, format string, args ...interface{}) {
+		errs = append(errs, problem.FieldError{Field: fieldName(path), Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	types := schemaTypes(s)
+	if len(types) > 0 {
+		matched := ""
+		for _, t := range types {
+			if hasType(value, t) {
+				matched = t
+				break
+			}
+		}
+		if matched == "" {
+			fail("type", "must be of type %s", strings.Join(types, " or "))
+			return errs
+		}
+		if matched == "null" {
+			return errs
+		}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		var allowed []string
+		for _, e := range s.Enum {
+			allowed = append(allowed, fmt.Sprint(e))
+		}
+		fail("enum", "must be one of: %s", strings.Join(allowed, ", "))
+	}
+
+	switch val := value.(type) {
+	case string:
+		if s.MinLength != nil && len([]rune(val)) < *s.MinLength {
+			fail("min", "must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && len([]rune(val)) > *s.MaxLength {
+			fail("max", "must be at most %d characters", *s.MaxLength)
+		}
+		if !validFormat(s.Format, val) {
+			fail("format", "must be a valid %s", s.Format)
+		}
+	case json.Number:
+		f, _ := val.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("min", "must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("max", "must be at most %v", *s.Maximum)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range val {
+				errs = v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case map[string]interface{}:
+		if s.Properties == nil {
+			return errs
+		}
+		if !v.partial {
+			for _, name := range s.Required {
+				if _, ok := val[name]; !ok {
+					errs = append(errs, problem.FieldError{Field: fieldName(join(path, name)), Rule: "required", Message: "is required"})
+				}
+			}
+		}
+
+		names := make([]string, 0, len(val))
+		for name := range val {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties == nil || !*s.AdditionalProperties {
+					errs = append(errs, problem.FieldError{Field: fieldName(join(path, name)), Rule: "unknown", Message: "is not a known field"})
+				}
+				continue
+			}
+			errs = v.validate(prop, val[name], join(path, name), errs)
+		}
+	}
+
+	return errs
+}
+
+func schemaTypes(s *Schema) []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return nil
+}
+
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "null":
+		return value == nil
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseInt(n.String(), 10, 64)
+		return err == nil
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func validFormat(format, value string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil
+	}
+	return true
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func fieldName(path string) string {
+	if path == "" {
+		return "body"
+	}
+	return path
+}
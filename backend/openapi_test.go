@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"coaching-backend/blob"
+	"coaching-backend/config"
+	"coaching-backend/handlers"
+	"coaching-backend/openapi"
+	"coaching-backend/problem"
+	"coaching-backend/tests/testutils"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPICoversAllRoutes(t *testing.T) {
+	r := setupTestRouter()
+	doc := openapi.Spec()
+
+	t.Run("Every Route Is Documented", func(t *testing.T) {
+		for _, route := range r.Routes() {
+			item, ok := doc.Paths[openapi.Path(route.Path)]
+			if !assert.True(t, ok, "route %s %s missing from OpenAPI paths", route.Method, route.Path) {
+				continue
+			}
+			_, ok = (*item)[strings.ToLower(route.Method)]
+			assert.True(t, ok, "route %s %s missing from OpenAPI operations", route.Method, route.Path)
+		}
+	})
+
+	t.Run("Every Documented Operation Is Routed", func(t *testing.T) {
+		routed := map[string]bool{}
+		for _, route := range r.Routes() {
+			routed[route.Method+" "+route.Path] = true
+		}
+
+		for _, op := range openapi.Operations {
+			assert.True(t, routed[op.Method+" "+op.Path], "documented operation %s %s has no route", op.Method, op.Path)
+		}
+	})
+}
+
+func TestOpenAPIEndpoints(t *testing.T) {
+	r := setupTestRouter()
+
+	t.Run("Serve OpenAPI Document", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/openapi.json", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var doc map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &doc)
+		assert.NoError(t, err)
+		assert.Equal(t, "3.1.0", doc["openapi"])
+		assert.Contains(t, doc["paths"], "/api/members/{id}")
+	})
+
+	t.Run("Serve Docs UI", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/docs", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, w.Body.String(), "openapi.json")
+	})
+}
+
+// TestAPIConformsToOpenAPI drives the real handlers through the validator
+// and fails on any response that does not match the document.
+func TestAPIConformsToOpenAPI(t *testing.T) {
+	testutils.SetupTestDB(t)
+	blob.Default = &blob.FileStore{Root: t.TempDir()}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(problem.Trace(), openapi.Validator(openapi.ValidatorConfig{
+		ValidateResponses: true,
+		OnResponseViolation: func(c *gin.Context, errs []problem.FieldError) {
+			t.Errorf("%s %s violates the OpenAPI document: %+v", c.Request.Method, c.Request.URL, errs)
+		},
+	}))
+	cfg := config.Default()
+	cfg.SCIM.Token = "scim-token"
+	require.NoError(t, registerRoutes(r, cfg, &handlers.RateLimits{}))
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"POST", "/api/members", `{"name":"John Doe","email":"john@example.com","picture":""}`, http.StatusCreated},
+		{"POST", "/api/members", `{"name":"John Doe","email":"john@example.com"}`, http.StatusConflict},
+		{"POST", "/api/members", `{"name":"John Doe","email":"john@example.com","age":3}`, http.StatusBadRequest},
+		{"POST", "/api/teams", `{"name":"Development Team","logo":""}`, http.StatusCreated},
+		{"POST", "/api/assignments", `{"member_id":1,"team_id":1}`, http.StatusOK},
+		{"GET", "/api/members", "", http.StatusOK},
+		{"GET", "/api/members/1", "", http.StatusOK},
+		{"GET", "/api/members/999", "", http.StatusNotFound},
+		{"GET", "/api/members/abc", "", http.StatusBadRequest},
+		{"PUT", "/api/members/1", `{"name":"Johnny Doe"}`, http.StatusOK},
+		{"GET", "/api/teams", "", http.StatusOK},
+		{"GET", "/api/teams/1", "", http.StatusOK},
+		{"GET", "/api/assignments", "", http.StatusOK},
+		{"GET", "/api/assignments/unassigned", "", http.StatusOK},
+		{"POST", "/api/feedback", `{"content":"Great demo","target_type":"member","target_id":1}`, http.StatusCreated},
+		{"POST", "/api/feedback", `{"content":"Great demo","target_type":"planet","target_id":1}`, http.StatusBadRequest},
+		{"GET", "/api/feedback?target_type=member&target_id=1", "", http.StatusOK},
+		{"GET", "/api/feedback?target_id=abc", "", http.StatusBadRequest},
+		{"GET", "/api/feedback/export?target_type=member&format=ndjson", "", http.StatusOK},
+		{"GET", "/api/feedback/export?target_id=abc", "", http.StatusBadRequest},
+		{"GET", "/api/members/export", "", http.StatusOK},
+		{"GET", "/api/members/export?format=pdf", "", http.StatusBadRequest},
+		{"GET", "/api/teams/export?format=xlsx", "", http.StatusOK},
+		{"GET", "/api/assignments/export?format=csv", "", http.StatusOK},
+		{"GET", "/api/feedback/1", "", http.StatusOK},
+		{"GET", "/api/feedback/target-types", "", http.StatusOK},
+		{"PUT", "/api/feedback/1", `{"content":"Great demo!"}`, http.StatusOK},
+		{"GET", "/api/feedback/1/attachments", "", http.StatusOK},
+		{"GET", "/api/feedback/999/attachments", "", http.StatusNotFound},
+		{"POST", "/api/feedback/1/attachments", `{"file":"x"}`, http.StatusBadRequest},
+		{"GET", "/api/feedback/1/attachments/999", "", http.StatusNotFound},
+		{"DELETE", "/api/feedback/1/attachments/abc", "", http.StatusBadRequest},
+		{"GET", "/api/members/1/notification-preferences", "", http.StatusOK},
+		{"POST", "/api/members/import", `{"file":"x"}`, http.StatusBadRequest},
+		{"POST", "/api/members/import?dry_run=maybe", "", http.StatusBadRequest},
+		{"PUT", "/api/members/1/notification-preferences", `{"feedback_emails":false}`, http.StatusOK},
+		{"PUT", "/api/members/1/notification-preferences", `{"feedback_emails":"no"}`, http.StatusBadRequest},
+		{"GET", "/api/members/1/digest", "", http.StatusOK},
+		{"GET", "/api/members/1/avatar", "", http.StatusOK},
+		{"GET", "/api/members/1/avatar?format=png&size=64", "", http.StatusOK},
+		{"GET", "/api/members/1/avatar?format=gif", "", http.StatusBadRequest},
+		{"GET", "/api/members/999/avatar", "", http.StatusNotFound},
+		{"GET", "/api/members/1/avatar?size=abc", "", http.StatusBadRequest},
+		{"PUT", "/api/members/1/avatar", `{"file":"x"}`, http.StatusBadRequest},
+		{"DELETE", "/api/members/1/avatar", "", http.StatusOK},
+		{"GET", "/api/teams/1/logo?size=100", "", http.StatusBadRequest},
+		{"GET", "/api/teams/1/logo?style=initials", "", http.StatusOK},
+		{"DELETE", "/api/teams/1/logo", "", http.StatusOK},
+		{"GET", "/api/members/1/digest?format=html", "", http.StatusOK},
+		{"GET", "/api/members/1/digest?format=pdf", "", http.StatusBadRequest},
+		{"GET", "/api/members/1/digest?from=2026-02-01&to=2026-01-01", "", http.StatusBadRequest},
+		{"GET", "/api/teams/1/digest?format=markdown&from=2026-01-01&to=2026-01-08", "", http.StatusOK},
+		{"GET", "/api/teams/999/digest", "", http.StatusNotFound},
+		{"POST", "/api/webhooks", `{"url":"https://example.com/hook","events":["feedback.created"]}`, http.StatusCreated},
+		{"POST", "/api/webhooks", `{"url":"https://example.com/hook","events":"feedback.created"}`, http.StatusBadRequest},
+		{"GET", "/api/webhooks", "", http.StatusOK},
+		{"GET", "/api/webhooks/1", "", http.StatusOK},
+		{"PUT", "/api/webhooks/1", `{"active":false}`, http.StatusOK},
+		{"GET", "/api/webhooks/1/deliveries", "", http.StatusOK},
+		{"POST", "/api/webhooks/1/deliveries/1/redeliver", "", http.StatusNotFound},
+		{"DELETE", "/api/webhooks/1", "", http.StatusOK},
+		{"POST", "/api/chatops/kudos", "text=%40alice+great+demo", http.StatusUnauthorized},
+		{"POST", "/api/graphql", `{"query":"{ teams { name members { name } feedback { content } } }"}`, http.StatusOK},
+		{"POST", "/api/graphql", `{"query":"{ team(id: 999) { name } }"}`, http.StatusOK},
+		{"POST", "/api/graphql", `{"variables":{}}`, http.StatusBadRequest},
+		{"DELETE", "/api/feedback/1", "", http.StatusOK},
+		{"DELETE", "/api/assignments/member/1", "", http.StatusOK},
+		{"DELETE", "/api/teams/1", "", http.StatusOK},
+		{"DELETE", "/api/members/1", "", http.StatusOK},
+		{"GET", "/api/openapi.json", "", http.StatusOK},
+		{"GET", "/api/docs", "", http.StatusOK},
+		{"GET", "/health", "", http.StatusOK},
+	}
+
+	for _, tt := range requests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("PUT and GET /api/teams/:id/logo", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/api/teams", strings.NewReader(`{"name":"Logo Team"}`)))
+		var team struct{ ID int }
+		json.Unmarshal(w.Body.Bytes(), &team)
+		path := "/api/teams/" + strconv.Itoa(team.ID) + "/logo"
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "logo.png")
+		png.Encode(part, image.NewGray(image.Rect(0, 0, 8, 8)))
+		form.Close()
+		req, _ := http.NewRequest("PUT", path, &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path+"?size=64", nil))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("POST /api/members/import", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "members.csv")
+		part.Write([]byte("name,email,team\nAda,ada@example.com,Imported Team\n"))
+		form.Close()
+		req, _ := http.NewRequest("POST", "/api/members/import?dry_run=true&create_teams=true", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+	t.Run("SCIM", func(t *testing.T) {
+		scimRequests := []struct {
+			method string
+			path   string
+			body   string
+			status int
+		}{
+			{"GET", "/scim/v2/ServiceProviderConfig", "", http.StatusOK},
+			{"GET", "/scim/v2/ResourceTypes", "", http.StatusOK},
+			{"GET", "/scim/v2/Schemas", "", http.StatusOK},
+			{"POST", "/scim/v2/Users", `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"ada@example.com","name":{"givenName":"Ada","familyName":"Lovelace"}}`, http.StatusCreated},
+			{"POST", "/scim/v2/Users", `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"ada@example.com"}`, http.StatusConflict},
+			{"GET", "/scim/v2/Users?filter=userName+eq+%22ada%40example.com%22&startIndex=1&count=10", "", http.StatusOK},
+			{"GET", "/scim/v2/Users?filter=userName+eq", "", http.StatusBadRequest},
+			{"POST", "/scim/v2/Groups", `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:Group"],"displayName":"SCIM Team"}`, http.StatusCreated},
+			{"GET", "/scim/v2/Groups?filter=displayName+sw+%22SCIM%22", "", http.StatusOK},
+			{"GET", "/scim/v2/Users/999", "", http.StatusNotFound},
+		}
+		for _, tt := range scimRequests {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/scim+json")
+			req.Header.Set("Authorization", "Bearer scim-token")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code, "%s %s: %s", tt.method, tt.path, w.Body.String())
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/scim/v2/Users", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
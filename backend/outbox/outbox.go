@@ -0,0 +1,100 @@
+// Package outbox makes event publishing part of the database transaction
+// that causes the event. The service layer records each event with Record
+// inside its transaction; the Relay publishes committed events to each of
+// its sinks in order, keeping each sink's place, and marks them published
+// once every sink has them. A crash between the commit and the publish
+// only delays the event, and a crash between the publish and recording it
+// repeats it, so sinks see every event at least once and should
+// deduplicate on its key.
+package outbox
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Record adds e to the outbox within tx. The event is published once tx
+// commits; call Notify after the commit to publish it without waiting for
+// the relay's next poll.
+func Record(tx *gorm.DB, e events.Event) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxMessage{
+		Key:        randomID("evt_"),
+		Type:       string(e.Type),
+		TeamID:     e.TeamID,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Data:       string(data),
+	}).Error
+}
+
+// wake is shared by every relay in the process.
+var wake = make(chan struct{}, 1)
+
+// Notify tells the relay that new events were committed.
+func Notify() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// randomID returns prefix followed by 32 random hex digits.
+func randomID(prefix string) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return prefix + hex.EncodeToString(b)
+}
+
+// toEvent turns a stored message back into the event that was recorded,
+// with Data decoded into the model the event type carries.
+func toEvent(msg *models.OutboxMessage) (events.Event, error) {
+	e := events.Event{
+		ID:         msg.ID,
+		Key:        msg.Key,
+		Type:       events.Type(msg.Type),
+		Time:       msg.CreatedAt.UTC(),
+		TeamID:     msg.TeamID,
+		TargetType: msg.TargetType,
+		TargetID:   msg.TargetID,
+	}
+
+	var err error
+	switch {
+	case strings.HasPrefix(msg.Type, "member."):
+		var member models.TeamMember
+		err = json.Unmarshal([]byte(msg.Data), &member)
+		e.Data = member
+	case strings.HasPrefix(msg.Type, "feedback."):
+		var feedback models.Feedback
+		err = json.Unmarshal([]byte(msg.Data), &feedback)
+		e.Data = feedback
+	case strings.HasPrefix(msg.Type, "project."):
+		var project models.Project
+		err = json.Unmarshal([]byte(msg.Data), &project)
+		e.Data = project
+	case strings.HasPrefix(msg.Type, "release."):
+		var release models.Release
+		err = json.Unmarshal([]byte(msg.Data), &release)
+		e.Data = release
+	case strings.HasPrefix(msg.Type, "meeting."):
+		var meeting models.Meeting
+		err = json.Unmarshal([]byte(msg.Data), &meeting)
+		e.Data = meeting
+	default:
+		return e, fmt.Errorf("unknown event type %q", msg.Type)
+	}
+	return e, err
+}
@@ -0,0 +1,286 @@
+package outbox
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/tests/testutils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// recorder is a sink that remembers what it was given and fails while err
+// is set.
+type recorder struct {
+	name   string
+	local  bool
+	err    error
+	events []events.Event
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Local() bool { return r.local }
+
+func (r *recorder) Publish(ctx context.Context, e events.Event) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.events = append(r.events, e)
+	return nil
+}
+
+func (r *recorder) keys() []string {
+	var keys []string
+	for _, e := range r.events {
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
+
+type brokerMessage struct {
+	topic, key string
+	payload    []byte
+}
+
+type fakeBroker struct{ messages []brokerMessage }
+
+func (b *fakeBroker) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	b.messages = append(b.messages, brokerMessage{topic, key, payload})
+	return nil
+}
+
+var testConfig = Config{PollInterval: 10 * time.Millisecond, RetryDelay: 10 * time.Millisecond, BatchSize: 2, Retention: time.Hour}
+
+func recordFeedback(t *testing.T, db *gorm.DB, content string) {
+	feedback := models.Feedback{ID: 1, Content: content, TargetType: "team", TargetID: 1}
+	require.NoError(t, Record(db, events.Event{Type: events.FeedbackCreated, TargetType: "team", TargetID: 1, Data: feedback}))
+}
+
+func TestRecord(t *testing.T) {
+	t.Run("Commits With The Transaction", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return Record(tx, events.Event{Type: events.MemberCreated, TargetType: "member", TargetID: 3, Data: models.TeamMember{ID: 3}})
+		})
+		require.NoError(t, err)
+
+		var msg models.OutboxMessage
+		require.NoError(t, db.First(&msg).Error)
+		assert.Equal(t, "member.created", msg.Type)
+		assert.Regexp(t, `^evt_[0-9a-f]{32}$`, msg.Key)
+		assert.Nil(t, msg.PublishedAt)
+	})
+
+	t.Run("Rolls Back With The Transaction", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := Record(tx, events.Event{Type: events.MemberCreated, Data: models.TeamMember{}}); err != nil {
+				return err
+			}
+			return errors.New("mutation failed")
+		})
+		require.Error(t, err)
+
+		var count int64
+		db.Model(&models.OutboxMessage{}).Count(&count)
+		assert.Zero(t, count)
+	})
+}
+
+func TestRelay(t *testing.T) {
+	t.Run("Publishes In Order To Every Sink", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		first, second := &recorder{name: "first"}, &recorder{name: "second"}
+		relay := NewRelay(db, testConfig, first, second)
+
+		for _, content := range []string{"one", "two", "three"} {
+			recordFeedback(t, db, content)
+		}
+
+		published, err := relay.Drain(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, published)
+
+		require.Len(t, first.events, 3)
+		assert.Equal(t, first.keys(), second.keys())
+		for i, e := range first.events {
+			assert.Equal(t, uint64(i+1), e.ID)
+			assert.Equal(t, events.FeedbackCreated, e.Type)
+		}
+		feedback, ok := first.events[2].Data.(models.Feedback)
+		require.True(t, ok, "data is decoded into its model")
+		assert.Equal(t, "three", feedback.Content)
+
+		var pending int64
+		db.Model(&models.OutboxMessage{}).Where("published_at IS NULL").Count(&pending)
+		assert.Zero(t, pending)
+
+		published, err = relay.Drain(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, published)
+	})
+
+	t.Run("A Failing Sink Holds Back Only Itself", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		ok, failing := &recorder{name: "ok"}, &recorder{name: "failing", err: errors.New("broker unavailable")}
+		relay := NewRelay(db, Config{PollInterval: time.Hour, RetryDelay: time.Hour, BatchSize: 2, Retention: 24 * time.Hour}, ok, failing)
+
+		recordFeedback(t, db, "one")
+		recordFeedback(t, db, "two")
+
+		published, err := relay.Drain(context.Background())
+		assert.ErrorContains(t, err, "failing sink: event 1: broker unavailable")
+		assert.Zero(t, published)
+		assert.Len(t, ok.events, 2, "the healthy sink carries on")
+
+		var cursor models.OutboxCursor
+		require.NoError(t, db.First(&cursor, "sink = ?", "failing").Error)
+		assert.Zero(t, cursor.LastID)
+		assert.Equal(t, 1, cursor.Attempts)
+		assert.Equal(t, "event 1: broker unavailable", cursor.LastError)
+		require.NotNil(t, cursor.RetryAt)
+
+		failing.err = nil
+		_, err = relay.Drain(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, failing.events, "the failed sink waits for the retry delay")
+
+		relay.now = func() time.Time { return time.Now().UTC().Add(2 * time.Hour) }
+		published, err = relay.Drain(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+		assert.Equal(t, ok.keys(), failing.keys())
+		assert.Len(t, ok.events, 2, "the healthy sink does not see the events again")
+
+		require.NoError(t, db.First(&cursor, "sink = ?", "failing").Error)
+		assert.Equal(t, uint64(2), cursor.LastID)
+		assert.Zero(t, cursor.Attempts)
+		assert.Empty(t, cursor.LastError)
+	})
+
+	t.Run("Shared Sinks Are Fed By One Relay At A Time", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		firstHooks, firstBus := &recorder{name: "webhooks"}, &recorder{name: "bus", local: true}
+		secondHooks, secondBus := &recorder{name: "webhooks"}, &recorder{name: "bus", local: true}
+		first := NewRelay(db, testConfig, firstHooks, firstBus)
+		second := NewRelay(db, testConfig, secondHooks, secondBus)
+		drain := func() {
+			_, err := first.Drain(context.Background())
+			require.NoError(t, err)
+			_, err = second.Drain(context.Background())
+			require.NoError(t, err)
+		}
+		drain()
+
+		recordFeedback(t, db, "one")
+		drain()
+
+		assert.Len(t, firstHooks.events, 1)
+		assert.Empty(t, secondHooks.events, "the first relay holds the lease")
+		assert.Len(t, firstBus.events, 1)
+		assert.Len(t, secondBus.events, 1, "every relay feeds its local sinks")
+
+		recordFeedback(t, db, "two")
+		second.now = func() time.Time { return time.Now().UTC().Add(2 * leaseDuration) }
+		_, err := second.Drain(context.Background())
+		require.NoError(t, err)
+
+		require.Len(t, secondHooks.events, 1, "the lease expired and the second relay carries on from the cursor")
+		assert.Equal(t, uint64(2), secondHooks.events[0].ID)
+		assert.Len(t, secondBus.events, 2)
+	})
+
+	t.Run("Waits For Events Committed Out Of Order", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		sink := &recorder{name: "sink"}
+		relay := NewRelay(db, testConfig, sink)
+
+		recordFeedback(t, db, "one")
+		recordFeedback(t, db, "two")
+		recordFeedback(t, db, "three")
+		require.NoError(t, db.Delete(&models.OutboxMessage{}, 2).Error)
+
+		_, err := relay.Drain(context.Background())
+		require.NoError(t, err)
+		require.Len(t, sink.events, 1, "event 2 may still be in flight")
+
+		relay.now = func() time.Time { return time.Now().UTC().Add(2 * gapTimeout) }
+		_, err = relay.Drain(context.Background())
+		require.NoError(t, err)
+		require.Len(t, sink.events, 2)
+		assert.Equal(t, uint64(3), sink.events[1].ID)
+	})
+
+	t.Run("Deletes Events Past Retention", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		relay := NewRelay(db, testConfig)
+		recordFeedback(t, db, "old")
+
+		_, err := relay.Drain(context.Background())
+		require.NoError(t, err)
+
+		relay.lastCleanup = time.Time{}
+		relay.now = func() time.Time { return time.Now().UTC().Add(2 * time.Hour) }
+		_, err = relay.Drain(context.Background())
+		require.NoError(t, err)
+
+		var count int64
+		db.Model(&models.OutboxMessage{}).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Notify Wakes Run", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		sink := &recorder{name: "sink"}
+		relay := NewRelay(db, Config{PollInterval: time.Hour, RetryDelay: time.Hour, BatchSize: 10}, sink)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go relay.Run(ctx)
+
+		recordFeedback(t, db, "now")
+		Notify()
+		assert.Eventually(t, func() bool {
+			var pending int64
+			db.Model(&models.OutboxMessage{}).Where("published_at IS NULL").Count(&pending)
+			return pending == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestSinks(t *testing.T) {
+	t.Run("Bus Drops Redeliveries", func(t *testing.T) {
+		bus := events.NewBus(10)
+		sink := BusSink{Bus: bus}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sub := bus.Subscribe(ctx, events.Filter{}, 0)
+
+		e := events.Event{ID: 5, Key: "evt_5", Type: events.MemberCreated}
+		require.NoError(t, sink.Publish(ctx, e))
+		require.NoError(t, sink.Publish(ctx, e))
+
+		assert.Equal(t, uint64(5), (<-sub.Events).ID)
+		assert.Empty(t, sub.Events)
+	})
+
+	t.Run("Broker Topic And Key", func(t *testing.T) {
+		broker := &fakeBroker{}
+		sink := BrokerSink{Broker: broker, TopicPrefix: "coaching."}
+
+		require.NoError(t, sink.Publish(context.Background(), events.Event{ID: 9, Key: "evt_9", Type: events.FeedbackDeleted}))
+
+		require.Len(t, broker.messages, 1)
+		assert.Equal(t, "coaching.feedback.deleted", broker.messages[0].topic)
+		assert.Equal(t, "evt_9", broker.messages[0].key)
+		assert.Contains(t, string(broker.messages[0].payload), `"key":"evt_9"`)
+	})
+}
@@ -0,0 +1,311 @@
+package outbox
+
+import (
+	"coaching-backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Config struct {
+	// PollInterval is how often the outbox is checked when Notify is not
+	// called, such as for events committed by another process.
+	PollInterval time.Duration
+	// RetryDelay is how long a sink that failed is left alone before its
+	// event is retried. The other sinks carry on meanwhile.
+	RetryDelay time.Duration
+	// BatchSize caps the events loaded at a time.
+	BatchSize int
+	// Retention is how long published events are kept.
+	Retention time.Duration
+}
+
+var DefaultConfig = Config{
+	PollInterval: time.Second,
+	RetryDelay:   10 * time.Second,
+	BatchSize:    100,
+	Retention:    7 * 24 * time.Hour,
+}
+
+const (
+	// cleanupInterval is how often published events past the retention
+	// are deleted.
+	cleanupInterval = time.Hour
+	// leaseDuration is how long a relay keeps a shared sink to itself
+	// after last making progress on it. A relay that stops, or is stuck
+	// in a sink for longer, hands the sink to the next one to drain.
+	leaseDuration = time.Minute
+	// gapTimeout is how long an ID missing from the outbox holds back the
+	// events after it. IDs are taken before commit, so a gap is usually a
+	// transaction still in flight; one that has not committed by then is
+	// taken to have rolled back.
+	gapTimeout = 10 * time.Second
+)
+
+// localCursor is how far a local sink has been fed by this relay.
+type localCursor struct {
+	lastID  uint64
+	retryAt time.Time
+}
+
+type Relay struct {
+	db    *gorm.DB
+	cfg   Config
+	sinks []Sink
+	// id names the relay as the owner of the shared sinks it leases.
+	id    string
+	local map[string]*localCursor
+	// marked is the last event the relay marked published.
+	marked      uint64
+	now         func() time.Time
+	lastCleanup time.Time
+}
+
+func NewRelay(db *gorm.DB, cfg Config, sinks ...Sink) *Relay {
+	return &Relay{db: db, cfg: cfg, sinks: sinks, id: randomID("relay_"), now: func() time.Time { return time.Now().UTC() }}
+}
+
+// Run relays committed events until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Drain(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// Drain feeds each sink the events it has not had yet, in ID order, and
+// marks the events every sink has had published. It reports how many it
+// marked.
+//
+// Each sink keeps its own place, so a failing sink only holds back its
+// own events: they are retried after RetryDelay while the other sinks
+// carry on. Shared sinks are fed by whichever relay holds their lease, so
+// that running several instances does not publish events several times;
+// local sinks are fed by every relay.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	if r.local == nil {
+		if err := r.start(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	var errs []error
+	var upTo *uint64
+	for _, sink := range r.sinks {
+		var lastID uint64
+		var err error
+		if isLocal(sink) {
+			lastID, err = r.drainLocal(ctx, sink)
+		} else {
+			lastID, err = r.drainShared(ctx, sink)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s sink: %w", sink.Name(), err))
+		}
+		if upTo == nil || lastID < *upTo {
+			upTo = &lastID
+		}
+	}
+
+	published := 0
+	if upTo == nil || *upTo > r.marked {
+		query := r.db.WithContext(ctx).Model(&models.OutboxMessage{}).Where("published_at IS NULL")
+		if upTo != nil {
+			query = query.Where("id <= ?", *upTo)
+		}
+		result := query.Update("published_at", r.now())
+		if result.Error != nil {
+			errs = append(errs, fmt.Errorf("failed to mark events published: %w", result.Error))
+		} else if upTo != nil {
+			r.marked = *upTo
+		}
+		published = int(result.RowsAffected)
+	}
+
+	if r.now().Sub(r.lastCleanup) >= cleanupInterval {
+		r.lastCleanup = r.now()
+		err := r.db.WithContext(ctx).Where("published_at < ?", r.now().Add(-r.cfg.Retention)).Delete(&models.OutboxMessage{}).Error
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete published events: %w", err))
+		}
+	}
+	return published, errors.Join(errs...)
+}
+
+// start places the sinks after the last published event: local sinks,
+// which have nothing to catch up on in a new process, and shared sinks
+// that have no cursor yet.
+func (r *Relay) start(ctx context.Context) error {
+	var lastID uint64
+	err := r.db.WithContext(ctx).Model(&models.OutboxMessage{}).
+		Where("published_at IS NOT NULL").
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&lastID).Error
+	if err != nil {
+		return fmt.Errorf("failed to find the last published event: %w", err)
+	}
+
+	local := map[string]*localCursor{}
+	for _, sink := range r.sinks {
+		if isLocal(sink) {
+			local[sink.Name()] = &localCursor{lastID: lastID}
+			continue
+		}
+		err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.OutboxCursor{Sink: sink.Name(), LastID: lastID}).Error
+		if err != nil {
+			return fmt.Errorf("failed to create the %s sink's cursor: %w", sink.Name(), err)
+		}
+	}
+	r.local = local
+	r.marked = lastID
+	return nil
+}
+
+// drainLocal feeds a local sink and returns the last event it has had.
+func (r *Relay) drainLocal(ctx context.Context, sink Sink) (uint64, error) {
+	cursor := r.local[sink.Name()]
+	if r.now().Before(cursor.retryAt) {
+		return cursor.lastID, nil
+	}
+	err := r.feed(ctx, sink, cursor.lastID, func(id uint64) (bool, error) {
+		cursor.lastID = id
+		return true, nil
+	})
+	if err != nil {
+		cursor.retryAt = r.now().Add(r.cfg.RetryDelay)
+	}
+	return cursor.lastID, err
+}
+
+// drainShared feeds a shared sink if the relay can lease it, and returns
+// the last event the sink has had from any relay.
+func (r *Relay) drainShared(ctx context.Context, sink Sink) (uint64, error) {
+	name := sink.Name()
+	leased, err := r.lease(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lease: %w", err)
+	}
+	var cursor models.OutboxCursor
+	if err := r.db.WithContext(ctx).First(&cursor, "sink = ?", name).Error; err != nil {
+		return 0, fmt.Errorf("failed to load the cursor: %w", err)
+	}
+	if !leased {
+		return cursor.LastID, nil
+	}
+
+	err = r.feed(ctx, sink, cursor.LastID, func(id uint64) (bool, error) {
+		cursor.LastID = id
+		return r.advance(ctx, name, id)
+	})
+	if err != nil && ctx.Err() == nil {
+		now := r.now()
+		r.db.WithContext(ctx).Model(&models.OutboxCursor{}).
+			Where("sink = ? AND owner = ?", name, r.id).
+			Updates(map[string]interface{}{
+				"attempts":     gorm.Expr("attempts + 1"),
+				"last_error":   err.Error(),
+				"retry_at":     now.Add(r.cfg.RetryDelay),
+				"owner":        "",
+				"leased_until": nil,
+			})
+	}
+	return cursor.LastID, err
+}
+
+// lease takes or renews the lease on a shared sink, unless another relay
+// holds it or the sink is waiting to retry, and reports whether the relay
+// holds it now.
+func (r *Relay) lease(ctx context.Context, sink string) (bool, error) {
+	now := r.now()
+	result := r.db.WithContext(ctx).Model(&models.OutboxCursor{}).
+		Where("sink = ? AND (owner = ? OR leased_until IS NULL OR leased_until <= ?) AND (retry_at IS NULL OR retry_at <= ?)", sink, r.id, now, now).
+		Updates(map[string]interface{}{
+			"owner":        r.id,
+			"leased_until": now.Add(leaseDuration),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// advance moves a shared sink's cursor past the event id and renews the
+// lease, and reports whether the relay still held it. A relay that lost
+// the lease stops; the new holder may publish the event again.
+func (r *Relay) advance(ctx context.Context, sink string, id uint64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.OutboxCursor{}).
+		Where("sink = ? AND owner = ?", sink, r.id).
+		Updates(map[string]interface{}{
+			"last_id":      id,
+			"attempts":     0,
+			"last_error":   "",
+			"retry_at":     nil,
+			"leased_until": r.now().Add(leaseDuration),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to advance past event %d: %w", id, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// feed publishes the events after lastID to sink in order, calling
+// published after each one, until none are left, the sink fails or
+// published returns false.
+func (r *Relay) feed(ctx context.Context, sink Sink, lastID uint64, published func(id uint64) (bool, error)) error {
+	for {
+		batch, err := r.pending(ctx, lastID)
+		if err != nil {
+			return err
+		}
+
+		for i := range batch {
+			e, err := toEvent(&batch[i])
+			if err == nil {
+				err = sink.Publish(ctx, e)
+			}
+			if err != nil {
+				return fmt.Errorf("event %d: %w", batch[i].ID, err)
+			}
+			lastID = batch[i].ID
+			if ok, err := published(lastID); !ok || err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < r.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+// pending loads the events after lastID, up to the first gap in the IDs
+// younger than gapTimeout.
+func (r *Relay) pending(ctx context.Context, lastID uint64) ([]models.OutboxMessage, error) {
+	var batch []models.OutboxMessage
+	err := r.db.WithContext(ctx).Where("id > ?", lastID).Order("id").Limit(r.cfg.BatchSize).Find(&batch).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	next := lastID + 1
+	for i, msg := range batch {
+		if msg.ID != next && r.now().Sub(msg.CreatedAt) < gapTimeout {
+			return batch[:i], nil
+		}
+		next = msg.ID + 1
+	}
+	return batch, nil
+}
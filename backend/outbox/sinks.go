@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"coaching-backend/events"
+	"context"
+	"encoding/json"
+	"log"
+)
+
+// Sink receives relayed events. Publish must be safe to repeat for the
+// same event: an event the sink accepted is published to it again when
+// the relay fails to record that, or loses the sink's lease meanwhile.
+//
+// A sink is shared by every instance, and fed by one relay at a time,
+// unless it is a LocalSink. Its Name identifies its place in the outbox,
+// so it must not change between releases.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, e events.Event) error
+}
+
+// LocalSink is a sink that lives in each process, such as the bus behind
+// its event streams, so that every instance's relay feeds its own.
+type LocalSink interface {
+	Sink
+	Local() bool
+}
+
+func isLocal(s Sink) bool {
+	local, ok := s.(LocalSink)
+	return ok && local.Local()
+}
+
+// BusSink publishes events to the in-process bus behind the live event
+// streams. It is local, so the streams of every instance see every event.
+// The bus drops events it has already published.
+type BusSink struct {
+	Bus *events.Bus
+}
+
+func (s BusSink) Name() string {
+	return "bus"
+}
+
+func (s BusSink) Local() bool {
+	return true
+}
+
+func (s BusSink) Publish(ctx context.Context, e events.Event) error {
+	s.Bus.Publish(e)
+	return nil
+}
+
+// LogSink writes one line per event, for debugging.
+type LogSink struct {
+	// Logger defaults to the standard logger.
+	Logger *log.Logger
+}
+
+func (s LogSink) Name() string {
+	return "log"
+}
+
+func (s LogSink) Publish(ctx context.Context, e events.Event) error {
+	logf := log.Printf
+	if s.Logger != nil {
+		logf = s.Logger.Printf
+	}
+	logf("event %d %s key=%s target=%s/%d", e.ID, e.Type, e.Key, e.TargetType, e.TargetID)
+	return nil
+}
+
+// Broker is a message broker producer, such as a Kafka, NATS or RabbitMQ
+// client. Publish returns once the broker has accepted the message.
+type Broker interface {
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+}
+
+// BrokerSink sends each event as JSON to the topic TopicPrefix + event
+// type, keyed by the event key so the broker can deduplicate.
+type BrokerSink struct {
+	Broker      Broker
+	TopicPrefix string
+}
+
+func (s BrokerSink) Name() string {
+	return "broker"
+}
+
+func (s BrokerSink) Publish(ctx context.Context, e events.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.Broker.Publish(ctx, s.TopicPrefix+string(e.Type), e.Key, payload)
+}
@@ -0,0 +1,109 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report validation errors by JSON field name ("email") rather than Go
+	// struct path ("TeamMember.Email").
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// Binding writes a 400 problem for an error returned by ShouldBindJSON,
+// listing each invalid field.
+func Binding(c *gin.Context, err error) {
+	if fieldErrs, ok := FieldErrors(err); ok {
+		p := New(http.StatusBadRequest, CodeValidation, "Request body failed validation")
+		p.Errors = fieldErrs
+		Write(c, p)
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		p := New(http.StatusBadRequest, CodeMalformedBody, "Request body has a field of the wrong type")
+		p.Errors = []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be a " + typeErr.Type.Kind().String(),
+		}}
+		Write(c, p)
+		return
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		Write(c, New(http.StatusRequestEntityTooLarge, CodeTooLarge, "Request body is larger than "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes"))
+		return
+	}
+
+	BadRequest(c, CodeMalformedBody, "Request body is not valid JSON")
+}
+
+// FieldErrors converts validator errors, as returned by binding or by
+// binding.Validator.ValidateStruct, into field errors.
+func FieldErrors(err error) ([]FieldError, bool) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+
+	fieldErrs := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fieldErrs = append(fieldErrs, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return fieldErrs, true
+}
+
+// Validation writes a 400 problem for field errors found outside of struct
+// binding, e.g. checks against the database.
+func Validation(c *gin.Context, errs ...FieldError) {
+	p := New(http.StatusBadRequest, CodeValidation, "Request body failed validation")
+	p.Errors = errs
+	Write(c, p)
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
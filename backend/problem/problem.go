@@ -0,0 +1,181 @@
+// Package problem renders API errors as RFC 7807 application/problem+json
+// documents with a stable error code and the request's trace ID.
+package problem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContentType = "application/problem+json"
+
+// Stable error codes. Clients may switch on these; do not rename them.
+const (
+	CodeValidation    = "validation_failed"
+	CodeMalformedBody = "malformed_body"
+	CodeInvalidParam  = "invalid_parameter"
+	CodeUnauthorized  = "unauthorized"
+	CodeForbidden     = "forbidden"
+	CodeNotFound      = "not_found"
+	CodeConflict      = "conflict"
+	CodeTooLarge      = "payload_too_large"
+	CodeUnsupported   = "unsupported_media_type"
+	CodeRateLimited   = "rate_limited"
+	CodeInternal      = "internal_error"
+)
+
+const (
+	TraceHeader     = "X-Request-ID"
+	traceContextKey = "trace_id"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	TraceID  string       `json:"trace_id"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "/problems/" + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Status returns the HTTP status for an error code.
+func Status(code string) int {
+	switch code {
+	case CodeValidation, CodeMalformedBody, CodeInvalidParam:
+		return http.StatusBadRequest
+	case CodeUnauthorized:
+		return http.StatusUnauthorized
+	case CodeForbidden:
+		return http.StatusForbidden
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeConflict:
+		return http.StatusConflict
+	case CodeTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case CodeUnsupported:
+		return http.StatusUnsupportedMediaType
+	case CodeRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+// Write aborts the request with p, filling in the trace ID and instance.
+func Write(c *gin.Context, p *Problem) {
+	p.TraceID = TraceID(c)
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+func BadRequest(c *gin.Context, code, detail string) {
+	Write(c, New(http.StatusBadRequest, code, detail))
+}
+
+func InvalidID(c *gin.Context) {
+	BadRequest(c, CodeInvalidParam, "Invalid ID")
+}
+
+// InvalidParam reports a single malformed path or query parameter.
+func InvalidParam(c *gin.Context, name, message string) {
+	p := New(http.StatusBadRequest, CodeInvalidParam, "Invalid "+name)
+	p.Errors = []FieldError{{Field: name, Rule: "type", Message: message}}
+	Write(c, p)
+}
+
+func Unauthorized(c *gin.Context, detail string) {
+	Write(c, New(http.StatusUnauthorized, CodeUnauthorized, detail))
+}
+
+func Forbidden(c *gin.Context, detail string) {
+	Write(c, New(http.StatusForbidden, CodeForbidden, detail))
+}
+
+func NotFound(c *gin.Context, detail string) {
+	Write(c, New(http.StatusNotFound, CodeNotFound, detail))
+}
+
+func Conflict(c *gin.Context, detail string) {
+	Write(c, New(http.StatusConflict, CodeConflict, detail))
+}
+
+func Internal(c *gin.Context, detail string) {
+	Write(c, New(http.StatusInternalServerError, CodeInternal, detail))
+}
+
+// TraceID returns the request's trace ID, generating one if the Trace
+// middleware has not run.
+func TraceID(c *gin.Context) string {
+	if id := c.GetString(traceContextKey); id != "" {
+		return id
+	}
+
+	id := c.GetHeader(TraceHeader)
+	if id == "" {
+		id = newTraceID()
+	}
+	c.Set(traceContextKey, id)
+	c.Header(TraceHeader, id)
+	return id
+}
+
+// Trace assigns every request a trace ID, reusing an incoming X-Request-ID,
+// and echoes it in the response headers.
+func Trace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		TraceID(c)
+		c.Next()
+	}
+}
+
+// Recovery turns panics into a 500 problem response. http.ErrAbortHandler
+// is panicked again, so the server drops the connection as handlers that
+// raise it intend.
+func Recovery(c *gin.Context, recovered any) {
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+	Internal(c, "Internal server error")
+}
+
+func NoRoute(c *gin.Context) {
+	NotFound(c, "Route not found")
+}
+
+func newTraceID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,136 @@
+package problem
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupGin() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Trace())
+	return r
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	var p Problem
+	err := json.Unmarshal(w.Body.Bytes(), &p)
+	assert.NoError(t, err)
+	return p
+}
+
+func TestWrite(t *testing.T) {
+	r := setupGin()
+	r.GET("/teams/:id", func(c *gin.Context) {
+		NotFound(c, "Team not found")
+	})
+
+	t.Run("Problem Document", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/teams/7", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+
+		p := decode(t, w)
+		assert.Equal(t, "/problems/not_found", p.Type)
+		assert.Equal(t, "Not Found", p.Title)
+		assert.Equal(t, http.StatusNotFound, p.Status)
+		assert.Equal(t, "Team not found", p.Detail)
+		assert.Equal(t, "/teams/7", p.Instance)
+		assert.Equal(t, CodeNotFound, p.Code)
+		assert.NotEmpty(t, p.TraceID)
+		assert.Equal(t, p.TraceID, w.Header().Get(TraceHeader))
+	})
+
+	t.Run("Incoming Request ID Is Reused", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/teams/7", nil)
+		req.Header.Set(TraceHeader, "abc123")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, "abc123", decode(t, w).TraceID)
+	})
+}
+
+func TestBinding(t *testing.T) {
+	type request struct {
+		Name  string `json:"name" binding:"required"`
+		Email string `json:"email" binding:"required,email"`
+		Age   int    `json:"age"`
+	}
+
+	r := setupGin()
+	r.POST("/bind", func(c *gin.Context) {
+		var body request
+		if err := c.ShouldBindJSON(&body); err != nil {
+			Binding(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/bind", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Field Errors Use JSON Names", func(t *testing.T) {
+		w := post(`{"email":"nope"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		p := decode(t, w)
+		assert.Equal(t, CodeValidation, p.Code)
+		assert.Equal(t, []FieldError{
+			{Field: "name", Rule: "required", Message: "is required"},
+			{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		}, p.Errors)
+	})
+
+	t.Run("Wrong Field Type", func(t *testing.T) {
+		w := post(`{"name":"a","email":"a@example.com","age":"old"}`)
+
+		p := decode(t, w)
+		assert.Equal(t, CodeMalformedBody, p.Code)
+		assert.Equal(t, "age", p.Errors[0].Field)
+	})
+
+	t.Run("Malformed JSON", func(t *testing.T) {
+		w := post(`{"name":`)
+
+		p := decode(t, w)
+		assert.Equal(t, CodeMalformedBody, p.Code)
+		assert.Empty(t, p.Errors)
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	r := setupGin()
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, Recovery))
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+	r.GET("/abort", func(c *gin.Context) { panic(http.ErrAbortHandler) })
+
+	t.Run("Internal Error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, CodeInternal, decode(t, w).Code)
+	})
+
+	t.Run("Aborted Handler Panics Again", func(t *testing.T) {
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+		})
+	})
+}
@@ -0,0 +1,15 @@
+package ratelimit
+
+import "time"
+
+// SetClock replaces the limiter's clock.
+func (l *Limiter) SetClock(now func() time.Time) {
+	l.now = now
+}
+
+// Len returns the number of buckets kept.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
@@ -0,0 +1,131 @@
+// Package ratelimit limits how often clients may call the API with token
+// buckets: every bucket holds up to a limit's Requests tokens, refills
+// evenly over Per, and each request takes a token. Buckets live in a
+// Store, in memory for a single server or in Redis to share them.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests requests per Per, in bursts of up to Requests. The
+// zero Limit allows everything.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Enabled reports whether the limit limits anything.
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// interval is how long the bucket takes to refill one token.
+func (l Limit) interval() time.Duration {
+	return l.Per / time.Duration(l.Requests)
+}
+
+// String formats the limit like ParseLimit reads it.
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return strconv.Itoa(l.Requests) + "/" + formatDuration(l.Per)
+}
+
+// Policy describes the limit for the RateLimit-Policy header, like 100;w=60.
+func (l Limit) Policy() string {
+	return fmt.Sprintf("%d;w=%d", l.Requests, int(math.Ceil(l.Per.Seconds())))
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	}
+	return d.String()
+}
+
+// ParseLimit reads a limit written as requests/period, like 100/1m or
+// 10/1s; the period may also be a bare unit, as in 100/m. Empty, 0 and
+// "off" are the zero Limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" || strings.EqualFold(s, "off") {
+		return Limit{}, nil
+	}
+	requests, per, ok := strings.Cut(s, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if !ok || err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, want requests/period like 100/1m", s)
+	}
+	per = strings.TrimSpace(per)
+	if per != "" && (per[0] < '0' || per[0] > '9') {
+		per = "1" + per
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, want requests/period like 100/1m", s)
+	}
+	return Limit{Requests: n, Per: d}, nil
+}
+
+// Store keeps token buckets.
+type Store interface {
+	// Take refills the bucket at key for limit up to now and takes a token
+	// if one is left. It returns the tokens left afterwards, which may be
+	// fractional, and whether a token was taken. Missing buckets start
+	// full.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (tokens float64, allowed bool, err error)
+}
+
+// Result is the outcome of a request against one limit.
+type Result struct {
+	Limit   Limit
+	Allowed bool
+	// Remaining is how many more requests the bucket allows right away.
+	Remaining int
+	// RetryAfter is how long a refused client should wait.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Limiter applies limits to buckets in a store.
+type Limiter struct {
+	store Store
+	now   func() time.Time
+}
+
+// New returns a limiter keeping its buckets in store.
+func New(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// Allow counts a request against the bucket at key. A disabled limit
+// allows everything without touching the store.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	result := Result{Limit: limit, Allowed: true}
+	if !limit.Enabled() {
+		return result, nil
+	}
+	tokens, allowed, err := l.store.Take(ctx, key, limit, l.now())
+	if err != nil {
+		return result, err
+	}
+	interval := limit.interval()
+	result.Allowed = allowed
+	result.Remaining = int(tokens)
+	result.Reset = time.Duration((float64(limit.Requests) - tokens) * float64(interval))
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(interval))
+	}
+	return result, nil
+}
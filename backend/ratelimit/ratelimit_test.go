@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	t.Run("Valid Limits", func(t *testing.T) {
+		for s, want := range map[string]Limit{
+			"100/1m":   {Requests: 100, Per: time.Minute},
+			"10/s":     {Requests: 10, Per: time.Second},
+			" 5 / 1h ": {Requests: 5, Per: time.Hour},
+			"30/90s":   {Requests: 30, Per: 90 * time.Second},
+			"":         {},
+			"off":      {},
+			"0":        {},
+		} {
+			got, err := ParseLimit(s)
+			require.NoError(t, err, s)
+			assert.Equal(t, want, got, s)
+		}
+	})
+
+	t.Run("Invalid Limits", func(t *testing.T) {
+		for _, s := range []string{"100", "x/1m", "-1/1m", "10/0s", "10/soon"} {
+			_, err := ParseLimit(s)
+			assert.Error(t, err, s)
+		}
+	})
+
+	t.Run("Formats", func(t *testing.T) {
+		assert.Equal(t, "100/1m", Limit{Requests: 100, Per: time.Minute}.String())
+		assert.Equal(t, "off", Limit{}.String())
+		assert.Equal(t, "100;w=60", Limit{Requests: 100, Per: time.Minute}.Policy())
+	})
+}
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 3, Per: 3 * time.Second}
+	newLimiter := func() (*Limiter, *MemoryStore) {
+		store := NewMemoryStore()
+		l := New(store)
+		l.SetClock(func() time.Time { return now })
+		return l, store
+	}
+
+	t.Run("Allows Bursts Then Refuses", func(t *testing.T) {
+		l, _ := newLimiter()
+		for i := 2; i >= 0; i-- {
+			result, err := l.Allow(ctx, "ip:1", limit)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, i, result.Remaining)
+		}
+		result, err := l.Allow(ctx, "ip:1", limit)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, 3*time.Second, result.Reset)
+
+		other, err := l.Allow(ctx, "ip:2", limit)
+		require.NoError(t, err)
+		assert.True(t, other.Allowed, "buckets are per key")
+	})
+
+	t.Run("Refills Over Time", func(t *testing.T) {
+		l, _ := newLimiter()
+		for range 3 {
+			_, _ = l.Allow(ctx, "key", limit)
+		}
+		l.SetClock(func() time.Time { return now.Add(1500 * time.Millisecond) })
+		result, err := l.Allow(ctx, "key", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, 2500*time.Millisecond, result.Reset)
+
+		result, _ = l.Allow(ctx, "key", limit)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+		l.SetClock(func() time.Time { return now.Add(time.Hour) })
+		result, _ = l.Allow(ctx, "key", limit)
+		assert.Equal(t, 2, result.Remaining, "buckets hold at most the limit")
+	})
+
+	t.Run("Disabled Limit", func(t *testing.T) {
+		l, store := newLimiter()
+		result, err := l.Allow(ctx, "key", Limit{})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Zero(t, store.Len())
+	})
+
+	t.Run("Forgets Full Buckets", func(t *testing.T) {
+		l, store := newLimiter()
+		for i := range sweepEvery - 1 {
+			_, _ = l.Allow(ctx, fmt.Sprint("ip:", i), limit)
+		}
+		assert.Equal(t, sweepEvery-1, store.Len())
+		l.SetClock(func() time.Time { return now.Add(time.Minute) })
+		_, _ = l.Allow(ctx, "ip:new", limit)
+		assert.Equal(t, 1, store.Len())
+	})
+}
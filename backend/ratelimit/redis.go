@@ -0,0 +1,297 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Evaler runs a Lua script on a Redis-compatible server, like EVAL. Any
+// client of Redis, Valkey, KeyDB or Dragonfly can be adapted to it; Client
+// is a small built-in one. Integer replies are int64 and array replies
+// []interface{}.
+type Evaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// takeScript is Take for RedisStore, run atomically on the server. A bucket
+// is a hash of its tokens and the time they were counted, in milliseconds,
+// that expires once it would be full again. It returns whether a token
+// was taken and the tokens left in thousandths.
+const takeScript = `
+local capacity = tonumber(ARGV[1])
+local per = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or capacity
+local ts = tonumber(bucket[2]) or now
+if now > ts then
+  tokens = math.min(capacity, tokens + (now - ts) * capacity / per)
+  ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', ts)
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) * per / capacity) + 1)
+return {allowed, math.floor(tokens * 1000)}
+`
+
+// RedisStore keeps buckets on a Redis-compatible server, so that every
+// server process shares them.
+type RedisStore struct {
+	client Evaler
+	prefix string
+}
+
+// NewRedisStore returns a store keeping buckets under keys starting with
+// prefix.
+func NewRedisStore(client Evaler, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (float64, bool, error) {
+	reply, err := s.client.Eval(ctx, takeScript, []string{s.prefix + key},
+		limit.Requests, limit.Per.Milliseconds(), now.UnixMilli())
+	if err != nil {
+		return 0, false, err
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return 0, false, fmt.Errorf("ratelimit: unexpected reply %v", reply)
+	}
+	allowed, ok1 := values[0].(int64)
+	milliTokens, ok2 := values[1].(int64)
+	if !ok1 || !ok2 {
+		return 0, false, fmt.Errorf("ratelimit: unexpected reply %v", reply)
+	}
+	return float64(milliTokens) / 1000, allowed == 1, nil
+}
+
+// RedisError is an error reply from the server.
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+const (
+	// defaultRedisTimeout bounds each command when the context has no
+	// deadline.
+	defaultRedisTimeout = 5 * time.Second
+	// maxIdleConns is how many connections Client keeps open.
+	maxIdleConns = 8
+	// maxReply caps the size of a bulk string read from the server.
+	maxReply = 1 << 20
+)
+
+// Client is a minimal client for Redis-compatible servers, speaking RESP2
+// over a small pool of connections. It only runs commands; there is no
+// pipelining or pub/sub.
+type Client struct {
+	addr     string
+	useTLS   bool
+	username string
+	password string
+	db       int
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// NewClient returns a client for a redis:// or rediss:// (TLS) URL, like
+// redis://:password@localhost:6379/0. Connections are made when needed.
+func NewClient(rawURL string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+		return nil, fmt.Errorf("invalid Redis URL %q, want redis://[:password@]host:port[/db]", rawURL)
+	}
+	c := &Client{addr: u.Host, useTLS: u.Scheme == "rediss", idle: make(chan *redisConn, maxIdleConns)}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid Redis database %q", db)
+		}
+	}
+	return c, nil
+}
+
+// Eval implements Evaler.
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	cmd := append([]string{"EVAL", script, strconv.Itoa(len(keys))}, keys...)
+	for _, arg := range args {
+		cmd = append(cmd, fmt.Sprint(arg))
+	}
+	return c.Do(ctx, cmd...)
+}
+
+// Do runs a command and returns its reply.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := c.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.do(ctx, args)
+	var redisErr RedisError
+	if err != nil && !errors.As(err, &redisErr) {
+		conn.Close()
+		return nil, err
+	}
+	select {
+	case c.idle <- conn:
+	default:
+		conn.Close()
+	}
+	return reply, err
+}
+
+// Close closes the idle connections.
+func (c *Client) Close() error {
+	for {
+		select {
+		case conn := <-c.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (c *Client) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: defaultRedisTimeout}
+	var netConn net.Conn
+	var err error
+	if c.useTLS {
+		host, _, _ := net.SplitHostPort(c.addr)
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	conn := &redisConn{Conn: netConn, r: bufio.NewReader(netConn)}
+
+	var setup [][]string
+	switch {
+	case c.username != "":
+		setup = append(setup, []string{"AUTH", c.username, c.password})
+	case c.password != "":
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	for _, cmd := range setup {
+		if _, err := conn.do(ctx, cmd); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (conn *redisConn) do(ctx context.Context, args []string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultRedisTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(conn, b.String()); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	return readReply(conn.r)
+}
+
+// readReply reads one RESP2 reply. Error replies are returned as a
+// RedisError.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+	switch kind, rest := line[0], line[1:]; kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, RedisError(rest)
+	case ':':
+		n, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid integer %q", rest)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n > maxReply {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", rest)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n > maxReply {
+			return nil, fmt.Errorf("redis: invalid array length %q", rest)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			value, err := readReply(r)
+			var redisErr RedisError
+			if err != nil && !errors.As(err, &redisErr) {
+				return nil, err
+			}
+			if err != nil {
+				value = redisErr
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -0,0 +1,174 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis answers RESP commands with reply, recording them.
+type fakeRedis struct {
+	listener net.Listener
+	reply    func(cmd []string) string
+
+	mu       sync.Mutex
+	commands [][]string
+	conns    int
+}
+
+func newFakeRedis(t *testing.T, reply func(cmd []string) string) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeRedis{listener: listener, reply: reply}
+	go f.serve()
+	t.Cleanup(func() { listener.Close() })
+	return f
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.conns++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		cmd := make([]string, n)
+		for i := range cmd {
+			header, _ := r.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return
+			}
+			cmd[i] = string(data[:size])
+		}
+		f.mu.Lock()
+		f.commands = append(f.commands, cmd)
+		f.mu.Unlock()
+		if _, err := io.WriteString(conn, f.reply(cmd)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) url(userinfo, db string) string {
+	return "redis://" + userinfo + f.listener.Addr().String() + db
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Requests: 10, Per: time.Minute}
+	now := time.UnixMilli(1767258000000)
+
+	t.Run("Runs Script", func(t *testing.T) {
+		server := newFakeRedis(t, func(cmd []string) string {
+			if cmd[0] == "EVAL" {
+				return "*2\r\n:1\r\n:8500\r\n"
+			}
+			return "+OK\r\n"
+		})
+		client, err := NewClient(server.url(":secret@", "/2"))
+		require.NoError(t, err)
+		defer client.Close()
+
+		store := NewRedisStore(client, "coaching:ratelimit:")
+		tokens, allowed, err := store.Take(ctx, "ip:1", limit, now)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, 8.5, tokens)
+		_, _, err = store.Take(ctx, "ip:1", limit, now)
+		require.NoError(t, err)
+
+		require.Len(t, server.commands, 4)
+		assert.Equal(t, []string{"AUTH", "secret"}, server.commands[0])
+		assert.Equal(t, []string{"SELECT", "2"}, server.commands[1])
+		eval := server.commands[2]
+		assert.Equal(t, []string{"EVAL", takeScript, "1", "coaching:ratelimit:ip:1", "10", "60000", "1767258000000"}, eval)
+		assert.Equal(t, 1, server.conns, "connections are reused")
+	})
+
+	t.Run("Limiter Uses Store Result", func(t *testing.T) {
+		server := newFakeRedis(t, func([]string) string { return "*2\r\n:0\r\n:250\r\n" })
+		client, err := NewClient(server.url("", ""))
+		require.NoError(t, err)
+		l := New(NewRedisStore(client, ""))
+
+		result, err := l.Allow(ctx, "ip:1", limit)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 4500*time.Millisecond, result.RetryAfter)
+	})
+
+	t.Run("Error Replies", func(t *testing.T) {
+		server := newFakeRedis(t, func(cmd []string) string {
+			if cmd[0] == "AUTH" {
+				return "-WRONGPASS invalid username-password pair\r\n"
+			}
+			return "-NOSCRIPT scripting disabled\r\n"
+		})
+		client, err := NewClient(server.url("bot:wrong@", ""))
+		require.NoError(t, err)
+		_, _, err = NewRedisStore(client, "").Take(ctx, "ip:1", limit, now)
+		assert.ErrorContains(t, err, "WRONGPASS")
+		assert.Equal(t, []string{"AUTH", "bot", "wrong"}, server.commands[0])
+
+		client, err = NewClient(server.url("", ""))
+		require.NoError(t, err)
+		_, _, err = NewRedisStore(client, "").Take(ctx, "ip:1", limit, now)
+		var redisErr RedisError
+		assert.ErrorAs(t, err, &redisErr)
+	})
+
+	t.Run("Unexpected Replies", func(t *testing.T) {
+		server := newFakeRedis(t, func([]string) string { return "$2\r\nhi\r\n" })
+		client, err := NewClient(server.url("", ""))
+		require.NoError(t, err)
+		_, _, err = NewRedisStore(client, "").Take(ctx, "ip:1", limit, now)
+		assert.ErrorContains(t, err, "unexpected reply")
+	})
+
+	t.Run("Invalid URLs", func(t *testing.T) {
+		for _, raw := range []string{"localhost:6379", "http://localhost", "redis://localhost/x"} {
+			_, err := NewClient(raw)
+			assert.Error(t, err, raw)
+		}
+		client, err := NewClient("rediss://cache.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "cache.example.com:6379", client.addr)
+		assert.True(t, client.useTLS)
+	})
+}
+
+func TestReadReply(t *testing.T) {
+	reply, err := readReply(bufio.NewReader(strings.NewReader("*4\r\n+OK\r\n$-1\r\n*1\r\n:-3\r\n-ERR nested\r\n")))
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"OK", nil, []interface{}{int64(-3)}, RedisError("ERR nested")}, reply)
+
+	_, err = readReply(bufio.NewReader(strings.NewReader(fmt.Sprintf("$%d\r\n", maxReply+1))))
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"coaching-backend/chatops"
+	"coaching-backend/config"
+	"coaching-backend/graph"
+	"coaching-backend/handlers"
+	"coaching-backend/openapi"
+	"coaching-backend/scim"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerRoutes mounts every API route, rate limited by limits, which the
+// gRPC server shares. Each route must also be described in
+// openapi.Operations; TestOpenAPICoversAllRoutes enforces this.
+func registerRoutes(r *gin.Engine, cfg *config.Config, limits *handlers.RateLimits) error {
+	sso, err := ssoConfig(cfg)
+	if err != nil {
+		return err
+	}
+	// Slack signs slash commands with its signing secret, Mattermost sends
+	// a per-command token.
+	slashCommands := chatops.Verifier{
+		SigningSecret: cfg.Chat.SlackSigningSecret,
+		Token:         cfg.Chat.MattermostCommandToken,
+	}
+
+	// server.auth_required turns away anonymous requests to scoped routes.
+	api := r.Group("/api",
+		handlers.LimitByIP(limits),
+		handlers.Authenticate(cfg.Server.AuthRequired),
+		handlers.LimitByClient(limits))
+	{
+		api.GET("/openapi.json", openapi.Handler)
+		api.GET("/docs", openapi.DocsHandler)
+		api.POST("/graphql", handlers.RequireReadScope("graphql"), graph.Handler(graph.DefaultLimits))
+		api.GET("/events", handlers.RequireScope("events"), handlers.StreamEvents)
+		api.GET("/events/ws", handlers.RequireScope("events"), handlers.EventsWebSocket(cfg.CORS.AllowedOrigins))
+
+		members := api.Group("/members", handlers.RequireScope("members"))
+		{
+			members.POST("", handlers.CreateTeamMember)
+			members.GET("", handlers.GetTeamMembers)
+			members.GET("/export", handlers.ExportMembers)
+			members.POST("/import", handlers.ImportMembers)
+			members.GET("/:id", handlers.GetTeamMember)
+			members.PUT("/:id", handlers.UpdateTeamMember)
+			members.DELETE("/:id", handlers.DeleteTeamMember)
+			members.GET("/:id/notification-preferences", handlers.GetNotificationPreferences)
+			members.PUT("/:id/notification-preferences", handlers.UpdateNotificationPreferences)
+			members.GET("/:id/digest", handlers.GetMemberDigest)
+			members.PUT("/:id/avatar", handlers.UploadMemberAvatar)
+			members.GET("/:id/avatar", handlers.GetMemberAvatar)
+			members.DELETE("/:id/avatar", handlers.DeleteMemberAvatar)
+		}
+
+		teams := api.Group("/teams", handlers.RequireScope("teams"))
+		{
+			teams.POST("", handlers.CreateTeam)
+			teams.GET("", handlers.GetTeams)
+			teams.GET("/export", handlers.ExportTeams)
+			teams.GET("/:id", handlers.GetTeam)
+			teams.PUT("/:id", handlers.UpdateTeam)
+			teams.DELETE("/:id", handlers.DeleteTeam)
+			teams.GET("/:id/digest", handlers.GetTeamDigest)
+			teams.PUT("/:id/logo", handlers.UploadTeamLogo)
+			teams.GET("/:id/logo", handlers.GetTeamLogo)
+			teams.DELETE("/:id/logo", handlers.DeleteTeamLogo)
+		}
+
+		projects := api.Group("/projects", handlers.RequireScope("projects"))
+		{
+			projects.POST("", handlers.CreateProject)
+			projects.GET("", handlers.GetProjects)
+			projects.GET("/:id", handlers.GetProject)
+			projects.PUT("/:id", handlers.UpdateProject)
+			projects.DELETE("/:id", handlers.DeleteProject)
+		}
+
+		releases := api.Group("/releases", handlers.RequireScope("releases"))
+		{
+			releases.POST("", handlers.CreateRelease)
+			releases.GET("", handlers.GetReleases)
+			releases.GET("/:id", handlers.GetRelease)
+			releases.PUT("/:id", handlers.UpdateRelease)
+			releases.DELETE("/:id", handlers.DeleteRelease)
+		}
+
+		meetings := api.Group("/meetings", handlers.RequireScope("meetings"))
+		{
+			meetings.POST("", handlers.CreateMeeting)
+			meetings.GET("", handlers.GetMeetings)
+			meetings.GET("/:id", handlers.GetMeeting)
+			meetings.PUT("/:id", handlers.UpdateMeeting)
+			meetings.DELETE("/:id", handlers.DeleteMeeting)
+		}
+
+		assignments := api.Group("/assignments", handlers.RequireScope("assignments"))
+		{
+			assignments.POST("", handlers.AssignMemberToTeam)
+			assignments.GET("", handlers.GetAssignments)
+			assignments.GET("/export", handlers.ExportAssignments)
+			assignments.GET("/unassigned", handlers.GetUnassignedMembers)
+			assignments.DELETE("/member/:id", handlers.RemoveMemberFromTeam)
+		}
+
+		feedback := api.Group("/feedback", handlers.RequireScope("feedback"))
+		{
+			feedback.POST("", handlers.CreateFeedback)
+			feedback.GET("", handlers.GetFeedback)
+			feedback.GET("/export", handlers.ExportFeedback)
+			feedback.GET("/target-types", handlers.GetFeedbackTargetTypes)
+			feedback.GET("/:id", handlers.GetFeedbackByID)
+			feedback.PUT("/:id", handlers.UpdateFeedback)
+			feedback.DELETE("/:id", handlers.DeleteFeedback)
+			feedback.POST("/:id/attachments", handlers.UploadAttachment)
+			feedback.GET("/:id/attachments", handlers.GetAttachments)
+			feedback.GET("/:id/attachments/:attachment_id", handlers.DownloadAttachment)
+			feedback.DELETE("/:id/attachments/:attachment_id", handlers.DeleteAttachment)
+		}
+
+		auth := api.Group("/auth")
+		{
+			auth.GET("/login", handlers.Login(sso))
+			auth.GET("/callback", handlers.LoginCallback(sso))
+			auth.GET("/session", handlers.GetSession)
+			auth.POST("/logout", handlers.Logout(sso))
+		}
+
+		api.POST("/chatops/kudos", handlers.SlashCommand(slashCommands))
+
+		webhooks := api.Group("/webhooks", handlers.RequireScope("webhooks"))
+		{
+			webhooks.POST("", handlers.CreateWebhook)
+			webhooks.GET("", handlers.GetWebhooks)
+			webhooks.GET("/:id", handlers.GetWebhook)
+			webhooks.PUT("/:id", handlers.UpdateWebhook)
+			webhooks.DELETE("/:id", handlers.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", handlers.GetWebhookDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", handlers.RedeliverWebhookDelivery)
+		}
+
+		apiKeys := api.Group("/api-keys", handlers.RequireScope("api_keys"))
+		{
+			apiKeys.POST("", handlers.CreateAPIKey)
+			apiKeys.GET("", handlers.GetAPIKeys)
+			apiKeys.GET("/:id", handlers.GetAPIKey)
+			apiKeys.POST("/:id/rotate", handlers.RotateAPIKey)
+			apiKeys.DELETE("/:id", handlers.RevokeAPIKey)
+		}
+	}
+
+	// SCIM clients are identity providers, authenticated by scim.token.
+	provisioning := r.Group(scim.Base, handlers.SCIMAuth(cfg.SCIM.Token))
+	{
+		provisioning.GET("/ServiceProviderConfig", handlers.GetSCIMServiceProviderConfig)
+		provisioning.GET("/ResourceTypes", handlers.GetSCIMResourceTypes)
+		provisioning.GET("/Schemas", handlers.GetSCIMSchemas)
+		provisioning.GET("/Users", handlers.GetSCIMUsers)
+		provisioning.POST("/Users", handlers.CreateSCIMUser)
+		provisioning.GET("/Users/:id", handlers.GetSCIMUser)
+		provisioning.PUT("/Users/:id", handlers.ReplaceSCIMUser)
+		provisioning.PATCH("/Users/:id", handlers.PatchSCIMUser)
+		provisioning.DELETE("/Users/:id", handlers.DeleteSCIMUser)
+		provisioning.GET("/Groups", handlers.GetSCIMGroups)
+		provisioning.POST("/Groups", handlers.CreateSCIMGroup)
+		provisioning.GET("/Groups/:id", handlers.GetSCIMGroup)
+		provisioning.PUT("/Groups/:id", handlers.ReplaceSCIMGroup)
+		provisioning.PATCH("/Groups/:id", handlers.PatchSCIMGroup)
+		provisioning.DELETE("/Groups/:id", handlers.DeleteSCIMGroup)
+	}
+
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok", "message": "Coaching API is running"})
+	})
+	return nil
+}
@@ -0,0 +1,163 @@
+package scim
+
+// The discovery endpoints (RFC 7644 section 4) describe what this service
+// provider supports.
+
+type Supported struct {
+	Supported bool `json:"supported"`
+}
+
+type BulkConfig struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+type FilterConfig struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type AuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ResourceMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type ServiceProviderConfig struct {
+	Schemas               []string               `json:"schemas"`
+	Patch                 Supported              `json:"patch"`
+	Bulk                  BulkConfig             `json:"bulk"`
+	Filter                FilterConfig           `json:"filter"`
+	ChangePassword        Supported              `json:"changePassword"`
+	Sort                  Supported              `json:"sort"`
+	ETag                  Supported              `json:"etag"`
+	AuthenticationSchemes []AuthenticationScheme `json:"authenticationSchemes"`
+	Meta                  ResourceMeta           `json:"meta"`
+}
+
+type ResourceType struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Endpoint    string       `json:"endpoint"`
+	Description string       `json:"description"`
+	Schema      string       `json:"schema"`
+	Meta        ResourceMeta `json:"meta"`
+}
+
+// SchemaAttribute describes an attribute of a resource schema.
+type SchemaAttribute struct {
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	MultiValued   bool              `json:"multiValued"`
+	Required      bool              `json:"required"`
+	CaseExact     bool              `json:"caseExact"`
+	Mutability    string            `json:"mutability"`
+	Returned      string            `json:"returned"`
+	Uniqueness    string            `json:"uniqueness"`
+	SubAttributes []SchemaAttribute `json:"subAttributes,omitempty"`
+}
+
+type Schema struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Attributes  []SchemaAttribute `json:"attributes"`
+	Meta        ResourceMeta      `json:"meta"`
+}
+
+var Config = ServiceProviderConfig{
+	Schemas: []string{ServiceProviderConfigSchema},
+	Patch:   Supported{true},
+	Filter:  FilterConfig{Supported: true, MaxResults: MaxCount},
+	AuthenticationSchemes: []AuthenticationScheme{{
+		Type:        "oauthbearertoken",
+		Name:        "Bearer token",
+		Description: "The token configured as SCIM_TOKEN, sent as Authorization: Bearer TOKEN",
+	}},
+	Meta: ResourceMeta{ResourceType: "ServiceProviderConfig", Location: Base + "/ServiceProviderConfig"},
+}
+
+var ResourceTypes = []ResourceType{
+	{
+		Schemas:     []string{ResourceTypeSchema},
+		ID:          "User",
+		Name:        "User",
+		Endpoint:    "/Users",
+		Description: "Team member",
+		Schema:      UserSchema,
+		Meta:        ResourceMeta{ResourceType: "ResourceType", Location: Base + "/ResourceTypes/User"},
+	},
+	{
+		Schemas:     []string{ResourceTypeSchema},
+		ID:          "Group",
+		Name:        "Group",
+		Endpoint:    "/Groups",
+		Description: "Team",
+		Schema:      GroupSchema,
+		Meta:        ResourceMeta{ResourceType: "ResourceType", Location: Base + "/ResourceTypes/Group"},
+	},
+}
+
+func attribute(name, typ string, required bool, mutability, uniqueness string) SchemaAttribute {
+	return SchemaAttribute{Name: name, Type: typ, Required: required, Mutability: mutability, Returned: "default", Uniqueness: uniqueness}
+}
+
+func multiValued(name, mutability string, subAttributes ...SchemaAttribute) SchemaAttribute {
+	a := attribute(name, "complex", false, mutability, "none")
+	a.MultiValued, a.SubAttributes = true, subAttributes
+	return a
+}
+
+// Schemas describe the attributes of Users and Groups that are stored.
+var Schemas = []Schema{
+	{
+		Schemas:     []string{SchemaSchema},
+		ID:          UserSchema,
+		Name:        "User",
+		Description: "Team member",
+		Attributes: []SchemaAttribute{
+			attribute("userName", "string", true, "readWrite", "server"),
+			{Name: "name", Type: "complex", Mutability: "readWrite", Returned: "default", Uniqueness: "none", SubAttributes: []SchemaAttribute{
+				attribute("formatted", "string", false, "readWrite", "none"),
+				attribute("givenName", "string", false, "writeOnly", "none"),
+				attribute("familyName", "string", false, "writeOnly", "none"),
+			}},
+			attribute("displayName", "string", false, "readWrite", "none"),
+			multiValued("emails", "readWrite",
+				attribute("value", "string", false, "readWrite", "none"),
+				attribute("type", "string", false, "readWrite", "none"),
+				attribute("primary", "boolean", false, "readWrite", "none")),
+			multiValued("photos", "readWrite",
+				attribute("value", "reference", false, "readWrite", "none"),
+				attribute("type", "string", false, "readWrite", "none")),
+			attribute("active", "boolean", false, "readWrite", "none"),
+			multiValued("groups", "readOnly",
+				attribute("value", "string", false, "readOnly", "none"),
+				attribute("$ref", "reference", false, "readOnly", "none"),
+				attribute("display", "string", false, "readOnly", "none")),
+		},
+		Meta: ResourceMeta{ResourceType: "Schema", Location: Base + "/Schemas/" + UserSchema},
+	},
+	{
+		Schemas:     []string{SchemaSchema},
+		ID:          GroupSchema,
+		Name:        "Group",
+		Description: "Team",
+		Attributes: []SchemaAttribute{
+			attribute("displayName", "string", true, "readWrite", "server"),
+			multiValued("members", "readWrite",
+				attribute("value", "string", false, "immutable", "none"),
+				attribute("$ref", "reference", false, "immutable", "none"),
+				attribute("display", "string", false, "readOnly", "none")),
+		},
+		Meta: ResourceMeta{ResourceType: "Schema", Location: Base + "/Schemas/" + GroupSchema},
+	},
+}
@@ -0,0 +1,479 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm/clause"
+)
+
+// Filter is a parsed filter expression (RFC 7644 section 3.4.2.2): a
+// Comparison, a Logical "and" or "or", a Not, or a ValuePath.
+type Filter interface {
+	filter()
+}
+
+// Comparison compares an attribute with Value using Op, one of eq, ne,
+// co, sw, ew, gt, ge, lt, le, or pr, which takes no value. Value is a
+// string, float64, bool or nil for null.
+type Comparison struct {
+	Attr  string
+	Op    string
+	Value interface{}
+}
+
+type Logical struct {
+	Op          string
+	Left, Right Filter
+}
+
+type Not struct {
+	Filter Filter
+}
+
+// ValuePath filters the entries of a multi-valued attribute, as in
+// emails[type eq "work"]. The attributes in Filter are relative to Attr.
+type ValuePath struct {
+	Attr   string
+	Filter Filter
+}
+
+func (Comparison) filter() {}
+func (Logical) filter()    {}
+func (Not) filter()        {}
+func (ValuePath) filter()  {}
+
+// RequestError is a request SCIM reports as a 400 with ScimType.
+type RequestError struct {
+	ScimType string
+	Detail   string
+}
+
+func (e *RequestError) Error() string {
+	return e.Detail
+}
+
+func filterError(format string, args ...interface{}) error {
+	return &RequestError{ScimType: InvalidFilter, Detail: fmt.Sprintf(format, args...)}
+}
+
+var operators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true, "pr": true,
+}
+
+// ParseFilter parses a filter. Attribute names are lower-cased and lose
+// any schema URN prefix; operators are lower-cased.
+func ParseFilter(s string) (Filter, error) {
+	p := &parser{input: s}
+	f, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.input) {
+		return nil, filterError("unexpected %q at position %d", p.rest(), p.pos+1)
+	}
+	return f, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+	// inPath is set within the brackets of a value path, where a
+	// closing bracket ends the filter.
+	inPath bool
+}
+
+func (p *parser) rest() string {
+	return p.input[p.pos:]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// keyword consumes word if it comes next, case-insensitively, followed by
+// a space or parenthesis.
+func (p *parser) keyword(word string) bool {
+	p.skipSpace()
+	rest := p.rest()
+	if len(rest) < len(word) || !strings.EqualFold(rest[:len(word)], word) {
+		return false
+	}
+	if len(rest) > len(word) && rest[len(word)] != ' ' && rest[len(word)] != '(' {
+		return false
+	}
+	p.pos += len(word)
+	return true
+}
+
+func (p *parser) or() (Filter, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = Logical{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Filter, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = Logical{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) term() (Filter, error) {
+	if p.keyword("not") {
+		p.skipSpace()
+		if !strings.HasPrefix(p.rest(), "(") {
+			return nil, filterError(`"not" must be followed by a parenthesized filter`)
+		}
+		f, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Filter: f}, nil
+	}
+
+	p.skipSpace()
+	if strings.HasPrefix(p.rest(), "(") {
+		p.pos++
+		inPath := p.inPath
+		p.inPath = false
+		f, err := p.or()
+		p.inPath = inPath
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); !strings.HasPrefix(p.rest(), ")") {
+			return nil, filterError("missing closing parenthesis")
+		}
+		p.pos++
+		return f, nil
+	}
+
+	attr, err := p.attrPath()
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(p.rest(), "[") {
+		if p.inPath {
+			return nil, filterError("value paths cannot be nested")
+		}
+		p.pos++
+		p.inPath = true
+		f, err := p.or()
+		p.inPath = false
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); !strings.HasPrefix(p.rest(), "]") {
+			return nil, filterError("missing closing bracket")
+		}
+		p.pos++
+		return ValuePath{Attr: attr, Filter: f}, nil
+	}
+
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) && unicode.IsLetter(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	op := strings.ToLower(p.input[start:p.pos])
+	if !operators[op] {
+		return nil, filterError("unknown operator %q", p.input[start:p.pos])
+	}
+	if op == "pr" {
+		return Comparison{Attr: attr, Op: op}, nil
+	}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	return Comparison{Attr: attr, Op: op, Value: value}, nil
+}
+
+// attrPath reads an attribute name with an optional sub-attribute.
+func (p *parser) attrPath() (string, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) && isAttrChar(p.input[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		if p.pos == len(p.input) {
+			return "", filterError("filter ends unexpectedly")
+		}
+		return "", filterError("expected an attribute at position %d", start+1)
+	}
+	return NormalizeAttr(p.input[start:p.pos]), nil
+}
+
+func isAttrChar(c byte) bool {
+	return c == '.' || c == ':' || c == '$' || c == '-' || c == '_' ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// NormalizeAttr lower-cases an attribute path and strips the URN of the
+// core User or Group schema from it.
+func NormalizeAttr(attr string) string {
+	lower := strings.ToLower(attr)
+	for _, urn := range []string{UserSchema, GroupSchema} {
+		if prefix := strings.ToLower(urn) + ":"; strings.HasPrefix(lower, prefix) {
+			return lower[len(prefix):]
+		}
+	}
+	return lower
+}
+
+// value reads a JSON string, number, true, false or null.
+func (p *parser) value() (interface{}, error) {
+	p.skipSpace()
+	rest := p.rest()
+	if strings.HasPrefix(rest, `"`) {
+		dec := json.NewDecoder(strings.NewReader(rest))
+		var s string
+		if err := dec.Decode(&s); err != nil {
+			return nil, filterError("invalid string at position %d", p.pos+1)
+		}
+		p.pos += int(dec.InputOffset())
+		return s, nil
+	}
+	end := 0
+	for end < len(rest) && rest[end] != ' ' && rest[end] != ')' && rest[end] != ']' {
+		end++
+	}
+	word := rest[:end]
+	p.pos += end
+	switch strings.ToLower(word) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(word, 64)
+	if err != nil || word == "" {
+		return nil, filterError("invalid value %q", word)
+	}
+	return n, nil
+}
+
+// Kind is the type of an attribute that can be filtered on.
+type Kind int
+
+const (
+	// String attributes compare case-insensitively.
+	String Kind = iota
+	// ID attributes hold record IDs, compared as numbers.
+	ID
+	DateTime
+	// Boolean attributes are true while their column, the time they
+	// became false such as deactivated_at, is NULL.
+	Boolean
+)
+
+// Attribute is how a filterable attribute is stored.
+type Attribute struct {
+	Column string
+	Kind   Kind
+	// In, if set, is a condition with a %s for the attribute's condition,
+	// for attributes stored in another table.
+	In string
+}
+
+// Attributes map normalized attribute paths to where they are stored.
+type Attributes map[string]Attribute
+
+// SQL translates f into a condition on the attributes' columns.
+func SQL(f Filter, attrs Attributes) (clause.Expr, error) {
+	var b sqlBuilder
+	if err := b.write(f, attrs, ""); err != nil {
+		return clause.Expr{}, err
+	}
+	return clause.Expr{SQL: b.sql.String(), Vars: b.vars}, nil
+}
+
+type sqlBuilder struct {
+	sql  strings.Builder
+	vars []interface{}
+}
+
+func (b *sqlBuilder) write(f Filter, attrs Attributes, prefix string) error {
+	switch f := f.(type) {
+	case Logical:
+		b.sql.WriteString("(")
+		if err := b.write(f.Left, attrs, prefix); err != nil {
+			return err
+		}
+		b.sql.WriteString(" " + strings.ToUpper(f.Op) + " ")
+		if err := b.write(f.Right, attrs, prefix); err != nil {
+			return err
+		}
+		b.sql.WriteString(")")
+		return nil
+	case Not:
+		b.sql.WriteString("NOT (")
+		if err := b.write(f.Filter, attrs, prefix); err != nil {
+			return err
+		}
+		b.sql.WriteString(")")
+		return nil
+	case ValuePath:
+		return b.write(f.Filter, attrs, f.Attr+".")
+	case Comparison:
+		attr, ok := attrs[prefix+f.Attr]
+		if !ok {
+			return filterError("filtering on %s is not supported", prefix+f.Attr)
+		}
+		condition, vars, err := compare(attr, f)
+		if err != nil {
+			return err
+		}
+		if attr.In != "" {
+			condition = fmt.Sprintf(attr.In, condition)
+		}
+		b.sql.WriteString(condition)
+		b.vars = append(b.vars, vars...)
+		return nil
+	}
+	return filterError("unsupported filter")
+}
+
+var sqlOperators = map[string]string{"eq": "=", "ne": "<>", "gt": ">", "ge": ">=", "lt": "<", "le": "<="}
+
+// likeEscaper escapes LIKE wildcards with "!", as a backslash would need
+// quoting in MySQL.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func compare(attr Attribute, c Comparison) (string, []interface{}, error) {
+	column := attr.Column
+	present := column + " IS NOT NULL"
+	if attr.Kind == String {
+		present = "(" + column + " IS NOT NULL AND " + column + " <> '')"
+	}
+	if attr.Kind == Boolean {
+		present = "1 = 1"
+	}
+
+	switch {
+	case c.Op == "pr":
+		return present, nil, nil
+	case c.Value == nil && c.Op == "eq":
+		return "NOT " + present, nil, nil
+	case c.Value == nil && c.Op == "ne":
+		return present, nil, nil
+	case c.Value == nil:
+		return "", nil, filterError("%s cannot be compared with null", c.Attr)
+	}
+
+	switch attr.Kind {
+	case String:
+		s, ok := c.Value.(string)
+		if !ok {
+			return "", nil, filterError("%s must be compared with a string", c.Attr)
+		}
+		s = strings.ToLower(s)
+		switch c.Op {
+		case "co":
+			return "LOWER(" + column + ") LIKE ? ESCAPE '!'", []interface{}{"%" + likeEscaper.Replace(s) + "%"}, nil
+		case "sw":
+			return "LOWER(" + column + ") LIKE ? ESCAPE '!'", []interface{}{likeEscaper.Replace(s) + "%"}, nil
+		case "ew":
+			return "LOWER(" + column + ") LIKE ? ESCAPE '!'", []interface{}{"%" + likeEscaper.Replace(s)}, nil
+		}
+		return "LOWER(" + column + ") " + sqlOperators[c.Op] + " ?", []interface{}{s}, nil
+
+	case ID:
+		var id float64
+		switch v := c.Value.(type) {
+		case string:
+			n, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				// No record has an ID that is not a number.
+				if c.Op == "ne" {
+					return present, nil, nil
+				}
+				return "1 = 0", nil, nil
+			}
+			id = float64(n)
+		case float64:
+			id = v
+		default:
+			return "", nil, filterError("%s must be compared with an ID", c.Attr)
+		}
+		if sqlOperators[c.Op] == "" {
+			return "", nil, filterError("%s cannot be compared with %s", c.Attr, c.Op)
+		}
+		return column + " " + sqlOperators[c.Op] + " ?", []interface{}{id}, nil
+
+	case DateTime:
+		s, _ := c.Value.(string)
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", nil, filterError("%s must be compared with an RFC 3339 date and time", c.Attr)
+		}
+		if sqlOperators[c.Op] == "" {
+			return "", nil, filterError("%s cannot be compared with %s", c.Attr, c.Op)
+		}
+		return column + " " + sqlOperators[c.Op] + " ?", []interface{}{t}, nil
+
+	case Boolean:
+		b, ok := c.Value.(bool)
+		if !ok || c.Op != "eq" && c.Op != "ne" {
+			return "", nil, filterError("%s can only be compared with true or false using eq or ne", c.Attr)
+		}
+		if b == (c.Op == "eq") {
+			return column + " IS NULL", nil, nil
+		}
+		return column + " IS NOT NULL", nil, nil
+	}
+	return "", nil, filterError("unsupported attribute %s", c.Attr)
+}
+
+// UserAttributes are the User attributes lists can be filtered on.
+var UserAttributes = Attributes{
+	"id":                {Column: "id", Kind: ID},
+	"username":          {Column: "email"},
+	"displayname":       {Column: "name"},
+	"name.formatted":    {Column: "name"},
+	"emails":            {Column: "email"},
+	"emails.value":      {Column: "email"},
+	"active":            {Column: "deactivated_at", Kind: Boolean},
+	"groups":            {Column: "team_id", Kind: ID},
+	"groups.value":      {Column: "team_id", Kind: ID},
+	"meta.created":      {Column: "created_at", Kind: DateTime},
+	"meta.lastmodified": {Column: "updated_at", Kind: DateTime},
+}
+
+// GroupAttributes are the Group attributes lists can be filtered on.
+var GroupAttributes = Attributes{
+	"id":                {Column: "id", Kind: ID},
+	"displayname":       {Column: "name"},
+	"members":           {Column: "id", Kind: ID, In: "id IN (SELECT team_id FROM team_members WHERE %s)"},
+	"members.value":     {Column: "id", Kind: ID, In: "id IN (SELECT team_id FROM team_members WHERE %s)"},
+	"meta.created":      {Column: "created_at", Kind: DateTime},
+	"meta.lastmodified": {Column: "updated_at", Kind: DateTime},
+}
@@ -0,0 +1,407 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PatchRequest is the body of a PATCH (RFC 7644 section 3.5.2).
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is one change. Op is add, remove or replace in any case.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func patchError(scimType, format string, args ...interface{}) error {
+	return &RequestError{ScimType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+// Patch applies the request's operations in order to resource, a User or
+// Group, by way of its JSON representation. Attributes of other schemas,
+// such as enterprise extensions, are ignored, as nothing stores them.
+func Patch[T any](resource *T, req PatchRequest) error {
+	if !contains(req.Schemas, PatchOpSchema) {
+		return patchError(InvalidSyntax, "schemas must contain %s", PatchOpSchema)
+	}
+	if len(req.Operations) == 0 {
+		return patchError(InvalidSyntax, "Operations must not be empty")
+	}
+
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	for i, op := range req.Operations {
+		if err := applyOperation(doc, op); err != nil {
+			var reqErr *RequestError
+			if errors.As(err, &reqErr) {
+				reqErr.Detail = fmt.Sprintf("Operations[%d]: %s", i, reqErr.Detail)
+			}
+			return err
+		}
+	}
+
+	if data, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	var patched T
+	if err := json.Unmarshal(data, &patched); err != nil {
+		return patchError(InvalidValue, "the patched resource is invalid: %v", err)
+	}
+	*resource = patched
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// path is a parsed PATCH path: attr, optionally a filter on its entries,
+// and optionally a sub-attribute.
+type path struct {
+	attr   string
+	filter Filter
+	sub    string
+}
+
+// extension reports whether a path belongs to a schema other than the
+// core User and Group schemas.
+func extension(p string) bool {
+	return strings.HasPrefix(strings.ToLower(NormalizeAttr(p)), "urn:")
+}
+
+func parsePath(s string) (path, error) {
+	p := path{}
+	attr, rest, hasFilter := strings.Cut(s, "[")
+	attr = NormalizeAttr(strings.TrimSpace(attr))
+	if hasFilter {
+		end := strings.LastIndex(rest, "]")
+		if end < 0 {
+			return p, patchError(InvalidPath, "missing closing bracket in %q", s)
+		}
+		f, err := ParseFilter(rest[:end])
+		if err != nil {
+			return p, patchError(InvalidPath, "invalid filter in %q: %v", s, err)
+		}
+		p.filter = f
+		if sub := rest[end+1:]; sub != "" {
+			if !strings.HasPrefix(sub, ".") {
+				return p, patchError(InvalidPath, "invalid path %q", s)
+			}
+			p.sub = strings.ToLower(sub[1:])
+		}
+	} else if name, sub, ok := strings.Cut(attr, "."); ok {
+		attr, p.sub = name, sub
+	}
+	if attr == "" || strings.ContainsAny(attr, " ]") {
+		return p, patchError(InvalidPath, "invalid path %q", s)
+	}
+	p.attr = attr
+	return p, nil
+}
+
+func applyOperation(doc map[string]interface{}, op PatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	if kind != "add" && kind != "remove" && kind != "replace" {
+		return patchError(InvalidSyntax, "unknown op %q", op.Op)
+	}
+
+	var value interface{}
+	if len(op.Value) > 0 {
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return patchError(InvalidSyntax, "value is not valid JSON")
+		}
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return patchError(NoTarget, "remove requires a path")
+		}
+		attrs, ok := value.(map[string]interface{})
+		if !ok {
+			return patchError(InvalidValue, "value must be an object when there is no path")
+		}
+		for name, v := range attrs {
+			if name == "schemas" || extension(name) {
+				continue
+			}
+			p, err := parsePath(name)
+			if err != nil {
+				return err
+			}
+			if err := apply(doc, kind, p, v, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if extension(op.Path) {
+		return nil
+	}
+	p, err := parsePath(op.Path)
+	if err != nil {
+		return err
+	}
+	if kind != "remove" && len(op.Value) == 0 {
+		return patchError(InvalidValue, "%s requires a value", kind)
+	}
+	return apply(doc, kind, p, value, len(op.Value) > 0)
+}
+
+func apply(doc map[string]interface{}, kind string, p path, value interface{}, hasValue bool) error {
+	// Some providers send booleans as strings, e.g. "False".
+	if s, ok := value.(string); ok && p.attr == "active" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return patchError(InvalidValue, "active must be a boolean")
+		}
+		value = b
+	}
+
+	key := keyOf(doc, p.attr)
+	current, exists := doc[key]
+
+	if p.filter != nil {
+		entries, _ := current.([]interface{})
+		matched := false
+		var kept []interface{}
+		for _, entry := range entries {
+			object, _ := entry.(map[string]interface{})
+			if object == nil || !Matches(p.filter, object) {
+				kept = append(kept, entry)
+				continue
+			}
+			matched = true
+			switch {
+			case kind == "remove" && p.sub == "":
+				continue
+			case kind == "remove":
+				delete(object, keyOf(object, p.sub))
+			case p.sub != "":
+				object[keyOf(object, p.sub)] = value
+			default:
+				replacement, ok := value.(map[string]interface{})
+				if !ok {
+					return patchError(InvalidValue, "value for %s must be an object", p.attr)
+				}
+				if kind == "add" {
+					for k, v := range replacement {
+						object[keyOf(object, k)] = v
+					}
+				} else {
+					entry = replacement
+				}
+			}
+			kept = append(kept, entry)
+		}
+		if !matched {
+			if kind == "remove" {
+				return nil
+			}
+			return patchError(NoTarget, "no %s entry matches the filter", p.attr)
+		}
+		doc[key] = kept
+		return nil
+	}
+
+	if p.sub != "" {
+		object, _ := current.(map[string]interface{})
+		if !exists || current == nil {
+			if kind == "remove" {
+				return nil
+			}
+			object = map[string]interface{}{}
+			doc[key] = object
+		}
+		if object == nil {
+			return patchError(InvalidPath, "%s has no sub-attribute %s", p.attr, p.sub)
+		}
+		if kind == "remove" {
+			delete(object, keyOf(object, p.sub))
+		} else {
+			object[keyOf(object, p.sub)] = value
+		}
+		return nil
+	}
+
+	entries, multiValued := current.([]interface{})
+	switch kind {
+	case "remove":
+		// A value lists the entries to remove, as some providers send for
+		// group members.
+		if multiValued && hasValue {
+			remove, _ := value.([]interface{})
+			var kept []interface{}
+			for _, entry := range entries {
+				if !containsEntry(remove, entry) {
+					kept = append(kept, entry)
+				}
+			}
+			doc[key] = kept
+			return nil
+		}
+		delete(doc, key)
+	case "add":
+		if added, ok := value.([]interface{}); ok && (multiValued || current == nil) {
+			for _, entry := range added {
+				if !containsEntry(entries, entry) {
+					entries = append(entries, entry)
+				}
+			}
+			doc[key] = entries
+			return nil
+		}
+		if object, ok := current.(map[string]interface{}); ok {
+			if added, ok := value.(map[string]interface{}); ok {
+				for k, v := range added {
+					object[keyOf(object, k)] = v
+				}
+				return nil
+			}
+		}
+		doc[key] = value
+	case "replace":
+		if object, ok := current.(map[string]interface{}); ok {
+			if replaced, ok := value.(map[string]interface{}); ok {
+				for k, v := range replaced {
+					object[keyOf(object, k)] = v
+				}
+				return nil
+			}
+		}
+		doc[key] = value
+	}
+	return nil
+}
+
+// containsEntry reports whether entries holds entry, comparing entries of
+// complex attributes by their value.
+func containsEntry(entries []interface{}, entry interface{}) bool {
+	object, _ := entry.(map[string]interface{})
+	for _, e := range entries {
+		other, _ := e.(map[string]interface{})
+		if object != nil && other != nil && object["value"] != nil {
+			if reflect.DeepEqual(object["value"], other["value"]) {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyOf returns the key of object matching name case-insensitively, or
+// name if there is none.
+func keyOf(object map[string]interface{}, name string) string {
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+// Matches evaluates f, a filter on the entries of a multi-valued
+// attribute, against one entry. Strings compare case-insensitively.
+func Matches(f Filter, entry map[string]interface{}) bool {
+	switch f := f.(type) {
+	case Logical:
+		if f.Op == "and" {
+			return Matches(f.Left, entry) && Matches(f.Right, entry)
+		}
+		return Matches(f.Left, entry) || Matches(f.Right, entry)
+	case Not:
+		return !Matches(f.Filter, entry)
+	case Comparison:
+		return matchValue(entry[keyOf(entry, f.Attr)], f)
+	}
+	return false
+}
+
+func matchValue(v interface{}, c Comparison) bool {
+	if c.Op == "pr" {
+		return v != nil && v != ""
+	}
+	if c.Value == nil {
+		return (v == nil) == (c.Op == "eq")
+	}
+	switch want := c.Value.(type) {
+	case string:
+		got, ok := v.(string)
+		if !ok {
+			return c.Op == "ne"
+		}
+		got, want = strings.ToLower(got), strings.ToLower(want)
+		switch c.Op {
+		case "eq":
+			return got == want
+		case "ne":
+			return got != want
+		case "co":
+			return strings.Contains(got, want)
+		case "sw":
+			return strings.HasPrefix(got, want)
+		case "ew":
+			return strings.HasSuffix(got, want)
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case float64:
+		got, ok := v.(float64)
+		if !ok {
+			return c.Op == "ne"
+		}
+		switch c.Op {
+		case "eq":
+			return got == want
+		case "ne":
+			return got != want
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case bool:
+		got, _ := v.(bool)
+		switch c.Op {
+		case "eq":
+			return got == want
+		case "ne":
+			return got != want
+		}
+	}
+	return false
+}
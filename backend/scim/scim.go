@@ -0,0 +1,276 @@
+// Package scim speaks SCIM 2.0 (RFC 7643 and 7644), so an identity
+// provider can provision team members as Users and teams as Groups. Group
+// membership is team assignment: a member is in at most one group, and
+// adding them to another moves them.
+package scim
+
+import (
+	"coaching-backend/models"
+	"crypto/subtle"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of SCIM requests and responses. Clients
+// may also send application/json.
+const ContentType = "application/scim+json"
+
+// Schema URNs.
+const (
+	UserSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	GroupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	ListResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PatchOpSchema               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ErrorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	ServiceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	ResourceTypeSchema          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+)
+
+// Base is the path the SCIM endpoints are served under.
+const Base = "/scim/v2"
+
+// Paging limits for list requests.
+const (
+	DefaultCount = 100
+	MaxCount     = 500
+)
+
+// Authorized reports whether the Authorization header carries token as a
+// bearer token. No request is authorized when token is empty.
+func Authorized(header, token string) bool {
+	scheme, credentials, ok := strings.Cut(header, " ")
+	if !ok || token == "" || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(credentials)), []byte(token)) == 1
+}
+
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// MultiValue is an entry of a multi-valued attribute such as emails.
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// User is a team member. userName and the primary email are both the
+// member's email address; displayName is their name. active is always
+// true when read: deactivating a user deletes the member.
+type User struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *Name        `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []MultiValue `json:"emails,omitempty"`
+	Photos      []MultiValue `json:"photos,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Groups      []MultiValue `json:"groups,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// Group is a team; its members are the team's members.
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+type ListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int64    `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+// NewListResponse wraps one page of resources.
+func NewListResponse[T any](resources []T, total int64, startIndex int) ListResponse[T] {
+	if resources == nil {
+		resources = []T{}
+	}
+	return ListResponse[T]{
+		Schemas:      []string{ListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+// Error is the body of every SCIM error response.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// scimType values of errors.
+const (
+	InvalidFilter = "invalidFilter"
+	InvalidSyntax = "invalidSyntax"
+	InvalidPath   = "invalidPath"
+	InvalidValue  = "invalidValue"
+	NoTarget      = "noTarget"
+	Mutability    = "mutability"
+	Uniqueness    = "uniqueness"
+)
+
+func NewError(status int, scimType, detail string) Error {
+	return Error{Schemas: []string{ErrorSchema}, Status: strconv.Itoa(status), ScimType: scimType, Detail: detail}
+}
+
+func formatID(id uint32) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// ParseID parses a resource ID. Resources are numbered like the records
+// they represent.
+func ParseID(id string) (uint32, bool) {
+	n, err := strconv.ParseUint(id, 10, 32)
+	return uint32(n), err == nil && n != 0
+}
+
+func UserLocation(id uint32) string {
+	return Base + "/Users/" + formatID(id)
+}
+
+func GroupLocation(id uint32) string {
+	return Base + "/Groups/" + formatID(id)
+}
+
+// FromMember represents a member as a User.
+func FromMember(member *models.TeamMember) User {
+	active := member.Active()
+	user := User{
+		Schemas:     []string{UserSchema},
+		ID:          formatID(member.ID),
+		UserName:    member.Email,
+		Name:        &Name{Formatted: member.Name},
+		DisplayName: member.Name,
+		Emails:      []MultiValue{{Value: member.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      member.CreatedAt,
+			LastModified: member.UpdatedAt,
+			Location:     UserLocation(member.ID),
+		},
+	}
+	if member.Picture != "" {
+		user.Photos = []MultiValue{{Value: member.Picture, Type: "photo"}}
+	}
+	if member.TeamID != nil {
+		group := MultiValue{Value: formatID(*member.TeamID), Ref: GroupLocation(*member.TeamID)}
+		if member.Team != nil {
+			group.Display = member.Team.Name
+		}
+		user.Groups = []MultiValue{group}
+	}
+	return user
+}
+
+// ApplyUser copies the attributes of u a member stores onto member. The
+// name is the displayName, or else the formatted or given and family
+// name, or else the userName; the email is the userName if it is an
+// address, or else the primary email. An inactive user is a deactivated
+// member; leaving active out keeps the member's state.
+func ApplyUser(u *User, member *models.TeamMember) {
+	switch {
+	case u.Active == nil:
+	case *u.Active:
+		member.DeactivatedAt = nil
+	case member.DeactivatedAt == nil:
+		now := time.Now().UTC()
+		member.DeactivatedAt = &now
+	}
+	member.Email = u.email()
+	member.Name = u.UserName
+	switch {
+	case u.DisplayName != "":
+		member.Name = u.DisplayName
+	case u.Name != nil && u.Name.Formatted != "":
+		member.Name = u.Name.Formatted
+	case u.Name != nil && u.Name.GivenName+u.Name.FamilyName != "":
+		member.Name = strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+	}
+	member.Picture = ""
+	if len(u.Photos) > 0 {
+		member.Picture = primary(u.Photos).Value
+	}
+}
+
+func (u *User) email() string {
+	if strings.Contains(u.UserName, "@") || len(u.Emails) == 0 {
+		return u.UserName
+	}
+	return primary(u.Emails).Value
+}
+
+// primary returns the entry marked primary, or the first one.
+func primary(values []MultiValue) MultiValue {
+	for _, v := range values {
+		if v.Primary {
+			return v
+		}
+	}
+	return values[0]
+}
+
+// FromTeam represents a team, with its members loaded, as a Group.
+func FromTeam(team *models.Team) Group {
+	group := Group{
+		Schemas:     []string{GroupSchema},
+		ID:          formatID(team.ID),
+		DisplayName: team.Name,
+		Members:     []MultiValue{},
+		Meta: &Meta{
+			ResourceType: "Group",
+			Created:      team.CreatedAt,
+			LastModified: team.UpdatedAt,
+			Location:     GroupLocation(team.ID),
+		},
+	}
+	for _, member := range team.Members {
+		group.Members = append(group.Members, MultiValue{
+			Value:   formatID(member.ID),
+			Display: member.Name,
+			Ref:     UserLocation(member.ID),
+		})
+	}
+	return group
+}
+
+// MemberIDs parses the IDs of a group's members.
+func (g *Group) MemberIDs() ([]uint32, error) {
+	ids := make([]uint32, 0, len(g.Members))
+	for _, m := range g.Members {
+		id, ok := ParseID(m.Value)
+		if !ok {
+			return nil, &RequestError{ScimType: InvalidValue, Detail: "member " + strconv.Quote(m.Value) + " is not a user ID"}
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
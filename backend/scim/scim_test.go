@@ -0,0 +1,177 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorized(t *testing.T) {
+	assert.True(t, Authorized("Bearer secret", "secret"))
+	assert.True(t, Authorized("bearer secret", "secret"))
+	assert.False(t, Authorized("Bearer other", "secret"))
+	assert.False(t, Authorized("Basic secret", "secret"))
+	assert.False(t, Authorized("secret", "secret"))
+	assert.False(t, Authorized("Bearer ", ""), "an empty token authorizes nothing")
+}
+
+func TestParseFilter(t *testing.T) {
+	t.Run("Precedence", func(t *testing.T) {
+		f, err := ParseFilter(`userName eq "a" or displayName co "b" and not (active eq false)`)
+		require.NoError(t, err)
+		assert.Equal(t, Logical{
+			Op:   "or",
+			Left: Comparison{Attr: "username", Op: "eq", Value: "a"},
+			Right: Logical{
+				Op:    "and",
+				Left:  Comparison{Attr: "displayname", Op: "co", Value: "b"},
+				Right: Not{Filter: Comparison{Attr: "active", Op: "eq", Value: false}},
+			},
+		}, f)
+	})
+
+	t.Run("Value Paths And URNs", func(t *testing.T) {
+		f, err := ParseFilter(`urn:ietf:params:scim:schemas:core:2.0:User:emails[type EQ "work" and value pr]`)
+		require.NoError(t, err)
+		assert.Equal(t, ValuePath{Attr: "emails", Filter: Logical{
+			Op:    "and",
+			Left:  Comparison{Attr: "type", Op: "eq", Value: "work"},
+			Right: Comparison{Attr: "value", Op: "pr"},
+		}}, f)
+	})
+
+	t.Run("Escaped Strings And Numbers", func(t *testing.T) {
+		f, err := ParseFilter(`displayName eq "say \"hi\"" or id ge 2`)
+		require.NoError(t, err)
+		assert.Equal(t, "say \"hi\"", f.(Logical).Left.(Comparison).Value)
+		assert.Equal(t, 2.0, f.(Logical).Right.(Comparison).Value)
+	})
+
+	t.Run("Invalid Filters", func(t *testing.T) {
+		for _, filter := range []string{
+			``, `userName`, `userName like "a"`, `userName eq`, `userName eq "a" and`,
+			`(userName eq "a"`, `emails[type eq "work"`, `emails[a[b eq 1]]`, `not userName eq "a"`,
+			`userName eq "a" extra`, `userName eq "unterminated`,
+		} {
+			_, err := ParseFilter(filter)
+			var reqErr *RequestError
+			if assert.ErrorAs(t, err, &reqErr, filter) {
+				assert.Equal(t, InvalidFilter, reqErr.ScimType)
+			}
+		}
+	})
+}
+
+func TestSQL(t *testing.T) {
+	sql := func(filter string, attrs Attributes) (string, []interface{}) {
+		f, err := ParseFilter(filter)
+		require.NoError(t, err)
+		expr, err := SQL(f, attrs)
+		require.NoError(t, err)
+		return expr.SQL, expr.Vars
+	}
+
+	t.Run("Strings Compare Case-Insensitively", func(t *testing.T) {
+		query, vars := sql(`userName eq "Ada@Example.com"`, UserAttributes)
+		assert.Equal(t, "LOWER(email) = ?", query)
+		assert.Equal(t, []interface{}{"ada@example.com"}, vars)
+	})
+
+	t.Run("Escapes LIKE Wildcards", func(t *testing.T) {
+		query, vars := sql(`displayName co "50%_off!"`, UserAttributes)
+		assert.Equal(t, "LOWER(name) LIKE ? ESCAPE '!'", query)
+		assert.Equal(t, []interface{}{"%50!%!_off!!%"}, vars)
+	})
+
+	t.Run("Logic", func(t *testing.T) {
+		query, _ := sql(`not (userName pr) or groups eq null`, UserAttributes)
+		assert.Equal(t, "(NOT ((email IS NOT NULL AND email <> '')) OR NOT team_id IS NOT NULL)", query)
+	})
+
+	t.Run("Members Use A Subquery", func(t *testing.T) {
+		query, vars := sql(`members[value eq "7"]`, GroupAttributes)
+		assert.Equal(t, "id IN (SELECT team_id FROM team_members WHERE id = ?)", query)
+		assert.Equal(t, []interface{}{7.0}, vars)
+	})
+
+	t.Run("Unsupported Attributes", func(t *testing.T) {
+		f, _ := ParseFilter(`nickName eq "ada"`)
+		_, err := SQL(f, UserAttributes)
+		var reqErr *RequestError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, InvalidFilter, reqErr.ScimType)
+	})
+}
+
+func TestPatch(t *testing.T) {
+	patch := func(user User, ops string) (User, error) {
+		var req PatchRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"schemas":["`+PatchOpSchema+`"],"Operations":`+ops+`}`), &req))
+		err := Patch(&user, req)
+		return user, err
+	}
+	user := User{
+		Schemas:     []string{UserSchema},
+		UserName:    "ada@example.com",
+		DisplayName: "Ada",
+		Emails:      []MultiValue{{Value: "ada@example.com", Type: "work", Primary: true}},
+	}
+
+	t.Run("Replace With Path", func(t *testing.T) {
+		got, err := patch(user, `[{"op":"Replace","path":"displayName","value":"Ada Lovelace"}]`)
+		require.NoError(t, err)
+		assert.Equal(t, "Ada Lovelace", got.DisplayName)
+		assert.Equal(t, "Ada", user.DisplayName, "the original is left alone")
+	})
+
+	t.Run("Replace Without Path", func(t *testing.T) {
+		got, err := patch(user, `[{"op":"replace","value":{"name.givenName":"Ada","active":"False","urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department":"R&D"}}]`)
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", got.Name.GivenName)
+		require.NotNil(t, got.Active)
+		assert.False(t, *got.Active)
+	})
+
+	t.Run("Filtered Paths", func(t *testing.T) {
+		got, err := patch(user, `[
+			{"op":"add","path":"emails","value":[{"value":"home@example.com","type":"home"}]},
+			{"op":"replace","path":"emails[type eq \"home\"].value","value":"lovelace@example.com"},
+			{"op":"remove","path":"emails[type eq \"work\"]"}
+		]`)
+		require.NoError(t, err)
+		assert.Equal(t, []MultiValue{{Value: "lovelace@example.com", Type: "home"}}, got.Emails)
+	})
+
+	t.Run("Remove By Value", func(t *testing.T) {
+		got, err := patch(user, `[{"op":"remove","path":"emails","value":[{"value":"ada@example.com"}]}]`)
+		require.NoError(t, err)
+		assert.Empty(t, got.Emails)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for ops, scimType := range map[string]string{
+			`[]`:                                   InvalidSyntax,
+			`[{"op":"move","path":"displayName"}]`: InvalidSyntax,
+			`[{"op":"remove"}]`:                    NoTarget,
+			`[{"op":"replace","path":"emails[type eq \"home\"]","value":{}}]`: NoTarget,
+			`[{"op":"replace","path":"emails[type eq","value":{}}]`:           InvalidPath,
+			`[{"op":"replace","path":"displayName"}]`:                         InvalidValue,
+			`[{"op":"replace","path":"active","value":"maybe"}]`:              InvalidValue,
+		} {
+			_, err := patch(user, ops)
+			var reqErr *RequestError
+			if assert.ErrorAs(t, err, &reqErr, ops) {
+				assert.Equal(t, scimType, reqErr.ScimType, ops)
+			}
+		}
+	})
+
+	t.Run("Requires The PatchOp Schema", func(t *testing.T) {
+		err := Patch(&user, PatchRequest{Operations: []PatchOperation{{Op: "remove", Path: "displayName"}}})
+		var reqErr *RequestError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, InvalidSyntax, reqErr.ScimType)
+	})
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"context"
+)
+
+type (
+	scopesKey   struct{}
+	operatorKey struct{}
+)
+
+// WithScopes returns ctx acting for a principal, an API key or a signed-in
+// member, with scopes.
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey{}, scopes)
+}
+
+// Scopes returns the scopes of ctx's principal, and false when ctx is
+// anonymous.
+func Scopes(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(scopesKey{}).([]string)
+	return scopes, ok
+}
+
+// AsOperator returns ctx acting for the operator, as the command-line
+// tools do. The operator is anonymous but may do what anonymous requests
+// may not, such as create API keys.
+func AsOperator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operatorKey{}, true)
+}
+
+// Authorize fails with a forbidden error unless ctx's principal has
+// access to resource. Anonymous contexts pass: the transport admits them
+// only when authentication is not required, and the command-line tools
+// act for the operator.
+func Authorize(ctx context.Context, resource, access string) error {
+	scopes, ok := Scopes(ctx)
+	if !ok || models.AllowsScope(scopes, resource, access) {
+		return nil
+	}
+	return &Error{Code: problem.CodeForbidden, Message: "This requires the " + models.Scope(resource, access) + " scope"}
+}
+
+// authenticated fails with an unauthorized error when ctx is anonymous
+// and not the operator's. It guards what would outlive a later switch to
+// requiring authentication, however the setting is now.
+func authenticated(ctx context.Context, action string) error {
+	if _, ok := Scopes(ctx); ok || ctx.Value(operatorKey{}) != nil {
+		return nil
+	}
+	return unauthorized("Sign in or use an API key to " + action)
+}
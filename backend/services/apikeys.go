@@ -0,0 +1,177 @@
+package services
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyIDLength is the length of the random part of a key's prefix.
+	apiKeyIDLength = 12
+	// lastUsedInterval is how stale a key's last_used_at may get, so that
+	// busy keys do not write on every request.
+	lastUsedInterval = time.Minute
+)
+
+// newAPIKeyToken returns a fresh token for the prefix, which is generated
+// when empty. Tokens look like ck_<id>_<secret>.
+func newAPIKeyToken(prefix string) (token, newPrefix string) {
+	if prefix == "" {
+		prefix = models.APIKeyPrefix + strings.ToLower(rand.Text()[:apiKeyIDLength])
+	}
+	return prefix + "_" + rand.Text() + rand.Text(), prefix
+}
+
+// CreateAPIKey stores a new key with the given name, scopes and optional
+// expiry. The token is written back to key.Token and cannot be recovered
+// later. A principal may only hand out scopes it holds itself, so that a
+// key cannot mint a broader one, and anonymous callers other than the
+// operator may not create keys at all.
+func (s *Service) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	if err := authenticated(ctx, "create API keys"); err != nil {
+		return err
+	}
+	if err := validateAPIKey(key); err != nil {
+		return err
+	}
+	for _, scope := range key.Scopes {
+		resource, access, _ := strings.Cut(scope, ":")
+		if err := Authorize(ctx, resource, access); err != nil {
+			return &Error{Code: problem.CodeForbidden, Message: "A key cannot be given the " + scope + " scope, which you do not have"}
+		}
+	}
+	key.Token, key.Prefix = newAPIKeyToken("")
+	key.SecretHash = hashToken(key.Token)
+	key.LastUsedAt, key.RotatedAt, key.RevokedAt = nil, nil, nil
+
+	if err := s.with(ctx).Create(key).Error; err != nil {
+		return databaseError(err, "Failed to create API key")
+	}
+	return nil
+}
+
+// ListAPIKeys returns every key, revoked ones included, newest first.
+func (s *Service) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := s.with(ctx).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch API keys")
+	}
+	return keys, nil
+}
+
+func (s *Service) GetAPIKey(ctx context.Context, id uint32) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := s.with(ctx).First(&key, id).Error; err != nil {
+		return nil, lookupError(err, "API key not found")
+	}
+	return &key, nil
+}
+
+// RotateAPIKey replaces the key's secret, keeping its prefix, scopes and
+// expiry. The old token stops working at once; the new one is written to
+// Token. Revoked keys cannot be rotated, and anonymous callers other
+// than the operator may not rotate keys.
+func (s *Service) RotateAPIKey(ctx context.Context, id uint32) (*models.APIKey, error) {
+	if err := authenticated(ctx, "rotate API keys"); err != nil {
+		return nil, err
+	}
+	key, err := s.GetAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, &Error{Code: problem.CodeConflict, Message: "A revoked API key cannot be rotated"}
+	}
+
+	now := time.Now().UTC()
+	key.Token, _ = newAPIKeyToken(key.Prefix)
+	key.SecretHash = hashToken(key.Token)
+	key.RotatedAt = &now
+	if err := s.with(ctx).Save(key).Error; err != nil {
+		return nil, databaseError(err, "Failed to rotate API key")
+	}
+	return key, nil
+}
+
+// RevokeAPIKey stops the key from working. The key stays listed with its
+// revocation time; revoking it again changes nothing.
+func (s *Service) RevokeAPIKey(ctx context.Context, id uint32) error {
+	key, err := s.GetAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	if err := s.with(ctx).Model(key).Update("revoked_at", time.Now().UTC()).Error; err != nil {
+		return databaseError(err, "Failed to revoke API key")
+	}
+	return nil
+}
+
+// AuthenticateAPIKey returns the active key a token belongs to and records
+// that it was used.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, token string) (*models.APIKey, error) {
+	invalidKey := unauthorized("The API key is invalid, expired or revoked")
+	rest, ok := strings.CutPrefix(token, models.APIKeyPrefix)
+	if !ok || len(rest) <= apiKeyIDLength || rest[apiKeyIDLength] != '_' {
+		return nil, invalidKey
+	}
+	prefix := models.APIKeyPrefix + rest[:apiKeyIDLength]
+
+	var key models.APIKey
+	err := s.with(ctx).Where("prefix = ?", prefix).Take(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, invalidKey
+	}
+	if err != nil {
+		return nil, databaseError(err, "Failed to fetch API key")
+	}
+	now := time.Now().UTC()
+	if subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(key.SecretHash)) != 1 ||
+		key.RevokedAt != nil || (key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)) {
+		return nil, invalidKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		if err := s.with(ctx).Model(&key).UpdateColumn("last_used_at", now).Error; err != nil {
+			return nil, databaseError(err, "Failed to record API key use")
+		}
+		key.LastUsedAt = &now
+	}
+	return &key, nil
+}
+
+func validateAPIKey(key *models.APIKey) error {
+	key.Name = strings.TrimSpace(key.Name)
+	if err := validate(key); err != nil {
+		return err
+	}
+
+	var fields []problem.FieldError
+	for _, scope := range key.Scopes {
+		if !models.KnownScope(scope) {
+			fields = append(fields, problem.FieldError{
+				Field:   "scopes",
+				Rule:    "oneof",
+				Message: "must contain only <resource>:read or <resource>:write, where resource is one of: " + strings.Join(models.ScopeResources, ", "),
+			})
+			break
+		}
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		fields = append(fields, problem.FieldError{Field: "expires_at", Rule: "future", Message: "must be in the future"})
+	}
+	if len(fields) > 0 {
+		return invalid(fields...)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (s *Service) AssignMember(ctx context.Context, memberID, teamID uint32) (*models.TeamMember, error) {
+	var member models.TeamMember
+	if err := s.with(ctx).First(&member, memberID).Error; err != nil {
+		return nil, lookupError(err, "Team member not found")
+	}
+
+	var team models.Team
+	if err := s.with(ctx).First(&team, teamID).Error; err != nil {
+		return nil, lookupError(err, "Team not found")
+	}
+
+	previousTeamID := member.TeamID
+	member.TeamID = &teamID
+	err := s.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(&member).Error; err != nil {
+			return databaseError(err, "Failed to assign member to team")
+		}
+		if err := recordTeamChange(tx, &member, previousTeamID, &teamID); err != nil {
+			return err
+		}
+		return recordMember(tx, events.MemberAssigned, &member, &teamID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *Service) UnassignMember(ctx context.Context, memberID uint32) (*models.TeamMember, error) {
+	var member models.TeamMember
+	if err := s.with(ctx).First(&member, memberID).Error; err != nil {
+		return nil, lookupError(err, "Team member not found")
+	}
+
+	previousTeamID := member.TeamID
+	member.TeamID = nil
+	err := s.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(&member).Error; err != nil {
+			return databaseError(err, "Failed to remove member from team")
+		}
+		if err := recordTeamChange(tx, &member, previousTeamID, nil); err != nil {
+			return err
+		}
+		return recordMember(tx, events.MemberUnassigned, &member, previousTeamID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *Service) ListAssignedMembers(ctx context.Context) ([]models.TeamMember, error) {
+	var members []models.TeamMember
+	if err := s.with(ctx).Scopes(activeMembers).Preload("Team").Where("team_id IS NOT NULL").Find(&members).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch assignments")
+	}
+	return members, nil
+}
+
+func (s *Service) ListUnassignedMembers(ctx context.Context) ([]models.TeamMember, error) {
+	var members []models.TeamMember
+	if err := s.with(ctx).Scopes(activeMembers).Where("team_id IS NULL").Find(&members).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch unassigned members")
+	}
+	return members, nil
+}
+
+// recordTeamChange adds the member's move from one team to another to the
+// assignment history. Either team may be nil; nothing is recorded when
+// they are the same.
+func recordTeamChange(tx *gorm.DB, member *models.TeamMember, from, to *uint32) error {
+	if from != nil && to != nil && *from == *to || from == nil && to == nil {
+		return nil
+	}
+	changes := []struct {
+		teamID *uint32
+		action string
+	}{{from, models.MemberLeft}, {to, models.MemberJoined}}
+
+	for _, change := range changes {
+		if change.teamID == nil {
+			continue
+		}
+		var team models.Team
+		err := tx.Select("name").Where("id = ?", *change.teamID).Take(&team).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return databaseError(err, "Failed to record assignment history")
+		}
+		err = tx.Create(&models.AssignmentChange{
+			MemberID:   member.ID,
+			MemberName: member.Name,
+			TeamID:     *change.teamID,
+			TeamName:   team.Name,
+			Action:     change.action,
+		}).Error
+		if err != nil {
+			return databaseError(err, "Failed to record assignment history")
+		}
+	}
+	return nil
+}
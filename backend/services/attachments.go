@@ -0,0 +1,166 @@
+package services
+
+import (
+	"coaching-backend/attachments"
+	"coaching-backend/blob"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Attachments are reached through their feedback: every method looks the
+// feedback up first, so attachments are visible exactly when it is.
+
+// AddAttachment checks the file read from r against attachments.Default
+// and attaches it to the feedback.
+func (s *Service) AddAttachment(ctx context.Context, feedbackID uint32, filename string, r io.Reader) (*models.Attachment, error) {
+	if _, err := s.GetFeedback(ctx, feedbackID); err != nil {
+		return nil, err
+	}
+
+	policy := attachments.Default
+	data, err := io.ReadAll(io.LimitReader(r, policy.MaxFileSize+1))
+	if err != nil {
+		return nil, &Error{Code: problem.CodeInternal, Message: "Failed to read upload", Err: err}
+	}
+	filename = attachments.Filename(filename)
+	contentType, err := policy.Check(ctx, filename, data)
+	if err != nil {
+		return nil, attachmentError(err, policy)
+	}
+
+	attachment := models.Attachment{
+		FeedbackID:  feedbackID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Key:         attachmentKey(feedbackID),
+	}
+	// Checked before and after storing: first to skip a pointless upload,
+	// then to account for concurrent uploads. The second check locks the
+	// feedback row, so that concurrent uploads to the same feedback sum
+	// their sizes one after the other.
+	if err := checkQuota(s.with(ctx), feedbackID, attachment.Size, policy); err != nil {
+		return nil, err
+	}
+	if err := s.putBlob(ctx, attachment.Key, data, contentType, "Failed to store attachment"); err != nil {
+		return nil, err
+	}
+
+	err = s.transaction(ctx, func(tx *gorm.DB) error {
+		var feedback models.Feedback
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&feedback, feedbackID).Error; err != nil {
+			return lookupError(err, "Feedback not found")
+		}
+		if err := checkQuota(tx, feedbackID, attachment.Size, policy); err != nil {
+			return err
+		}
+		if err := tx.Create(&attachment).Error; err != nil {
+			return databaseError(err, "Failed to save attachment")
+		}
+		return nil
+	})
+	if err != nil {
+		s.deleteBlob(ctx, attachment.Key)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (s *Service) ListAttachments(ctx context.Context, feedbackID uint32) ([]models.Attachment, error) {
+	feedback, err := s.GetFeedback(ctx, feedbackID)
+	if err != nil {
+		return nil, err
+	}
+	if feedback.Attachments == nil {
+		return []models.Attachment{}, nil
+	}
+	return feedback.Attachments, nil
+}
+
+func (s *Service) GetAttachment(ctx context.Context, feedbackID, id uint32) (*models.Attachment, error) {
+	if _, err := s.GetFeedback(ctx, feedbackID); err != nil {
+		return nil, err
+	}
+
+	var attachment models.Attachment
+	if err := s.with(ctx).Where("feedback_id = ?", feedbackID).First(&attachment, id).Error; err != nil {
+		return nil, lookupError(err, "Attachment not found")
+	}
+	return &attachment, nil
+}
+
+// OpenAttachment opens the attachment's content. The caller closes the
+// reader.
+func (s *Service) OpenAttachment(ctx context.Context, attachment *models.Attachment) (io.ReadCloser, error) {
+	body, _, err := s.blobs.Get(ctx, attachment.Key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, notFound("Attachment file is missing")
+	}
+	if err != nil {
+		return nil, &Error{Code: problem.CodeInternal, Message: "Failed to load attachment", Err: err}
+	}
+	return body, nil
+}
+
+func (s *Service) DeleteAttachment(ctx context.Context, feedbackID, id uint32) error {
+	attachment, err := s.GetAttachment(ctx, feedbackID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.with(ctx).Delete(attachment).Error; err != nil {
+		return databaseError(err, "Failed to delete attachment")
+	}
+	s.deleteBlob(ctx, attachment.Key)
+	return nil
+}
+
+// checkQuota fails if adding size bytes would take the feedback's
+// attachments over the policy's total.
+func checkQuota(db *gorm.DB, feedbackID uint32, size int64, policy attachments.Policy) error {
+	var used int64
+	err := db.Model(&models.Attachment{}).Where("feedback_id = ?", feedbackID).
+		Select("COALESCE(SUM(size), 0)").Scan(&used).Error
+	if err != nil {
+		return databaseError(err, "Failed to check attachment quota")
+	}
+	if used+size > policy.MaxTotalSize {
+		return attachmentError(attachments.ErrQuotaExceeded, policy)
+	}
+	return nil
+}
+
+// attachmentKey is a fresh blob key, so attachments with the same content
+// can be deleted independently.
+func attachmentKey(feedbackID uint32) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return fmt.Sprintf("attachments/%d/%s", feedbackID, hex.EncodeToString(b))
+}
+
+func attachmentError(err error, policy attachments.Policy) error {
+	switch {
+	case errors.Is(err, attachments.ErrTooLarge):
+		return &Error{Code: problem.CodeTooLarge, Message: "Attachment is larger than " + megabytes(policy.MaxFileSize)}
+	case errors.Is(err, attachments.ErrQuotaExceeded):
+		return &Error{Code: problem.CodeTooLarge, Message: "Attachments of one feedback may total at most " + megabytes(policy.MaxTotalSize)}
+	case errors.Is(err, attachments.ErrType):
+		return &Error{Code: problem.CodeUnsupported, Message: "Attachment type is not allowed"}
+	case errors.Is(err, attachments.ErrInfected):
+		return invalid(problem.FieldError{Field: "file", Rule: "scan", Message: "was rejected by the virus scanner"})
+	default:
+		return &Error{Code: problem.CodeInternal, Message: "Failed to scan attachment", Err: err}
+	}
+}
+
+func megabytes(n int64) string {
+	return fmt.Sprintf("%d MB", n>>20)
+}
@@ -0,0 +1,609 @@
+package services
+
+import (
+	"archive/zip"
+	"coaching-backend/blob"
+	"coaching-backend/database"
+	"coaching-backend/images"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/targets"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// BackupFormat identifies the archives Backup writes, and BackupVersion
+// the version of their layout: manifest.json, one tables/NAME.ndjson file
+// per table and the uploaded files under blobs/.
+const (
+	BackupFormat  = "coaching-backup"
+	BackupVersion = 1
+)
+
+// BackupManifest is the manifest.json of a backup archive.
+type BackupManifest struct {
+	Format        string        `json:"format"`
+	Version       int           `json:"version"`
+	SchemaVersion int           `json:"schema_version"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Tables        []BackupTable `json:"tables"`
+	Blobs         []BackupBlob  `json:"blobs"`
+	// MissingBlobs are files records refer to that were not in the blob
+	// store when the backup was made.
+	MissingBlobs []string `json:"missing_blobs,omitempty"`
+}
+
+type BackupTable struct {
+	Name   string `json:"name"`
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+type BackupBlob struct {
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// RestoreReport says what Restore loaded. Remapped is set when the
+// database was not empty, so every record got a new ID. Dropped counts
+// records left out because what they belong to was not in the backup.
+type RestoreReport struct {
+	Remapped         bool
+	Tables           []BackupTable
+	Blobs            int
+	Dropped          int
+	DisabledWebhooks int
+}
+
+// backupTable is a table in backups and the columns that hold IDs of
+// other records, in an order where referenced tables come first.
+type backupTable struct {
+	model interface{}
+	refs  []backupRef
+}
+
+// backupRef is a column holding the ID of a to record or, when
+// typeColumn is set, of the feedback target or image owner type named by
+// typeColumn. Records whose owner reference cannot be resolved are
+// dropped; other unresolved references are cleared.
+type backupRef struct {
+	column     string
+	to         interface{}
+	typeColumn string
+	owner      bool
+}
+
+// backupTables lists every table but the outbox and its cursors, whose
+// messages are only kept until they are relayed, and sessions and API
+// keys, which a restored copy should not honor.
+var backupTables = []backupTable{
+	{model: &models.Team{}},
+	{model: &models.TeamMember{}, refs: []backupRef{{column: "team_id", to: &models.Team{}}}},
+	{model: &models.Project{}},
+	{model: &models.Release{}},
+	{model: &models.Meeting{}},
+	{model: &models.Feedback{}, refs: []backupRef{{column: "target_id", typeColumn: "target_type"}}},
+	{model: &models.Attachment{}, refs: []backupRef{{column: "feedback_id", to: &models.Feedback{}, owner: true}}},
+	{model: &models.Image{}, refs: []backupRef{{column: "owner_id", typeColumn: "owner_type", owner: true}}},
+	{model: &models.AssignmentChange{}, refs: []backupRef{{column: "member_id", to: &models.TeamMember{}}, {column: "team_id", to: &models.Team{}}}},
+	{model: &models.NotificationPreferences{}, refs: []backupRef{{column: "member_id", to: &models.TeamMember{}, owner: true}}},
+	{model: &models.Notification{}, refs: []backupRef{{column: "member_id", to: &models.TeamMember{}, owner: true}, {column: "feedback_id", to: &models.Feedback{}}}},
+	{model: &models.DigestDelivery{}, refs: []backupRef{{column: "member_id", to: &models.TeamMember{}, owner: true}}},
+	{model: &models.Webhook{}},
+	{model: &models.WebhookDelivery{}, refs: []backupRef{{column: "webhook_id", to: &models.Webhook{}, owner: true}, {column: "redelivery_of", to: &models.WebhookDelivery{}}}},
+}
+
+// backupTargets are the records of the target types, which image owner
+// types are a subset of, whose IDs are remapped: those stored in a table.
+// Other types, like the organization, keep their IDs.
+var backupTargets = targets.Models()
+
+func invalidBackup(format string, args ...interface{}) *Error {
+	return &Error{Code: problem.CodeValidation, Message: "Invalid backup: " + fmt.Sprintf(format, args...)}
+}
+
+func (s *Service) tableSchema(table backupTable) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: s.db}
+	if err := stmt.Parse(table.model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// columns are the fields of a table's schema stored in the database.
+func columns(sch *schema.Schema) []*schema.Field {
+	var fields []*schema.Field
+	for _, field := range sch.Fields {
+		if field.DBName != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Backup writes every table and the uploaded files they refer to to w as
+// a zip archive, read in one transaction so the tables are consistent.
+// Rows are stored by column name, including those the API never returns
+// such as webhook secrets, so the archive must be kept as safe as the
+// database.
+func (s *Service) Backup(ctx context.Context, w io.Writer) (*BackupManifest, error) {
+	archive := zip.NewWriter(w)
+	manifest := &BackupManifest{
+		Format:        BackupFormat,
+		Version:       BackupVersion,
+		SchemaVersion: database.SchemaVersion,
+		CreatedAt:     time.Now().UTC(),
+		Tables:        []BackupTable{},
+		Blobs:         []BackupBlob{},
+	}
+
+	err := s.with(ctx).Transaction(func(tx *gorm.DB) error {
+		var blobs []BackupBlob
+		for _, table := range backupTables {
+			written, found, err := s.backupTable(ctx, tx, archive, table)
+			if err != nil {
+				return err
+			}
+			manifest.Tables = append(manifest.Tables, *written)
+			blobs = append(blobs, found...)
+		}
+		for _, b := range blobs {
+			copied, err := s.backupBlob(ctx, archive, b)
+			if errors.Is(err, blob.ErrNotFound) {
+				manifest.MissingBlobs = append(manifest.MissingBlobs, b.Key)
+				continue
+			}
+			if err != nil {
+				return &Error{Code: problem.CodeInternal, Message: "Failed to back up " + b.Key, Err: err}
+			}
+			manifest.Blobs = append(manifest.Blobs, *copied)
+		}
+		return nil
+	})
+	if err != nil {
+		var serviceErr *Error
+		if errors.As(err, &serviceErr) {
+			return nil, err
+		}
+		return nil, &Error{Code: problem.CodeInternal, Message: "Failed to write backup", Err: err}
+	}
+
+	f, err := archive.Create("manifest.json")
+	if err == nil {
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(manifest)
+	}
+	if err == nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		return nil, &Error{Code: problem.CodeInternal, Message: "Failed to write backup", Err: err}
+	}
+	return manifest, nil
+}
+
+// backupTable writes a table as one JSON object per row and returns the
+// blobs its rows refer to.
+func (s *Service) backupTable(ctx context.Context, tx *gorm.DB, archive *zip.Writer, table backupTable) (*BackupTable, []BackupBlob, error) {
+	sch, err := s.tableSchema(table)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := archive.Create("tables/" + sch.Table + ".ndjson")
+	if err != nil {
+		return nil, nil, err
+	}
+	sum := sha256.New()
+	encoder := json.NewEncoder(io.MultiWriter(f, sum))
+	written := &BackupTable{Name: sch.Table}
+	var blobs []BackupBlob
+
+	fields := columns(sch)
+	batch := reflect.New(reflect.SliceOf(sch.ModelType))
+	var encodeErr error
+	err = tx.Model(table.model).FindInBatches(batch.Interface(), exportBatchSize, func(*gorm.DB, int) error {
+		rows := batch.Elem()
+		for i := 0; i < rows.Len(); i++ {
+			row := rows.Index(i)
+			values := make(map[string]interface{}, len(fields))
+			for _, field := range fields {
+				values[field.DBName] = field.ReflectValueOf(ctx, row).Interface()
+			}
+			if encodeErr = encoder.Encode(values); encodeErr != nil {
+				return encodeErr
+			}
+			written.Rows++
+			blobs = append(blobs, rowBlobs(row.Addr().Interface())...)
+		}
+		return nil
+	}).Error
+	if encodeErr != nil {
+		return nil, nil, encodeErr
+	}
+	if err != nil {
+		return nil, nil, databaseError(err, "Failed to read "+sch.Table)
+	}
+	written.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	return written, blobs, nil
+}
+
+// rowBlobs are the keys and content types of the files a record refers
+// to.
+func rowBlobs(record interface{}) []BackupBlob {
+	switch r := record.(type) {
+	case *models.Attachment:
+		return []BackupBlob{{Key: r.Key, ContentType: r.ContentType}}
+	case *models.Image:
+		blobs := []BackupBlob{{Key: imageKey(r, 0), ContentType: r.ContentType}}
+		for _, size := range images.Sizes {
+			blobs = append(blobs, BackupBlob{Key: imageKey(r, size), ContentType: images.ThumbnailType(r.ContentType)})
+		}
+		return blobs
+	}
+	return nil
+}
+
+func (s *Service) backupBlob(ctx context.Context, archive *zip.Writer, b BackupBlob) (*BackupBlob, error) {
+	body, _, err := s.blobs.Get(ctx, b.Key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	f, err := archive.Create("blobs/" + b.Key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.New()
+	if b.Size, err = io.Copy(io.MultiWriter(f, sum), body); err != nil {
+		return nil, err
+	}
+	b.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	return &b, nil
+}
+
+// openBackup checks that r is a backup archive this version can restore
+// and that every file in it matches its checksum, and returns its files
+// by name.
+func (s *Service) openBackup(r io.ReaderAt, size int64) (map[string]*zip.File, *BackupManifest, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, invalidBackup("not a zip archive")
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	f, ok := files["manifest.json"]
+	if !ok {
+		return nil, nil, invalidBackup("manifest.json is missing")
+	}
+	var manifest BackupManifest
+	if err := readJSON(f, &manifest); err != nil || manifest.Format != BackupFormat {
+		return nil, nil, invalidBackup("manifest.json is not a %s manifest", BackupFormat)
+	}
+	if manifest.Version != BackupVersion {
+		return nil, nil, invalidBackup("archive version %d is not supported", manifest.Version)
+	}
+	if manifest.SchemaVersion != database.SchemaVersion {
+		return nil, nil, invalidBackup("schema version %d does not match this version's %d", manifest.SchemaVersion, database.SchemaVersion)
+	}
+
+	for _, table := range manifest.Tables {
+		if s.findTable(table.Name) == nil {
+			return nil, nil, invalidBackup("unknown table %s", table.Name)
+		}
+		if err := verify(files, "tables/"+table.Name+".ndjson", table.SHA256); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, b := range manifest.Blobs {
+		if !blob.ValidKey(b.Key) {
+			return nil, nil, invalidBackup("invalid blob key %q", b.Key)
+		}
+		if err := verify(files, "blobs/"+b.Key, b.SHA256); err != nil {
+			return nil, nil, err
+		}
+	}
+	return files, &manifest, nil
+}
+
+func (s *Service) findTable(name string) *backupTable {
+	for i, table := range backupTables {
+		if sch, err := s.tableSchema(table); err == nil && sch.Table == name {
+			return &backupTables[i]
+		}
+	}
+	return nil
+}
+
+func readJSON(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
+
+func verify(files map[string]*zip.File, name, checksum string) error {
+	f, ok := files[name]
+	if !ok {
+		return invalidBackup("%s is missing", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return invalidBackup("%s cannot be read", name)
+	}
+	defer rc.Close()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, rc); err != nil {
+		return invalidBackup("%s cannot be read", name)
+	}
+	if hex.EncodeToString(sum.Sum(nil)) != checksum {
+		return invalidBackup("%s does not match its checksum", name)
+	}
+	return nil
+}
+
+// Restore loads a backup made by Backup in one transaction, after
+// checking its versions and checksums. Into an empty database records
+// keep their IDs; otherwise every record gets a new ID and the references
+// to it are rewritten. Restored webhooks are disabled and pending
+// notifications skipped, so a copy of the data neither calls the
+// original's subscribers nor emails its members.
+func (s *Service) Restore(ctx context.Context, r io.ReaderAt, size int64) (*RestoreReport, error) {
+	files, manifest, err := s.openBackup(r, size)
+	if err != nil {
+		return nil, err
+	}
+	inArchive := make(map[string]bool, len(manifest.Tables))
+	for _, table := range manifest.Tables {
+		inArchive[table.Name] = true
+	}
+
+	report := &RestoreReport{}
+	err = s.transaction(ctx, func(tx *gorm.DB) error {
+		empty, err := s.isEmpty(tx)
+		if err != nil {
+			return err
+		}
+		restore := &restorer{tx: tx, remap: !empty, ids: map[reflect.Type]map[uint32]uint32{}, blobKeys: map[string]string{}, report: report}
+		report.Remapped = restore.remap
+		for _, table := range backupTables {
+			sch, err := s.tableSchema(table)
+			if err != nil {
+				return err
+			}
+			restore.ids[sch.ModelType] = map[uint32]uint32{}
+			if !inArchive[sch.Table] {
+				continue
+			}
+			if err := restore.table(ctx, files["tables/"+sch.Table+".ndjson"], sch, table.refs); err != nil {
+				return err
+			}
+		}
+		return s.restoreBlobs(ctx, files, manifest.Blobs, restore.blobKeys, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) isEmpty(tx *gorm.DB) (bool, error) {
+	for _, table := range backupTables {
+		var count int64
+		if err := tx.Model(table.model).Limit(1).Count(&count).Error; err != nil {
+			return false, databaseError(err, "Failed to count records")
+		}
+		if count > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// restoreBlobs copies the files restored records refer to into the blob
+// store under their new keys. Blobs copied before a failure are deleted,
+// as the transaction is rolled back.
+func (s *Service) restoreBlobs(ctx context.Context, files map[string]*zip.File, blobs []BackupBlob, keys map[string]string, report *RestoreReport) error {
+	var copied []string
+	for _, b := range blobs {
+		key, ok := keys[b.Key]
+		if !ok {
+			continue
+		}
+		err := s.restoreBlob(ctx, files["blobs/"+b.Key], key, b)
+		if err != nil {
+			for _, key := range copied {
+				s.deleteBlob(ctx, key)
+			}
+			return &Error{Code: problem.CodeInternal, Message: "Failed to restore " + b.Key, Err: err}
+		}
+		copied = append(copied, key)
+	}
+	report.Blobs = len(copied)
+	return nil
+}
+
+func (s *Service) restoreBlob(ctx context.Context, f *zip.File, key string, b BackupBlob) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return s.blobs.Put(ctx, key, rc, b.Size, b.ContentType)
+}
+
+// restorer loads the tables of a backup in order. ids maps the IDs in the
+// backup to the restored ones per model type, and blobKeys the keys of the
+// files restored records refer to.
+type restorer struct {
+	tx       *gorm.DB
+	remap    bool
+	ids      map[reflect.Type]map[uint32]uint32
+	blobKeys map[string]string
+	report   *RestoreReport
+}
+
+func (r *restorer) table(ctx context.Context, f *zip.File, sch *schema.Schema, refs []backupRef) error {
+	rc, err := f.Open()
+	if err != nil {
+		return invalidBackup("%s cannot be read", f.Name)
+	}
+	defer rc.Close()
+
+	byName := make(map[string]*schema.Field)
+	for _, field := range columns(sch) {
+		byName[field.DBName] = field
+	}
+	primary := sch.PrioritizedPrimaryField
+	restored := BackupTable{Name: sch.Table}
+	decoder := json.NewDecoder(rc)
+	for line := 1; ; line++ {
+		var row map[string]json.RawMessage
+		if err := decoder.Decode(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return invalidBackup("%s line %d is not a JSON object", f.Name, line)
+		}
+
+		record := reflect.New(sch.ModelType)
+		value := record.Elem()
+		for name, raw := range row {
+			field, ok := byName[name]
+			if !ok {
+				return invalidBackup("%s line %d has unknown column %s", f.Name, line, name)
+			}
+			if err := json.Unmarshal(raw, field.ReflectValueOf(ctx, value).Addr().Interface()); err != nil {
+				return invalidBackup("%s line %d has an invalid %s", f.Name, line, name)
+			}
+		}
+
+		blobs := rowBlobs(record.Interface())
+		oldID, _ := referencedID(primary.ReflectValueOf(ctx, value))
+		if r.remap {
+			if !r.rewriteRefs(ctx, value, byName, refs) {
+				r.report.Dropped++
+				continue
+			}
+			if primary.AutoIncrement {
+				primary.ReflectValueOf(ctx, value).SetZero()
+			}
+		}
+		r.report.DisabledWebhooks += quiet(record.Interface())
+
+		if err := r.tx.Omit(clause.Associations).Create(record.Interface()).Error; err != nil {
+			return databaseError(err, "Failed to restore "+sch.Table)
+		}
+		newID, _ := referencedID(primary.ReflectValueOf(ctx, value))
+		r.ids[sch.ModelType][oldID] = newID
+		for i, b := range rowBlobs(record.Interface()) {
+			r.blobKeys[blobs[i].Key] = b.Key
+		}
+		if column, url, ok := rebaseImageURL(record.Interface(), oldID); ok {
+			if err := r.tx.Model(record.Interface()).UpdateColumn(column, url).Error; err != nil {
+				return databaseError(err, "Failed to restore "+sch.Table)
+			}
+		}
+		restored.Rows++
+	}
+	r.report.Tables = append(r.report.Tables, restored)
+	return nil
+}
+
+// rewriteRefs points the references of a record at the restored IDs. It
+// returns false if the record must be dropped.
+func (r *restorer) rewriteRefs(ctx context.Context, value reflect.Value, byName map[string]*schema.Field, refs []backupRef) bool {
+	for _, ref := range refs {
+		field := byName[ref.column].ReflectValueOf(ctx, value)
+		id, ok := referencedID(field)
+		if !ok {
+			continue
+		}
+		to := ref.to
+		if ref.typeColumn != "" {
+			if to, ok = backupTargets[byName[ref.typeColumn].ReflectValueOf(ctx, value).String()]; !ok {
+				continue
+			}
+		}
+		newID, ok := r.ids[reflect.TypeOf(to).Elem()][id]
+		switch {
+		case ok && field.Kind() == reflect.Pointer:
+			field.Set(reflect.ValueOf(&newID))
+		case ok:
+			field.SetUint(uint64(newID))
+		case ref.owner:
+			return false
+		default:
+			field.SetZero()
+		}
+	}
+	return true
+}
+
+// referencedID reads an ID column, which is either a number or a nullable
+// one. It returns false for zero and NULL.
+func referencedID(v reflect.Value) (uint32, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	id := uint32(v.Uint())
+	return id, id != 0
+}
+
+// quiet disables a restored webhook and skips a pending notification. It
+// returns 1 if it disabled a webhook.
+func quiet(record interface{}) int {
+	switch r := record.(type) {
+	case *models.Webhook:
+		if r.Active {
+			now := time.Now()
+			r.Active, r.DisabledAt = false, &now
+			return 1
+		}
+	case *models.Notification:
+		if r.Status == models.NotificationPending {
+			r.Status = models.NotificationSkipped
+		}
+	}
+	return 0
+}
+
+// rebaseImageURL moves the avatar or logo URL of a restored member or
+// team that was given a new ID to that ID.
+func rebaseImageURL(record interface{}, oldID uint32) (column, url string, ok bool) {
+	var ownerType string
+	var newID uint32
+	switch r := record.(type) {
+	case *models.TeamMember:
+		ownerType, newID, url = models.ImageMember, r.ID, r.Picture
+	case *models.Team:
+		ownerType, newID, url = models.ImageTeam, r.ID, r.Logo
+	default:
+		return "", "", false
+	}
+	version, found := strings.CutPrefix(url, imagePath(ownerType, oldID)+"?v=")
+	if !found || newID == oldID {
+		return "", "", false
+	}
+	return imageOwners[ownerType].column, imagePath(ownerType, newID) + "?v=" + version, true
+}
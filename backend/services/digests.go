@@ -0,0 +1,45 @@
+package services
+
+import (
+	"coaching-backend/digest"
+	"coaching-backend/problem"
+	"context"
+	"time"
+)
+
+// maxDigestPeriod caps the period a digest may cover.
+const maxDigestPeriod = 366 * 24 * time.Hour
+
+// MemberDigest compiles the member's digest for [from, to).
+func (s *Service) MemberDigest(ctx context.Context, id uint32, from, to time.Time) (*digest.Digest, error) {
+	if err := validatePeriod(from, to); err != nil {
+		return nil, err
+	}
+	d, err := digest.ForMember(ctx, s.db, id, from, to)
+	if err != nil {
+		return nil, lookupError(err, "Team member not found")
+	}
+	return d, nil
+}
+
+// TeamDigest compiles the team's digest for [from, to).
+func (s *Service) TeamDigest(ctx context.Context, id uint32, from, to time.Time) (*digest.Digest, error) {
+	if err := validatePeriod(from, to); err != nil {
+		return nil, err
+	}
+	d, err := digest.ForTeam(ctx, s.db, id, from, to)
+	if err != nil {
+		return nil, lookupError(err, "Team not found")
+	}
+	return d, nil
+}
+
+func validatePeriod(from, to time.Time) error {
+	switch {
+	case !from.Before(to):
+		return invalid(problem.FieldError{Field: "from", Rule: "ltfield", Message: "must be before to"})
+	case to.Sub(from) > maxDigestPeriod:
+		return invalid(problem.FieldError{Field: "from", Rule: "max", Message: "period must not exceed 366 days"})
+	}
+	return nil
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Directory sync actions say what a sync does to a member.
+const (
+	DirectoryCreate     = "create"
+	DirectoryUpdate     = "update"
+	DirectoryDeactivate = "deactivate"
+)
+
+// DirectoryPerson is a person as read from the directory. Team names the
+// team they belong to, or is empty for none.
+type DirectoryPerson struct {
+	DN    string
+	Email string
+	Name  string
+	Team  string
+}
+
+type DirectorySyncOptions struct {
+	// DryRun reports what the sync would do without changing anything.
+	DryRun bool
+	// SyncTeams lets the directory decide members' teams: a person
+	// without a team is removed from theirs.
+	SyncTeams bool
+	// CreateTeams creates teams that do not exist yet. Otherwise people
+	// in them keep their current team and a warning is reported.
+	CreateTeams bool
+}
+
+// DirectorySyncReport describes a sync: counts, and the changes made to
+// each member as a diff.
+type DirectorySyncReport struct {
+	DryRun       bool              `json:"dry_run"`
+	Created      int               `json:"created"`
+	Updated      int               `json:"updated"`
+	Deactivated  int               `json:"deactivated"`
+	Unchanged    int               `json:"unchanged"`
+	TeamsCreated []string          `json:"teams_created"`
+	Changes      []DirectoryChange `json:"changes"`
+	Skipped      []DirectorySkip   `json:"skipped"`
+	Warnings     []string          `json:"warnings"`
+}
+
+type DirectoryChange struct {
+	Action string `json:"action"`
+	Email  string `json:"email"`
+	// MemberID is zero for members a dry run would create.
+	MemberID uint32        `json:"member_id,omitempty"`
+	Fields   []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a changed attribute. Teams are given by name, and no
+// team as an empty string.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// DirectorySkip is a directory entry that could not be synced.
+type DirectorySkip struct {
+	DN     string `json:"dn"`
+	Reason string `json:"reason"`
+}
+
+// SyncDirectory makes the members synced from the directory match people:
+// members are matched by email regardless of case, or else by the entry
+// they were synced from, and are created or updated to match. Members
+// synced earlier whose entry is gone have left and are deactivated: they
+// keep their records but drop out of lists and cannot sign in, and come
+// back if their entry does. Members created here are never touched unless
+// the directory lists their email, in which case they become synced. Entries
+// that are not valid members are skipped and reported. The sync is
+// applied as a whole or not at all.
+func (s *Service) SyncDirectory(ctx context.Context, people []DirectoryPerson, opts DirectorySyncOptions) (*DirectorySyncReport, error) {
+	if opts.DryRun {
+		plan, err := planDirectorySync(s.with(ctx), people, opts)
+		if err != nil {
+			return nil, err
+		}
+		return plan.report(true), nil
+	}
+
+	var report *DirectorySyncReport
+	err := s.transaction(ctx, func(tx *gorm.DB) error {
+		plan, err := planDirectorySync(tx, people, opts)
+		if err != nil {
+			return err
+		}
+		if err := plan.apply(tx); err != nil {
+			return err
+		}
+		report = plan.report(false)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// directoryPlan is what a sync does, worked out before anything changes.
+type directoryPlan struct {
+	changes   []plannedDirectoryChange
+	unchanged int
+	// saves are unchanged members whose DN is recorded or updated.
+	saves     []models.TeamMember
+	newTeams  map[string]*models.Team
+	teamOrder []string
+	skipped   []DirectorySkip
+	warnings  []string
+}
+
+type plannedDirectoryChange struct {
+	action string
+	member models.TeamMember
+	fields []FieldChange
+	// team is the member's new team, when it changes to one; its ID is
+	// zero until a new team is created.
+	team           *models.Team
+	teamChanged    bool
+	previousTeamID *uint32
+}
+
+func planDirectorySync(db *gorm.DB, people []DirectoryPerson, opts DirectorySyncOptions) (*directoryPlan, error) {
+	var existing []models.TeamMember
+	if err := db.Find(&existing).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch team members")
+	}
+	byEmail := make(map[string]*models.TeamMember, len(existing))
+	byDN := make(map[string]*models.TeamMember)
+	synced := 0
+	for i := range existing {
+		m := &existing[i]
+		byEmail[strings.ToLower(m.Email)] = m
+		if m.DirectoryDN != "" {
+			byDN[strings.ToLower(m.DirectoryDN)] = m
+			synced++
+		}
+	}
+	// An empty result is far more likely a misconfigured filter than
+	// everyone leaving.
+	if len(people) == 0 && synced > 0 {
+		return nil, invalid(problem.FieldError{Field: "directory", Rule: "required",
+			Message: fmt.Sprintf("returned nobody; refusing to deactivate all %d synced members", synced)})
+	}
+
+	var teams []models.Team
+	if err := db.Find(&teams).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch teams")
+	}
+	teamsByName := make(map[string]*models.Team, len(teams))
+	teamNames := make(map[uint32]string, len(teams))
+	for i := range teams {
+		teamsByName[strings.ToLower(teams[i].Name)] = &teams[i]
+		teamNames[teams[i].ID] = teams[i].Name
+	}
+	teamName := func(id *uint32) string {
+		if id == nil {
+			return ""
+		}
+		return teamNames[*id]
+	}
+
+	people = append([]DirectoryPerson(nil), people...)
+	sort.SliceStable(people, func(i, j int) bool {
+		return strings.ToLower(people[i].Email) < strings.ToLower(people[j].Email)
+	})
+
+	plan := &directoryPlan{newTeams: make(map[string]*models.Team)}
+	seen := make(map[string]string)
+	matched := make(map[uint32]bool)
+	for _, person := range people {
+		person.Email = strings.TrimSpace(person.Email)
+		person.Name = strings.TrimSpace(person.Name)
+		person.Team = strings.TrimSpace(person.Team)
+		if person.Name == "" {
+			person.Name, _, _ = strings.Cut(person.Email, "@")
+		}
+		skip := func(reason string) {
+			plan.skipped = append(plan.skipped, DirectorySkip{DN: person.DN, Reason: reason})
+		}
+
+		candidate := models.TeamMember{Name: person.Name, Email: person.Email, DirectoryDN: person.DN}
+		if err := validate(&candidate); err != nil {
+			var serviceErr *Error
+			if !errors.As(err, &serviceErr) || serviceErr.Code != problem.CodeValidation {
+				return nil, err
+			}
+			f := serviceErr.Fields[0]
+			skip(f.Field + " " + f.Message)
+			continue
+		}
+		key := strings.ToLower(person.Email)
+		if first, ok := seen[key]; ok {
+			skip("has the same email as " + first)
+			continue
+		}
+		seen[key] = person.DN
+
+		current := byEmail[key]
+		if current == nil {
+			current = byDN[strings.ToLower(person.DN)]
+		}
+		if current != nil && matched[current.ID] {
+			skip("matches the same member as another entry")
+			continue
+		}
+
+		var team *models.Team
+		keepTeam := !opts.SyncTeams
+		if opts.SyncTeams && person.Team != "" {
+			teamKey := strings.ToLower(person.Team)
+			switch {
+			case teamsByName[teamKey] != nil:
+				team = teamsByName[teamKey]
+			case plan.newTeams[teamKey] != nil:
+				team = plan.newTeams[teamKey]
+			case opts.CreateTeams:
+				team = &models.Team{Name: person.Team}
+				plan.newTeams[teamKey] = team
+				plan.teamOrder = append(plan.teamOrder, teamKey)
+			default:
+				plan.warnings = append(plan.warnings, fmt.Sprintf("%s is in team %q, which does not exist", person.Email, person.Team))
+				keepTeam = true
+			}
+		}
+
+		if current == nil {
+			change := plannedDirectoryChange{action: DirectoryCreate, member: candidate, team: team, teamChanged: team != nil}
+			change.fields = append(change.fields, FieldChange{Field: "name", To: person.Name})
+			if team != nil {
+				change.fields = append(change.fields, FieldChange{Field: "team", To: team.Name})
+			}
+			plan.changes = append(plan.changes, change)
+			continue
+		}
+
+		matched[current.ID] = true
+		change := plannedDirectoryChange{action: DirectoryUpdate, member: *current, team: team, previousTeamID: current.TeamID}
+		if current.Email != person.Email {
+			change.fields = append(change.fields, FieldChange{Field: "email", From: current.Email, To: person.Email})
+			change.member.Email = person.Email
+		}
+		if current.Name != person.Name {
+			change.fields = append(change.fields, FieldChange{Field: "name", From: current.Name, To: person.Name})
+			change.member.Name = person.Name
+		}
+		if !keepTeam && !inTeam(current.TeamID, team) {
+			change.teamChanged = true
+			to := ""
+			if team != nil {
+				to = team.Name
+			}
+			change.fields = append(change.fields, FieldChange{Field: "team", From: teamName(current.TeamID), To: to})
+		}
+		if !current.Active() {
+			change.fields = append(change.fields, FieldChange{Field: "active", From: "false", To: "true"})
+			change.member.DeactivatedAt = nil
+		}
+		change.member.DirectoryDN = person.DN
+		switch {
+		case len(change.fields) > 0:
+			plan.changes = append(plan.changes, change)
+		case current.DirectoryDN != person.DN:
+			plan.saves = append(plan.saves, change.member)
+			plan.unchanged++
+		default:
+			plan.unchanged++
+		}
+	}
+
+	for i := range existing {
+		m := &existing[i]
+		if m.DirectoryDN != "" && !matched[m.ID] && m.Active() {
+			plan.changes = append(plan.changes, plannedDirectoryChange{action: DirectoryDeactivate, member: *m})
+		}
+	}
+	return plan, nil
+}
+
+// inTeam reports whether a member's team is team, where nil is no team and
+// a team not created yet is nobody's.
+func inTeam(teamID *uint32, team *models.Team) bool {
+	if team == nil || teamID == nil {
+		return team == nil && teamID == nil
+	}
+	return team.ID != 0 && team.ID == *teamID
+}
+
+// apply creates the planned teams and members, and updates and
+// deactivates the others.
+func (p *directoryPlan) apply(tx *gorm.DB) error {
+	for _, key := range p.teamOrder {
+		if err := tx.Create(p.newTeams[key]).Error; err != nil {
+			return databaseError(err, "Failed to create team")
+		}
+	}
+	for i := range p.saves {
+		if err := tx.Save(&p.saves[i]).Error; err != nil {
+			return databaseError(err, "Failed to update team member")
+		}
+	}
+
+	for i := range p.changes {
+		change := &p.changes[i]
+		member := &change.member
+		if change.teamChanged {
+			member.TeamID = nil
+			if change.team != nil {
+				teamID := change.team.ID
+				member.TeamID = &teamID
+			}
+		}
+		switch change.action {
+		case DirectoryCreate:
+			if err := tx.Create(member).Error; err != nil {
+				return databaseError(err, "Failed to create team member")
+			}
+			if err := recordTeamChange(tx, member, nil, member.TeamID); err != nil {
+				return err
+			}
+			if err := recordMember(tx, events.MemberCreated, member, member.TeamID); err != nil {
+				return err
+			}
+		case DirectoryUpdate:
+			if err := tx.Save(member).Error; err != nil {
+				return databaseError(err, "Failed to update team member")
+			}
+			if err := recordTeamChange(tx, member, change.previousTeamID, member.TeamID); err != nil {
+				return err
+			}
+		case DirectoryDeactivate:
+			if err := deactivateMember(tx, member); err != nil {
+				return databaseError(err, "Failed to deactivate team member")
+			}
+		}
+	}
+	return nil
+}
+
+func (p *directoryPlan) report(dryRun bool) *DirectorySyncReport {
+	report := &DirectorySyncReport{
+		DryRun:       dryRun,
+		Unchanged:    p.unchanged,
+		TeamsCreated: []string{},
+		Changes:      make([]DirectoryChange, 0, len(p.changes)),
+		Skipped:      p.skipped,
+		Warnings:     p.warnings,
+	}
+	if report.Skipped == nil {
+		report.Skipped = []DirectorySkip{}
+	}
+	if report.Warnings == nil {
+		report.Warnings = []string{}
+	}
+	for _, key := range p.teamOrder {
+		report.TeamsCreated = append(report.TeamsCreated, p.newTeams[key].Name)
+	}
+	for _, change := range p.changes {
+		switch change.action {
+		case DirectoryCreate:
+			report.Created++
+		case DirectoryUpdate:
+			report.Updated++
+		case DirectoryDeactivate:
+			report.Deactivated++
+		}
+		report.Changes = append(report.Changes, DirectoryChange{
+			Action:   change.action,
+			Email:    change.member.Email,
+			MemberID: change.member.ID,
+			Fields:   change.fields,
+		})
+	}
+	return report
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"coaching-backend/problem"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Error is a failure every transport reports the same way. Code is one of
+// the stable problem codes; Err is the underlying cause of internal errors
+// and is meant for logs, not clients.
+type Error struct {
+	Code    string
+	Message string
+	Fields  []problem.FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func notFound(message string) *Error {
+	return &Error{Code: problem.CodeNotFound, Message: message}
+}
+
+func invalid(fields ...problem.FieldError) *Error {
+	return &Error{Code: problem.CodeValidation, Message: "Request failed validation", Fields: fields}
+}
+
+// lookupError reports an error from fetching a single record: not found
+// with the given message for gorm.ErrRecordNotFound, internal otherwise.
+func lookupError(err error, message string) *Error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(message)
+	}
+	return &Error{Code: problem.CodeInternal, Message: "Failed to load record", Err: err}
+}
+
+// DatabaseError reports an error from a query outside the service, such
+// as the GraphQL resolvers' batched loads, the way the service reports
+// its own.
+func DatabaseError(err error, failure string) error {
+	return databaseError(err, failure)
+}
+
+// databaseError reports an error from a write or list query: a conflict
+// for unique constraint violations, not found for gorm.ErrRecordNotFound
+// and internal with the given message for anything else.
+func databaseError(err error, failure string) *Error {
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &Error{Code: problem.CodeConflict, Message: "A record with the same unique value already exists"}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return notFound("Record not found")
+	default:
+		return &Error{Code: problem.CodeInternal, Message: failure, Err: err}
+	}
+}
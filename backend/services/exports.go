@@ -0,0 +1,64 @@
+package services
+
+import (
+	"coaching-backend/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many records exports load at a time.
+const exportBatchSize = 500
+
+// The Each methods call fn for every record, in ID order, loading them in
+// batches so exports never hold a whole table in memory. They stop at the
+// first error fn returns and pass it through unchanged.
+
+// EachMember visits the active members, like ListMembers.
+func (s *Service) EachMember(ctx context.Context, fn func(*models.TeamMember) error) error {
+	return eachInBatches(s.with(ctx).Scopes(activeMembers).Preload("Team"), "Failed to fetch team members", fn)
+}
+
+func (s *Service) EachTeam(ctx context.Context, fn func(*models.Team) error) error {
+	return eachInBatches(s.with(ctx), "Failed to fetch teams", fn)
+}
+
+// EachAssignedMember visits the members who are on a team, like
+// ListAssignedMembers.
+func (s *Service) EachAssignedMember(ctx context.Context, fn func(*models.TeamMember) error) error {
+	query := s.with(ctx).Scopes(activeMembers).Preload("Team").Where("team_id IS NOT NULL")
+	return eachInBatches(query, "Failed to fetch assignments", fn)
+}
+
+// EachFeedback visits the feedback matching filter, like ListFeedback but
+// oldest first.
+func (s *Service) EachFeedback(ctx context.Context, filter FeedbackFilter, fn func(*models.Feedback) error) error {
+	query := s.with(ctx).Preload("Attachments")
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	return eachInBatches(query, "Failed to fetch feedback", fn)
+}
+
+func eachInBatches[T any](query *gorm.DB, failure string, fn func(*T) error) error {
+	var fnErr error
+	var batch []T
+	err := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if fnErr = fn(&batch[i]); fnErr != nil {
+				return fnErr
+			}
+		}
+		return nil
+	}).Error
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return databaseError(err, failure)
+	}
+	return nil
+}
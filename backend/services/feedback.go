@@ -0,0 +1,193 @@
+package services
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/targets"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// FeedbackFilter selects feedback by target. Zero fields match anything.
+type FeedbackFilter struct {
+	TargetType string
+	TargetID   uint32
+}
+
+func (s *Service) CreateFeedback(ctx context.Context, feedback *models.Feedback) error {
+	if err := validate(feedback); err != nil {
+		return err
+	}
+	if err := s.resolveTarget(ctx, feedback); err != nil {
+		return err
+	}
+	// Attachments are uploaded separately, once the feedback exists.
+	feedback.Attachments = nil
+
+	return s.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(feedback).Error; err != nil {
+			return databaseError(err, "Failed to create feedback")
+		}
+		return recordFeedback(tx, events.FeedbackCreated, feedback)
+	})
+}
+
+// ListFeedback returns matching feedback, newest first.
+func (s *Service) ListFeedback(ctx context.Context, filter FeedbackFilter) ([]models.Feedback, error) {
+	query := s.with(ctx).Preload("Attachments").Order("created_at DESC")
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+
+	var feedback []models.Feedback
+	if err := query.Find(&feedback).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch feedback")
+	}
+	return feedback, nil
+}
+
+func (s *Service) GetFeedback(ctx context.Context, id uint32) (*models.Feedback, error) {
+	var feedback models.Feedback
+	if err := s.with(ctx).Preload("Attachments").First(&feedback, id).Error; err != nil {
+		return nil, lookupError(err, "Feedback not found")
+	}
+	return &feedback, nil
+}
+
+// UpdateFeedback loads the feedback, lets apply change it and saves the
+// result. The target is re-resolved only when apply changes it, and
+// attachments cannot be changed. Errors returned by apply are passed
+// through unchanged.
+func (s *Service) UpdateFeedback(ctx context.Context, id uint32, apply func(*models.Feedback) error) (*models.Feedback, error) {
+	var feedback models.Feedback
+	if err := s.with(ctx).Preload("Attachments").First(&feedback, id).Error; err != nil {
+		return nil, lookupError(err, "Feedback not found")
+	}
+
+	targetType, targetID, targetName := feedback.TargetType, feedback.TargetID, feedback.TargetName
+	// Cloned, since decoding a request into feedback reuses the slice.
+	attachments := slices.Clone(feedback.Attachments)
+	if err := apply(&feedback); err != nil {
+		return nil, err
+	}
+	feedback.Attachments = attachments
+	if err := validate(&feedback); err != nil {
+		return nil, err
+	}
+
+	if feedback.TargetType != targetType || feedback.TargetID != targetID {
+		if err := s.resolveTarget(ctx, &feedback); err != nil {
+			return nil, err
+		}
+	} else {
+		feedback.TargetName = targetName
+	}
+
+	err := s.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Attachments").Save(&feedback).Error; err != nil {
+			return databaseError(err, "Failed to update feedback")
+		}
+		return recordFeedback(tx, events.FeedbackUpdated, &feedback)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+// DeleteFeedback deletes the feedback and its attachments. Deleting
+// feedback that does not exist succeeds.
+func (s *Service) DeleteFeedback(ctx context.Context, id uint32) error {
+	var feedback models.Feedback
+	err := s.with(ctx).Preload("Attachments").First(&feedback, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return lookupError(err, "Feedback not found")
+	}
+
+	err = s.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Where("feedback_id = ?", id).Delete(&models.Attachment{}).Error; err != nil {
+			return databaseError(err, "Failed to delete feedback")
+		}
+		if err := tx.Delete(&feedback).Error; err != nil {
+			return databaseError(err, "Failed to delete feedback")
+		}
+		return recordFeedback(tx, events.FeedbackDeleted, &feedback)
+	})
+	if err != nil {
+		return err
+	}
+	for _, attachment := range feedback.Attachments {
+		s.deleteBlob(ctx, attachment.Key)
+	}
+	return nil
+}
+
+// WatchFeedback subscribes to changes of feedback matching filter.
+func (s *Service) WatchFeedback(ctx context.Context, filter FeedbackFilter) *events.Subscription {
+	return s.bus.Subscribe(ctx, events.Filter{
+		TargetType: filter.TargetType,
+		TargetID:   filter.TargetID,
+		Types:      []events.Type{events.FeedbackCreated, events.FeedbackUpdated, events.FeedbackDeleted},
+	}, 0)
+}
+
+func (s *Service) FeedbackTargetTypes() []targets.TypeInfo {
+	return targets.Types()
+}
+
+// resolveTarget validates the feedback target against the target registry
+// and fills in TargetName.
+func (s *Service) resolveTarget(ctx context.Context, feedback *models.Feedback) error {
+	name, err := targets.Resolve(s.with(ctx), feedback.TargetType, feedback.TargetID)
+	switch {
+	case errors.Is(err, targets.ErrUnknownType):
+		types := make([]string, 0)
+		for _, info := range targets.Types() {
+			types = append(types, info.Type)
+		}
+		return invalid(problem.FieldError{
+			Field:   "target_type",
+			Rule:    "target_type",
+			Message: "must be one of: " + strings.Join(types, ", "),
+		})
+	case errors.Is(err, targets.ErrNotFound):
+		return notFound(err.Error())
+	case err != nil:
+		return databaseError(err, "Failed to resolve feedback target")
+	}
+
+	feedback.TargetName = name
+	return nil
+}
+
+func recordFeedback(tx *gorm.DB, t events.Type, feedback *models.Feedback) error {
+	return record(tx, events.Event{
+		Type:       t,
+		TeamID:     feedbackTeam(tx, feedback),
+		TargetType: feedback.TargetType,
+		TargetID:   feedback.TargetID,
+		Data:       *feedback,
+	})
+}
+
+// feedbackTeam returns the team feedback concerns, as its target type
+// tells: the target team, or the team of the target member. Lookup
+// failures only cost the team filter.
+func feedbackTeam(tx *gorm.DB, feedback *models.Feedback) *uint32 {
+	teamID, err := targets.TeamID(tx, feedback.TargetType, feedback.TargetID)
+	if err != nil {
+		return nil
+	}
+	return teamID
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"coaching-backend/avatar"
+	"coaching-backend/blob"
+	"coaching-backend/images"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// imageOwner describes where an owner type's records live and which of
+// their columns holds the image URL.
+type imageOwner struct {
+	model    func() interface{}
+	column   string
+	path     string // URL path under /api, e.g. "members/%d/avatar"
+	notFound string
+	// fallback returns the stored picture or logo URL of a loaded record
+	// and the default to draw when it has none.
+	fallback func(record interface{}) (string, avatar.Avatar)
+}
+
+var imageOwners = map[string]imageOwner{
+	models.ImageMember: {
+		model:    func() interface{} { return &models.TeamMember{} },
+		column:   "picture",
+		path:     "members/%d/avatar",
+		notFound: "Team member not found",
+		fallback: func(record interface{}) (string, avatar.Avatar) {
+			member := record.(*models.TeamMember)
+			return member.Picture, avatar.ForMember(member.Name, member.Email)
+		},
+	},
+	models.ImageTeam: {
+		model:    func() interface{} { return &models.Team{} },
+		column:   "logo",
+		path:     "teams/%d/logo",
+		notFound: "Team not found",
+		fallback: func(record interface{}) (string, avatar.Avatar) {
+			team := record.(*models.Team)
+			return team.Logo, avatar.ForTeam(team.Name)
+		},
+	},
+}
+
+// ImageSource is what an avatar or logo URL serves, in order of
+// preference: the uploaded Image, the stored picture or logo URL, or a
+// Generated default. Exactly one is set.
+type ImageSource struct {
+	Image     *models.Image
+	URL       string
+	Generated *avatar.Avatar
+}
+
+// ImageVersion identifies the content of an image in its URL.
+func ImageVersion(img *models.Image) string {
+	return img.Hash[:12]
+}
+
+// ImageURL is the URL stored in a member's picture or a team's logo once
+// an image is uploaded. The version changes with the content, so clients
+// may cache the response forever.
+func ImageURL(img *models.Image) string {
+	return imagePath(img.OwnerType, img.OwnerID) + "?v=" + ImageVersion(img)
+}
+
+func imagePath(ownerType string, id uint32) string {
+	return "/api/" + fmt.Sprintf(imageOwners[ownerType].path, id)
+}
+
+// imageKey is the blob key of the original (size 0) or a thumbnail.
+func imageKey(img *models.Image, size int) string {
+	variant, ext := "original", images.Extension(img.ContentType)
+	if size != 0 {
+		variant, ext = strconv.Itoa(size), images.Extension(images.ThumbnailType(img.ContentType))
+	}
+	return fmt.Sprintf("images/%s/%d/%s/%s%s", img.OwnerType, img.OwnerID, img.Hash, variant, ext)
+}
+
+// SetImage validates the upload read from r, stores it with its
+// thumbnails and points the owner's picture or logo at it, replacing any
+// previous image.
+func (s *Service) SetImage(ctx context.Context, ownerType string, id uint32, r io.Reader) (*models.Image, error) {
+	owner := imageOwners[ownerType]
+	if err := s.with(ctx).First(owner.model(), id).Error; err != nil {
+		return nil, lookupError(err, owner.notFound)
+	}
+
+	upload, err := images.Read(r)
+	if err != nil {
+		return nil, uploadError(err)
+	}
+	thumbnails, err := images.Thumbnails(upload)
+	if err != nil {
+		return nil, uploadError(err)
+	}
+
+	img := models.Image{
+		OwnerType:   ownerType,
+		OwnerID:     id,
+		Hash:        upload.Hash,
+		ContentType: upload.ContentType,
+		Width:       upload.Width,
+		Height:      upload.Height,
+		Size:        int64(len(upload.Data)),
+	}
+	if err := s.putBlob(ctx, imageKey(&img, 0), upload.Data, img.ContentType, "Failed to store image"); err != nil {
+		return nil, err
+	}
+	for size, data := range thumbnails {
+		if err := s.putBlob(ctx, imageKey(&img, size), data, images.ThumbnailType(img.ContentType), "Failed to store image"); err != nil {
+			return nil, err
+		}
+	}
+
+	var previous models.Image
+	err = s.transaction(ctx, func(tx *gorm.DB) error {
+		err := tx.Where("owner_type = ? AND owner_id = ?", ownerType, id).First(&previous).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return databaseError(err, "Failed to save image")
+		}
+		img.ID, img.CreatedAt = previous.ID, previous.CreatedAt
+		if err := tx.Save(&img).Error; err != nil {
+			return databaseError(err, "Failed to save image")
+		}
+		if err := tx.Model(owner.model()).Where("id = ?", id).Update(owner.column, ImageURL(&img)).Error; err != nil {
+			return databaseError(err, "Failed to save image")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if previous.ID != 0 && previous.Hash != img.Hash {
+		s.deleteBlobs(ctx, &previous)
+	}
+	return &img, nil
+}
+
+// GetImageSource finds what the owner's avatar or logo URL serves. A
+// stored URL is only used if it is an absolute http(s) URL other than the
+// avatar or logo URL itself.
+func (s *Service) GetImageSource(ctx context.Context, ownerType string, id uint32) (*ImageSource, error) {
+	owner := imageOwners[ownerType]
+	record := owner.model()
+	if err := s.with(ctx).First(record, id).Error; err != nil {
+		return nil, lookupError(err, owner.notFound)
+	}
+
+	var img models.Image
+	err := s.with(ctx).Where("owner_type = ? AND owner_id = ?", ownerType, id).First(&img).Error
+	if err == nil {
+		return &ImageSource{Image: &img}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, databaseError(err, "Failed to load image")
+	}
+
+	stored, generated := owner.fallback(record)
+	if u, err := url.Parse(stored); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		!strings.HasSuffix(u.Path, imagePath(ownerType, id)) {
+		return &ImageSource{URL: stored}, nil
+	}
+	return &ImageSource{Generated: &generated}, nil
+}
+
+// OpenImage opens the original of img, or the thumbnail of the given
+// size. The caller closes the reader.
+func (s *Service) OpenImage(ctx context.Context, img *models.Image, size int) (io.ReadCloser, blob.Info, error) {
+	body, info, err := s.blobs.Get(ctx, imageKey(img, size))
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, blob.Info{}, notFound("Image file is missing")
+	}
+	if err != nil {
+		return nil, blob.Info{}, &Error{Code: problem.CodeInternal, Message: "Failed to load image", Err: err}
+	}
+	return body, info, nil
+}
+
+// DeleteImage removes the owner's image and clears their picture or logo
+// if it still points at it. Deleting a missing image succeeds.
+func (s *Service) DeleteImage(ctx context.Context, ownerType string, id uint32) error {
+	owner := imageOwners[ownerType]
+	if err := s.with(ctx).First(owner.model(), id).Error; err != nil {
+		return lookupError(err, owner.notFound)
+	}
+
+	var img *models.Image
+	err := s.transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		if img, err = deleteImageRecord(tx, ownerType, id); err != nil {
+			return databaseError(err, "Failed to delete image")
+		}
+		err = tx.Model(owner.model()).
+			Where("id = ? AND "+owner.column+" LIKE ?", id, imagePath(ownerType, id)+"?v=%").
+			Update(owner.column, "").Error
+		if err != nil {
+			return databaseError(err, "Failed to delete image")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.deleteBlobs(ctx, img)
+	return nil
+}
+
+// deleteImageRecord deletes the owner's image record within tx and
+// returns it, or nil if there was none.
+func deleteImageRecord(tx *gorm.DB, ownerType string, id uint32) (*models.Image, error) {
+	var img models.Image
+	err := tx.Where("owner_type = ? AND owner_id = ?", ownerType, id).First(&img).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &img, tx.Delete(&img).Error
+}
+
+// deleteBlobs removes an image's files once its record is gone.
+func (s *Service) deleteBlobs(ctx context.Context, img *models.Image) {
+	if img == nil {
+		return
+	}
+	for _, size := range append([]int{0}, images.Sizes...) {
+		s.deleteBlob(ctx, imageKey(img, size))
+	}
+}
+
+func uploadError(err error) error {
+	switch {
+	case errors.Is(err, images.ErrTooLarge):
+		return &Error{Code: problem.CodeTooLarge, Message: "Image is larger than 5 MB"}
+	case errors.Is(err, images.ErrUnsupported):
+		return &Error{Code: problem.CodeUnsupported, Message: "Image must be a PNG, JPEG, GIF or WebP file"}
+	case errors.Is(err, images.ErrInvalid), errors.Is(err, images.ErrDimensions):
+		return invalid(problem.FieldError{Field: "file", Rule: "image", Message: strings.TrimPrefix(err.Error(), "image ")})
+	default:
+		return &Error{Code: problem.CodeInternal, Message: "Failed to read upload", Err: err}
+	}
+}
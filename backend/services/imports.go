@@ -0,0 +1,363 @@
+package services
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/spreadsheet"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Limits on import files.
+const (
+	MaxImportBytes = 10 << 20
+	MaxImportRows  = 10000
+)
+
+// Import actions say what an import does to the member in a row.
+const (
+	ImportCreate    = "create"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+)
+
+type ImportOptions struct {
+	// DryRun reports what the import would do without changing anything.
+	DryRun bool
+	// CreateTeams creates teams named in the file that do not exist yet,
+	// instead of rejecting their rows.
+	CreateTeams bool
+}
+
+// ImportReport describes an import. Rows are numbered as in the file, the
+// header being row 1.
+type ImportReport struct {
+	DryRun       bool              `json:"dry_run"`
+	Created      int               `json:"created"`
+	Updated      int               `json:"updated"`
+	Unchanged    int               `json:"unchanged"`
+	TeamsCreated []string          `json:"teams_created"`
+	Rows         []ImportRowResult `json:"rows"`
+	Errors       []ImportError     `json:"errors"`
+}
+
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	Email  string `json:"email"`
+	Action string `json:"action"`
+	// MemberID is zero for members a dry run would create.
+	MemberID uint32 `json:"member_id,omitempty"`
+}
+
+type ImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// importRow is a member as read from one row of an import file.
+type importRow struct {
+	row                        int
+	name, email, picture, team string
+}
+
+// importColumns maps normalized header names to the fields they fill.
+var importColumns = map[string]string{
+	"name":          "name",
+	"full_name":     "name",
+	"email":         "email",
+	"email_address": "email",
+	"picture":       "picture",
+	"picture_url":   "picture",
+	"avatar":        "picture",
+	"team":          "team",
+	"team_name":     "team",
+}
+
+// ImportMembers creates or updates the members listed in a CSV or XLSX
+// file, matching existing members by email regardless of case. A blank
+// picture or team leaves the member's current one. The import is applied
+// as a whole or not at all: if any row is invalid nothing changes and the
+// row errors are returned as field errors named like "rows[3].email".
+func (s *Service) ImportMembers(ctx context.Context, filename string, data []byte, opts ImportOptions) (*ImportReport, error) {
+	rows, err := readImport(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		plan, err := planImport(s.with(ctx), rows, opts)
+		if err != nil {
+			return nil, err
+		}
+		return plan.report(true), nil
+	}
+
+	var report *ImportReport
+	err = s.transaction(ctx, func(tx *gorm.DB) error {
+		plan, err := planImport(tx, rows, opts)
+		if err != nil {
+			return err
+		}
+		if len(plan.errors) > 0 {
+			return plan.invalid()
+		}
+		if err := plan.apply(tx); err != nil {
+			return err
+		}
+		report = plan.report(false)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func readImport(filename string, data []byte) ([]importRow, error) {
+	format, err := spreadsheet.Detect(filename, data)
+	if err != nil {
+		return nil, &Error{Code: problem.CodeUnsupported, Message: "Import file must be CSV or XLSX"}
+	}
+	table, err := spreadsheet.Read(format, data)
+	if err != nil {
+		return nil, invalid(problem.FieldError{Field: "file", Rule: "format", Message: "could not be read: " + err.Error()})
+	}
+	if len(table) == 0 {
+		return nil, invalid(problem.FieldError{Field: "file", Rule: "required", Message: "has no header row"})
+	}
+	if len(table)-1 > MaxImportRows {
+		return nil, invalid(problem.FieldError{Field: "file", Rule: "max", Message: fmt.Sprintf("may list at most %d members", MaxImportRows)})
+	}
+
+	columns := make(map[string]int)
+	for i, heading := range table[0] {
+		key := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(heading)))
+		field, ok := importColumns[key]
+		if !ok {
+			continue
+		}
+		if _, seen := columns[field]; seen {
+			return nil, invalid(problem.FieldError{Field: "file", Rule: "header", Message: "has more than one " + field + " column"})
+		}
+		columns[field] = i
+	}
+	for _, field := range []string{"name", "email"} {
+		if _, ok := columns[field]; !ok {
+			return nil, invalid(problem.FieldError{Field: "file", Rule: "header", Message: "has no " + field + " column"})
+		}
+	}
+
+	cell := func(values []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(values) {
+			return ""
+		}
+		return strings.TrimSpace(values[i])
+	}
+	var rows []importRow
+	for i, values := range table[1:] {
+		row := importRow{
+			row:     i + 2,
+			name:    cell(values, "name"),
+			email:   cell(values, "email"),
+			picture: cell(values, "picture"),
+			team:    cell(values, "team"),
+		}
+		if row == (importRow{row: row.row}) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// importPlan is what an import does, worked out before anything changes.
+type importPlan struct {
+	members []plannedMember
+	// newTeams are the teams to create, keyed by lowercased name.
+	newTeams  map[string]*models.Team
+	teamOrder []string
+	errors    []ImportError
+}
+
+type plannedMember struct {
+	row    int
+	action string
+	member models.TeamMember
+	// team is set when the row names a team; its ID is zero until a new
+	// team is created.
+	team           *models.Team
+	previousTeamID *uint32
+}
+
+func planImport(db *gorm.DB, rows []importRow, opts ImportOptions) (*importPlan, error) {
+	var existing []models.TeamMember
+	if err := db.Find(&existing).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch team members")
+	}
+	byEmail := make(map[string]models.TeamMember, len(existing))
+	for _, m := range existing {
+		byEmail[strings.ToLower(m.Email)] = m
+	}
+	var teams []models.Team
+	if err := db.Find(&teams).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch teams")
+	}
+	teamsByName := make(map[string]*models.Team, len(teams))
+	for i := range teams {
+		teamsByName[strings.ToLower(teams[i].Name)] = &teams[i]
+	}
+
+	plan := &importPlan{newTeams: make(map[string]*models.Team)}
+	seen := make(map[string]int)
+	for _, row := range rows {
+		rowErrors := len(plan.errors)
+		fail := func(field, rule, message string) {
+			plan.errors = append(plan.errors, ImportError{Row: row.row, Field: field, Rule: rule, Message: message})
+		}
+
+		candidate := models.TeamMember{Name: row.name, Email: row.email, Picture: row.picture}
+		if err := validate(&candidate); err != nil {
+			var serviceErr *Error
+			if !errors.As(err, &serviceErr) || serviceErr.Code != problem.CodeValidation {
+				return nil, err
+			}
+			for _, f := range serviceErr.Fields {
+				fail(f.Field, f.Rule, f.Message)
+			}
+		}
+		key := strings.ToLower(row.email)
+		if first, ok := seen[key]; ok && row.email != "" {
+			fail("email", "unique", fmt.Sprintf("duplicates row %d", first))
+		} else {
+			seen[key] = row.row
+		}
+
+		var team *models.Team
+		if row.team != "" {
+			teamKey := strings.ToLower(row.team)
+			switch {
+			case teamsByName[teamKey] != nil:
+				team = teamsByName[teamKey]
+			case plan.newTeams[teamKey] != nil:
+				team = plan.newTeams[teamKey]
+			case opts.CreateTeams:
+				team = &models.Team{Name: row.team}
+				plan.newTeams[teamKey] = team
+				plan.teamOrder = append(plan.teamOrder, teamKey)
+			default:
+				fail("team", "exists", "names a team that does not exist: "+row.team)
+			}
+		}
+		if len(plan.errors) > rowErrors {
+			continue
+		}
+
+		planned := plannedMember{row: row.row, team: team}
+		if current, ok := byEmail[key]; ok {
+			planned.member = current
+			planned.previousTeamID = current.TeamID
+			planned.action = ImportUnchanged
+			if current.Name != row.name || row.picture != "" && current.Picture != row.picture ||
+				team != nil && (team.ID == 0 || current.TeamID == nil || *current.TeamID != team.ID) {
+				planned.action = ImportUpdate
+			}
+			planned.member.Name = row.name
+			if row.picture != "" {
+				planned.member.Picture = row.picture
+			}
+		} else {
+			planned.member = candidate
+			planned.action = ImportCreate
+		}
+		plan.members = append(plan.members, planned)
+	}
+	return plan, nil
+}
+
+// apply creates the planned teams and members and updates the existing
+// members, recording their assignment history.
+func (p *importPlan) apply(tx *gorm.DB) error {
+	for _, key := range p.teamOrder {
+		if err := tx.Create(p.newTeams[key]).Error; err != nil {
+			return databaseError(err, "Failed to create team")
+		}
+	}
+
+	for i := range p.members {
+		planned := &p.members[i]
+		member := &planned.member
+		if planned.team != nil {
+			teamID := planned.team.ID
+			member.TeamID = &teamID
+		}
+		switch planned.action {
+		case ImportCreate:
+			if err := tx.Create(member).Error; err != nil {
+				return databaseError(err, "Failed to create team member")
+			}
+			if err := recordTeamChange(tx, member, nil, member.TeamID); err != nil {
+				return err
+			}
+			if err := recordMember(tx, events.MemberCreated, member, member.TeamID); err != nil {
+				return err
+			}
+		case ImportUpdate:
+			if err := tx.Save(member).Error; err != nil {
+				return databaseError(err, "Failed to update team member")
+			}
+			if err := recordTeamChange(tx, member, planned.previousTeamID, member.TeamID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *importPlan) report(dryRun bool) *ImportReport {
+	report := &ImportReport{
+		DryRun:       dryRun,
+		TeamsCreated: []string{},
+		Rows:         make([]ImportRowResult, 0, len(p.members)),
+		Errors:       p.errors,
+	}
+	if report.Errors == nil {
+		report.Errors = []ImportError{}
+	}
+	for _, key := range p.teamOrder {
+		report.TeamsCreated = append(report.TeamsCreated, p.newTeams[key].Name)
+	}
+	for _, planned := range p.members {
+		switch planned.action {
+		case ImportCreate:
+			report.Created++
+		case ImportUpdate:
+			report.Updated++
+		default:
+			report.Unchanged++
+		}
+		report.Rows = append(report.Rows, ImportRowResult{
+			Row:      planned.row,
+			Email:    planned.member.Email,
+			Action:   planned.action,
+			MemberID: planned.member.ID,
+		})
+	}
+	return report
+}
+
+// invalid reports the row errors as field errors of the whole import.
+func (p *importPlan) invalid() *Error {
+	fields := make([]problem.FieldError, len(p.errors))
+	for i, e := range p.errors {
+		fields[i] = problem.FieldError{Field: fmt.Sprintf("rows[%d].%s", e.Row, e.Field), Rule: e.Rule, Message: e.Message}
+	}
+	return invalid(fields...)
+}
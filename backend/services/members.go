@@ -0,0 +1,216 @@
+package services
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *Service) CreateMember(ctx context.Context, member *models.TeamMember) error {
+	if err := validate(member); err != nil {
+		return err
+	}
+	return s.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(member).Error; err != nil {
+			return databaseError(err, "Failed to create team member")
+		}
+		if err := recordTeamChange(tx, member, nil, member.TeamID); err != nil {
+			return err
+		}
+		return recordMember(tx, events.MemberCreated, member, member.TeamID)
+	})
+}
+
+// ListMembers returns the active members.
+func (s *Service) ListMembers(ctx context.Context) ([]models.TeamMember, error) {
+	var members []models.TeamMember
+	if err := s.with(ctx).Scopes(activeMembers).Preload("Team").Find(&members).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch team members")
+	}
+	return members, nil
+}
+
+// PageMembers returns the members matching cond, or all of them when it
+// is nil, ordered by ID from offset, and how many match in total.
+// Deactivated members are included, for provisioning clients to see.
+func (s *Service) PageMembers(ctx context.Context, cond clause.Expression, offset, limit int) ([]models.TeamMember, int64, error) {
+	query := s.with(ctx).Model(&models.TeamMember{})
+	if cond != nil {
+		query = query.Where(cond)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, databaseError(err, "Failed to fetch team members")
+	}
+	var members []models.TeamMember
+	if err := query.Preload("Team").Order("id").Offset(offset).Limit(limit).Find(&members).Error; err != nil {
+		return nil, 0, databaseError(err, "Failed to fetch team members")
+	}
+	return members, total, nil
+}
+
+func (s *Service) GetMember(ctx context.Context, id uint32) (*models.TeamMember, error) {
+	var member models.TeamMember
+	if err := s.with(ctx).Preload("Team").First(&member, id).Error; err != nil {
+		return nil, lookupError(err, "Team member not found")
+	}
+	return &member, nil
+}
+
+// ResolveMember finds the member a chat mention refers to: an email
+// address, or a handle matching the part of a member's email before the
+// "@". Both compare case-insensitively. A handle shared by several members
+// is rejected as ambiguous.
+func (s *Service) ResolveMember(ctx context.Context, mention string) (*models.TeamMember, error) {
+	query := s.with(ctx).Scopes(activeMembers).Limit(2)
+	if strings.Contains(mention, "@") {
+		query = query.Where("LOWER(email) = ?", strings.ToLower(mention))
+	} else {
+		// "!" escapes LIKE wildcards; a backslash would need quoting in MySQL.
+		pattern := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(strings.ToLower(mention))
+		query = query.Where("LOWER(email) LIKE ? ESCAPE '!'", pattern+"@%")
+	}
+
+	var members []models.TeamMember
+	if err := query.Find(&members).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch team members")
+	}
+	switch len(members) {
+	case 0:
+		return nil, notFound("No team member matches " + mention)
+	case 1:
+		return &members[0], nil
+	default:
+		return nil, invalid(problem.FieldError{Field: "mention", Rule: "unique", Message: mention + " matches several team members"})
+	}
+}
+
+// UpdateMember loads the member, lets apply change it and saves the result.
+// A member it deactivates is signed out. Errors returned by apply are
+// passed through unchanged.
+func (s *Service) UpdateMember(ctx context.Context, id uint32, apply func(*models.TeamMember) error) (*models.TeamMember, error) {
+	var member models.TeamMember
+	if err := s.with(ctx).First(&member, id).Error; err != nil {
+		return nil, lookupError(err, "Team member not found")
+	}
+
+	// Copied because decoding into member writes through the pointer.
+	var previousTeamID *uint32
+	if member.TeamID != nil {
+		teamID := *member.TeamID
+		previousTeamID = &teamID
+	}
+	wasActive := member.Active()
+	if err := apply(&member); err != nil {
+		return nil, err
+	}
+	if err := validate(&member); err != nil {
+		return nil, err
+	}
+
+	err := s.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(&member).Error; err != nil {
+			return databaseError(err, "Failed to update team member")
+		}
+		if wasActive && !member.Active() {
+			if err := tx.Where("member_id = ?", member.ID).Delete(&models.Session{}).Error; err != nil {
+				return databaseError(err, "Failed to sign out team member")
+			}
+		}
+		return recordTeamChange(tx, &member, previousTeamID, member.TeamID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// DeleteMember deletes the member, their notification preferences and
+// their avatar. A member of a team is recorded as leaving it first.
+func (s *Service) DeleteMember(ctx context.Context, id uint32) error {
+	var avatar *models.Image
+	err := s.transaction(ctx, func(tx *gorm.DB) error {
+		var members []models.TeamMember
+		if err := tx.Where("id = ?", id).Find(&members).Error; err != nil {
+			return databaseError(err, "Failed to delete team member")
+		}
+		for i := range members {
+			if err := leaveTeam(tx, &members[i]); err != nil {
+				return err
+			}
+		}
+		var err error
+		if avatar, err = deleteMember(tx, id); err != nil {
+			return databaseError(err, "Failed to delete team member")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.deleteBlobs(ctx, avatar)
+	return nil
+}
+
+// leaveTeam clears the member's team, if they have one, and records them
+// leaving it within tx, before the member or the team is deleted. The
+// caller saves or deletes the member.
+func leaveTeam(tx *gorm.DB, member *models.TeamMember) error {
+	teamID := member.TeamID
+	if teamID == nil {
+		return nil
+	}
+	member.TeamID = nil
+	if err := recordTeamChange(tx, member, teamID, nil); err != nil {
+		return err
+	}
+	return recordMember(tx, events.MemberUnassigned, member, teamID)
+}
+
+// deleteMember deletes the member's records within tx and returns their
+// avatar, whose files the caller deletes once tx commits.
+func deleteMember(tx *gorm.DB, id uint32) (*models.Image, error) {
+	if err := tx.Where("member_id = ?", id).Delete(&models.NotificationPreferences{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("member_id = ?", id).Delete(&models.Session{}).Error; err != nil {
+		return nil, err
+	}
+	avatar, err := deleteImageRecord(tx, models.ImageMember, id)
+	if err != nil {
+		return nil, err
+	}
+	return avatar, tx.Delete(&models.TeamMember{}, id).Error
+}
+
+// activeMembers limits a query to members who have not been deactivated.
+func activeMembers(db *gorm.DB) *gorm.DB {
+	return db.Where("deactivated_at IS NULL")
+}
+
+// deactivateMember marks the member inactive within tx and signs them out.
+// Their records, team and feedback are kept.
+func deactivateMember(tx *gorm.DB, member *models.TeamMember) error {
+	now := time.Now().UTC()
+	member.DeactivatedAt = &now
+	if err := tx.Model(member).UpdateColumn("deactivated_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Where("member_id = ?", member.ID).Delete(&models.Session{}).Error
+}
+
+func recordMember(tx *gorm.DB, t events.Type, member *models.TeamMember, teamID *uint32) error {
+	return record(tx, events.Event{
+		Type:       t,
+		TeamID:     teamID,
+		TargetType: "member",
+		TargetID:   member.ID,
+		Data:       *member,
+	})
+}
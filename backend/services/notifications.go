@@ -0,0 +1,41 @@
+package services
+
+import (
+	"coaching-backend/models"
+	"coaching-backend/notify"
+	"context"
+	"time"
+)
+
+// GetNotificationPreferences returns the member's notification
+// preferences, or the defaults when they never changed them.
+func (s *Service) GetNotificationPreferences(ctx context.Context, memberID uint32) (*models.NotificationPreferences, error) {
+	if _, err := s.GetMember(ctx, memberID); err != nil {
+		return nil, err
+	}
+	prefs, err := notify.Preferences(s.with(ctx), memberID)
+	if err != nil {
+		return nil, databaseError(err, "Failed to fetch notification preferences")
+	}
+	return &prefs, nil
+}
+
+// UpdateNotificationPreferences loads the member's preferences, lets apply
+// change them and saves the result. Errors returned by apply are passed
+// through unchanged.
+func (s *Service) UpdateNotificationPreferences(ctx context.Context, memberID uint32, apply func(*models.NotificationPreferences) error) (*models.NotificationPreferences, error) {
+	prefs, err := s.GetNotificationPreferences(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(prefs); err != nil {
+		return nil, err
+	}
+
+	prefs.MemberID = memberID
+	prefs.UpdatedAt = time.Now().UTC()
+	if err := s.with(ctx).Save(prefs).Error; err != nil {
+		return nil, databaseError(err, "Failed to update notification preferences")
+	}
+	return prefs, nil
+}
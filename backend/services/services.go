@@ -0,0 +1,88 @@
+// Package services implements the coaching operations behind every API.
+// The REST handlers, the GraphQL resolvers and the gRPC server are thin
+// transports over it, so they share validation, feedback target resolution
+// and error codes.
+package services
+
+import (
+	"bytes"
+	"coaching-backend/blob"
+	"coaching-backend/events"
+	"coaching-backend/outbox"
+	"coaching-backend/problem"
+	"context"
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+type Service struct {
+	db    *gorm.DB
+	bus   *events.Bus
+	blobs blob.Store
+}
+
+// New returns a service whose feedback watches subscribe to events.Default
+// and whose uploads are kept in blob.Default. Changes are published
+// through the outbox.
+func New(db *gorm.DB) *Service {
+	return &Service{db: db, bus: events.Default, blobs: blob.Default}
+}
+
+func (s *Service) with(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx)
+}
+
+// transaction runs fn in a database transaction and, once it commits,
+// wakes the outbox relay to publish the events fn recorded. Errors
+// returned by fn are passed through unchanged.
+func (s *Service) transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	if err := s.with(ctx).Transaction(fn); err != nil {
+		var serviceErr *Error
+		if errors.As(err, &serviceErr) {
+			return err
+		}
+		return databaseError(err, "Failed to commit transaction")
+	}
+	outbox.Notify()
+	return nil
+}
+
+// record adds e to the outbox within tx.
+func record(tx *gorm.DB, e events.Event) error {
+	if err := outbox.Record(tx, e); err != nil {
+		return databaseError(err, "Failed to record event")
+	}
+	return nil
+}
+
+// putBlob stores data in the blob store, reporting failure as an internal
+// error with the given message.
+func (s *Service) putBlob(ctx context.Context, key string, data []byte, contentType, failure string) error {
+	if err := s.blobs.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return &Error{Code: problem.CodeInternal, Message: failure, Err: err}
+	}
+	return nil
+}
+
+// deleteBlob removes a blob whose record is gone. Failures only leave an
+// orphaned blob behind, so they are logged rather than returned.
+func (s *Service) deleteBlob(ctx context.Context, key string) {
+	if err := s.blobs.Delete(ctx, key); err != nil {
+		log.Printf("blob: failed to delete %s: %v", key, err)
+	}
+}
+
+// validate applies the models' binding rules, the same ones gin enforces
+// when binding a REST request body.
+func validate(v interface{}) error {
+	if err := binding.Validator.ValidateStruct(v); err != nil {
+		if fields, ok := problem.FieldErrors(err); ok {
+			return invalid(fields...)
+		}
+		return &Error{Code: problem.CodeInternal, Message: "Failed to validate request", Err: err}
+	}
+	return nil
+}
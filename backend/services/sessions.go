@@ -0,0 +1,196 @@
+package services
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SSOIdentity is a person as the identity provider vouches for them.
+type SSOIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// SSOMapping maps identity provider groups to teams and roles.
+type SSOMapping struct {
+	// Teams maps group names to team names; missing teams are created. A
+	// member in none of the groups keeps their team, and one in several
+	// joins the team of the first group the provider lists.
+	Teams map[string]string
+	// AdminGroups make their members admins. Everybody else signs in with
+	// RoleMember.
+	AdminGroups []string
+}
+
+func unauthorized(message string) *Error {
+	return &Error{Code: problem.CodeUnauthorized, Message: message}
+}
+
+// SignIn finds the member an identity belongs to, creating them on their
+// first sign-in, and updates their name, email, team and role from it.
+// Members are found by the identity's subject, or else by its email
+// regardless of case, which links existing members to the identity. Only
+// verified emails are trusted, and an email already linked to another
+// identity is refused.
+func (s *Service) SignIn(ctx context.Context, identity SSOIdentity, mapping SSOMapping) (*models.TeamMember, error) {
+	identity.Email = strings.TrimSpace(identity.Email)
+	if identity.Subject == "" || identity.Email == "" || !identity.EmailVerified {
+		return nil, unauthorized("The identity provider did not vouch for a verified email")
+	}
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	var member models.TeamMember
+	err := s.transaction(ctx, func(tx *gorm.DB) error {
+		err := tx.Where("sso_subject = ?", identity.Subject).Take(&member).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = tx.Where("LOWER(email) = ?", strings.ToLower(identity.Email)).Take(&member).Error
+			if err == nil && member.SSOSubject != "" {
+				return unauthorized("The email is linked to another identity")
+			}
+		}
+		isNew := errors.Is(err, gorm.ErrRecordNotFound)
+		if err != nil && !isNew {
+			return databaseError(err, "Failed to fetch team member")
+		}
+		if !isNew && !member.Active() {
+			return unauthorized("The team member has been deactivated")
+		}
+
+		previousTeamID := member.TeamID
+		member.Name = name
+		member.Email = identity.Email
+		member.SSOSubject = identity.Subject
+		member.Role = models.RoleMember
+		for _, group := range identity.Groups {
+			if containsFold(mapping.AdminGroups, group) {
+				member.Role = models.RoleAdmin
+			}
+		}
+		if err := mapTeam(tx, &member, identity.Groups, mapping.Teams); err != nil {
+			return err
+		}
+		if err := validate(&member); err != nil {
+			return err
+		}
+
+		if isNew {
+			if err := tx.Create(&member).Error; err != nil {
+				return databaseError(err, "Failed to create team member")
+			}
+			if err := recordMember(tx, events.MemberCreated, &member, member.TeamID); err != nil {
+				return err
+			}
+		} else if err := tx.Omit("Team").Save(&member).Error; err != nil {
+			return databaseError(err, "Failed to update team member")
+		}
+		return recordTeamChange(tx, &member, previousTeamID, member.TeamID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// mapTeam puts the member in the team of the first of groups that teams
+// maps, creating the team if needed.
+func mapTeam(tx *gorm.DB, member *models.TeamMember, groups []string, teams map[string]string) error {
+	for _, group := range groups {
+		name, ok := teams[group]
+		if !ok {
+			continue
+		}
+		var team models.Team
+		err := tx.Where("LOWER(name) = ?", strings.ToLower(name)).Take(&team).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			team = models.Team{Name: name}
+			err = tx.Create(&team).Error
+		}
+		if err != nil {
+			return databaseError(err, "Failed to fetch team")
+		}
+		member.TeamID = &team.ID
+		return nil
+	}
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hashToken returns the hex SHA-256 of a secret token, which is what is
+// stored in its place.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession signs the member in for ttl and returns the session's
+// token, which only the caller ever sees. Expired sessions are cleaned up
+// on the way.
+func (s *Service) CreateSession(ctx context.Context, memberID uint32, ttl time.Duration) (string, *models.Session, error) {
+	token := rand.Text() + rand.Text()
+	session := &models.Session{
+		TokenHash: hashToken(token),
+		MemberID:  memberID,
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+	err := s.with(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now().UTC()).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(session).Error
+	})
+	if err != nil {
+		return "", nil, databaseError(err, "Failed to create session")
+	}
+	return token, session, nil
+}
+
+// GetSession returns the unexpired session a token belongs to, with its
+// member.
+func (s *Service) GetSession(ctx context.Context, token string) (*models.Session, error) {
+	var session models.Session
+	err := s.with(ctx).Preload("Member.Team").
+		Where("token_hash = ? AND expires_at > ?", hashToken(token), time.Now().UTC()).
+		Take(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, unauthorized("The session has expired or does not exist")
+	}
+	if err != nil {
+		return nil, databaseError(err, "Failed to fetch session")
+	}
+	if session.Member == nil || !session.Member.Active() {
+		return nil, unauthorized("The session has expired or does not exist")
+	}
+	return &session, nil
+}
+
+// DeleteSession signs a session out. Unknown tokens are ignored.
+func (s *Service) DeleteSession(ctx context.Context, token string) error {
+	if err := s.with(ctx).Where("token_hash = ?", hashToken(token)).Delete(&models.Session{}).Error; err != nil {
+		return databaseError(err, "Failed to delete session")
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Projects, releases and meetings are feedback targets with nothing more
+// to them than a name, so they share the functions below. Each change
+// records a TYPE.created, TYPE.updated or TYPE.deleted event. A deleted
+// target's feedback keeps the name it was given under.
+
+func (s *Service) CreateProject(ctx context.Context, project *models.Project) error {
+	return createTarget(s, ctx, project, "project")
+}
+
+// ListProjects returns the projects by name.
+func (s *Service) ListProjects(ctx context.Context) ([]models.Project, error) {
+	return listTargets[models.Project](s, ctx, "name", "projects")
+}
+
+func (s *Service) GetProject(ctx context.Context, id uint32) (*models.Project, error) {
+	return getTarget[models.Project](s, ctx, id, "Project")
+}
+
+// UpdateProject loads the project, lets apply change it and saves the
+// result. Errors returned by apply are passed through unchanged.
+func (s *Service) UpdateProject(ctx context.Context, id uint32, apply func(*models.Project) error) (*models.Project, error) {
+	return updateTarget(s, ctx, id, apply, "Project")
+}
+
+func (s *Service) DeleteProject(ctx context.Context, id uint32) error {
+	return deleteTarget[models.Project](s, ctx, id, "Project")
+}
+
+func (s *Service) CreateRelease(ctx context.Context, release *models.Release) error {
+	return createTarget(s, ctx, release, "release")
+}
+
+// ListReleases returns the releases, newest first.
+func (s *Service) ListReleases(ctx context.Context) ([]models.Release, error) {
+	return listTargets[models.Release](s, ctx, "id DESC", "releases")
+}
+
+func (s *Service) GetRelease(ctx context.Context, id uint32) (*models.Release, error) {
+	return getTarget[models.Release](s, ctx, id, "Release")
+}
+
+// UpdateRelease loads the release, lets apply change it and saves the
+// result. Errors returned by apply are passed through unchanged.
+func (s *Service) UpdateRelease(ctx context.Context, id uint32, apply func(*models.Release) error) (*models.Release, error) {
+	return updateTarget(s, ctx, id, apply, "Release")
+}
+
+func (s *Service) DeleteRelease(ctx context.Context, id uint32) error {
+	return deleteTarget[models.Release](s, ctx, id, "Release")
+}
+
+func (s *Service) CreateMeeting(ctx context.Context, meeting *models.Meeting) error {
+	return createTarget(s, ctx, meeting, "meeting")
+}
+
+// ListMeetings returns the meetings, latest scheduled first.
+func (s *Service) ListMeetings(ctx context.Context) ([]models.Meeting, error) {
+	return listTargets[models.Meeting](s, ctx, "scheduled_at DESC, id DESC", "meetings")
+}
+
+func (s *Service) GetMeeting(ctx context.Context, id uint32) (*models.Meeting, error) {
+	return getTarget[models.Meeting](s, ctx, id, "Meeting")
+}
+
+// UpdateMeeting loads the meeting, lets apply change it and saves the
+// result. Errors returned by apply are passed through unchanged.
+func (s *Service) UpdateMeeting(ctx context.Context, id uint32, apply func(*models.Meeting) error) (*models.Meeting, error) {
+	return updateTarget(s, ctx, id, apply, "Meeting")
+}
+
+func (s *Service) DeleteMeeting(ctx context.Context, id uint32) error {
+	return deleteTarget[models.Meeting](s, ctx, id, "Meeting")
+}
+
+func createTarget[T any](s *Service, ctx context.Context, target *T, name string) error {
+	if err := validate(target); err != nil {
+		return err
+	}
+	return s.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(target).Error; err != nil {
+			return databaseError(err, "Failed to create "+name)
+		}
+		return recordTarget(tx, name, "created", target)
+	})
+}
+
+func listTargets[T any](s *Service, ctx context.Context, order, plural string) ([]T, error) {
+	list := []T{}
+	if err := s.with(ctx).Order(order).Find(&list).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch "+plural)
+	}
+	return list, nil
+}
+
+func getTarget[T any](s *Service, ctx context.Context, id uint32, label string) (*T, error) {
+	var target T
+	if err := s.with(ctx).First(&target, id).Error; err != nil {
+		return nil, lookupError(err, label+" not found")
+	}
+	return &target, nil
+}
+
+func updateTarget[T any](s *Service, ctx context.Context, id uint32, apply func(*T) error, label string) (*T, error) {
+	target, err := getTarget[T](s, ctx, id, label)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(target); err != nil {
+		return nil, err
+	}
+	if err := validate(target); err != nil {
+		return nil, err
+	}
+	name := strings.ToLower(label)
+	err = s.transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(target).Error; err != nil {
+			return databaseError(err, "Failed to update "+name)
+		}
+		return recordTarget(tx, name, "updated", target)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func deleteTarget[T any](s *Service, ctx context.Context, id uint32, label string) error {
+	name := strings.ToLower(label)
+	return s.transaction(ctx, func(tx *gorm.DB) error {
+		var target T
+		if err := tx.First(&target, id).Error; err != nil {
+			return lookupError(err, label+" not found")
+		}
+		if err := tx.Delete(new(T), id).Error; err != nil {
+			return databaseError(err, "Failed to delete "+name)
+		}
+		return recordTarget(tx, name, "deleted", &target)
+	})
+}
+
+// recordTarget records the event for an action on a project, release or
+// meeting, whose target type is name.
+func recordTarget(tx *gorm.DB, name, action string, target interface{}) error {
+	var id uint32
+	var data interface{}
+	switch t := target.(type) {
+	case *models.Project:
+		id, data = t.ID, *t
+	case *models.Release:
+		id, data = t.ID, *t
+	case *models.Meeting:
+		id, data = t.ID, *t
+	}
+	return record(tx, events.Event{
+		Type:       events.Type(name + "." + action),
+		TargetType: name,
+		TargetID:   id,
+		Data:       data,
+	})
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *Service) CreateTeam(ctx context.Context, team *models.Team) error {
+	if err := validate(team); err != nil {
+		return err
+	}
+	if err := s.with(ctx).Create(team).Error; err != nil {
+		return databaseError(err, "Failed to create team")
+	}
+	return nil
+}
+
+func (s *Service) ListTeams(ctx context.Context) ([]models.Team, error) {
+	var teams []models.Team
+	if err := s.with(ctx).Preload("Members", activeMembers).Find(&teams).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch teams")
+	}
+	return teams, nil
+}
+
+// PageTeams returns the teams matching cond, or all of them when it is
+// nil, with their members, ordered by ID from offset, and how many match
+// in total.
+func (s *Service) PageTeams(ctx context.Context, cond clause.Expression, offset, limit int) ([]models.Team, int64, error) {
+	query := s.with(ctx).Model(&models.Team{})
+	if cond != nil {
+		query = query.Where(cond)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, databaseError(err, "Failed to fetch teams")
+	}
+	var teams []models.Team
+	if err := query.Preload("Members", activeMembers).Order("id").Offset(offset).Limit(limit).Find(&teams).Error; err != nil {
+		return nil, 0, databaseError(err, "Failed to fetch teams")
+	}
+	return teams, total, nil
+}
+
+func (s *Service) GetTeam(ctx context.Context, id uint32) (*models.Team, error) {
+	var team models.Team
+	if err := s.with(ctx).Preload("Members", activeMembers).First(&team, id).Error; err != nil {
+		return nil, lookupError(err, "Team not found")
+	}
+	return &team, nil
+}
+
+// UpdateTeam loads the team, lets apply change it and saves the result.
+// Errors returned by apply are passed through unchanged.
+func (s *Service) UpdateTeam(ctx context.Context, id uint32, apply func(*models.Team) error) (*models.Team, error) {
+	var team models.Team
+	if err := s.with(ctx).First(&team, id).Error; err != nil {
+		return nil, lookupError(err, "Team not found")
+	}
+
+	if err := apply(&team); err != nil {
+		return nil, err
+	}
+	if err := validate(&team); err != nil {
+		return nil, err
+	}
+
+	if err := s.with(ctx).Save(&team).Error; err != nil {
+		return nil, databaseError(err, "Failed to update team")
+	}
+	return &team, nil
+}
+
+// DeleteTeam deletes the team and its logo. Its members are left without a
+// team, each recorded as leaving it.
+func (s *Service) DeleteTeam(ctx context.Context, id uint32) error {
+	var logo *models.Image
+	err := s.transaction(ctx, func(tx *gorm.DB) error {
+		var members []models.TeamMember
+		if err := tx.Where("team_id = ?", id).Find(&members).Error; err != nil {
+			return databaseError(err, "Failed to fetch team members")
+		}
+		for i := range members {
+			member := &members[i]
+			if err := leaveTeam(tx, member); err != nil {
+				return err
+			}
+			if err := tx.Model(member).UpdateColumn("team_id", nil).Error; err != nil {
+				return databaseError(err, "Failed to remove member from team")
+			}
+		}
+
+		var err error
+		if logo, err = deleteImageRecord(tx, models.ImageTeam, id); err != nil {
+			return databaseError(err, "Failed to delete team")
+		}
+		if err := tx.Delete(&models.Team{}, id).Error; err != nil {
+			return databaseError(err, "Failed to delete team")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.deleteBlobs(ctx, logo)
+	return nil
+}
+
+// SaveTeamMembers creates the team, or saves it if it has an ID, and makes
+// the members with memberIDs its only members: members of other teams
+// move to it and members not listed are left without a team. It all
+// happens in one transaction, with each move recorded.
+func (s *Service) SaveTeamMembers(ctx context.Context, team *models.Team, memberIDs []uint32) error {
+	if err := validate(team); err != nil {
+		return err
+	}
+	return s.transaction(ctx, func(tx *gorm.DB) error {
+		if team.ID == 0 {
+			if err := tx.Omit("Members").Create(team).Error; err != nil {
+				return databaseError(err, "Failed to create team")
+			}
+		} else if err := tx.Omit("Members").Save(team).Error; err != nil {
+			return databaseError(err, "Failed to update team")
+		}
+
+		var current []models.TeamMember
+		if err := tx.Where("team_id = ?", team.ID).Find(&current).Error; err != nil {
+			return databaseError(err, "Failed to fetch team members")
+		}
+		var members []models.TeamMember
+		if len(memberIDs) > 0 {
+			if err := tx.Where("id IN ?", memberIDs).Find(&members).Error; err != nil {
+				return databaseError(err, "Failed to fetch team members")
+			}
+		}
+		found := make(map[uint32]bool, len(members))
+		for _, member := range members {
+			found[member.ID] = true
+		}
+		for _, id := range memberIDs {
+			if !found[id] {
+				return invalid(problem.FieldError{Field: "members", Rule: "exists", Message: fmt.Sprintf("Team member %d not found", id)})
+			}
+		}
+
+		for i := range current {
+			member := &current[i]
+			if found[member.ID] {
+				continue
+			}
+			member.TeamID = nil
+			if err := tx.Save(member).Error; err != nil {
+				return databaseError(err, "Failed to remove member from team")
+			}
+			if err := recordTeamChange(tx, member, &team.ID, nil); err != nil {
+				return err
+			}
+			if err := recordMember(tx, events.MemberUnassigned, member, &team.ID); err != nil {
+				return err
+			}
+		}
+		for i := range members {
+			member := &members[i]
+			if member.TeamID != nil && *member.TeamID == team.ID {
+				continue
+			}
+			previousTeamID := member.TeamID
+			member.TeamID = &team.ID
+			if err := tx.Save(member).Error; err != nil {
+				return databaseError(err, "Failed to assign member to team")
+			}
+			if err := recordTeamChange(tx, member, previousTeamID, &team.ID); err != nil {
+				return err
+			}
+			if err := recordMember(tx, events.MemberAssigned, member, &team.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
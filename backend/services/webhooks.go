@@ -0,0 +1,193 @@
+package services
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/problem"
+	"coaching-backend/webhooks"
+	"context"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxDeliveries caps the delivery log returned for a webhook.
+const maxDeliveries = 100
+
+// CreateWebhook stores a new, active webhook. A secret is generated when
+// none is given; the stored webhook, secret included, is written back.
+func (s *Service) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	if err := validateWebhook(hook); err != nil {
+		return err
+	}
+	if hook.Secret == "" {
+		hook.Secret = webhooks.NewSecret()
+	}
+	hook.Active = true
+	hook.Failures = 0
+	hook.DisabledAt = nil
+
+	if err := s.with(ctx).Create(hook).Error; err != nil {
+		return databaseError(err, "Failed to create webhook")
+	}
+	return nil
+}
+
+// ListWebhooks returns every webhook. Secrets are omitted.
+func (s *Service) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	if err := s.with(ctx).Find(&hooks).Error; err != nil {
+		return nil, databaseError(err, "Failed to fetch webhooks")
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return hooks, nil
+}
+
+// GetWebhook returns the webhook without its secret.
+func (s *Service) GetWebhook(ctx context.Context, id uint32) (*models.Webhook, error) {
+	var hook models.Webhook
+	if err := s.with(ctx).First(&hook, id).Error; err != nil {
+		return nil, lookupError(err, "Webhook not found")
+	}
+	hook.Secret = ""
+	return &hook, nil
+}
+
+// UpdateWebhook loads the webhook, lets apply change it and saves the
+// result. Re-activating a webhook clears its failure count; an empty
+// secret keeps the current one. Errors returned by apply are passed
+// through unchanged.
+func (s *Service) UpdateWebhook(ctx context.Context, id uint32, apply func(*models.Webhook) error) (*models.Webhook, error) {
+	var hook models.Webhook
+	if err := s.with(ctx).First(&hook, id).Error; err != nil {
+		return nil, lookupError(err, "Webhook not found")
+	}
+
+	current := hook
+	if err := apply(&hook); err != nil {
+		return nil, err
+	}
+	if err := validateWebhook(&hook); err != nil {
+		return nil, err
+	}
+
+	hook.ID = current.ID
+	hook.Failures, hook.DisabledAt = current.Failures, current.DisabledAt
+	if hook.Secret == "" {
+		hook.Secret = current.Secret
+	}
+	switch {
+	case hook.Active && !current.Active:
+		hook.Failures, hook.DisabledAt = 0, nil
+	case !hook.Active && current.Active:
+		now := time.Now().UTC()
+		hook.DisabledAt = &now
+	}
+
+	if err := s.with(ctx).Save(&hook).Error; err != nil {
+		return nil, databaseError(err, "Failed to update webhook")
+	}
+	hook.Secret = ""
+	return &hook, nil
+}
+
+// DeleteWebhook deletes the webhook and its delivery log.
+func (s *Service) DeleteWebhook(ctx context.Context, id uint32) error {
+	err := s.with(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Webhook{}, id).Error
+	})
+	if err != nil {
+		return databaseError(err, "Failed to delete webhook")
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the webhook's most recent deliveries,
+// newest first.
+func (s *Service) ListWebhookDeliveries(ctx context.Context, webhookID uint32) ([]models.WebhookDelivery, error) {
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := s.with(ctx).Where("webhook_id = ?", webhookID).Order("id DESC").Limit(maxDeliveries).Find(&deliveries).Error
+	if err != nil {
+		return nil, databaseError(err, "Failed to fetch webhook deliveries")
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues a copy of a past delivery for immediate sending.
+// The payload and idempotency key are unchanged, so receivers can
+// deduplicate it.
+func (s *Service) RedeliverWebhook(ctx context.Context, webhookID, deliveryID uint32) (*models.WebhookDelivery, error) {
+	var original models.WebhookDelivery
+	if err := s.with(ctx).Where("webhook_id = ?", webhookID).First(&original, deliveryID).Error; err != nil {
+		return nil, lookupError(err, "Webhook delivery not found")
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID:     original.WebhookID,
+		EventID:       original.EventID,
+		EventKey:      original.EventKey,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: time.Now().UTC(),
+		RedeliveryOf:  &original.ID,
+	}
+	if err := s.with(ctx).Create(&delivery).Error; err != nil {
+		return nil, databaseError(err, "Failed to queue redelivery")
+	}
+	return &delivery, nil
+}
+
+func validateWebhook(hook *models.Webhook) error {
+	if err := validate(hook); err != nil {
+		return err
+	}
+
+	var fields []problem.FieldError
+	if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		fields = append(fields, problem.FieldError{Field: "url", Rule: "url", Message: "must be an http or https URL"})
+	} else if privateHost(u.Hostname()) {
+		// Names are checked again when delivering, once resolved.
+		fields = append(fields, problem.FieldError{Field: "url", Rule: "public", Message: "must not point to a loopback, private or link-local address"})
+	}
+	for _, name := range hook.Events {
+		if !events.Known(events.Type(name)) {
+			types := make([]string, len(events.Types))
+			for i, t := range events.Types {
+				types[i] = string(t)
+			}
+			fields = append(fields, problem.FieldError{
+				Field:   "events",
+				Rule:    "oneof",
+				Message: "must contain only: " + strings.Join(types, ", "),
+			})
+			break
+		}
+	}
+	if len(fields) > 0 {
+		return invalid(fields...)
+	}
+	return nil
+}
+
+// privateHost reports whether host is localhost or an address webhooks
+// may not reach.
+func privateHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && webhooks.Blocked(addr)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// serve serves HTTP on listener until ctx is done, then shuts the server
+// down: it stops accepting connections and waits up to timeout for the
+// requests in flight, after which the rest are cut off.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, timeout time.Duration) error {
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("requests still in flight after %s were cut off", timeout)
+	}
+	return nil
+}
+
+// stopGRPC stops server, waiting up to timeout for the calls in flight.
+func stopGRPC(server *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		server.Stop()
+	}
+}
+
+// workers runs background workers, which return once their context is
+// done.
+type workers struct {
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+func newWorkers() *workers {
+	ctx, stop := context.WithCancel(context.Background())
+	return &workers{ctx: ctx, stop: stop}
+}
+
+// Go runs run in its own goroutine.
+func (w *workers) Go(run func(context.Context)) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		run(w.ctx)
+	}()
+}
+
+// Stop cancels the workers' context and waits up to timeout for them to
+// return. It reports whether they all did. Work they leave unfinished is
+// picked up on the next start, as deliveries, notifications and outbox
+// events stay queued until done.
+func (w *workers) Stop(timeout time.Duration) bool {
+	w.stop()
+	stopped := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServe runs serve with handler on a local port and returns its URL
+// and the channel serve's result arrives on.
+func startServe(t *testing.T, ctx context.Context, handler http.HandlerFunc, timeout time.Duration) (string, <-chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, &http.Server{Handler: handler}, listener, timeout) }()
+	return "http://" + listener.Addr().String(), served
+}
+
+func TestServe(t *testing.T) {
+	t.Run("Drains Requests In Flight", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		started := make(chan struct{})
+		url, served := startServe(t, ctx, func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			io.WriteString(w, "done")
+		}, 5*time.Second)
+
+		responses := make(chan string, 1)
+		go func() {
+			resp, err := http.Get(url)
+			if err != nil {
+				responses <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			responses <- string(body)
+		}()
+		<-started
+		cancel()
+
+		assert.Equal(t, "done", <-responses)
+		assert.NoError(t, <-served)
+	})
+
+	t.Run("Cuts Off Requests After Timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		started := make(chan struct{})
+		url, served := startServe(t, ctx, func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+		}, 50*time.Millisecond)
+
+		go http.Get(url)
+		<-started
+		cancel()
+
+		assert.ErrorContains(t, <-served, "requests still in flight after 50ms were cut off")
+	})
+
+	t.Run("Refuses New Connections", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		url, served := startServe(t, ctx, func(w http.ResponseWriter, r *http.Request) {}, time.Second)
+		cancel()
+		require.NoError(t, <-served)
+
+		_, err := http.Get(url)
+		assert.Error(t, err)
+	})
+
+	t.Run("Reports Serve Errors", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		listener.Close()
+
+		err = serve(context.Background(), &http.Server{}, listener, time.Second)
+		assert.Error(t, err)
+	})
+}
+
+func TestWorkers(t *testing.T) {
+	t.Run("Stop Waits For Workers", func(t *testing.T) {
+		w := newWorkers()
+		finished := make(chan struct{})
+		w.Go(func(ctx context.Context) {
+			<-ctx.Done()
+			time.Sleep(20 * time.Millisecond)
+			close(finished)
+		})
+
+		assert.True(t, w.Stop(time.Second))
+		select {
+		case <-finished:
+		default:
+			t.Fatal("Stop returned before the worker finished")
+		}
+	})
+
+	t.Run("Stop Gives Up After Timeout", func(t *testing.T) {
+		w := newWorkers()
+		release := make(chan struct{})
+		defer close(release)
+		w.Go(func(ctx context.Context) { <-release })
+
+		assert.False(t, w.Stop(20*time.Millisecond))
+	})
+}
@@ -0,0 +1,120 @@
+// Package spreadsheet reads and writes tables as CSV files and as XLSX
+// workbooks of one worksheet, with rows of cell text.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrFormat         = errors.New("file is neither CSV nor XLSX")
+	ErrFormatMismatch = errors.New("file content does not match its extension")
+)
+
+// Format is a file format tables are read from.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// zipMagic starts every XLSX file, as they are ZIP archives.
+var zipMagic = []byte("PK\x03\x04")
+
+// Detect tells the format of data named name. XLSX is recognized by its ZIP
+// signature; anything else that is valid UTF-8 is taken for CSV.
+func Detect(name string, data []byte) (Format, error) {
+	format := CSV
+	if bytes.HasPrefix(data, zipMagic) {
+		format = XLSX
+	} else if !utf8.Valid(data) {
+		return "", ErrFormat
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case "":
+	case ".csv", ".txt":
+		if format != CSV {
+			return "", ErrFormatMismatch
+		}
+	case ".xlsx":
+		if format != XLSX {
+			return "", ErrFormatMismatch
+		}
+	default:
+		return "", ErrFormat
+	}
+	return format, nil
+}
+
+// Read returns the rows of data in the given format. Rows may have
+// different lengths; trailing empty rows are dropped.
+func Read(format Format, data []byte) ([][]string, error) {
+	var rows [][]string
+	var err error
+	switch format {
+	case CSV:
+		rows, err = readCSV(data)
+	case XLSX:
+		rows, err = readXLSX(data)
+	default:
+		return nil, ErrFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	for len(rows) > 0 && blank(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows, nil
+}
+
+// readCSV accepts comma or semicolon separated values, the latter being
+// what spreadsheet programs export in many locales, and skips a UTF-8 byte
+// order mark.
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	r := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+
+	// The reader skips blank lines; they are kept as empty rows so rows are
+	// numbered as spreadsheet programs number them.
+	var rows [][]string
+	// next is the line the reader continues on, at byte offset.
+	next, offset := 1, int64(0)
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, _ := r.FieldPos(0)
+		for line := next; line < start; line++ {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, row)
+		next += bytes.Count(data[offset:r.InputOffset()], []byte("\n"))
+		offset = r.InputOffset()
+	}
+}
+
+func blank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workbook builds an XLSX file whose first sheet, listed before a second
+// one, is xl/worksheets/people.xml.
+func workbook(t *testing.T, sheet, shared string) []byte {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="People" sheetId="2" r:id="rId7"/><sheet name="Other" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId7" Target="/xl/worksheets/people.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>wrong sheet</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/people.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheet + `</sheetData></worksheet>`,
+	}
+	if shared != "" {
+		parts["xl/sharedStrings.xml"] = `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + shared + `</sst>`
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		f.Write([]byte(content))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	xlsx := workbook(t, "", "")
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		want     Format
+		err      error
+	}{
+		{"CSV", "people.csv", []byte("name,email\n"), CSV, nil},
+		{"XLSX", "People.XLSX", xlsx, XLSX, nil},
+		{"No Extension", "upload", xlsx, XLSX, nil},
+		{"XLSX Named CSV", "people.csv", xlsx, "", ErrFormatMismatch},
+		{"CSV Named XLSX", "people.xlsx", []byte("name,email\n"), "", ErrFormatMismatch},
+		{"Binary", "people.csv", []byte{0xff, 0xfe, 0x00}, "", ErrFormat},
+		{"Other Extension", "people.ods", []byte("x"), "", ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := Detect(tt.filename, tt.data)
+			assert.Equal(t, tt.want, format)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	t.Run("Comma Separated", func(t *testing.T) {
+		rows, err := Read(CSV, []byte("name,email\n\"Lovelace, Ada\",ada@example.com\n\n,\n"))
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"name", "email"}, {"Lovelace, Ada", "ada@example.com"}}, rows)
+	})
+
+	t.Run("Semicolon Separated With Byte Order Mark", func(t *testing.T) {
+		rows, err := Read(CSV, []byte("\ufeffname;email;team\r\nAda;ada@example.com\r\n"))
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"name", "email", "team"}, {"Ada", "ada@example.com"}}, rows)
+	})
+
+	t.Run("Keeps Blank Lines As Empty Rows", func(t *testing.T) {
+		rows, err := Read(CSV, []byte("name,notes\nAda,\"two\nlines\"\n\nGrace,\n"))
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"name", "notes"}, {"Ada", "two\nlines"}, nil, {"Grace", ""}}, rows)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := Read(CSV, []byte("name\n\"unterminated\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestReadXLSX(t *testing.T) {
+	t.Run("Values", func(t *testing.T) {
+		data := workbook(t,
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>team</t></is></c></row>`+
+				`<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3" t="str"><v>ada@example.com</v></c><c r="C3"><v>42</v></c><c r="D3" t="b"><v>1</v></c></row>`+
+				`<row><c><v>1.5</v></c><c t="inlineStr"><is><r><t>Rich </t></r><r><t>text</t></r></is></c></row>`,
+			`<si><t>name</t></si><si><t>email</t></si><si><r><t>Ada </t></r><r><t>Lovelace</t></r></si>`)
+
+		rows, err := Read(XLSX, data)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"name", "email", "", "team"},
+			nil,
+			{"Ada Lovelace", "ada@example.com", "42", "TRUE"},
+			{"1.5", "Rich text"},
+		}, rows)
+	})
+
+	t.Run("Missing Shared String", func(t *testing.T) {
+		_, err := Read(XLSX, workbook(t, `<row r="1"><c r="A1" t="s"><v>3</v></c></row>`, ""))
+		assert.ErrorContains(t, err, "missing string")
+	})
+
+	t.Run("Cells Out Of Order", func(t *testing.T) {
+		_, err := Read(XLSX, workbook(t, `<row r="1"><c r="B1"><v>1</v></c><c r="A1"><v>2</v></c></row>`, ""))
+		assert.ErrorContains(t, err, "out of order")
+	})
+
+	t.Run("Not A Workbook", func(t *testing.T) {
+		_, err := Read(XLSX, []byte("PK\x03\x04 truncated"))
+		assert.ErrorContains(t, err, "invalid XLSX workbook")
+	})
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "XFD1": 16383} {
+		got, err := columnIndex(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, got, ref)
+	}
+	for _, ref := range []string{"1", "XFE1", "ZZZZZZZZ1"} {
+		_, err := columnIndex(ref)
+		assert.Error(t, err, ref)
+	}
+}
@@ -0,0 +1,181 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// Column describes a column written by a Writer.
+type Column struct {
+	Name string
+	// Numeric columns are written as numbers to XLSX, and are not guarded
+	// against formulas in CSV.
+	Numeric bool
+}
+
+// Writer writes a table row by row, without holding earlier rows in
+// memory. The header row is written first. Close finishes the file but
+// does not close the underlying writer.
+type Writer interface {
+	Write(values []string) error
+	Close() error
+}
+
+// NewWriter writes a table with the given columns in format to w.
+func NewWriter(format Format, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w, columns)
+	case XLSX:
+		return newXLSXWriter(w, columns)
+	default:
+		return nil, ErrFormat
+	}
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns}
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Name
+	}
+	return cw, cw.w.Write(header)
+}
+
+// Write prefixes text that spreadsheet programs would run as a formula
+// with a quote, as exports contain what users typed.
+func (w *csvWriter) Write(values []string) error {
+	row := make([]string, len(values))
+	for i, value := range values {
+		if i < len(w.columns) && !w.columns[i].Numeric && value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			value = "'" + value
+		}
+		row[i] = value
+	}
+	return w.w.Write(row)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// maxCellLength is the most UTF-16 code units Excel shows in a cell.
+const maxCellLength = 32767
+
+// xlsxWriter writes a workbook of one worksheet. The sheet is the last
+// part of the archive, so its rows can be written as they come.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	columns []Column
+	row     int
+}
+
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func newXLSXWriter(w io.Writer, columns []Column) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+	f, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	xw := &xlsxWriter{archive: archive, sheet: bufio.NewWriter(f), columns: columns}
+	xw.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Name
+	}
+	// The header is text even above numeric columns.
+	return xw, xw.writeRow(header, false)
+}
+
+func (w *xlsxWriter) Write(values []string) error {
+	return w.writeRow(values, true)
+}
+
+func (w *xlsxWriter) writeRow(values []string, typed bool) error {
+	w.row++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.row)
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		ref := columnName(i) + fmt.Sprint(w.row)
+		if typed && i < len(w.columns) && w.columns[i].Numeric {
+			fmt.Fprintf(w.sheet, `<c r="%s"><v>`, ref)
+			xml.EscapeText(w.sheet, []byte(value))
+			w.sheet.WriteString(`</v></c>`)
+			continue
+		}
+		fmt.Fprintf(w.sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		if err := xml.EscapeText(w.sheet, []byte(truncateCell(value))); err != nil {
+			return err
+		}
+		w.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	w.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.archive.Close()
+}
+
+// columnName returns the letters of a zero-based column: 0 is A, 26 is AA.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func truncateCell(value string) string {
+	if len(value) <= maxCellLength {
+		return value
+	}
+	units := 0
+	for i, r := range value {
+		units += utf16.RuneLen(r)
+		if units > maxCellLength {
+			return value[:i]
+		}
+	}
+	return value
+}
@@ -0,0 +1,88 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testColumns = []Column{{Name: "id", Numeric: true}, {Name: "name"}, {Name: "notes"}}
+
+func writeTable(t *testing.T, format Format, rows [][]string) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, testColumns)
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestWriteCSV(t *testing.T) {
+	data := writeTable(t, CSV, [][]string{
+		{"1", "Ada", "=HYPERLINK(\"http://evil\")"},
+		{"-2", "+Grace", "two\nlines, quoted"},
+	})
+	assert.Equal(t, "id,name,notes\n"+
+		"1,Ada,\"'=HYPERLINK(\"\"http://evil\"\")\"\n"+
+		"-2,'+Grace,\"two\nlines, quoted\"\n", string(data))
+}
+
+func TestWriteXLSX(t *testing.T) {
+	t.Run("Round Trip", func(t *testing.T) {
+		rows := [][]string{
+			{"1", "Ada <Lovelace> & co", "=1+1"},
+			{"2", "", "  padded\x00"},
+		}
+		data := writeTable(t, XLSX, rows)
+
+		format, err := Detect("export.xlsx", data)
+		require.NoError(t, err)
+		assert.Equal(t, XLSX, format)
+		read, err := Read(XLSX, data)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"id", "name", "notes"},
+			{"1", "Ada <Lovelace> & co", "=1+1"},
+			{"2", "", "  padded�"},
+		}, read)
+	})
+
+	t.Run("Numeric Cells", func(t *testing.T) {
+		data := writeTable(t, XLSX, [][]string{{"7", "Ada", ""}})
+		assert.Contains(t, sheetXML(t, data), `<c r="A2"><v>7</v></c><c r="B2" t="inlineStr">`)
+	})
+
+	t.Run("Truncates Long Cells", func(t *testing.T) {
+		data := writeTable(t, XLSX, [][]string{{"1", strings.Repeat("é", maxCellLength+10), ""}})
+		read, err := Read(XLSX, data)
+		require.NoError(t, err)
+		assert.Equal(t, maxCellLength, len([]rune(read[1][1])))
+	})
+}
+
+func sheetXML(t *testing.T, data []byte) string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	f, err := archive.Open("xl/worksheets/sheet1.xml")
+	require.NoError(t, err)
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA", 16383: "XFD"} {
+		assert.Equal(t, want, columnName(i))
+		got, err := columnIndex(want + "1")
+		require.NoError(t, err)
+		assert.Equal(t, i, got)
+	}
+}
@@ -0,0 +1,263 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxXLSXPart caps each decompressed part of a workbook, so a small upload
+// cannot expand into gigabytes.
+const maxXLSXPart = 64 << 20
+
+var errXLSX = errors.New("invalid XLSX workbook")
+
+// readXLSX reads the first worksheet of a workbook. Only cell values are
+// read: formulas yield their cached result and formatting is ignored, so
+// dates come out as serial numbers.
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errXLSX, err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheet, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = sharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+	return sheetRows(sheet, shared)
+}
+
+// firstSheet finds the worksheet listed first in the workbook, which is
+// not necessarily sheet1.xml.
+func firstSheet(files map[string]*zip.File) (*zip.File, error) {
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("%w: no worksheets", errXLSX)
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		name := path.Join("xl", rel.Target)
+		if strings.HasPrefix(rel.Target, "/") {
+			name = strings.TrimPrefix(rel.Target, "/")
+		}
+		if f, ok := files[name]; ok {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: first worksheet is missing", errXLSX)
+}
+
+func decodePart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: %s is missing", errXLSX, name)
+	}
+	r, err := openPart(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", errXLSX, name, err)
+	}
+	return nil
+}
+
+func openPart(f *zip.File) (io.ReadCloser, error) {
+	if f.UncompressedSize64 > maxXLSXPart {
+		return nil, fmt.Errorf("%w: %s is too large", errXLSX, f.Name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errXLSX, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(r, maxXLSXPart), r}, nil
+}
+
+// richText is the content of a shared or inline string: plain text in t,
+// or runs of formatted text each with their own t.
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+func sharedStrings(f *zip.File) ([]string, error) {
+	var table struct {
+		Items []richText `xml:"si"`
+	}
+	r, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	if err := xml.NewDecoder(r).Decode(&table); err != nil {
+		return nil, fmt.Errorf("%w: shared strings: %v", errXLSX, err)
+	}
+	strs := make([]string, len(table.Items))
+	for i, item := range table.Items {
+		strs[i] = item.String()
+	}
+	return strs, nil
+}
+
+type cell struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Value  string   `xml:"v"`
+	Inline richText `xml:"is"`
+}
+
+// sheetRows places cells by their references, so rows and columns left
+// out of the file come back empty.
+func sheetRows(f *zip.File, shared []string) ([][]string, error) {
+	var sheet struct {
+		Rows []struct {
+			Index int    `xml:"r,attr"`
+			Cells []cell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	r, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	if err := xml.NewDecoder(r).Decode(&sheet); err != nil {
+		return nil, fmt.Errorf("%w: worksheet: %v", errXLSX, err)
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		index := row.Index - 1
+		if index > maxRows {
+			return nil, fmt.Errorf("%w: row %d is out of range", errXLSX, row.Index)
+		}
+		if index < len(rows) {
+			index = len(rows)
+		}
+		for len(rows) < index {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for _, c := range row.Cells {
+			column := len(values)
+			if c.Ref != "" {
+				if column, err = columnIndex(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if column < len(values) || column > maxColumns {
+				return nil, fmt.Errorf("%w: cell %s is out of order", errXLSX, c.Ref)
+			}
+			for len(values) < column {
+				values = append(values, "")
+			}
+			value, err := c.text(shared)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+func (c cell) text(shared []string) (string, error) {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(c.Value)
+		if err != nil || i < 0 || i >= len(shared) {
+			return "", fmt.Errorf("%w: cell %s refers to a missing string", errXLSX, c.Ref)
+		}
+		return shared[i], nil
+	case "inlineStr":
+		return c.Inline.String(), nil
+	case "b":
+		if c.Value == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	default:
+		return c.Value, nil
+	}
+}
+
+// maxRows and maxColumns are the largest sheet Excel allows, up to cell
+// XFD1048576.
+const (
+	maxRows    = 1 << 20
+	maxColumns = 16384
+)
+
+// columnIndex returns the zero-based column of a cell reference such as
+// "C7".
+func columnIndex(ref string) (int, error) {
+	column := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A') + 1
+		letters++
+		if column > maxColumns {
+			break
+		}
+	}
+	if letters == 0 || column > maxColumns {
+		return 0, fmt.Errorf("%w: invalid cell reference %q", errXLSX, ref)
+	}
+	return column - 1, nil
+}
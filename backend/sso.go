@@ -0,0 +1,43 @@
+package main
+
+import (
+	"coaching-backend/config"
+	"coaching-backend/handlers"
+	"coaching-backend/oidc"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ssoConfig builds single sign-on from the sso settings, returning to
+// app.url after sign-in. It is off when no issuer is set.
+func ssoConfig(cfg *config.Config) (*handlers.SSO, error) {
+	settings := cfg.SSO
+	sso := &handlers.SSO{SessionTTL: settings.SessionTTL, AppURL: cfg.App.URL}
+	if settings.Issuer == "" {
+		return sso, nil
+	}
+	client := oidc.Config{
+		Issuer:       settings.Issuer,
+		ClientID:     settings.ClientID,
+		ClientSecret: settings.ClientSecret,
+		RedirectURL:  settings.RedirectURL,
+		Scopes:       strings.Fields(strings.Join(settings.Scopes, " ")),
+		GroupsClaim:  settings.GroupsClaim,
+	}
+	if client.ClientID == "" || client.RedirectURL == "" {
+		return nil, errors.New("sso.client_id and sso.redirect_url are required with sso.issuer")
+	}
+	sso.Client = oidc.New(client)
+	sso.SecureCookies = strings.HasPrefix(client.RedirectURL, "https://")
+
+	sso.Mapping.AdminGroups = settings.AdminGroups
+	teams, err := settings.TeamMapping()
+	if err != nil {
+		return nil, fmt.Errorf("sso.team_groups: %w", err)
+	}
+	if len(teams) > 0 {
+		sso.Mapping.Teams = teams
+	}
+	return sso, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"coaching-backend/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSOConfig(t *testing.T) {
+	t.Run("Off Without Issuer", func(t *testing.T) {
+		sso, err := ssoConfig(loadConfig(t))
+		require.NoError(t, err)
+		assert.Nil(t, sso.Client)
+		assert.Equal(t, 12*time.Hour, sso.SessionTTL)
+	})
+
+	t.Run("Group Mapping", func(t *testing.T) {
+		t.Setenv("OIDC_ISSUER", "https://login.example.com")
+		t.Setenv("OIDC_CLIENT_ID", "coaching")
+		t.Setenv("OIDC_REDIRECT_URL", "https://coaching.example.com/api/auth/callback")
+		t.Setenv("OIDC_SCOPES", "openid email")
+		t.Setenv("OIDC_ADMIN_GROUPS", "coaching-admins, it")
+		t.Setenv("OIDC_TEAM_GROUPS", "eng-platform=Platform, eng-design = Design")
+		t.Setenv("SESSION_TTL", "8h")
+		t.Setenv("APP_URL", "https://coaching.example.com")
+
+		sso, err := ssoConfig(loadConfig(t))
+		require.NoError(t, err)
+		assert.NotNil(t, sso.Client)
+		assert.True(t, sso.SecureCookies)
+		assert.Equal(t, 8*time.Hour, sso.SessionTTL)
+		assert.Equal(t, "https://coaching.example.com", sso.AppURL)
+		assert.Equal(t, []string{"coaching-admins", "it"}, sso.Mapping.AdminGroups)
+		assert.Equal(t, map[string]string{"eng-platform": "Platform", "eng-design": "Design"}, sso.Mapping.Teams)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.SSO.Issuer = "https://login.example.com"
+		_, err := ssoConfig(cfg)
+		assert.Error(t, err, "client ID and redirect URL are required")
+
+		cfg.SSO.ClientID = "coaching"
+		cfg.SSO.RedirectURL = "https://coaching.example.com/api/auth/callback"
+		cfg.SSO.TeamGroups = []string{"Platform"}
+		_, err = ssoConfig(cfg)
+		assert.EqualError(t, err, `sso.team_groups: "Platform" is not group=Team`)
+	})
+}
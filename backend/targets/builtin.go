@@ -0,0 +1,172 @@
+package targets
+
+import (
+	"coaching-backend/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// OrganizationID is the only valid target ID for the "organization" type.
+const OrganizationID uint32 = 1
+
+// OrganizationName is the organization target's display name. main sets
+// it from the app.organization_name setting.
+var OrganizationName = "Organization"
+
+func init() {
+	Register(teamTarget{})
+	Register(memberTarget{})
+	Register(projectTarget{})
+	Register(releaseTarget{})
+	Register(meetingTarget{})
+	Register(organizationTarget{})
+}
+
+func exists(db *gorm.DB, model interface{}, id uint32) (bool, error) {
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+type teamTarget struct{}
+
+func (teamTarget) Type() string  { return "team" }
+func (teamTarget) Label() string { return "Team" }
+
+func (teamTarget) Model() interface{} { return &models.Team{} }
+
+func (teamTarget) Exists(db *gorm.DB, id uint32) (bool, error) {
+	return exists(db, &models.Team{}, id)
+}
+
+func (teamTarget) TeamID(db *gorm.DB, id uint32) (*uint32, error) {
+	return &id, nil
+}
+
+// Recipients are the team's active members.
+func (teamTarget) Recipients(db *gorm.DB, id uint32) ([]uint32, error) {
+	var members []uint32
+	err := db.Model(&models.TeamMember{}).Where("team_id = ? AND deactivated_at IS NULL", id).Pluck("id", &members).Error
+	return members, err
+}
+
+func (teamTarget) DisplayName(db *gorm.DB, id uint32) (string, error) {
+	var team models.Team
+	if err := db.First(&team, id).Error; err != nil {
+		return "", err
+	}
+	return team.Name, nil
+}
+
+type memberTarget struct{}
+
+func (memberTarget) Type() string  { return "member" }
+func (memberTarget) Label() string { return "Team member" }
+
+func (memberTarget) Model() interface{} { return &models.TeamMember{} }
+
+func (memberTarget) Exists(db *gorm.DB, id uint32) (bool, error) {
+	return exists(db, &models.TeamMember{}, id)
+}
+
+// TeamID is the member's current team.
+func (memberTarget) TeamID(db *gorm.DB, id uint32) (*uint32, error) {
+	var member models.TeamMember
+	if err := db.Select("team_id").First(&member, id).Error; err != nil {
+		return nil, err
+	}
+	return member.TeamID, nil
+}
+
+// Recipients is the member, unless they are deactivated.
+func (memberTarget) Recipients(db *gorm.DB, id uint32) ([]uint32, error) {
+	var members []uint32
+	err := db.Model(&models.TeamMember{}).Where("id = ? AND deactivated_at IS NULL", id).Pluck("id", &members).Error
+	return members, err
+}
+
+func (memberTarget) DisplayName(db *gorm.DB, id uint32) (string, error) {
+	var member models.TeamMember
+	if err := db.First(&member, id).Error; err != nil {
+		return "", err
+	}
+	return member.Name, nil
+}
+
+type projectTarget struct{}
+
+func (projectTarget) Type() string  { return "project" }
+func (projectTarget) Label() string { return "Project" }
+
+func (projectTarget) Model() interface{} { return &models.Project{} }
+
+func (projectTarget) Exists(db *gorm.DB, id uint32) (bool, error) {
+	return exists(db, &models.Project{}, id)
+}
+
+func (projectTarget) DisplayName(db *gorm.DB, id uint32) (string, error) {
+	var project models.Project
+	if err := db.First(&project, id).Error; err != nil {
+		return "", err
+	}
+	return project.Name, nil
+}
+
+type releaseTarget struct{}
+
+func (releaseTarget) Type() string  { return "release" }
+func (releaseTarget) Label() string { return "Release" }
+
+func (releaseTarget) Model() interface{} { return &models.Release{} }
+
+func (releaseTarget) Exists(db *gorm.DB, id uint32) (bool, error) {
+	return exists(db, &models.Release{}, id)
+}
+
+func (releaseTarget) DisplayName(db *gorm.DB, id uint32) (string, error) {
+	var release models.Release
+	if err := db.First(&release, id).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s", release.Name, release.Version), nil
+}
+
+type meetingTarget struct{}
+
+func (meetingTarget) Type() string  { return "meeting" }
+func (meetingTarget) Label() string { return "Meeting" }
+
+func (meetingTarget) Model() interface{} { return &models.Meeting{} }
+
+func (meetingTarget) Exists(db *gorm.DB, id uint32) (bool, error) {
+	return exists(db, &models.Meeting{}, id)
+}
+
+func (meetingTarget) DisplayName(db *gorm.DB, id uint32) (string, error) {
+	var meeting models.Meeting
+	if err := db.First(&meeting, id).Error; err != nil {
+		return "", err
+	}
+	if meeting.ScheduledAt.IsZero() {
+		return meeting.Title, nil
+	}
+	return fmt.Sprintf("%s (%s)", meeting.Title, meeting.ScheduledAt.Format("2006-01-02")), nil
+}
+
+// organizationTarget is the organization as a whole. There is no table for
+// it; the display name is OrganizationName.
+type organizationTarget struct{}
+
+func (organizationTarget) Type() string  { return "organization" }
+func (organizationTarget) Label() string { return "Organization" }
+
+func (organizationTarget) Exists(db *gorm.DB, id uint32) (bool, error) {
+	return id == OrganizationID, nil
+}
+
+func (organizationTarget) DisplayName(db *gorm.DB, id uint32) (string, error) {
+	return OrganizationName, nil
+}
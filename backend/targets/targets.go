@@ -0,0 +1,163 @@
+package targets
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownType = errors.New("unknown feedback target type")
+	ErrNotFound    = errors.New("feedback target not found")
+)
+
+// Target is something feedback can be given to. Each target type registers
+// one implementation; handlers never switch on the type themselves.
+type Target interface {
+	// Type is the value stored in Feedback.TargetType, e.g. "member".
+	Type() string
+	// Label is a human readable name for the type, e.g. "Team member".
+	Label() string
+	Exists(db *gorm.DB, id uint32) (bool, error)
+	DisplayName(db *gorm.DB, id uint32) (string, error)
+}
+
+// Stored is implemented by targets kept in a table of their own. Model
+// returns a pointer to a zero record of that table.
+type Stored interface {
+	Model() interface{}
+}
+
+// TeamScoped is implemented by targets that belong to a team. TeamID
+// returns nil when the target has no team.
+type TeamScoped interface {
+	TeamID(db *gorm.DB, id uint32) (*uint32, error)
+}
+
+// Audience is implemented by targets whose members are told about
+// feedback they get. Recipients returns their member IDs.
+type Audience interface {
+	Recipients(db *gorm.DB, id uint32) ([]uint32, error)
+}
+
+// TypeInfo is the client-facing description of a registered target type.
+type TypeInfo struct {
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Target{}
+)
+
+// Register makes a target type available for feedback. It panics if the
+// type is empty or already registered.
+func Register(t Target) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if t.Type() == "" {
+		panic("targets: Register called with empty type")
+	}
+	if _, dup := registry[t.Type()]; dup {
+		panic("targets: Register called twice for type " + t.Type())
+	}
+	registry[t.Type()] = t
+}
+
+func Lookup(targetType string) (Target, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	t, ok := registry[targetType]
+	return t, ok
+}
+
+// Types lists the registered target types sorted by type name.
+func Types() []TypeInfo {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	types := make([]TypeInfo, 0, len(registry))
+	for _, t := range registry {
+		types = append(types, TypeInfo{Type: t.Type(), Label: t.Label()})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	return types
+}
+
+// notFoundError reads as "Team not found" while still matching ErrNotFound.
+type notFoundError struct {
+	label string
+}
+
+func (e notFoundError) Error() string {
+	return e.label + " not found"
+}
+
+func (e notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Resolve checks that the target exists and returns its display name.
+// The returned error wraps ErrUnknownType or ErrNotFound when applicable.
+func Resolve(db *gorm.DB, targetType string, id uint32) (string, error) {
+	t, ok := Lookup(targetType)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownType, targetType)
+	}
+
+	exists, err := t.Exists(db, id)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", notFoundError{label: t.Label()}
+	}
+
+	return t.DisplayName(db, id)
+}
+
+// Models returns the record of every stored target type, by type.
+func Models() map[string]interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	models := map[string]interface{}{}
+	for name, t := range registry {
+		if stored, ok := t.(Stored); ok {
+			models[name] = stored.Model()
+		}
+	}
+	return models
+}
+
+// TeamID returns the team the target belongs to, or nil for unknown types
+// and targets without a team.
+func TeamID(db *gorm.DB, targetType string, id uint32) (*uint32, error) {
+	t, ok := Lookup(targetType)
+	if !ok {
+		return nil, nil
+	}
+	if scoped, ok := t.(TeamScoped); ok {
+		return scoped.TeamID(db, id)
+	}
+	return nil, nil
+}
+
+// Recipients returns the members told about feedback to the target, or
+// none for unknown types and targets without an audience.
+func Recipients(db *gorm.DB, targetType string, id uint32) ([]uint32, error) {
+	t, ok := Lookup(targetType)
+	if !ok {
+		return nil, nil
+	}
+	if audience, ok := t.(Audience); ok {
+		return audience.Recipients(db, id)
+	}
+	return nil, nil
+}
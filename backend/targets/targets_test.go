@@ -0,0 +1,176 @@
+package targets
+
+import (
+	"coaching-backend/models"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	err = db.AutoMigrate(&models.TeamMember{}, &models.Team{}, &models.Project{}, &models.Release{}, &models.Meeting{})
+	assert.NoError(t, err)
+
+	return db
+}
+
+type stubTarget struct{}
+
+func (stubTarget) Type() string  { return "stub" }
+func (stubTarget) Label() string { return "Stub" }
+
+func (stubTarget) Exists(db *gorm.DB, id uint32) (bool, error) {
+	return id == 42, nil
+}
+
+func (stubTarget) DisplayName(db *gorm.DB, id uint32) (string, error) {
+	return "The Answer", nil
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("Builtin Types Registered", func(t *testing.T) {
+		var types []string
+		for _, info := range Types() {
+			types = append(types, info.Type)
+		}
+
+		assert.Subset(t, types, []string{"meeting", "member", "organization", "project", "release", "team"})
+	})
+
+	t.Run("Register Custom Type", func(t *testing.T) {
+		Register(stubTarget{})
+
+		target, ok := Lookup("stub")
+		assert.True(t, ok)
+		assert.Equal(t, "Stub", target.Label())
+
+		name, err := Resolve(nil, "stub", 42)
+		assert.NoError(t, err)
+		assert.Equal(t, "The Answer", name)
+	})
+
+	t.Run("Register Duplicate Type Panics", func(t *testing.T) {
+		assert.Panics(t, func() { Register(teamTarget{}) })
+	})
+
+	t.Run("Resolve Unknown Type", func(t *testing.T) {
+		_, err := Resolve(nil, "invalid", 1)
+		assert.True(t, errors.Is(err, ErrUnknownType))
+	})
+}
+
+func TestResolveBuiltinTargets(t *testing.T) {
+	db := setupTestDB(t)
+
+	t.Run("Resolve Team", func(t *testing.T) {
+		team := models.Team{Name: "Development Team"}
+		db.Create(&team)
+
+		name, err := Resolve(db, "team", team.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Development Team", name)
+	})
+
+	t.Run("Resolve Release", func(t *testing.T) {
+		release := models.Release{Name: "Coaching App", Version: "v1.2.0"}
+		db.Create(&release)
+
+		name, err := Resolve(db, "release", release.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Coaching App v1.2.0", name)
+	})
+
+	t.Run("Resolve Meeting", func(t *testing.T) {
+		meeting := models.Meeting{Title: "Sprint Retro", ScheduledAt: time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)}
+		db.Create(&meeting)
+
+		name, err := Resolve(db, "meeting", meeting.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Sprint Retro (2025-03-14)", name)
+	})
+
+	t.Run("Resolve Organization", func(t *testing.T) {
+		name, err := Resolve(db, "organization", OrganizationID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Organization", name)
+
+		_, err = Resolve(db, "organization", 2)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("Resolve Missing Project", func(t *testing.T) {
+		_, err := Resolve(db, "project", 999)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.Equal(t, "Project not found", err.Error())
+	})
+}
+
+func TestTargetCapabilities(t *testing.T) {
+	db := setupTestDB(t)
+	team := models.Team{Name: "Platform"}
+	db.Create(&team)
+	alice := models.TeamMember{Name: "Alice", Email: "alice@example.com", TeamID: &team.ID}
+	bob := models.TeamMember{Name: "Bob", Email: "bob@example.com", TeamID: &team.ID}
+	carol := models.TeamMember{Name: "Carol", Email: "carol@example.com"}
+	deactivated := time.Now()
+	dave := models.TeamMember{Name: "Dave", Email: "dave@example.com", TeamID: &team.ID, DeactivatedAt: &deactivated}
+	db.Create(&alice)
+	db.Create(&bob)
+	db.Create(&carol)
+	db.Create(&dave)
+
+	t.Run("Team ID", func(t *testing.T) {
+		teamID, err := TeamID(db, "team", team.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &team.ID, teamID)
+
+		teamID, err = TeamID(db, "member", alice.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &team.ID, teamID)
+
+		teamID, err = TeamID(db, "member", carol.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, teamID)
+
+		teamID, err = TeamID(db, "project", 1)
+		assert.NoError(t, err)
+		assert.Nil(t, teamID)
+	})
+
+	t.Run("Recipients", func(t *testing.T) {
+		recipients, err := Recipients(db, "team", team.ID)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []uint32{alice.ID, bob.ID}, recipients)
+
+		recipients, err = Recipients(db, "member", carol.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint32{carol.ID}, recipients)
+
+		recipients, err = Recipients(db, "member", dave.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, recipients, "deactivated members are not told")
+
+		recipients, err = Recipients(db, "organization", OrganizationID)
+		assert.NoError(t, err)
+		assert.Empty(t, recipients)
+
+		recipients, err = Recipients(db, "invalid", 1)
+		assert.NoError(t, err)
+		assert.Empty(t, recipients)
+	})
+
+	t.Run("Models", func(t *testing.T) {
+		stored := Models()
+		assert.IsType(t, &models.Team{}, stored["team"])
+		assert.IsType(t, &models.TeamMember{}, stored["member"])
+		assert.IsType(t, &models.Meeting{}, stored["meeting"])
+		assert.NotContains(t, stored, "organization")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"coaching-backend/database"
 	"coaching-backend/models"
 	"encoding/json"
+	"fmt"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"net/http"
@@ -13,12 +14,12 @@ import (
 )
 
 func SetupTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{TranslateError: true})
 	if err != nil {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
 
-	err = db.AutoMigrate(&models.TeamMember{}, &models.Team{}, &models.Feedback{})
+	err = db.AutoMigrate(database.Models()...)
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -28,9 +29,17 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 }
 
 func CreateTestTeamMember(db *gorm.DB) *models.TeamMember {
+	var count int64
+	db.Model(&models.TeamMember{}).Count(&count)
+
+	email := "john@example.com"
+	if count > 0 {
+		email = fmt.Sprintf("john%d@example.com", count+1)
+	}
+
 	member := &models.TeamMember{
 		Name:    "John Doe",
-		Email:   "john@example.com",
+		Email:   email,
 		Picture: "https://example.com/john.jpg",
 	}
 	db.Create(member)
@@ -38,15 +47,23 @@ func CreateTestTeamMember(db *gorm.DB) *models.TeamMember {
 }
 
 func CreateTestTeam(db *gorm.DB) *models.Team {
+	var count int64
+	db.Model(&models.Team{}).Count(&count)
+
+	name := "Development Team"
+	if count > 0 {
+		name = fmt.Sprintf("Development Team %d", count+1)
+	}
+
 	team := &models.Team{
-		Name: "Development Team",
+		Name: name,
 		Logo: "https://example.com/logo.png",
 	}
 	db.Create(team)
 	return team
 }
 
-func CreateTestFeedback(db *gorm.DB, targetType string, targetID uint) *models.Feedback {
+func CreateTestFeedback(db *gorm.DB, targetType string, targetID uint32) *models.Feedback {
 	feedback := &models.Feedback{
 		Content:    "Great work!",
 		TargetType: targetType,
@@ -91,12 +108,12 @@ type TestTeamRequest struct {
 }
 
 type TestAssignRequest struct {
-	MemberID uint `json:"member_id"`
-	TeamID   uint `json:"team_id"`
+	MemberID uint32 `json:"member_id"`
+	TeamID   uint32 `json:"team_id"`
 }
 
 type TestFeedbackRequest struct {
 	Content    string `json:"content"`
 	TargetType string `json:"target_type"`
-	TargetID   uint   `json:"target_id"`
+	TargetID   uint32 `json:"target_id"`
 }
@@ -0,0 +1,323 @@
+// Package webhooks delivers domain events to subscribed URLs. The
+// Dispatcher is an outbox sink: every relayed event is queued in the
+// webhook_deliveries table, once per active webhook subscribed to its
+// type. A worker sends due deliveries and reschedules failed ones with
+// exponential backoff, so the queue survives restarts.
+package webhooks
+
+import (
+	"bytes"
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	EventHeader    = "X-Webhook-Event"
+	DeliveryHeader = "X-Webhook-Delivery"
+	// IdempotencyHeader carries the event key, which is the same for every
+	// delivery and redelivery of an event.
+	IdempotencyHeader = "Idempotency-Key"
+	userAgent         = "coaching-backend-webhooks/1.0"
+
+	// maxResponseBody is how much of a receiver's response is read, so
+	// that its connection can be reused. Responses are not stored.
+	maxResponseBody = 4096
+	// claimLease hides a delivery from other workers while it is sent. It
+	// must exceed the client timeout.
+	claimLease = time.Minute
+)
+
+type Config struct {
+	Client *http.Client
+	// PollInterval is how often the queue is checked for due deliveries.
+	PollInterval time.Duration
+	// MaxAttempts is how often a delivery is tried before it is marked
+	// failed.
+	MaxAttempts int
+	// The delay before retry n is BaseBackoff * 2^(n-1), capped at
+	// MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// DisableAfter consecutive failed attempts disable a webhook.
+	DisableAfter int
+	// BatchSize caps the deliveries sent per poll.
+	BatchSize int
+}
+
+// DefaultConfig retries a delivery for about two hours. Its client only
+// reaches public addresses.
+var DefaultConfig = Config{
+	Client:       NewClient(10 * time.Second),
+	PollInterval: time.Second,
+	MaxAttempts:  8,
+	BaseBackoff:  time.Minute,
+	MaxBackoff:   time.Hour,
+	DisableAfter: 20,
+	BatchSize:    20,
+}
+
+type Dispatcher struct {
+	db   *gorm.DB
+	cfg  Config
+	now  func() time.Time
+	wake chan struct{}
+}
+
+func New(db *gorm.DB, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		db:   db,
+		cfg:  cfg,
+		now:  func() time.Time { return time.Now().UTC() },
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Run sends due deliveries until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("webhooks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhooks"
+}
+
+// Publish queues e for every active webhook subscribed to its type.
+// Disabled webhooks miss the event. Webhooks that already have a delivery
+// for the event's key are skipped, so relaying an event twice queues it
+// once.
+func (d *Dispatcher) Publish(ctx context.Context, e events.Event) error {
+	var hooks []models.Webhook
+	if err := d.db.WithContext(ctx).Where("active = ?", true).Find(&hooks).Error; err != nil {
+		return err
+	}
+
+	queued := map[uint32]bool{}
+	if e.Key != "" {
+		var ids []uint32
+		err := d.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+			Where("event_key = ? AND redelivery_of IS NULL", e.Key).
+			Pluck("webhook_id", &ids).Error
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			queued[id] = true
+		}
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, hook := range hooks {
+		if queued[hook.ID] || !Subscribed(&hook, e.Type) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       e.ID,
+			EventKey:      e.Key,
+			EventType:     string(e.Type),
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: d.now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := d.db.WithContext(ctx).Create(&deliveries).Error; err != nil {
+		return err
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Subscribed reports whether hook receives events of type t.
+func Subscribed(hook *models.Webhook, t events.Type) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, name := range hook.Events {
+		if name == string(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliverDue sends every pending delivery of an active webhook whose next
+// attempt is due, and reports how many it sent.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	now := d.now()
+	active := d.db.Model(&models.Webhook{}).Select("id").Where("active = ?", true)
+
+	var due []models.WebhookDelivery
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ? AND webhook_id IN (?)", models.DeliveryPending, now, active).
+		Order("next_attempt_at").
+		Limit(d.cfg.BatchSize).
+		Find(&due).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load due deliveries: %w", err)
+	}
+
+	sent := 0
+	disabled := map[uint32]bool{}
+	for i := range due {
+		if disabled[due[i].WebhookID] {
+			continue
+		}
+		claimed, err := d.claim(ctx, &due[i], now)
+		if err != nil {
+			return sent, fmt.Errorf("failed to claim delivery %d: %w", due[i].ID, err)
+		}
+		if !claimed {
+			continue
+		}
+		stillActive, err := d.attempt(ctx, &due[i])
+		if err != nil {
+			return sent, fmt.Errorf("failed to record delivery %d: %w", due[i].ID, err)
+		}
+		if !stillActive {
+			disabled[due[i].WebhookID] = true
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// claim counts the attempt and pushes the next attempt past the lease, so
+// a concurrent worker that loaded the same row skips it.
+func (d *Dispatcher) claim(ctx context.Context, delivery *models.WebhookDelivery, now time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, models.DeliveryPending, delivery.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": now.Add(claimLease),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	delivery.Attempts++
+	return result.RowsAffected == 1, nil
+}
+
+// attempt sends the delivery, records the outcome and reports whether the
+// webhook is still active afterwards.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	var hook models.Webhook
+	if err := d.db.WithContext(ctx).First(&hook, delivery.WebhookID).Error; err != nil {
+		return false, err
+	}
+
+	code, sendErr := d.post(ctx, &hook, delivery)
+	if ctx.Err() != nil {
+		// Shutting down; the delivery is retried once the lease expires.
+		return true, nil
+	}
+
+	now := d.now()
+	delivery.ResponseCode = code
+	delivery.Error = ""
+	hookUpdates := map[string]interface{}{}
+
+	if sendErr == nil && code >= 200 && code < 300 {
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		hookUpdates["failures"] = 0
+	} else {
+		if sendErr != nil {
+			delivery.Error = sendErr.Error()
+		} else {
+			delivery.Error = fmt.Sprintf("receiver responded with status %d", code)
+		}
+		if delivery.Attempts >= d.cfg.MaxAttempts {
+			delivery.Status = models.DeliveryFailed
+		} else {
+			delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+		}
+
+		hookUpdates["failures"] = hook.Failures + 1
+		if hook.Failures+1 >= d.cfg.DisableAfter {
+			hook.Active = false
+			hookUpdates["active"] = false
+			hookUpdates["disabled_at"] = now
+			log.Printf("webhooks: disabled webhook %d after %d consecutive failures", hook.ID, hook.Failures+1)
+		}
+	}
+
+	if err := d.db.WithContext(ctx).Save(delivery).Error; err != nil {
+		return false, err
+	}
+	if err := d.db.WithContext(ctx).Model(&hook).Updates(hookUpdates).Error; err != nil {
+		return false, err
+	}
+	return hook.Active, nil
+}
+
+func (d *Dispatcher) post(ctx context.Context, hook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	if delivery.EventKey != "" {
+		req.Header.Set(IdempotencyHeader, delivery.EventKey)
+	}
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, d.now(), body))
+
+	resp, err := d.cfg.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,325 @@
+package webhooks
+
+import (
+	"coaching-backend/events"
+	"coaching-backend/models"
+	"coaching-backend/tests/testutils"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// receiver is a stand-in webhook endpoint that answers with the queued
+// status codes (200 once they run out) and records what it received.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.mu.Unlock()
+
+		w.WriteHeader(status)
+		w.Write([]byte("received"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+var testConfig = Config{
+	Client:       http.DefaultClient,
+	PollInterval: 10 * time.Millisecond,
+	MaxAttempts:  3,
+	BaseBackoff:  time.Minute,
+	MaxBackoff:   time.Hour,
+	DisableAfter: 5,
+	BatchSize:    10,
+}
+
+type clock struct{ now time.Time }
+
+func (c *clock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newDispatcher(db *gorm.DB, cfg Config) (*Dispatcher, *clock) {
+	d := New(db, cfg)
+	c := &clock{now: time.Now().UTC()}
+	d.now = func() time.Time { return c.now }
+	return d, c
+}
+
+func createWebhook(t *testing.T, db *gorm.DB, url string, types ...string) *models.Webhook {
+	hook := &models.Webhook{URL: url, Secret: "s3cret", Events: types, Active: true}
+	require.NoError(t, db.Create(hook).Error)
+	return hook
+}
+
+func deliveryFor(t *testing.T, db *gorm.DB, hook *models.Webhook) models.WebhookDelivery {
+	var delivery models.WebhookDelivery
+	require.NoError(t, db.Where("webhook_id = ?", hook.ID).Last(&delivery).Error)
+	return delivery
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("Delivers Signed Event", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		d, clock := newDispatcher(db, testConfig)
+		rcv := newReceiver(t)
+		hook := createWebhook(t, db, rcv.URL, string(events.FeedbackCreated))
+
+		event := events.Event{ID: 7, Type: events.FeedbackCreated, TargetType: "team", TargetID: 1}
+		require.NoError(t, d.Publish(context.Background(), event))
+		sent, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+
+		require.Equal(t, 1, rcv.count())
+		req, body := rcv.requests[0], rcv.bodies[0]
+		assert.Equal(t, "feedback.created", req.Header.Get(EventHeader))
+		assert.NotEmpty(t, req.Header.Get(DeliveryHeader))
+		assert.NoError(t, Verify("s3cret", req.Header.Get(SignatureHeader), body, 5*time.Minute, clock.now))
+
+		var payload events.Event
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, uint64(7), payload.ID)
+
+		delivery := deliveryFor(t, db, hook)
+		assert.Equal(t, models.DeliverySucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusOK, delivery.ResponseCode)
+		assert.NotNil(t, delivery.DeliveredAt)
+	})
+
+	t.Run("Refuses Private Receivers", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		rcv := newReceiver(t)
+		cfg := testConfig
+		cfg.Client = NewClient(time.Second)
+		d, _ := newDispatcher(db, cfg)
+		hook := createWebhook(t, db, rcv.URL)
+
+		require.NoError(t, d.Publish(context.Background(), events.Event{ID: 8, Type: events.FeedbackCreated}))
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+
+		assert.Zero(t, rcv.count(), "the loopback receiver is never reached")
+		delivery := deliveryFor(t, db, hook)
+		assert.Equal(t, models.DeliveryPending, delivery.Status)
+		assert.Contains(t, delivery.Error, ErrBlockedAddress.Error())
+	})
+
+	t.Run("Only Queues Subscribed Types For Active Webhooks", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		d, _ := newDispatcher(db, testConfig)
+		feedbackOnly := createWebhook(t, db, "http://example.com/feedback", string(events.FeedbackCreated))
+		everything := createWebhook(t, db, "http://example.com/all")
+		disabled := createWebhook(t, db, "http://example.com/disabled")
+		require.NoError(t, db.Model(disabled).Update("active", false).Error)
+
+		require.NoError(t, d.Publish(context.Background(), events.Event{ID: 1, Type: events.MemberAssigned}))
+
+		var queued []models.WebhookDelivery
+		require.NoError(t, db.Find(&queued).Error)
+		require.Len(t, queued, 1)
+		assert.Equal(t, everything.ID, queued[0].WebhookID)
+		assert.NotEqual(t, feedbackOnly.ID, queued[0].WebhookID)
+	})
+
+	t.Run("Retries With Exponential Backoff", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		d, clock := newDispatcher(db, testConfig)
+		rcv := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+		hook := createWebhook(t, db, rcv.URL)
+		require.NoError(t, d.Publish(context.Background(), events.Event{ID: 1, Type: events.FeedbackCreated}))
+
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		delivery := deliveryFor(t, db, hook)
+		assert.Equal(t, models.DeliveryPending, delivery.Status)
+		assert.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+		assert.Equal(t, "receiver responded with status 500", delivery.Error)
+		assert.WithinDuration(t, clock.now.Add(time.Minute), delivery.NextAttemptAt, time.Second)
+
+		// Not due yet.
+		sent, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+
+		clock.advance(time.Minute)
+		_, err = d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		delivery = deliveryFor(t, db, hook)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.WithinDuration(t, clock.now.Add(2*time.Minute), delivery.NextAttemptAt, time.Second)
+
+		clock.advance(2 * time.Minute)
+		_, err = d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		delivery = deliveryFor(t, db, hook)
+		assert.Equal(t, models.DeliverySucceeded, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Empty(t, delivery.Error)
+
+		var stored models.Webhook
+		db.First(&stored, hook.ID)
+		assert.Zero(t, stored.Failures, "a success resets the failure count")
+	})
+
+	t.Run("Gives Up After Max Attempts", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		d, clock := newDispatcher(db, testConfig)
+		hook := createWebhook(t, db, "http://127.0.0.1:1/unreachable")
+		require.NoError(t, d.Publish(context.Background(), events.Event{ID: 1, Type: events.FeedbackCreated}))
+
+		for i := 0; i < testConfig.MaxAttempts; i++ {
+			_, err := d.DeliverDue(context.Background())
+			require.NoError(t, err)
+			clock.advance(time.Hour)
+		}
+
+		delivery := deliveryFor(t, db, hook)
+		assert.Equal(t, models.DeliveryFailed, delivery.Status)
+		assert.Equal(t, testConfig.MaxAttempts, delivery.Attempts)
+		assert.Zero(t, delivery.ResponseCode)
+		assert.Contains(t, delivery.Error, "connection refused")
+
+		sent, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+	})
+
+	t.Run("Disables Webhook After Repeated Failures", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		d, _ := newDispatcher(db, testConfig)
+		rcv := newReceiver(t, 500, 500, 500, 500, 500, 500)
+		hook := createWebhook(t, db, rcv.URL)
+
+		for i := 0; i < testConfig.DisableAfter+1; i++ {
+			require.NoError(t, d.Publish(context.Background(), events.Event{ID: uint64(i + 1), Type: events.FeedbackCreated}))
+		}
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, testConfig.DisableAfter, rcv.count(), "deliveries stop once the webhook is disabled")
+
+		var stored models.Webhook
+		db.First(&stored, hook.ID)
+		assert.False(t, stored.Active)
+		assert.Equal(t, testConfig.DisableAfter, stored.Failures)
+		assert.NotNil(t, stored.DisabledAt)
+	})
+
+	t.Run("Run Delivers Published Events", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		d, _ := newDispatcher(db, testConfig)
+		d.now = func() time.Time { return time.Now().UTC() }
+		rcv := newReceiver(t)
+		createWebhook(t, db, rcv.URL)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go d.Run(ctx)
+
+		require.NoError(t, d.Publish(ctx, events.Event{ID: 1, Key: "evt_1", Type: events.MemberCreated}))
+		assert.Eventually(t, func() bool { return rcv.count() == 1 }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Queues Each Event Key Once", func(t *testing.T) {
+		db := testutils.SetupTestDB(t)
+		d, _ := newDispatcher(db, testConfig)
+		rcv := newReceiver(t)
+		hook := createWebhook(t, db, rcv.URL)
+
+		event := events.Event{ID: 3, Key: "evt_3", Type: events.FeedbackDeleted}
+		require.NoError(t, d.Publish(context.Background(), event))
+		require.NoError(t, d.Publish(context.Background(), event))
+
+		var count int64
+		db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID).Count(&count)
+		assert.Equal(t, int64(1), count)
+
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, rcv.count())
+		assert.Equal(t, "evt_3", rcv.requests[0].Header.Get(IdempotencyHeader))
+	})
+}
+
+func TestBlocked(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "100.64.0.1", "0.0.0.0", "::", "224.0.0.1", "::ffff:127.0.0.1"} {
+		assert.True(t, Blocked(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{"93.184.216.34", "8.8.8.8", "2606:4700::1111"} {
+		assert.False(t, Blocked(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := New(nil, Config{BaseBackoff: time.Minute, MaxBackoff: 10 * time.Minute})
+
+	assert.Equal(t, time.Minute, d.backoff(1))
+	assert.Equal(t, 2*time.Minute, d.backoff(2))
+	assert.Equal(t, 8*time.Minute, d.backoff(4))
+	assert.Equal(t, 10*time.Minute, d.backoff(5))
+	assert.Equal(t, 10*time.Minute, d.backoff(50))
+}
+
+func TestSignature(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"id":1}`)
+	header := Sign("secret", now, body)
+
+	t.Run("Valid Signature", func(t *testing.T) {
+		assert.NoError(t, Verify("secret", header, body, time.Minute, now))
+	})
+
+	t.Run("Tampered Body", func(t *testing.T) {
+		assert.ErrorIs(t, Verify("secret", header, []byte(`{"id":2}`), time.Minute, now), ErrSignatureMismatch)
+	})
+
+	t.Run("Wrong Secret", func(t *testing.T) {
+		assert.ErrorIs(t, Verify("other", header, body, time.Minute, now), ErrSignatureMismatch)
+	})
+
+	t.Run("Expired Timestamp", func(t *testing.T) {
+		assert.ErrorIs(t, Verify("secret", header, body, time.Minute, now.Add(2*time.Minute)), ErrSignatureExpired)
+	})
+
+	t.Run("Malformed Header", func(t *testing.T) {
+		assert.ErrorIs(t, Verify("secret", "sha256=abc", body, time.Minute, now), ErrMalformedSignature)
+	})
+
+	t.Run("Generated Secrets Differ", func(t *testing.T) {
+		assert.NotEqual(t, NewSecret(), NewSecret())
+	})
+}
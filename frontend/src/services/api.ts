@@ -1,9 +1,40 @@
-import { TeamMember, Team, Feedback } from '../types';
+import { TeamMember, Team, Feedback, FeedbackTargetTypeInfo } from '../types';
 
 const API_BASE_URL = process.env.NODE_ENV === 'production' 
   ? '/api' 
   : 'http://localhost:8080/api';
 
+export interface ProblemFieldError {
+  field: string;
+  rule: string;
+  message: string;
+}
+
+export interface Problem {
+  type: string;
+  title: string;
+  status: number;
+  detail?: string;
+  instance?: string;
+  code: string;
+  trace_id: string;
+  errors?: ProblemFieldError[];
+}
+
+export class ApiError extends Error {
+  constructor(public status: number, statusText: string, public problem?: Problem) {
+    super(problem?.detail ?? `API Error: ${status} ${statusText}`);
+    this.name = 'ApiError';
+  }
+
+  static async fromResponse(response: Response): Promise<ApiError> {
+    if (response.headers?.get('Content-Type')?.includes('application/problem+json')) {
+      return new ApiError(response.status, response.statusText, await response.json());
+    }
+    return new ApiError(response.status, response.statusText);
+  }
+}
+
 class ApiService {
   private async request<T>(endpoint: string, options?: RequestInit): Promise<T> {
     const url = `${API_BASE_URL}${endpoint}`;
@@ -18,7 +49,7 @@ class ApiService {
     const response = await fetch(url, config);
     
     if (!response.ok) {
-      throw new Error(`API Error: ${response.status} ${response.statusText}`);
+      throw await ApiError.fromResponse(response);
     }
 
     return response.json();
@@ -109,6 +140,10 @@ class ApiService {
     return this.request<Feedback[]>(endpoint);
   }
 
+  async getFeedbackTargetTypes(): Promise<FeedbackTargetTypeInfo[]> {
+    return this.request<FeedbackTargetTypeInfo[]>('/feedback/target-types');
+  }
+
   async createFeedback(feedback: { content: string; target_type: string; target_id: number }): Promise<Feedback> {
     return this.request<Feedback>('/feedback', {
       method: 'POST',
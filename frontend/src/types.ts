@@ -7,6 +7,7 @@ export interface TeamMember {
   team?: Team;
   created_at: string;
   updated_at: string;
+  deactivated_at?: string;
 }
 
 export interface Team {
@@ -18,10 +19,19 @@ export interface Team {
   updated_at: string;
 }
 
+// Target types are registered by the backend, which lists them at
+// GET /feedback/target-types, so any string it returns is valid.
+export type FeedbackTargetType = string;
+
+export interface FeedbackTargetTypeInfo {
+  type: FeedbackTargetType;
+  label: string;
+}
+
 export interface Feedback {
   id: number;
   content: string;
-  target_type: 'team' | 'member';
+  target_type: FeedbackTargetType;
   target_id: number;
   target_name: string;
   created_at: string;